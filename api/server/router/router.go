@@ -2,9 +2,13 @@ package router
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
+	"sync"
+	"time"
 
 	"github.com/docker/docker/api/server/httputils"
+	"github.com/maliceio/malice/plugins"
 )
 
 // Router defines an interface to specify a group of routes to add to the docker server.
@@ -23,16 +27,19 @@ type Route interface {
 	Path() string
 }
 
-// GetRoutes returns all API routes as a map for the server
-func GetRoutes() (map[string]http.HandlerFunc, error) {
+// HealthCheck reports whether a service the API depends on is reachable
+type HealthCheck func(ctx context.Context) error
+
+// healthTimeout bounds how long /health waits for the checks
+const healthTimeout = 5 * time.Second
+
+// GetRoutes returns all API routes as a map for the server, /health runs the
+// checks by the name of the service they check, e.g. docker or database
+func GetRoutes(checks map[string]HealthCheck) (map[string]http.HandlerFunc, error) {
 	routes := make(map[string]http.HandlerFunc)
 
 	// Health check endpoint
-	routes["/health"] = func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		w.Write([]byte(`{"status":"ok","version":"0.4.0"}`))
-	}
+	routes["/health"] = healthHandler(checks)
 
 	// Info endpoint
 	routes["/info"] = func(w http.ResponseWriter, r *http.Request) {
@@ -41,6 +48,16 @@ func GetRoutes() (map[string]http.HandlerFunc, error) {
 		w.Write([]byte(`{"name":"Malice","version":"0.4.0","description":"Open Source Malware Analysis Framework"}`))
 	}
 
+	// Scan profiles endpoint
+	routes["/profiles"] = func(w http.ResponseWriter, r *http.Request) {
+		profiles, err := plugins.Profiles()
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"profiles": profiles})
+	}
+
 	// Scan endpoint (placeholder)
 	routes["/scan"] = func(w http.ResponseWriter, r *http.Request) {
 		switch r.Method {
@@ -66,3 +83,51 @@ func GetRoutes() (map[string]http.HandlerFunc, error) {
 
 	return routes, nil
 }
+
+// checkStatus is the outcome of a HealthCheck
+type checkStatus struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// healthHandler runs the checks concurrently, so one hanging service doesn't
+// hide the state of the others, and answers 503 unless all of them pass
+func healthHandler(checks map[string]HealthCheck) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), healthTimeout)
+		defer cancel()
+
+		var mu sync.Mutex
+		var wg sync.WaitGroup
+		statuses := make(map[string]checkStatus, len(checks))
+		for name, check := range checks {
+			wg.Add(1)
+			go func(name string, check HealthCheck) {
+				defer wg.Done()
+				status := checkStatus{Status: "ok"}
+				if err := check(ctx); err != nil {
+					status = checkStatus{Status: "unavailable", Error: err.Error()}
+				}
+				mu.Lock()
+				defer mu.Unlock()
+				statuses[name] = status
+			}(name, check)
+		}
+		wg.Wait()
+
+		status, code := "ok", http.StatusOK
+		for _, check := range statuses {
+			if check.Status != "ok" {
+				status, code = "unavailable", http.StatusServiceUnavailable
+			}
+		}
+		writeJSON(w, code, map[string]interface{}{"status": status, "version": "0.4.0", "checks": statuses})
+	}
+}
+
+// writeJSON writes v as the JSON response body
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,75 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHealth(t *testing.T) {
+	up := func(ctx context.Context) error { return nil }
+	down := func(ctx context.Context) error { return errors.New("unable to connect to docker") }
+
+	tests := []struct {
+		name       string
+		checks     map[string]HealthCheck
+		wantCode   int
+		wantStatus string
+		wantChecks map[string]string
+	}{
+		{
+			name:       "healthy",
+			checks:     map[string]HealthCheck{"docker": up, "database": up},
+			wantCode:   http.StatusOK,
+			wantStatus: "ok",
+			wantChecks: map[string]string{"docker": "ok", "database": "ok"},
+		},
+		{
+			name:       "docker down",
+			checks:     map[string]HealthCheck{"docker": down, "database": up},
+			wantCode:   http.StatusServiceUnavailable,
+			wantStatus: "unavailable",
+			wantChecks: map[string]string{"docker": "unavailable", "database": "ok"},
+		},
+		{
+			name:       "no checks",
+			wantCode:   http.StatusOK,
+			wantStatus: "ok",
+			wantChecks: map[string]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			routes, err := GetRoutes(tt.checks)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp := httptest.NewRecorder()
+			routes["/health"](resp, httptest.NewRequest("GET", "/health", nil))
+
+			var body struct {
+				Status string                 `json:"status"`
+				Checks map[string]checkStatus `json:"checks"`
+			}
+			if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+				t.Fatal(err)
+			}
+			if resp.Code != tt.wantCode || body.Status != tt.wantStatus {
+				t.Errorf("/health = %d %s, want %d %s", resp.Code, body.Status, tt.wantCode, tt.wantStatus)
+			}
+			if len(body.Checks) != len(tt.wantChecks) {
+				t.Errorf("/health checks = %v, want %v", body.Checks, tt.wantChecks)
+			}
+			for name, want := range tt.wantChecks {
+				check := body.Checks[name]
+				if check.Status != want || (want != "ok") != (check.Error != "") {
+					t.Errorf("/health check %s = %+v, want %s", name, check, want)
+				}
+			}
+		})
+	}
+}
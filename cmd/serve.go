@@ -23,9 +23,14 @@ import (
 	"syscall"
 
 	log "github.com/Sirupsen/logrus"
+	"github.com/malice-plugins/pkgs/database/elasticsearch"
+	"github.com/malice-plugins/pkgs/utils"
 	"github.com/spf13/cobra"
 	"github.com/maliceio/malice/api/server"
 	"github.com/maliceio/malice/api/server/router"
+	"github.com/maliceio/malice/config"
+	"github.com/maliceio/malice/malice/database"
+	"github.com/maliceio/malice/malice/docker/client"
 )
 
 // serveCmd represents the serve command
@@ -57,8 +62,25 @@ func runServe() error {
 	srv := server.New(cfg)
 	defer srv.Close()
 
+	// The daemon may be down or restart while the server runs, /health
+	// reports it and the client reconnects once it is back
+	docker, err := client.NewDockerClient()
+	if err != nil {
+		return fmt.Errorf("failed to create docker client: %w", err)
+	}
+	es := elasticsearch.Database{
+		URL:      utils.Getopt("MALICE_ELASTICSEARCH_URL", config.Conf.DB.URL),
+		Username: utils.Getopt("MALICE_ELASTICSEARCH_USERNAME", config.Conf.DB.Username),
+		Password: utils.Getopt("MALICE_ELASTICSEARCH_PASSWORD", config.Conf.DB.Password),
+	}
+
 	// Initialize routes
-	routes, err := router.GetRoutes()
+	routes, err := router.GetRoutes(map[string]router.HealthCheck{
+		"docker": docker.Health,
+		"database": func(ctx context.Context) error {
+			return database.Ping(ctx, es)
+		},
+	})
 	if err != nil {
 		return fmt.Errorf("failed to get routes: %w", err)
 	}
@@ -0,0 +1,255 @@
+package commands
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/maliceio/malice/plugins"
+	"github.com/maliceio/malice/utils/clitable"
+	"github.com/pkg/errors"
+)
+
+// defaultBatchConcurrency is the number of samples scanned at the same time
+const defaultBatchConcurrency = 4
+
+// batchOptions controls which paths a batch scan picks up
+type batchOptions struct {
+	recursive bool
+	// fromFile is a file listing one path per line
+	fromFile string
+	include  []string
+	exclude  []string
+	// concurrency bounds the number of samples scanned at once, plugin
+	// containers are still bounded globally by the plugin concurrency
+	concurrency int
+}
+
+// batchResult is the outcome of scanning one sample in a batch
+type batchResult struct {
+	path    string
+	results []plugins.Result
+	err     error
+}
+
+// isBatchScan returns true if the scan arguments can't be handled as a single
+// file, a single file is filtered by --include and --exclude in a batch too
+func isBatchScan(args []string, bopts batchOptions) bool {
+	if len(args) == 0 {
+		return bopts.fromFile != ""
+	}
+	if bopts.fromFile != "" || len(args) > 1 || args[0] == "-" {
+		return true
+	}
+	if len(bopts.include) > 0 || len(bopts.exclude) > 0 {
+		return true
+	}
+	info, err := os.Stat(args[0])
+	return err == nil && info.IsDir()
+}
+
+// cmdBatchScan scans every file found from args, stdin and the list file
+func cmdBatchScan(args []string, opts scanOptions, bopts batchOptions) error {
+	paths, err := collectScanPaths(args, os.Stdin, bopts)
+	if err != nil {
+		return err
+	}
+	if len(paths) == 0 {
+		return fmt.Errorf("no files to scan")
+	}
+	log.WithField("files", len(paths)).Info("batch scan starting")
+
+	s, err := newScanner(opts)
+	if err != nil {
+		return err
+	}
+	s.quiet = true
+
+	concurrency := bopts.concurrency
+	if concurrency < 1 {
+		concurrency = defaultBatchConcurrency
+	}
+	files := make(chan struct{}, concurrency)
+
+	var wg sync.WaitGroup
+	batch := make([]batchResult, len(paths))
+	for i, path := range paths {
+		wg.Add(1)
+		files <- struct{}{}
+		go func(i int, path string) {
+			defer wg.Done()
+			defer func() { <-files }()
+
+			ctx, cancel := context.WithTimeout(context.Background(), opts.scanTimeout())
+			defer cancel()
+
+			results, err := s.scanFile(ctx, path)
+			if err != nil {
+				log.WithError(err).WithField("file", path).Error("scan failed")
+			}
+			batch[i] = batchResult{path: path, results: results, err: err}
+		}(i, path)
+	}
+	wg.Wait()
+
+	failed := printBatchSummary(batch)
+	if failed > 0 {
+		return fmt.Errorf("%d of %d files failed to scan", failed, len(paths))
+	}
+	return nil
+}
+
+// collectScanPaths expands directories, "-" (paths on stdin) and the list
+// file into the de-duplicated list of files to scan
+func collectScanPaths(args []string, stdin io.Reader, bopts batchOptions) ([]string, error) {
+	var candidates []string
+
+	for _, arg := range args {
+		if arg == "-" {
+			lines, err := readPathList(stdin)
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to read paths from stdin")
+			}
+			candidates = append(candidates, lines...)
+			continue
+		}
+		candidates = append(candidates, arg)
+	}
+
+	if bopts.fromFile != "" {
+		f, err := os.Open(bopts.fromFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to open file list")
+		}
+		lines, err := readPathList(f)
+		f.Close()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read file list")
+		}
+		candidates = append(candidates, lines...)
+	}
+
+	seen := make(map[string]bool)
+	var paths []string
+	add := func(path string) {
+		abs, err := filepath.Abs(path)
+		if err != nil || seen[abs] || !matchesGlobs(path, bopts.include, bopts.exclude) {
+			return
+		}
+		if err := validateAndNormalizePath(abs); err != nil {
+			log.WithError(err).Warn("skipping file")
+			return
+		}
+		seen[abs] = true
+		paths = append(paths, abs)
+	}
+
+	for _, candidate := range candidates {
+		info, err := os.Stat(candidate)
+		if err != nil {
+			log.WithError(err).Warnf("skipping %s", candidate)
+			continue
+		}
+		if !info.IsDir() {
+			add(candidate)
+			continue
+		}
+		err = filepath.Walk(candidate, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				log.WithError(err).Warnf("skipping %s", path)
+				return nil
+			}
+			if info.IsDir() {
+				if path != candidate && !bopts.recursive {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if info.Mode().IsRegular() {
+				add(path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return paths, nil
+}
+
+// readPathList reads one path per line, skipping blank lines and # comments
+func readPathList(r io.Reader) ([]string, error) {
+	var paths []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		paths = append(paths, line)
+	}
+	return paths, scanner.Err()
+}
+
+// matchesGlobs returns true if the file's name or path matches one of the
+// include globs (or there are none) and none of the exclude globs
+func matchesGlobs(path string, include, exclude []string) bool {
+	match := func(pattern string) bool {
+		if ok, _ := filepath.Match(pattern, filepath.Base(path)); ok {
+			return true
+		}
+		ok, _ := filepath.Match(pattern, path)
+		return ok
+	}
+	for _, pattern := range exclude {
+		if match(pattern) {
+			return false
+		}
+	}
+	if len(include) == 0 {
+		return true
+	}
+	for _, pattern := range include {
+		if match(pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// printBatchSummary prints one row per scanned file and returns the number of failed scans
+func printBatchSummary(batch []batchResult) int {
+	failed := 0
+	fmt.Println("#### Batch Summary")
+	table := clitable.New([]string{"File", "Plugins", "Cached", "Invalid", "Errors", "Status"})
+	for _, file := range batch {
+		counts := map[string]int{}
+		for _, result := range file.results {
+			counts[resultStatus(result)]++
+		}
+		status := "ok"
+		if file.err != nil {
+			status = "failed"
+			failed++
+		}
+		table.AddRow(map[string]interface{}{
+			"File":    file.path,
+			"Plugins": len(file.results),
+			"Cached":  counts["cached"],
+			"Invalid": counts["invalid"],
+			"Errors":  counts["error"],
+			"Status":  status,
+		})
+	}
+	table.Markdown = true
+	table.Print()
+	fmt.Printf("Scanned %d files, %d failed\n", len(batch), failed)
+	return failed
+}
@@ -0,0 +1,136 @@
+package commands
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMatchesGlobs(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		include []string
+		exclude []string
+		want    bool
+	}{
+		{name: "no filters", path: "/samples/a.exe", want: true},
+		{name: "include by name", path: "/samples/a.exe", include: []string{"*.exe"}, want: true},
+		{name: "not included", path: "/samples/a.dll", include: []string{"*.exe"}, want: false},
+		{name: "exclude wins", path: "/samples/a.exe", include: []string{"*.exe"}, exclude: []string{"a.*"}, want: false},
+		{name: "exclude by path", path: "/samples/tmp/a.exe", exclude: []string{"/samples/tmp/*"}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matchesGlobs(tt.path, tt.include, tt.exclude); got != tt.want {
+				t.Errorf("matchesGlobs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsBatchScan(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "a.exe")
+	if err := ioutil.WriteFile(file, []byte("a.exe"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		args  []string
+		bopts batchOptions
+		want  bool
+	}{
+		{name: "single file", args: []string{file}, want: false},
+		{name: "directory", args: []string{dir}, want: true},
+		{name: "stdin", args: []string{"-"}, want: true},
+		{name: "list file", bopts: batchOptions{fromFile: "list.txt"}, want: true},
+		{name: "single file with include", args: []string{file}, bopts: batchOptions{include: []string{"*.dll"}}, want: true},
+		{name: "single file with exclude", args: []string{file}, bopts: batchOptions{exclude: []string{"*.exe"}}, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isBatchScan(tt.args, tt.bopts); got != tt.want {
+				t.Errorf("isBatchScan() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCollectScanPaths(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.exe", "b.dll", filepath.Join("sub", "c.exe")} {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	list := filepath.Join(dir, "list.txt")
+	listing := "# samples\n" + filepath.Join(dir, "a.exe") + "\n\n" + filepath.Join(dir, "sub", "c.exe") + "\n"
+	if err := ioutil.WriteFile(list, []byte(listing), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		args  []string
+		stdin string
+		bopts batchOptions
+		want  []string
+	}{
+		{
+			name:  "directory",
+			args:  []string{dir},
+			bopts: batchOptions{include: []string{"*.exe"}},
+			want:  []string{"a.exe"},
+		},
+		{
+			name:  "recursive directory",
+			args:  []string{dir},
+			bopts: batchOptions{recursive: true, exclude: []string{"*.txt"}},
+			want:  []string{"a.exe", "b.dll", filepath.Join("sub", "c.exe")},
+		},
+		{
+			name:  "stdin and duplicates",
+			args:  []string{"-", filepath.Join(dir, "a.exe")},
+			stdin: filepath.Join(dir, "b.dll") + "\n" + filepath.Join(dir, "a.exe") + "\n",
+			want:  []string{"b.dll", "a.exe"},
+		},
+		{
+			name:  "file list",
+			bopts: batchOptions{fromFile: list},
+			want:  []string{"a.exe", filepath.Join("sub", "c.exe")},
+		},
+		{
+			name: "missing file is skipped",
+			args: []string{filepath.Join(dir, "missing.exe"), filepath.Join(dir, "b.dll")},
+			want: []string{"b.dll"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			paths, err := collectScanPaths(tt.args, strings.NewReader(tt.stdin), tt.bopts)
+			if err != nil {
+				t.Fatalf("collectScanPaths() error = %v", err)
+			}
+			var got []string
+			for _, path := range paths {
+				rel, _ := filepath.Rel(dir, path)
+				got = append(got, rel)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("collectScanPaths() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"os"
 
 	log "github.com/Sirupsen/logrus"
+	"github.com/maliceio/malice/plugins"
 	"github.com/urfave/cli/v2"
 )
 
@@ -15,14 +16,70 @@ var Commands = []*cli.Command{
 	{
 		Name:        "scan",
 		Usage:       "Scan a file",
-		Description: "File to be scanned.",
+		Description: "File(s) to be scanned, a directory, or - to read paths from stdin.",
 		Flags: []cli.Flag{
 			&cli.BoolFlag{
 				Name:  "logs",
 				Usage: "Display the Logs of the Plugin containers",
 			},
+			&cli.BoolFlag{
+				Name:  "force",
+				Usage: "Ignore cached results and re-run every plugin",
+			},
+			&cli.DurationFlag{
+				Name:  "rescan-older-than",
+				Usage: "Re-run plugins whose cached results are older than `DURATION` (overrides the configured cache ttl)",
+			},
+			&cli.BoolFlag{
+				Name:    "recursive",
+				Aliases: []string{"r"},
+				Usage:   "Scan files in sub-directories of a directory",
+			},
+			&cli.StringFlag{
+				Name:  "from-file",
+				Usage: "Scan the files listed one per line in `FILE`",
+			},
+			&cli.StringSliceFlag{
+				Name:  "include",
+				Usage: "Only scan files matching `GLOB` (may be repeated)",
+			},
+			&cli.StringSliceFlag{
+				Name:  "exclude",
+				Usage: "Skip files matching `GLOB` (may be repeated)",
+			},
+			&cli.IntFlag{
+				Name:  "concurrency",
+				Value: defaultBatchConcurrency,
+				Usage: "Number of files to scan at the same time",
+			},
+			&cli.StringFlag{
+				Name:  "profile",
+				Usage: "Scan with the plugins and limits of the config profile `NAME` instead of the enabled plugins",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			profile, err := plugins.GetProfile(c.String("profile"))
+			if err != nil {
+				return err
+			}
+			opts := scanOptions{
+				logs:            c.Bool("logs"),
+				force:           c.Bool("force"),
+				rescanOlderThan: c.Duration("rescan-older-than"),
+				profile:         profile,
+			}
+			bopts := batchOptions{
+				recursive:   c.Bool("recursive"),
+				fromFile:    c.String("from-file"),
+				include:     c.StringSlice("include"),
+				exclude:     c.StringSlice("exclude"),
+				concurrency: c.Int("concurrency"),
+			}
+			if isBatchScan(c.Args().Slice(), bopts) {
+				return cmdBatchScan(c.Args().Slice(), opts, bopts)
+			}
+			return cmdScan(c.Args().First(), opts)
 		},
-		Action: func(c *cli.Context) error { return cmdScan(c.Args().First(), c.Bool("logs")) },
 	},
 	{
 		Name:        "watch",
@@ -33,25 +90,52 @@ var Commands = []*cli.Command{
 				Name:  "logs",
 				Usage: "Display the Logs of the Plugin containers",
 			},
+			&cli.BoolFlag{
+				Name:  "warm",
+				Usage: "Keep plugin containers running between samples",
+			},
 		},
-		Action: func(c *cli.Context) error { return cmdWatch(c.Args().First(), c.Bool("logs")) },
+		Action: func(c *cli.Context) error { return cmdWatch(c.Args().First(), c.Bool("logs"), c.Bool("warm")) },
 	},
 	{
 		Name:      "lookup",
-		Usage:     "Look up a file hash (md5/sha1)",
-		ArgsUsage: "hash of file to lookup `HASH`",
+		Usage:     "Look up a file hash (md5/sha1/sha256)",
+		ArgsUsage: "hash(es) of file to lookup `HASH`..., or - to read hashes from stdin",
 		// Description: "Hash to be queried.",
 		Flags: []cli.Flag{
 			&cli.BoolFlag{
 				Name:  "logs",
 				Usage: "Display the Logs of the Plugin containers",
 			},
+			&cli.StringFlag{
+				Name:    "file",
+				Aliases: []string{"f"},
+				Usage:   "Look up the hashes in `FILE` (hash list, md5sum output or CSV IOC feed, - for stdin)",
+			},
+			&cli.IntFlag{
+				Name:  "batch-size",
+				Value: defaultLookupBatchSize,
+				Usage: "Number of hashes to look up at the same time",
+			},
+			&cli.BoolFlag{
+				Name:  "json",
+				Usage: "Print the consolidated results as JSON",
+			},
 		},
 		Action: func(c *cli.Context) error {
+			lopts := lookupOptions{
+				logs:      c.Bool("logs"),
+				file:      c.String("file"),
+				batchSize: c.Int("batch-size"),
+				json:      c.Bool("json"),
+			}
+			if isBulkLookUp(c.Args().Slice(), lopts) {
+				return cmdBulkLookUp(c.Args().Slice(), lopts)
+			}
 			if c.Args().Len() > 0 {
 				return cmdLookUp(c.Args().First(), c.Bool("logs"))
 			}
-			log.Error("Please supply a MD5/SHA1 hash to query.")
+			log.Error("Please supply a MD5/SHA1/SHA256 hash to query.")
 
 			return nil
 		},
@@ -68,6 +152,109 @@ var Commands = []*cli.Command{
 		},
 		Action: func(c *cli.Context) error { return cmdELK(c.Bool("logs")) },
 	},
+	{
+		Name:        "gc",
+		Usage:       "Remove containers left behind by dead scans",
+		Description: "Only containers started on this host by a malice process that is no longer running are removed.",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "dry-run",
+				Usage: "List the orphaned containers without removing them",
+			},
+		},
+		Action: func(c *cli.Context) error { return cmdGC(c.Bool("dry-run")) },
+	},
+	{
+		Name:  "images",
+		Usage: "Pull, Inspect or Prune plugin images",
+		Subcommands: []*cli.Command{
+			{
+				Name:  "status",
+				Usage: "list plugin images and their size",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "all",
+						Usage: "list all plugins, not only enabled ones",
+					},
+				},
+				Action: func(c *cli.Context) error { return cmdImagesStatus(c.Bool("all")) },
+			},
+			{
+				Name:        "pull",
+				Usage:       "pull plugin images in parallel",
+				Description: "Pulls the database image and the plugin images and records the plugins' digests in plugins.lock.",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "all",
+						Usage: "pull all plugins, not only enabled ones",
+					},
+					&cli.BoolFlag{
+						Name:  "locked",
+						Usage: "pull the digests recorded in plugins.lock",
+					},
+					&cli.IntFlag{
+						Name:    "concurrency",
+						Aliases: []string{"c"},
+						Value:   plugins.DefaultPullConcurrency,
+						Usage:   "number of images pulled at once",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					return cmdImagesPull(c.Bool("all"), c.Bool("locked"), c.Int("concurrency"))
+				},
+			},
+			{
+				Name:        "prune",
+				Usage:       "remove stale plugin images and stopped malice containers",
+				Description: "Removes plugin images that lost their tag to a newer pull or that no plugin runs since its version or digest changed, and the stopped containers of scans whose malice process died.",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "dry-run",
+						Usage: "List what would be removed without removing it",
+					},
+				},
+				Action: func(c *cli.Context) error { return cmdImagesPrune(c.Bool("dry-run")) },
+			},
+		},
+	},
+	{
+		Name:  "host",
+		Usage: "Create, List or Remove analysis hosts",
+		Subcommands: []*cli.Command{
+			{
+				Name:        "create",
+				Usage:       "provision a host with a driver",
+				Description: "The generic driver uses an existing Linux host reached over ssh and makes sure docker runs on it.",
+				ArgsUsage:   "NAME",
+				Flags:       hostCreateFlags(),
+				Action:      cmdHostCreate,
+			},
+			{
+				Name:   "ls",
+				Usage:  "list hosts and their state",
+				Action: func(c *cli.Context) error { return cmdHostList() },
+			},
+			{
+				Name:      "rm",
+				Usage:     "remove a host",
+				ArgsUsage: "NAME",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:    "force",
+						Aliases: []string{"f"},
+						Usage:   "Forget the host even if the driver fails to remove it",
+					},
+				},
+				Action: func(c *cli.Context) error { return cmdHostRemove(c.Args().First(), c.Bool("force")) },
+			},
+			{
+				Name:      "env",
+				Usage:     "print the commands pointing docker at a host",
+				ArgsUsage: "NAME",
+				Action:    func(c *cli.Context) error { return cmdHostEnv(c.Args().First()) },
+			},
+		},
+	},
 	// {
 	// 	Name:  "web",
 	// 	Usage: "Start, Stop Web services",
@@ -93,6 +280,35 @@ var Commands = []*cli.Command{
 	// 		}
 	// 	},
 	// },
+	{
+		Name:  "secret",
+		Usage: "Manage secrets used by plugins",
+		Subcommands: []*cli.Command{
+			{
+				Name:      "set",
+				Usage:     "store a secret, the value is read from stdin",
+				ArgsUsage: "NAME",
+				Action:    func(c *cli.Context) error { return cmdSecretSet(c.Args().First()) },
+			},
+			{
+				Name:      "get",
+				Usage:     "print a secret",
+				ArgsUsage: "NAME",
+				Action:    func(c *cli.Context) error { return cmdSecretGet(c.Args().First()) },
+			},
+			{
+				Name:      "rm",
+				Usage:     "remove a secret",
+				ArgsUsage: "NAME",
+				Action:    func(c *cli.Context) error { return cmdSecretRemove(c.Args().First()) },
+			},
+			{
+				Name:   "ls",
+				Usage:  "list stored secrets",
+				Action: func(c *cli.Context) error { return cmdSecretList() },
+			},
+		},
+	},
 	{
 		Name:  "plugin",
 		Usage: "List, Install or Remove Plugins",
@@ -113,15 +329,119 @@ var Commands = []*cli.Command{
 				Action: func(c *cli.Context) error { return cmdListPlugins(c.Bool("all"), c.Bool("detail")) },
 			},
 			{
-				Name:   "install",
-				Usage:  "install plugin",
-				Action: func(c *cli.Context) error { return cmdInstallPlugin(c.Args().First()) },
+				Name:      "enable",
+				Usage:     "enable plugin",
+				ArgsUsage: "NAME",
+				Action:    func(c *cli.Context) error { return cmdEnablePlugin(c.Args().First()) },
+			},
+			{
+				Name:      "disable",
+				Usage:     "disable plugin",
+				ArgsUsage: "NAME",
+				Action:    func(c *cli.Context) error { return cmdDisablePlugin(c.Args().First()) },
+			},
+			{
+				Name:   "profiles",
+				Usage:  "list scan profiles and the plugins they select",
+				Action: func(c *cli.Context) error { return cmdListProfiles() },
+			},
+			{
+				Name:      "search",
+				Usage:     "search the plugin indexes",
+				ArgsUsage: "TERM",
+				Action:    func(c *cli.Context) error { return cmdPluginSearch(c.Args().First()) },
+			},
+			{
+				Name:      "info",
+				Aliases:   []string{"show"},
+				Usage:     "show plugin details, image state and index metadata",
+				ArgsUsage: "NAME",
+				Action:    func(c *cli.Context) error { return cmdShowPlugin(c.Args().First()) },
+			},
+			{
+				Name:  "outdated",
+				Usage: "list plugins with a newer version in the index or image in the registry",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "all",
+						Usage: "check all plugins, not only enabled ones",
+					},
+				},
+				Action: func(c *cli.Context) error { return cmdShowOutdatedPlugins(c.Bool("all")) },
+			},
+			{
+				Name:      "init",
+				Usage:     "create a new plugin from the go or python template",
+				ArgsUsage: "[DIR]",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "lang",
+						Value: "go",
+						Usage: "plugin language, go or python",
+					},
+					&cli.StringFlag{
+						Name:  "name",
+						Usage: "plugin name",
+					},
+					&cli.StringFlag{
+						Name:  "category",
+						Usage: "plugin category, e.g. av, intel, exe, metadata",
+					},
+					&cli.StringFlag{
+						Name:  "mime",
+						Usage: "mime type of the samples the plugin scans, * for all",
+					},
+					&cli.StringFlag{
+						Name:  "description",
+						Usage: "plugin description",
+					},
+					&cli.StringFlag{
+						Name:  "image",
+						Usage: "docker image of the plugin (default: malice/NAME)",
+					},
+					&cli.StringFlag{
+						Name:  "repo",
+						Usage: "go import path / repository of the plugin (default: github.com/malice-plugins/NAME)",
+					},
+					&cli.StringFlag{
+						Name:  "author",
+						Usage: "plugin author",
+					},
+					&cli.StringFlag{
+						Name:  "email",
+						Usage: "plugin author's email",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					return cmdPluginInit(plugins.ScaffoldOptions{
+						Lang:        c.String("lang"),
+						Name:        c.String("name"),
+						Category:    c.String("category"),
+						Mime:        c.String("mime"),
+						Description: c.String("description"),
+						Image:       c.String("image"),
+						Repository:  c.String("repo"),
+						Author:      c.String("author"),
+						Email:       c.String("email"),
+					}, c.Args().First())
+				},
+			},
+			{
+				Name:      "install",
+				Usage:     "install plugin from a docker image, git repository or local directory",
+				ArgsUsage: "IMAGE|GIT-URL|PATH",
+				Action:    func(c *cli.Context) error { return cmdInstallPlugin(c.Args().First()) },
 			},
 			{
 				Name:   "remove",
 				Usage:  "remove plugin",
 				Action: func(c *cli.Context) error { return cmdRemovePlugin(c.Args().First()) },
 			},
+			{
+				Name:   "rollback",
+				Usage:  "restore plugins.toml from before its last change",
+				Action: func(c *cli.Context) error { return cmdRollbackPlugins() },
+			},
 			{
 				Name:  "update",
 				Usage: "update plugin",
@@ -137,6 +457,50 @@ var Commands = []*cli.Command{
 				},
 				Action: func(c *cli.Context) error { return cmdUpdatePlugin(c.Args().First(), c.Bool("all"), c.Bool("source")) },
 			},
+			{
+				Name:      "verify",
+				Usage:     "verify plugin image signatures against the trust public key",
+				ArgsUsage: "[NAME]",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "all",
+						Usage: "verify all plugins, not only enabled ones",
+					},
+				},
+				Action: func(c *cli.Context) error { return cmdVerifyPlugins(c.Args().First(), c.Bool("all")) },
+			},
+			{
+				Name:      "validate",
+				Usage:     "validate plugin output against its result schema",
+				ArgsUsage: "NAME OUTPUT.json",
+				Action:    func(c *cli.Context) error { return cmdValidatePlugin(c.Args().Get(0), c.Args().Get(1)) },
+			},
+			{
+				Name:      "test",
+				Usage:     "run plugin against EICAR, a benign file and extra samples and check its output",
+				ArgsUsage: "NAME|PATH",
+				Flags: []cli.Flag{
+					&cli.StringSliceFlag{
+						Name:  "sample",
+						Usage: "extra sample to test, optionally with the expected detection: FILE[=infected|clean]",
+					},
+					&cli.DurationFlag{
+						Name:  "timeout",
+						Usage: "time each sample scan may take (default: docker timeout from config)",
+					},
+					&cli.StringFlag{
+						Name:  "image",
+						Usage: "test this image instead of the plugin's, e.g. a CI build",
+					},
+					&cli.BoolFlag{
+						Name:  "json",
+						Usage: "print report as JSON",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					return cmdTestPlugin(c.Args().First(), c.StringSlice("sample"), c.Duration("timeout"), c.String("image"), c.Bool("json"))
+				},
+			},
 		},
 		BashComplete: func(c *cli.Context) {
 			// This will complete if no args are passed
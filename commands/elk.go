@@ -13,7 +13,10 @@ import (
 
 func cmdELK(logs bool) error {
 
-	docker := client.NewDockerClient()
+	docker, err := client.ConnectDockerClient()
+	if err != nil {
+		return err
+	}
 
 	if _, running, _ := container.Running(docker, config.Conf.DB.Name); !running {
 		err := database.Start(docker, elasticsearch.Database{URL: config.Conf.DB.URL}, logs)
@@ -0,0 +1,49 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/maliceio/malice/malice/docker/client"
+	"github.com/maliceio/malice/malice/docker/client/container"
+	"github.com/pkg/errors"
+)
+
+// cmdGC removes the containers of scans whose malice process died, with
+// dryRun it only lists them
+func cmdGC(dryRun bool) error {
+	docker, err := client.ConnectDockerClient()
+	if err != nil {
+		return err
+	}
+
+	var orphans []types.Container
+	if dryRun {
+		orphans, err = container.Orphans(docker)
+	} else {
+		orphans, err = container.RemoveOrphans(docker)
+	}
+	// report what was removed before a removal failed too
+	for _, contr := range orphans {
+		fmt.Printf("%s\tscan=%s plugin=%s pid=%s\n",
+			strings.TrimPrefix(contr.Names[0], "/"),
+			contr.Labels[client.ScanLabel],
+			contr.Labels[client.PluginLabel],
+			contr.Labels[client.PIDLabel],
+		)
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to remove orphaned containers")
+	}
+
+	switch {
+	case len(orphans) == 0:
+		fmt.Println("no orphaned containers")
+	case dryRun:
+		fmt.Printf("%d orphaned containers would be removed\n", len(orphans))
+	default:
+		fmt.Printf("removed %d orphaned containers\n", len(orphans))
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/maliceio/malice/malice/drivers"
+	_ "github.com/maliceio/malice/malice/drivers/generic"
+	"github.com/maliceio/malice/malice/host"
+	"github.com/maliceio/malice/malice/maldirs"
+	"github.com/maliceio/malice/malice/persist"
+	"github.com/maliceio/malice/utils/clitable"
+	"github.com/pkg/errors"
+	"github.com/urfave/cli/v2"
+)
+
+// hostStore keeps the hosts in ~/.malice/machines
+func hostStore() *persist.Filestore {
+	return persist.NewFilestore(maldirs.GetBaseDir(), "", "")
+}
+
+// hostCreateFlags returns the flags of every driver
+func hostCreateFlags() []cli.Flag {
+	flags := []cli.Flag{
+		&cli.StringFlag{
+			Name:    "driver",
+			Aliases: []string{"d"},
+			Value:   "generic",
+			Usage:   "Driver provisioning the host: " + strings.Join(drivers.Names(), ", "),
+		},
+	}
+	for _, name := range drivers.Names() {
+		driver, _ := drivers.New(name, "", "")
+		flags = append(flags, driver.GetCreateFlags()...)
+	}
+	return flags
+}
+
+// cmdHostCreate provisions a host with the driver and saves it
+func cmdHostCreate(c *cli.Context) error {
+	name := c.Args().First()
+	if name == "" {
+		return fmt.Errorf("host name required")
+	}
+	if err := host.ValidateHostName(name); err != nil {
+		return err
+	}
+	store := hostStore()
+	if exists, err := store.Exists(name); err != nil {
+		return err
+	} else if exists {
+		return fmt.Errorf("host %s already exists", name)
+	}
+
+	driver, err := drivers.New(c.String("driver"), name, store.Path)
+	if err != nil {
+		return err
+	}
+	if err := driver.SetConfigFromFlags(c); err != nil {
+		return err
+	}
+	if err := driver.PreCreateCheck(); err != nil {
+		return errors.Wrapf(err, "host %s can't be created", name)
+	}
+	if err := driver.Create(); err != nil {
+		return errors.Wrapf(err, "failed to create host %s", name)
+	}
+	if err := store.Save(host.NewHost(driver)); err != nil {
+		return errors.Wrapf(err, "failed to save host %s", name)
+	}
+	fmt.Printf("host %s created, run `malice host env %s` to use it\n", name, name)
+	return nil
+}
+
+// cmdHostList prints the hosts with their state
+func cmdHostList() error {
+	store := hostStore()
+	names, err := store.List()
+	if err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		fmt.Println("no hosts, add one with `malice host create`")
+		return nil
+	}
+
+	table := clitable.New([]string{"Name", "Driver", "State", "URL"})
+	for _, name := range names {
+		h, err := store.Load(name)
+		if err != nil {
+			log.WithError(err).WithField("host", name).Warn("failed to load host")
+			table.AddRow(map[string]interface{}{"Name": name, "Driver": "-", "State": "Error", "URL": "-"})
+			continue
+		}
+		state, err := h.Driver.GetState()
+		if err != nil {
+			state = drivers.Error
+		}
+		url, err := h.Driver.GetURL()
+		if err != nil {
+			url = "-"
+		}
+		table.AddRow(map[string]interface{}{
+			"Name":   h.Name,
+			"Driver": h.DriverName,
+			"State":  state.String(),
+			"URL":    url,
+		})
+	}
+	table.Markdown = true
+	table.Print()
+	return nil
+}
+
+// cmdHostRemove removes the host with its driver and forgets it, with force
+// it is forgotten even when the driver fails to remove it
+func cmdHostRemove(name string, force bool) error {
+	if name == "" {
+		return fmt.Errorf("host name required")
+	}
+	// force must not get a path outside of the store to the removal
+	if err := host.ValidateHostName(name); err != nil {
+		return err
+	}
+	store := hostStore()
+	h, err := store.Load(name)
+	if err != nil {
+		if !force {
+			return err
+		}
+		log.WithError(err).WithField("host", name).Warn("failed to load host")
+	} else if err := h.Driver.Remove(); err != nil {
+		if !force {
+			return errors.Wrapf(err, "failed to remove host %s", name)
+		}
+		log.WithError(err).WithField("host", name).Warn("failed to remove host")
+	}
+	if err := store.Remove(name); err != nil {
+		return errors.Wrapf(err, "failed to forget host %s", name)
+	}
+	fmt.Printf("host %s removed\n", name)
+	return nil
+}
+
+// cmdHostEnv prints the shell commands pointing docker at the host and the
+// docker endpoint malice spreads plugins on
+func cmdHostEnv(name string) error {
+	if name == "" {
+		return fmt.Errorf("host name required")
+	}
+	if err := host.ValidateHostName(name); err != nil {
+		return err
+	}
+	h, err := hostStore().Load(name)
+	if err != nil {
+		return err
+	}
+	url, err := h.Driver.GetURL()
+	if err != nil {
+		return errors.Wrapf(err, "failed to get the URL of host %s", name)
+	}
+
+	key := h.Driver.GetSSHKeyPath()
+	fmt.Printf("export DOCKER_HOST=%q\n", url)
+	if key != "" {
+		fmt.Printf("# the docker cli reads the ssh key from the agent, add it with: ssh-add %s\n", key)
+	}
+	fmt.Printf("# Run this command to configure your shell:\n# eval $(malice host env %s)\n", name)
+	fmt.Printf("#\n# To run plugins on the host add it to the [docker] section of config.toml:\n")
+	fmt.Printf("# [[docker.endpoints]]\n#   name = %q\n#   host = %q\n", name, url)
+	if key != "" {
+		fmt.Printf("#   ssh_key = %q\n", key)
+	}
+	return nil
+}
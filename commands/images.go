@@ -0,0 +1,174 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/docker/go-units"
+	"github.com/maliceio/malice/malice/docker/client"
+	"github.com/maliceio/malice/malice/docker/client/container"
+	"github.com/maliceio/malice/malice/docker/client/image"
+	"github.com/maliceio/malice/plugins"
+	"github.com/maliceio/malice/utils/clitable"
+	"github.com/pkg/errors"
+)
+
+// imagePlugins returns the enabled plugins, or all of them
+func imagePlugins(all bool) []plugins.Plugin {
+	if all {
+		return plugins.Plugs.Plugins
+	}
+	return plugins.GetEnabledPlugins()
+}
+
+// cmdImagesStatus prints the image and size of each plugin and what
+// `malice images prune` would free
+func cmdImagesStatus(all bool) error {
+	docker, err := client.ConnectDockerClient()
+	if err != nil {
+		return err
+	}
+	statuses, err := plugins.ImageStatuses(docker, imagePlugins(all))
+	if err != nil {
+		return err
+	}
+
+	var total int64
+	installed := map[string]bool{}
+	fmt.Println("#### Plugin Images")
+	table := clitable.New([]string{"Plugin", "Image", "ID", "Size", "Status"})
+	for _, status := range statuses {
+		row := map[string]interface{}{"Plugin": status.Plugin, "Image": status.Image, "ID": "-", "Size": "-", "Status": "missing"}
+		if status.ID != "" {
+			row["ID"] = shortDigest(status.ID)
+			row["Size"] = units.HumanSize(float64(status.Size))
+			row["Status"] = "installed"
+			// plugins sharing an image count once
+			if !installed[status.ID] {
+				total += status.Size
+			}
+			installed[status.ID] = true
+		}
+		table.AddRow(row)
+	}
+	table.Markdown = true
+	table.Print()
+	fmt.Printf("%d images, %s (layers shared between images are counted for each)\n", len(installed), units.HumanSize(float64(total)))
+
+	stale, err := plugins.StaleImages(docker, plugins.Plugs.Plugins)
+	if err != nil {
+		return err
+	}
+	stopped, err := container.StoppedOrphans(docker)
+	if err != nil {
+		return err
+	}
+	if len(stale) > 0 || len(stopped) > 0 {
+		fmt.Printf("%d stale images (%s) and %d stopped containers can be removed with `malice images prune`\n",
+			len(stale), units.HumanSize(float64(staleSize(stale))), len(stopped))
+	}
+	return nil
+}
+
+// cmdImagesPull pulls the plugin images and the database image, concurrency
+// pulls at a time, and records the plugins' digests in plugins.lock
+func cmdImagesPull(all, locked bool, concurrency int) error {
+	docker, err := client.ConnectDockerClient()
+	if err != nil {
+		return err
+	}
+	changes := plugins.UpdatePluginsWithOptions(docker, imagePlugins(all), plugins.UpdateOptions{
+		Locked:        locked,
+		Concurrency:   concurrency,
+		SupportImages: plugins.SupportImages(),
+	})
+
+	failed := printDigestChanges(changes)
+	if failed > 0 {
+		return fmt.Errorf("%d of %d plugins failed to pull", failed, len(changes))
+	}
+	return nil
+}
+
+// cmdImagesPrune removes the stopped containers of dead scans and the
+// dangling or superseded plugin images, with dryRun it only lists them
+func cmdImagesPrune(dryRun bool) error {
+	docker, err := client.ConnectDockerClient()
+	if err != nil {
+		return err
+	}
+	// the containers go first, they would keep their images from being removed
+	stopped, err := container.StoppedOrphans(docker)
+	if err != nil {
+		return errors.Wrap(err, "failed to list stopped containers")
+	}
+	stale, err := plugins.StaleImages(docker, plugins.Plugs.Plugins)
+	if err != nil {
+		return err
+	}
+	if len(stopped) == 0 && len(stale) == 0 {
+		fmt.Println("nothing to prune")
+		return nil
+	}
+
+	failed := 0
+	removedContainers := 0
+	for _, contr := range stopped {
+		fmt.Printf("container\t%s\tplugin=%s scan=%s\n",
+			strings.TrimPrefix(contr.Names[0], "/"),
+			contr.Labels[client.PluginLabel],
+			contr.Labels[client.ScanLabel],
+		)
+		if dryRun {
+			continue
+		}
+		if err := container.Remove(docker, contr.ID, true, false, false); err != nil {
+			log.WithError(err).WithField("container", contr.ID).Warn("failed to remove container")
+			failed++
+			continue
+		}
+		removedContainers++
+	}
+
+	var reclaimed int64
+	removedImages := 0
+	for _, img := range stale {
+		reason := "superseded"
+		name := strings.Join(img.RepoTags, ",")
+		if img.Dangling {
+			reason = "dangling"
+			name = shortDigest(img.ID)
+		}
+		fmt.Printf("image\t%s\tplugin=%s %s %s\n", name, img.Plugin, reason, units.HumanSize(float64(img.Size)))
+		if dryRun {
+			continue
+		}
+		if err := image.Remove(docker, img.ID, false); err != nil {
+			log.WithError(err).WithField("image", name).Warn("failed to remove image")
+			failed++
+			continue
+		}
+		removedImages++
+		reclaimed += img.Size
+	}
+
+	if dryRun {
+		fmt.Printf("%d containers and %d images (%s) would be removed\n", len(stopped), len(stale), units.HumanSize(float64(staleSize(stale))))
+		return nil
+	}
+	fmt.Printf("removed %d containers and %d images, up to %s reclaimed\n", removedContainers, removedImages, units.HumanSize(float64(reclaimed)))
+	if failed > 0 {
+		return fmt.Errorf("%d containers or images could not be removed", failed)
+	}
+	return nil
+}
+
+// staleSize sums the size of the images, layers they share are counted for each
+func staleSize(stale []plugins.StaleImage) int64 {
+	var size int64
+	for _, img := range stale {
+		size += img.Size
+	}
+	return size
+}
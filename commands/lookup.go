@@ -1,8 +1,15 @@
 package commands
 
 import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
 	"fmt"
+	"io"
+	"os"
+	"sort"
 	"strings"
+	"sync"
 
 	log "github.com/Sirupsen/logrus"
 	"github.com/malice-plugins/pkgs/database/elasticsearch"
@@ -12,12 +19,182 @@ import (
 	"github.com/maliceio/malice/malice/docker/client"
 	"github.com/maliceio/malice/malice/docker/client/container"
 	"github.com/maliceio/malice/plugins"
+	"github.com/maliceio/malice/utils/clitable"
 	"github.com/pkg/errors"
 )
 
+// defaultLookupBatchSize is the number of hashes looked up at the same time
+const defaultLookupBatchSize = 10
+
+// lookupOptions holds the flags that control a bulk lookup
+type lookupOptions struct {
+	logs bool
+	// file is a hash list or CSV IOC feed, - reads stdin
+	file      string
+	batchSize int
+	json      bool
+}
+
+// hashLookup is the consolidated intel result for one hash
+type hashLookup struct {
+	Hash     string                 `json:"hash"`
+	HashType string                 `json:"hash_type"`
+	ScanID   string                 `json:"scan_id,omitempty"`
+	Results  map[string]interface{} `json:"results"`
+	Errors   map[string]string      `json:"errors,omitempty"`
+
+	results []plugins.Result
+}
+
 func cmdLookUp(hash string, logs bool) error {
 
-	docker := client.NewDockerClient()
+	docker, es, elasticsearchInDocker, err := lookupSetup(logs)
+	if err != nil {
+		return err
+	}
+
+	/////////////////////////////
+	// Write hash to the Database
+	resp, err := es.StoreHash(hash)
+	if err != nil {
+		return errors.Wrap(err, "cmd lookup failed to store hash")
+	}
+
+	// the results are printed as tables once their output was checked
+	results := plugins.RunIntelPlugins(docker, hash, resp.Id, true, elasticsearchInDocker)
+
+	if err := database.RecordImageDigests(es, results); err != nil {
+		log.WithError(err).Warn("failed to record plugin image digests")
+	}
+	// Flag intel output that does not match its result schema on the lookup
+	return database.FlagInvalidResults(es, results)
+}
+
+// isBulkLookUp returns true if the lookup arguments can't be handled as a single hash
+func isBulkLookUp(args []string, lopts lookupOptions) bool {
+	return lopts.file != "" || lopts.json || len(args) > 1 || (len(args) == 1 && args[0] == "-")
+}
+
+// cmdBulkLookUp looks up every hash found in args, stdin and the hash file
+// with the intel plugins, respecting each provider's rate limit
+func cmdBulkLookUp(args []string, lopts lookupOptions) error {
+	hashes, skipped, err := collectHashes(args, os.Stdin, lopts.file)
+	if err != nil {
+		return err
+	}
+	if skipped > 0 {
+		log.Warnf("skipped %d entries without a valid md5/sha1/sha256/sha512 hash", skipped)
+	}
+	if len(hashes) == 0 {
+		return fmt.Errorf("no hashes to look up")
+	}
+	log.WithField("hashes", len(hashes)).Info("bulk lookup starting")
+
+	docker, es, elasticsearchInDocker, err := lookupSetup(lopts.logs)
+	if err != nil {
+		return err
+	}
+
+	limiters := plugins.ProviderLimiters(plugins.GetIntelPlugins("", true))
+
+	batchSize := lopts.batchSize
+	if batchSize < 1 {
+		batchSize = defaultLookupBatchSize
+	}
+
+	ctx := context.Background()
+	for start := 0; start < len(hashes); start += batchSize {
+		end := start + batchSize
+		if end > len(hashes) {
+			end = len(hashes)
+		}
+		log.Debugf("looking up hashes %d-%d of %d", start+1, end, len(hashes))
+
+		var wg sync.WaitGroup
+		for i := start; i < end; i++ {
+			wg.Add(1)
+			go func(lookup *hashLookup) {
+				defer wg.Done()
+				lookUpHash(ctx, docker, es, elasticsearchInDocker, limiters, lookup)
+			}(&hashes[i])
+		}
+		wg.Wait()
+	}
+
+	var all []plugins.Result
+	for _, lookup := range hashes {
+		all = append(all, lookup.results...)
+	}
+	if err := database.FlagInvalidResults(es, all); err != nil {
+		log.WithError(err).Error("failed to flag invalid intel results")
+	}
+	if err := database.RecordImageDigests(es, all); err != nil {
+		log.WithError(err).Error("failed to record plugin image digests")
+	}
+
+	if lopts.json {
+		out, err := json.MarshalIndent(hashes, "", "  ")
+		if err != nil {
+			return errors.Wrap(err, "failed to marshal lookup results")
+		}
+		fmt.Println(string(out))
+		return nil
+	}
+	printLookupSummary(hashes)
+	return nil
+}
+
+// lookUpHash stores the hash and runs every intel plugin supporting its type
+func lookUpHash(ctx context.Context, docker *client.Docker, es elasticsearch.Database, elasticsearchInDocker bool, limiters map[string]*plugins.Limiter, lookup *hashLookup) {
+	lookup.Results = map[string]interface{}{}
+	lookup.Errors = map[string]string{}
+
+	resp, err := es.StoreHash(lookup.Hash)
+	if err != nil {
+		lookup.Errors["database"] = errors.Wrap(err, "failed to store hash").Error()
+		return
+	}
+	lookup.ScanID = resp.Id
+
+	runLookupPlugins(ctx, docker, plugins.GetIntelPlugins(lookup.HashType, true), elasticsearchInDocker, limiters, lookup)
+}
+
+// runLookupPlugins runs the intel plugins against the hash of lookup and
+// collects their results, each waits for the limiter of its provider
+func runLookupPlugins(ctx context.Context, docker *client.Docker, intelPlugins []plugins.Plugin, elasticsearchInDocker bool, limiters map[string]*plugins.Limiter, lookup *hashLookup) {
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, plugin := range intelPlugins {
+		wg.Add(1)
+		go func(plugin plugins.Plugin) {
+			defer wg.Done()
+
+			var result plugins.Result
+			if err := limiters[plugin.Name].Wait(ctx); err != nil {
+				result = plugins.Result{Plugin: plugin.Name, Category: plugin.Category, ScanID: lookup.ScanID, Err: err}
+			} else {
+				result = plugin.StartPlugin(ctx, docker, lookup.Hash, lookup.ScanID, nil, false, elasticsearchInDocker)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			lookup.results = append(lookup.results, result)
+			if result.Err != nil {
+				lookup.Errors[plugin.Name] = result.Err.Error()
+				return
+			}
+			lookup.Results[plugin.Name] = result.Data
+		}(plugin)
+	}
+	wg.Wait()
+}
+
+// lookupSetup connects to docker and makes sure the database and plugins are ready
+func lookupSetup(logs bool) (*client.Docker, elasticsearch.Database, bool, error) {
+	docker, err := client.ConnectDockerClient()
+	if err != nil {
+		return nil, elasticsearch.Database{}, false, err
+	}
 
 	elasticsearchInDocker := false
 	es := elasticsearch.Database{
@@ -36,7 +213,7 @@ func cmdLookUp(hash string, logs bool) error {
 			log.Error("database is NOT running, starting now...")
 			err := database.Start(docker, es, logs)
 			if err != nil {
-				return errors.Wrap(err, "failed to start to database")
+				return nil, es, false, errors.Wrap(err, "failed to start to database")
 			}
 		}
 	}
@@ -51,20 +228,161 @@ func cmdLookUp(hash string, logs bool) error {
 		fmt.Println("All enabled plugins not installed would you like to install them now? (yes/no)")
 		fmt.Println("[Warning] This can take a while if it is the first time you have ran Malice.")
 		if utils.AskForConfirmation() {
-			plugins.UpdateAllPlugins(docker)
+			plugins.InstallEnabledPlugins(docker)
 		}
 	}
 
-	/////////////////////////////
-	// Write hash to the Database
-	resp, err := es.StoreHash(hash)
-	if err != nil {
-		return errors.Wrap(err, "cmd lookup failed to store hash")
+	return docker, es, elasticsearchInDocker, nil
+}
+
+// collectHashes reads hashes from args, stdin ("-") and the hash file and
+// returns them de-duplicated with their detected type, along with the
+// number of entries that did not contain a valid hash
+func collectHashes(args []string, stdin io.Reader, file string) ([]hashLookup, int, error) {
+	var candidates []string
+	skipped := 0
+
+	read := func(r io.Reader, name string) error {
+		found, missing, err := readHashes(r)
+		if err != nil {
+			return errors.Wrapf(err, "failed to read hashes from %s", name)
+		}
+		candidates = append(candidates, found...)
+		skipped += missing
+		return nil
 	}
 
-	plugins.RunIntelPlugins(docker, hash, resp.Id, true, elasticsearchInDocker)
+	for _, arg := range args {
+		if arg == "-" {
+			if err := read(stdin, "stdin"); err != nil {
+				return nil, 0, err
+			}
+			continue
+		}
+		candidates = append(candidates, arg)
+	}
 
-	return nil
+	switch file {
+	case "":
+	case "-":
+		if err := read(stdin, "stdin"); err != nil {
+			return nil, 0, err
+		}
+	default:
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, 0, errors.Wrap(err, "failed to open hash file")
+		}
+		err = read(f, file)
+		f.Close()
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+
+	seen := make(map[string]bool)
+	var hashes []hashLookup
+	for _, candidate := range candidates {
+		hash := strings.ToLower(candidate)
+		hashType, err := utils.GetHashType(hash)
+		if err != nil {
+			skipped++
+			continue
+		}
+		if seen[hash] {
+			continue
+		}
+		seen[hash] = true
+		hashes = append(hashes, hashLookup{Hash: hash, HashType: hashType})
+	}
+
+	return hashes, skipped, nil
+}
+
+// readHashes reads a plain hash list, md5sum style output or a CSV IOC feed.
+// Every field that looks like a hash is returned, records without one (such
+// as a CSV header) are counted as missing.
+func readHashes(r io.Reader) ([]string, int, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.TrimLeadingSpace = true
+
+	var hashes []string
+	missing := 0
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+
+		found := false
+		for _, field := range record {
+			for _, word := range strings.Fields(field) {
+				if _, err := utils.GetHashType(word); err == nil {
+					hashes = append(hashes, word)
+					found = true
+				}
+			}
+		}
+		if !found {
+			missing++
+		}
+	}
+	return hashes, missing, nil
+}
+
+// lookupCell summarizes a plugin's verdict on a hash for the summary table
+func lookupCell(lookup hashLookup, plugin string) string {
+	if _, failed := lookup.Errors[plugin]; failed {
+		return "error"
+	}
+	data, ok := lookup.Results[plugin].(map[string]interface{})
+	if !ok {
+		return "-"
+	}
+	if found, _ := data["found"].(bool); found {
+		return "found"
+	}
+	return "not found"
+}
+
+// printLookupSummary prints one row per hash and one column per intel plugin
+func printLookupSummary(hashes []hashLookup) {
+	pluginSet := make(map[string]bool)
+	for _, lookup := range hashes {
+		for plugin := range lookup.Results {
+			pluginSet[plugin] = true
+		}
+		for plugin := range lookup.Errors {
+			pluginSet[plugin] = true
+		}
+	}
+	var pluginNames []string
+	for plugin := range pluginSet {
+		pluginNames = append(pluginNames, plugin)
+	}
+	sort.Strings(pluginNames)
+
+	fmt.Println("#### Lookup Summary")
+	table := clitable.New(append([]string{"Hash", "Type"}, pluginNames...))
+	for _, lookup := range hashes {
+		row := map[string]interface{}{
+			"Hash": lookup.Hash,
+			"Type": lookup.HashType,
+		}
+		for _, plugin := range pluginNames {
+			row[plugin] = lookupCell(lookup, plugin)
+		}
+		table.AddRow(row)
+	}
+	table.Markdown = true
+	table.Print()
+	fmt.Printf("Looked up %d hashes\n", len(hashes))
 }
 
 // APILookUp is an API wrapper for cmdLookUp
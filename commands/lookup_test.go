@@ -0,0 +1,131 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/maliceio/malice/malice/docker/client/fake"
+	"github.com/maliceio/malice/malice/maldirs"
+	"github.com/maliceio/malice/plugins"
+)
+
+const (
+	testMD5    = "44d88612fea8a8f36de82e1278abb02f"
+	testSHA1   = "3395856ce81f2b7382dee72602f798b642f14140"
+	testSHA256 = "275a021bbfb6489e54d471899f7db9d1663fc695ec2fe2a2c4538aabf651fd0f"
+)
+
+func TestCollectHashes(t *testing.T) {
+	feed := filepath.Join(t.TempDir(), "iocs.csv")
+	csvFeed := "name,md5,sha256\n\"eicar.com\"," + testMD5 + "," + strings.ToUpper(testSHA256) + "\n"
+	if err := ioutil.WriteFile(feed, []byte(csvFeed), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		args        []string
+		stdin       string
+		file        string
+		wantHashes  []string
+		wantTypes   []string
+		wantSkipped int
+	}{
+		{
+			name:       "arguments",
+			args:       []string{testMD5, testSHA1},
+			wantHashes: []string{testMD5, testSHA1},
+			wantTypes:  []string{"md5", "sha1"},
+		},
+		{
+			name:        "md5sum output on stdin with duplicates",
+			args:        []string{"-", testSHA256},
+			stdin:       "# samples\n" + testSHA256 + "  eicar.com\n\nnot-a-hash\n",
+			wantHashes:  []string{testSHA256},
+			wantTypes:   []string{"sha256"},
+			wantSkipped: 1,
+		},
+		{
+			name:        "csv feed",
+			file:        feed,
+			wantHashes:  []string{testMD5, testSHA256},
+			wantTypes:   []string{"md5", "sha256"},
+			wantSkipped: 1,
+		},
+		{
+			name:        "invalid argument",
+			args:        []string{"1234"},
+			wantSkipped: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hashes, skipped, err := collectHashes(tt.args, strings.NewReader(tt.stdin), tt.file)
+			if err != nil {
+				t.Fatalf("collectHashes() error = %v", err)
+			}
+			var gotHashes, gotTypes []string
+			for _, lookup := range hashes {
+				gotHashes = append(gotHashes, lookup.Hash)
+				gotTypes = append(gotTypes, lookup.HashType)
+			}
+			if !reflect.DeepEqual(gotHashes, tt.wantHashes) || !reflect.DeepEqual(gotTypes, tt.wantTypes) {
+				t.Errorf("collectHashes() = %v %v, want %v %v", gotHashes, gotTypes, tt.wantHashes, tt.wantTypes)
+			}
+			if skipped != tt.wantSkipped {
+				t.Errorf("collectHashes() skipped = %d, want %d", skipped, tt.wantSkipped)
+			}
+		})
+	}
+}
+
+func TestRunLookupPlugins(t *testing.T) {
+	defer func(base string) { maldirs.BaseDir = base }(maldirs.BaseDir)
+	maldirs.BaseDir = t.TempDir()
+
+	runtime := fake.New()
+	for _, name := range []string{"nsrl", "shadow-server", "virustotal"} {
+		runtime.AddImage("malice/"+name, "sha256:"+name, nil)
+	}
+	runtime.Script("malice/nsrl", fake.Behavior{Stdout: `{"nsrl":{"found":true}}`})
+	runtime.Script("malice/shadow-server", fake.Behavior{StartErr: errors.New("no such network")})
+	intel := []plugins.Plugin{
+		{Name: "nsrl", Category: "intel", Image: "malice/nsrl"},
+		{Name: "shadow-server", Category: "intel", Image: "malice/shadow-server"},
+		{Name: "virustotal", Category: "intel", Image: "malice/virustotal"},
+	}
+
+	// the provider's only request of the hour was made by another lookup
+	limiters := map[string]*plugins.Limiter{"virustotal": plugins.NewLimiter(time.Hour)}
+	if err := limiters["virustotal"].Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	lookup := &hashLookup{Hash: testMD5, HashType: "md5", ScanID: "scan1", Results: map[string]interface{}{}, Errors: map[string]string{}}
+	runLookupPlugins(ctx, runtime.Docker(), intel, false, limiters, lookup)
+
+	if found, _ := lookup.Results["nsrl"].(map[string]interface{}); found == nil || found["found"] != true {
+		t.Errorf("nsrl result = %v, want the hash found", lookup.Results["nsrl"])
+	}
+	if _, ok := lookup.Errors["shadow-server"]; !ok {
+		t.Errorf("lookup errors = %v, want the failed shadow-server plugin", lookup.Errors)
+	}
+	if _, ok := lookup.Errors["virustotal"]; !ok {
+		t.Errorf("lookup errors = %v, want virustotal throttled", lookup.Errors)
+	}
+	if len(lookup.results) != len(intel) {
+		t.Errorf("got %d results, want one per plugin", len(lookup.results))
+	}
+	if created := runtime.Count("ContainerCreate"); created != 2 {
+		t.Errorf("created %d containers, want none for the throttled plugin", created)
+	}
+}
@@ -1,34 +1,74 @@
 package commands
 
 import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
 	"os"
+	"strings"
+	"time"
 
 	log "github.com/Sirupsen/logrus"
+	"github.com/docker/go-units"
+	"github.com/maliceio/malice/config"
 	"github.com/maliceio/malice/malice/docker/client"
 	"github.com/maliceio/malice/plugins"
+	"github.com/maliceio/malice/utils/clitable"
+	"github.com/pkg/errors"
 )
 
-func cmdEnablePlugin() {
-	// ~ ❯❯❯ apm enable go-plus
-	// Enabled:
-	//   go-plus
-	// ✓
+func cmdEnablePlugin(name string) error {
+	return setPluginEnabled(name, true)
 }
 
-func cmdDisablePlugin() {
-	// ~ ❯❯❯ apm disable go-plus
-	// Disabled:
-	//   go-plus
-	// ✓
+func cmdDisablePlugin(name string) error {
+	return setPluginEnabled(name, false)
+}
+
+// setPluginEnabled flips a plugin's enabled flag in the user's plugins.toml
+func setPluginEnabled(name string, enabled bool) error {
+	if name == "" {
+		return fmt.Errorf("plugin name required")
+	}
+	plugin := plugins.GetPluginByName(name)
+	if plugin.Name == "" {
+		return fmt.Errorf("plugin %s not found", name)
+	}
+
+	state := "Enabled"
+	if !enabled {
+		state = "Disabled"
+	}
+	if plugin.Enabled == enabled {
+		fmt.Printf("%s %s already\n", plugin.Name, strings.ToLower(state))
+		return nil
+	}
+
+	if err := plugins.SetPluginOption(plugin.Name, "enabled", enabled); err != nil {
+		return errors.Wrapf(err, "failed to update plugin %s", plugin.Name)
+	}
+	fmt.Printf("%s:\n  %s\n✓\n", state, plugin.Name)
+	return nil
 }
 
 func cmdGoToPluginHome() {
 
 }
 
-func cmdPluginInit() {
-	// ~ ❯❯❯ apm init
-	// You must specify either --package, --theme or --language to `apm init`
+func cmdPluginInit(opts plugins.ScaffoldOptions, dir string) error {
+	if opts.Name == "" {
+		return fmt.Errorf("usage: malice plugin init --name NAME --category CATEGORY --mime MIME [DIR]")
+	}
+	if dir == "" {
+		dir = opts.Name
+	}
+
+	if err := plugins.Scaffold(dir, opts); err != nil {
+		return errors.Wrap(err, "failed to create plugin")
+	}
+	fmt.Printf("Created %s plugin %s in %s\n", opts.Lang, opts.Name, dir)
+	fmt.Printf("Implement the scan in %s, then run `malice plugin install %s`\n", map[string]string{"go": "scan.go", "python": "scan.py"}[opts.Lang], dir)
+	return nil
 }
 
 func cmdLogin() {
@@ -75,34 +115,237 @@ func cmdListPlugins(all bool, detail bool) error {
 	return nil
 }
 
-func cmdShowOutdatedPlugins() {
-	// ~ ❯❯❯ apm outdated
-	// Package Updates Available (1)
-	// └── MagicPython 0.5.15 -> 0.5.16
+func cmdListProfiles() error {
+	profiles, err := plugins.Profiles()
+	if err != nil {
+		return err
+	}
+
+	table := clitable.New([]string{"Profile", "Description", "Plugins", "Timeout", "Concurrency"})
+	for _, profile := range profiles {
+		var selected []string
+		for _, plugin := range plugins.Plugs.Plugins {
+			if profile.Selects(plugin) {
+				selected = append(selected, plugin.Name)
+			}
+		}
+		timeout, concurrency := "-", "-"
+		if profile.Timeout > 0 {
+			timeout = profile.Timeout.String()
+		}
+		if profile.Concurrency > 0 {
+			concurrency = fmt.Sprint(profile.Concurrency)
+		}
+		table.AddRow(map[string]interface{}{
+			"Profile":     profile.Name,
+			"Description": profile.Description,
+			"Plugins":     strings.Join(selected, ", "),
+			"Timeout":     timeout,
+			"Concurrency": concurrency,
+		})
+	}
+	table.Markdown = true
+	table.Print()
+	return nil
 }
 
-func cmdPluginSearch(name string) {
-	// ~ ❯❯❯ apm search go-plus
-	// Search Results For 'go-plus' (29)
-	// ├── go-plus Makes working with go in Atom awesome. (213713 downloads, 372 stars)
-	// ├── browser-plus Browser Plus (70140 downloads, 144 stars)
-	// <SNIP>
-	// ├── symbols-plus Symbol browser and (project-wide) jump (replaces built-in symbols-view). (707 downloads, 3 stars)
-	// └── notepad-plus-plus-syntax Notepad++ default theme syntax (596 downloads, 2 stars)
+func cmdShowOutdatedPlugins(all bool) error {
+	docker, err := client.ConnectDockerClient()
+	if err != nil {
+		return err
+	}
+
+	candidates := plugins.GetEnabledPlugins()
+	if all {
+		candidates = plugins.Plugs.Plugins
+	}
 
-	// Use `apm install` to install them or visit http://atom.io/packages to read more about them.
+	entries, errs := plugins.LoadIndexes(config.Conf.Registry.Indexes)
+	outdated := plugins.IndexOutdated(candidates, entries)
+	pinned := make(map[string]bool, len(outdated))
+	for _, plugin := range outdated {
+		pinned[plugin.Plugin.Name] = true
+	}
+
+	newer, digestErrs := plugins.OutdatedPlugins(docker, candidates)
+	for _, plugin := range newer {
+		if !pinned[plugin.Plugin.Name] {
+			outdated = append(outdated, plugin)
+		}
+	}
+	for _, err := range append(errs, digestErrs...) {
+		log.Warn(err)
+	}
+
+	fmt.Printf("Plugin Updates Available (%d)\n", len(outdated))
+	for i, plugin := range outdated {
+		branch := "├──"
+		if i == len(outdated)-1 {
+			branch = "└──"
+		}
+		fmt.Printf("%s %s %s -> %s\n", branch, plugin.Plugin.Name, shortDigest(plugin.Local), shortDigest(plugin.Remote))
+	}
+	if len(outdated) > 0 {
+		fmt.Println("\nRun `malice plugin update NAME` to update them, bump `version` in plugins.toml first for newer versions.")
+	}
+	return nil
 }
 
-func cmdShowPlugin() {
-	// ~ ❯❯❯ apm show go-plus                                                                                                                                                         ⏎
-	// go-plus
-	// ├── 4.3.2
-	// ├── https://github.com/joefitzgerald/go-plus
-	// ├── Makes working with go in Atom awesome.
-	// ├── 213622 downloads
-	// └── 372 stars
+// shortDigest trims a sha256 digest for display
+func shortDigest(digest string) string {
+	digest = strings.TrimPrefix(digest, "sha256:")
+	if len(digest) > 12 {
+		return digest[:12]
+	}
+	return digest
+}
 
-	// Run `apm install go-plus` to install this package.
+func cmdPluginSearch(term string) error {
+	entries, errs := plugins.LoadIndexes(config.Conf.Registry.Indexes)
+	for _, err := range errs {
+		log.Warn(err)
+	}
+	if len(entries) == 0 && len(errs) > 0 {
+		return fmt.Errorf("no plugin index could be loaded")
+	}
+
+	found := plugins.SearchIndex(entries, term)
+	fmt.Printf("Search Results For '%s' (%d)\n", term, len(found))
+	for i, entry := range found {
+		branch := "├──"
+		if i == len(found)-1 {
+			branch = "└──"
+		}
+		version := entry.Version
+		if version == "" {
+			version = "latest"
+		}
+		installed := ""
+		if plugins.GetPluginByName(entry.Name).Name != "" {
+			installed = " [installed]"
+		}
+		fmt.Printf("%s %s %s (%s, %s)%s\n", branch, entry.Name, entry.Description, entry.Category, version, installed)
+	}
+	if len(found) > 0 {
+		fmt.Println("\nUse `malice plugin install IMAGE` to install them or `malice plugin info NAME` to read more about them.")
+	}
+	return nil
+}
+
+func cmdShowPlugin(name string) error {
+	if name == "" {
+		return fmt.Errorf("plugin name required")
+	}
+	entries, errs := plugins.LoadIndexes(config.Conf.Registry.Indexes)
+	for _, err := range errs {
+		log.Debug(err)
+	}
+	entry, indexed := plugins.FindIndexEntry(entries, name)
+
+	plugin := plugins.GetPluginByName(name)
+	if plugin.Name == "" {
+		if !indexed {
+			return fmt.Errorf("plugin %s not found", name)
+		}
+		printIndexEntry(entry)
+		return nil
+	}
+
+	docker, err := client.ConnectDockerClient()
+	if err != nil {
+		return err
+	}
+	info, err := plugin.Info(docker)
+	if err != nil {
+		return err
+	}
+
+	hashTypes := strings.Join(plugin.HashTypes, ", ")
+	if hashTypes == "" {
+		hashTypes = "-"
+	}
+	rows := [][2]interface{}{
+		{"Name", plugin.Name},
+		{"Description", plugin.Description},
+		{"Category", plugin.Category},
+		{"Enabled", plugin.Enabled},
+		{"Image", plugin.Image},
+		{"Installed", info.Installed},
+		{"Mime", plugin.Mime},
+		{"Hash Types", hashTypes},
+	}
+	if info.Installed {
+		digest := info.Digest
+		if digest == "" {
+			digest = "- (built locally)"
+		}
+		rows = append(rows,
+			[2]interface{}{"Image ID", info.ImageID},
+			[2]interface{}{"Digest", digest},
+			[2]interface{}{"Last Update", info.Updated.Local().Format("2006-01-02 15:04:05")},
+			[2]interface{}{"Size", units.HumanSize(float64(info.Size))},
+		)
+		if info.SigDBVersion != "" {
+			rows = append(rows, [2]interface{}{"Signature DB", info.SigDBVersion})
+		}
+	}
+	if indexed {
+		rows = append(rows, indexRows(entry)...)
+	}
+
+	printFields(rows)
+	return nil
+}
+
+// printIndexEntry shows a plugin that is listed in an index but not installed
+func printIndexEntry(entry plugins.IndexEntry) {
+	hashTypes := strings.Join(entry.HashTypes, ", ")
+	if hashTypes == "" {
+		hashTypes = "-"
+	}
+	rows := [][2]interface{}{
+		{"Name", entry.Name},
+		{"Description", entry.Description},
+		{"Category", entry.Category},
+		{"Image", entry.Image},
+		{"Installed", false},
+		{"Mime", entry.Mime},
+		{"Hash Types", hashTypes},
+		{"Repository", entry.Repository},
+	}
+	printFields(append(rows, indexRows(entry)...))
+}
+
+// indexRows are the details only a plugin index knows about
+func indexRows(entry plugins.IndexEntry) [][2]interface{} {
+	latest := entry.Version
+	if latest == "" {
+		latest = "-"
+	}
+	versions := strings.Join(entry.Versions, ", ")
+	if versions == "" {
+		versions = "-"
+	}
+	maintainers := strings.Join(entry.Maintainers, ", ")
+	if maintainers == "" {
+		maintainers = "-"
+	}
+	return [][2]interface{}{
+		{"Latest Version", latest},
+		{"Versions", versions},
+		{"Maintainers", maintainers},
+		{"Index", entry.Index},
+	}
+}
+
+// printFields prints field/value rows as a markdown table
+func printFields(rows [][2]interface{}) {
+	table := clitable.New([]string{"Field", "Value"})
+	for _, row := range rows {
+		table.AddRow(map[string]interface{}{"Field": row[0], "Value": row[1]})
+	}
+	table.Markdown = true
+	table.Print()
 }
 
 func cmdInstallPlugin(name string) error {
@@ -140,61 +383,261 @@ func cmdInstallPlugin(name string) error {
 	// ==============================================================================
 	// ~ ❯❯❯ apm i                                                                                                                                                                    ⏎
 	// Installing modules ✓
-	testPlugin := plugins.Plugin{
-		Name:        name,
-		Enabled:     true,
-		Category:    "test",
-		Description: "This is a test plugin",
-		Image:       "blacktop/test",
-		Mime:        "image/png",
+	if name == "" {
+		return fmt.Errorf("usage: malice plugin install <image|git-url|path>")
+	}
+
+	docker, err := client.ConnectDockerClient()
+	if err != nil {
+		return err
+	}
+	plugin, err := plugins.InstallFromSource(docker, name)
+	if err != nil {
+		return errors.Wrapf(err, "failed to install plugin from %s", name)
 	}
-	return plugins.InstallPlugin(&testPlugin)
+	fmt.Printf("Installed:\n  %s (%s)\n✓\n", plugin.Name, plugin.Image)
+	return nil
+}
+
+func cmdVerifyPlugins(name string, all bool) error {
+	candidates := plugins.GetEnabledPlugins()
+	switch {
+	case name != "":
+		plugin := plugins.GetPluginByName(name)
+		if plugin.Name == "" {
+			return fmt.Errorf("plugin %s not found", name)
+		}
+		candidates = []plugins.Plugin{plugin}
+	case all:
+		candidates = plugins.Plugs.Plugins
+	}
+
+	docker, err := client.ConnectDockerClient()
+	if err != nil {
+		return err
+	}
+	verifications, err := plugins.VerifyPlugins(docker, candidates)
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	table := clitable.New([]string{"Plugin", "Image", "Signature"})
+	for _, verification := range verifications {
+		status := "verified"
+		if verification.Err != nil {
+			status = verification.Err.Error()
+			failed++
+		}
+		table.AddRow(map[string]interface{}{
+			"Plugin":    verification.Plugin,
+			"Image":     verification.Image,
+			"Signature": status,
+		})
+	}
+	table.Markdown = true
+	table.Print()
+
+	if failed > 0 {
+		return fmt.Errorf("%d plugin image(s) failed verification", failed)
+	}
+	return nil
 }
 
 func cmdRemovePlugin(name string) error {
 	return plugins.DeletePlugin(name)
 }
 
+func cmdRollbackPlugins() error {
+	if err := plugins.RollbackConfig(); err != nil {
+		return err
+	}
+	fmt.Printf("Restored %s from %s\n", plugins.ConfigPath(), plugins.BackupPath())
+	return nil
+}
+
 func cmdUpdatePlugin(name string, all bool, source bool) error {
-	docker := client.NewDockerClient()
-	if all {
-		plugins.UpdateEnabledPlugins(docker)
+	docker, err := client.ConnectDockerClient()
+	if err != nil {
+		return err
+	}
+
+	var changes []plugins.DigestChange
+	switch {
+	case all && source:
+		changes = plugins.UpdatePlugins(docker, plugins.GetEnabledPlugins(), true, false)
+	case all:
+		changes = plugins.UpdateEnabledPlugins(docker)
+	case name == "":
+		return fmt.Errorf("please enter a valid plugin name")
+	default:
+		plugin := plugins.GetPluginByName(name)
+		if plugin.Name == "" {
+			return fmt.Errorf("plugin %s not found", name)
+		}
+		changes = plugins.UpdatePlugins(docker, []plugins.Plugin{plugin}, source, false)
+	}
+
+	failed := printDigestChanges(changes)
+	if failed > 0 {
+		return fmt.Errorf("%d of %d plugins failed to update", failed, len(changes))
+	}
+	return nil
+}
+
+// printDigestChanges prints the image digest each plugin moved from and to
+// and returns the number of plugins that failed to update
+func printDigestChanges(changes []plugins.DigestChange) int {
+	failed := 0
+	fmt.Println("#### Plugin Updates")
+	table := clitable.New([]string{"Plugin", "Image", "Old", "New", "Status"})
+	for _, change := range changes {
+		status := "unchanged"
+		switch {
+		case change.Err != nil:
+			status = "failed"
+			failed++
+			log.WithError(change.Err).WithField("plugin", change.Plugin).Error("update failed")
+		case change.Old == "":
+			status = "added"
+		case change.Old != change.New:
+			status = "updated"
+		}
+		table.AddRow(map[string]interface{}{
+			"Plugin": change.Plugin,
+			"Image":  change.Image,
+			"Old":    shortDigest(change.Old),
+			"New":    shortDigest(change.New),
+			"Status": status,
+		})
+	}
+	table.Markdown = true
+	table.Print()
+	fmt.Printf("Lockfile: %s\n", plugins.LockPath())
+	return failed
+}
+
+func cmdValidatePlugin(name, outputPath string) error {
+	if name == "" || outputPath == "" {
+		return fmt.Errorf("usage: malice plugin validate <name> <output.json>")
+	}
+
+	plugin := plugins.GetPluginByName(name)
+	if plugin.Name == "" {
+		return fmt.Errorf("plugin %s not found", name)
+	}
+
+	output, err := ioutil.ReadFile(outputPath)
+	if err != nil {
+		return errors.Wrap(err, "failed to read plugin output")
+	}
+
+	// categories without a schema only check the results are keyed by plugin name
+	matches, fails := "is keyed by plugin name", "is NOT valid"
+	if schema, ok := plugin.Schema(); ok {
+		matches, fails = "matches schema "+schema.ID(), "does NOT match schema "+schema.ID()
 	} else {
-		if name == "" {
-			log.Error("Please enter a valid plugin name.")
-			os.Exit(1)
+		log.Warnf("no result schema for plugin %s of category %q, only checking results are keyed by plugin name", plugin.Name, plugin.Category)
+	}
+
+	_, violations, err := plugin.ValidateResults(output)
+	if err != nil {
+		return err
+	}
+	if len(violations) > 0 {
+		fmt.Printf("%s output %s:\n", plugin.Name, fails)
+		for _, violation := range violations {
+			fmt.Println(" - " + violation)
 		}
-		if source {
-			plugins.GetPluginByName(name).UpdatePluginFromRepository(docker)
-		} else {
-			plugins.GetPluginByName(name).UpdatePlugin(docker)
+		return fmt.Errorf("%d schema violation(s)", len(violations))
+	}
+
+	fmt.Printf("%s output %s ✓\n", plugin.Name, matches)
+	return nil
+}
+
+// cmdTestPlugin runs a plugin, registered or given as a directory holding its
+// manifest, against the conformance samples and fails if any check fails
+func cmdTestPlugin(name string, samples []string, timeout time.Duration, imageName string, asJSON bool) error {
+	if name == "" {
+		return fmt.Errorf("usage: malice plugin test NAME|PATH [--sample FILE[=infected|clean]]")
+	}
+
+	var plugin plugins.Plugin
+	if _, err := os.Stat(name); err == nil {
+		if plugin, _, _, err = plugins.ReadManifest(name); err != nil {
+			return err
 		}
+	} else if plugin = plugins.GetPluginByName(name); plugin.Name == "" {
+		return fmt.Errorf("plugin %s not found", name)
+	}
+	if imageName != "" {
+		plugin.Image, plugin.Version, plugin.Digest = imageName, "", ""
+	}
+
+	var testSamples []plugins.TestSample
+	for _, arg := range samples {
+		sample, err := plugins.ParseTestSample(arg)
+		if err != nil {
+			return err
+		}
+		testSamples = append(testSamples, sample)
+	}
+	if timeout <= 0 {
+		timeout = time.Duration(config.Conf.Docker.Timeout) * time.Second
+	}
+
+	docker, err := client.ConnectDockerClient()
+	if err != nil {
+		return err
+	}
+	report, err := plugins.TestPlugin(docker, plugin, testSamples, timeout)
+	if err != nil {
+		return errors.Wrapf(err, "failed to test plugin %s", plugin.Name)
+	}
+
+	if asJSON {
+		out, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(out))
+	} else {
+		printTestReport(report)
+	}
+
+	if !report.Passed {
+		return fmt.Errorf("plugin %s failed %d of %d samples", plugin.Name, report.Failed(), len(report.Samples))
 	}
 	return nil
 }
 
-func cmdTestPlugin() {
-	// 	~ ❯❯❯ apm test go-plus
-	// [42497:1008/100044:WARNING:resource_bundle.cc(311)] locale_file_path.empty() for locale English
-	// [warn] kq_init: detected broken kqueue; not using.: Undefined error: 0
-	// [42497:1008/100044:WARNING:dns_config_service_posix.cc(146)] dns_config has unhandled options!
-	// [warn] kq_init: detected broken kqueue; not using.: Undefined error: 0
-	// [warn] kq_init: detected broken kqueue; not using.: Undefined error: 0
-	// [warn] kq_init: detected broken kqueue; not using.: Undefined error: 0
-	// [warn] kq_init: detected broken kqueue; not using.: Undefined error: 0
-	// [42501:1008/100045:WARNING:resource_bundle.cc(311)] locale_file_path.empty() for locale English
-	// [warn] kq_init: detected broken kqueue; not using.: Undefined error: 0
-	// [42497:1008/100046:INFO:CONSOLE(84)] "Error: Cannot find module '/Users/blacktop/spec'
-	//     at Module._resolveFilename (module.js:339:15)
-	//     at Function.Module._resolveFilename (/Applications/Atom.app/Contents/Resources/app.asar/src/module-cache.js:383:52)
-	//     at Function.Module._load (module.js:290:25)
-	//     at Module.require (module.js:367:17)
-	//     at require (/Applications/Atom.app/Contents/Resources/app.asar/src/native-compile-cache.js:50:27)
-	//     at requireSpecs (/Applications/Atom.app/Contents/Resources/app.asar/spec/jasmine-test-runner.js:101:7)
-	//     at module.exports (/Applications/Atom.app/Contents/Resources/app.asar/spec/jasmine-test-runner.js:49:7)
-	//     at module.exports (/Applications/Atom.app/Contents/Resources/app.asar/src/initialize-test-window.js:68:17)
-	//     at setupWindow (file:///Applications/Atom.app/Contents/Resources/app.asar/static/index.js:82:12)
-	//     at window.onload (file:///Applications/Atom.app/Contents/Resources/app.asar/static/index.js:41:9)", source: /Applications/Atom.app/Contents/Resources/app.asar/src/initialize-test-window.js (84)
-	// [42498:1008/100046:WARNING:channel.cc(359)] RawChannel write error
-	// Tests failed
+// printTestReport prints a row per conformance check
+func printTestReport(report plugins.TestReport) {
+	network := "allowed"
+	if report.Offline {
+		network = "disabled"
+	}
+	fmt.Printf("#### %s (%s, network %s)\n", report.Plugin, report.Image, network)
+	table := clitable.New([]string{"Sample", "Check", "Result", "Detail"})
+	for _, sample := range report.Samples {
+		for _, check := range sample.Checks {
+			result := "pass"
+			if !check.Passed {
+				result = "FAIL"
+			}
+			table.AddRow(map[string]interface{}{
+				"Sample": sample.Sample,
+				"Check":  check.Name,
+				"Result": result,
+				"Detail": check.Detail,
+			})
+		}
+	}
+	table.Markdown = true
+	table.Print()
+
+	if report.Passed {
+		fmt.Printf("%s passed %d samples ✓\n", report.Plugin, len(report.Samples))
+	}
 }
@@ -15,11 +15,16 @@ import (
 	"github.com/malice-plugins/pkgs/utils"
 	"github.com/maliceio/malice/config"
 	"github.com/maliceio/malice/internal/util"
+	"github.com/maliceio/malice/malice/cache"
 	"github.com/maliceio/malice/malice/database"
+	"github.com/maliceio/malice/malice/delivery"
 	"github.com/maliceio/malice/malice/docker/client"
 	"github.com/maliceio/malice/malice/docker/client/container"
+	"github.com/maliceio/malice/malice/docker/cluster"
+	"github.com/maliceio/malice/malice/maldirs"
 	"github.com/maliceio/malice/malice/persist"
 	"github.com/maliceio/malice/plugins"
+	"github.com/maliceio/malice/utils/clitable"
 	"github.com/pkg/errors"
 )
 
@@ -32,16 +37,43 @@ const (
 	maxConcurrentPlugins = 10
 )
 
+// scanOptions holds the flags that control a scan
+type scanOptions struct {
+	logs bool
+	// force ignores cached results and re-runs every plugin
+	force bool
+	// rescanOlderThan overrides the configured cache TTL when set
+	rescanOlderThan time.Duration
+	// profile selects the plugins and may override the timeouts and concurrency
+	profile plugins.Profile
+}
+
+// scanTimeout returns how long the scan of one sample may take
+func (opts scanOptions) scanTimeout() time.Duration {
+	if opts.profile.ScanTimeout > 0 {
+		return opts.profile.ScanTimeout
+	}
+	return scanTimeout
+}
+
+// pluginConcurrency returns the number of plugin containers that may run at once
+func (opts scanOptions) pluginConcurrency() int {
+	if opts.profile.Concurrency > 0 {
+		return opts.profile.Concurrency
+	}
+	return maxConcurrentPlugins
+}
+
 // cmdScan scans a sample with all appropriate malice plugins
-func cmdScan(path string, logs bool) error {
-	ctx, cancel := context.WithTimeout(context.Background(), scanTimeout)
+func cmdScan(path string, opts scanOptions) error {
+	ctx, cancel := context.WithTimeout(context.Background(), opts.scanTimeout())
 	defer cancel()
 
-	return cmdScanWithContext(ctx, path, logs)
+	return cmdScanWithContext(ctx, path, opts)
 }
 
 // cmdScanWithContext scans a sample with context and timeout support
-func cmdScanWithContext(ctx context.Context, path string, logs bool) error {
+func cmdScanWithContext(ctx context.Context, path string, opts scanOptions) error {
 	if len(path) == 0 {
 		return fmt.Errorf("file path required")
 	}
@@ -51,21 +83,52 @@ func cmdScanWithContext(ctx context.Context, path string, logs bool) error {
 		return err
 	}
 
-	docker := client.NewDockerClient()
+	s, err := newScanner(opts)
+	if err != nil {
+		return err
+	}
+
+	results, err := s.scanFile(ctx, path)
+	printScanSummary(results)
 
-	// clean stale containers from previous runs
-	containers, err := container.List(docker, true)
+	return err
+}
+
+// scanner holds everything the scans of one or many samples share
+type scanner struct {
+	opts                  scanOptions
+	docker                *client.Docker
+	es                    elasticsearch.Database
+	elasticsearchInDocker bool
+	store                 *cache.Store
+	policy                cache.Policy
+	// sem bounds the number of plugin containers running across all samples
+	sem chan struct{}
+	// limiters space out the requests of intel plugins to their providers
+	limiters map[string]*plugins.Limiter
+	// quiet suppresses per sample output when scanning in batches
+	quiet bool
+	// pool runs plugins in warm containers when set
+	pool *plugins.Pool
+	// delivery gets the samples into the plugin containers
+	delivery delivery.Strategy
+	// hosts are the docker endpoints file plugins run on, they run on
+	// docker when it is nil
+	hosts *cluster.Cluster
+}
+
+// newScanner connects to docker and the database and makes sure plugins are installed
+func newScanner(opts scanOptions) (*scanner, error) {
+	docker, err := client.ConnectDockerClient()
 	if err != nil {
-		return errors.Wrap(err, "failed to list containers")
+		return nil, err
 	}
 
-	for _, contr := range containers {
-		if utils.StringInSlice("malice", contr.Names) {
-			if err := container.Remove(docker, contr.ID, true, true, true); err != nil {
-				log.WithError(err).Warnf("failed to remove stale container: %s", contr.Names[0])
-				// Don't fail - continue with scan
-			}
-		}
+	// clean containers left behind by dead scans, those of running scans are kept
+	if removed, err := container.RemoveOrphans(docker); err != nil {
+		log.WithError(err).Warn("failed to remove orphaned containers")
+	} else if len(removed) > 0 {
+		log.WithField("count", len(removed)).Debug("removed orphaned containers")
 	}
 
 	elasticsearchInDocker := false
@@ -83,8 +146,8 @@ func cmdScanWithContext(ctx context.Context, path string, logs bool) error {
 		// Check that database is running
 		if _, running, _ := container.Running(docker, config.Conf.DB.Name); !running {
 			log.Info("database is NOT running, starting now...")
-			if err := database.Start(docker, es, logs); err != nil {
-				return errors.Wrap(err, "failed to start database")
+			if err := database.Start(docker, es, opts.logs); err != nil {
+				return nil, errors.Wrap(err, "failed to start database")
 			}
 		}
 	}
@@ -100,43 +163,101 @@ func cmdScanWithContext(ctx context.Context, path string, logs bool) error {
 		fmt.Println("All enabled plugins not installed would you like to install them now? (yes/no)")
 		fmt.Println("[Warning] This can take a while if it is the first time you have ran Malice.")
 		if utils.AskForConfirmation() {
-			plugins.UpdateEnabledPlugins(docker)
+			plugins.InstallEnabledPlugins(docker)
 		}
 	}
 
 	es.Plugins = database.GetPluginsByCategory()
 
+	store, policy, err := resultCache(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	sample, err := delivery.FromConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	hosts, err := cluster.FromConfig(context.Background())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to connect to docker endpoints")
+	}
+	if hosts != nil && sample.Name() == delivery.Store {
+		return nil, fmt.Errorf("sample delivery %s mounts a directory of this host, use %s or %s with docker endpoints", delivery.Store, delivery.Copy, delivery.Stdin)
+	}
+
+	return &scanner{
+		opts:                  opts,
+		docker:                docker,
+		es:                    es,
+		elasticsearchInDocker: elasticsearchInDocker,
+		store:                 store,
+		policy:                policy,
+		sem:                   make(chan struct{}, opts.pluginConcurrency()),
+		limiters:              plugins.ProviderLimiters(opts.profile.IntelPlugins(nil)),
+		delivery:              sample,
+		hosts:                 hosts,
+	}, nil
+}
+
+// scanFile scans a single sample and returns the result of every plugin that took part
+func (s *scanner) scanFile(ctx context.Context, path string) ([]plugins.Result, error) {
+	docker := s.docker
+	es := s.es
+	elasticsearchInDocker := s.elasticsearchInDocker
+	store, policy := s.store, s.policy
+
 	file := persist.File{Path: path}
 	file.Init()
 
 	// Output File Hashes
-	file.ToMarkdownTable()
+	if !s.quiet {
+		file.ToMarkdownTable()
+	}
 	// fmt.Println(string(file.ToJSON()))
 
 	//////////////////////////////////////
-	// Copy file to malice volume
-	container.CopyToVolume(docker, file)
+	// Make the file available to the plugin containers
+	if err := s.delivery.Prepare(file.Path, file.SHA256); err != nil {
+		return nil, errors.Wrap(err, "failed to prepare sample for delivery")
+	}
+	defer s.delivery.Release(file.SHA256)
 
 	//////////////////////////////////////
 	// Write all file data to the Database
 	resp, err := es.StoreFileInfo(structs.Map(file))
 	if err != nil {
-		return errors.Wrap(err, "scan cmd failed to store file info")
+		return nil, errors.Wrap(err, "scan cmd failed to store file info")
 	}
 
 	scanID := resp.Id
 
 	/////////////////////////////////////////////////////////////////
 	// Run all Intel Plugins on the md5 hash associated with the file
-	plugins.RunIntelPlugins(docker, file.SHA1, scanID, true, elasticsearchInDocker)
-
-	// Get file's mime type with operation timeout
-	mimeCtx, cancel := context.WithTimeout(ctx, operationTimeout)
-	defer cancel()
+	hashes := plugins.SampleHashes(file.MD5, file.SHA1, file.SHA256, file.SHA512)
+	results := plugins.RunIntelPluginsForSample(ctx, docker, s.opts.profile, hashes, scanID, s.sem, s.limiters, !s.quiet, elasticsearchInDocker)
+
+	// Get file's mime type, the cache remembers it between scans
+	mimeType := ""
+	if store != nil && !policy.Force {
+		if sample, err := store.Load(file.SHA256); err == nil {
+			mimeType = sample.Mime
+		}
+	}
+	if mimeType == "" {
+		mimeCtx, cancel := context.WithTimeout(ctx, operationTimeout)
+		defer cancel()
 
-	mimeType, err := persist.GetMimeType(mimeCtx, docker, file.SHA256)
-	if err != nil {
-		return errors.Wrap(err, "failed to get file's mime type")
+		mimeType, err = persist.GetMimeType(mimeCtx, docker, file.SHA256, s.delivery)
+		if err != nil {
+			return results, errors.Wrap(err, "failed to get file's mime type")
+		}
+		if store != nil {
+			if err := store.SetMime(file.SHA256, mimeType); err != nil {
+				log.WithError(err).Warn("failed to cache the sample's mime type")
+			}
+		}
 	}
 
 	log.WithFields(log.Fields{
@@ -145,34 +266,201 @@ func cmdScanWithContext(ctx context.Context, path string, logs bool) error {
 	}).Debug("detected file mime type")
 
 	// Iterate over all applicable installed plugins
-	pluginsForMime := plugins.GetPluginsForMime(mimeType, true)
+	pluginsForMime := s.opts.profile.PluginsForMime(mimeType)
 	log.WithField("plugin_count", len(pluginsForMime)).Debug("found plugins for mime type")
 	for _, plugin := range pluginsForMime {
 		log.Debugf("  - %s", plugin.Name)
 	}
 
+	// Reuse results of plugins that already scanned this sample with the same engine
+	cached, toRun, engines := lookupCachedResults(docker, store, policy, file.SHA256, scanID, pluginsForMime)
+	if err := database.StoreResults(es, cached); err != nil {
+		log.WithError(err).Warn("failed to store cached plugin results")
+	}
+	results = append(results, cached...)
+
 	// Run plugins with bounded concurrency using semaphore
-	return runPluginsWithSemaphore(ctx, docker, file.SHA256, scanID, logs, elasticsearchInDocker, pluginsForMime)
+	mimeResults, err := runPluginsWithSemaphore(ctx, docker, s.pool, s.hosts, s.sem, s.delivery, file.SHA256, scanID, s.opts.logs, elasticsearchInDocker, toRun)
+	cacheResults(store, file.SHA256, engines, mimeResults)
+	// file plugins are kept off the network of malice, their results are stored here
+	if storeErr := database.StoreResults(es, mimeResults); storeErr != nil {
+		log.WithError(storeErr).Warn("failed to store plugin results")
+	}
+	results = append(results, mimeResults...)
+
+	// Flag plugin output that does not match its result schema on the scan
+	if flagErr := database.FlagInvalidResults(es, results); flagErr != nil {
+		log.WithError(flagErr).Warn("failed to flag invalid plugin results")
+	}
+	if digestErr := database.RecordImageDigests(es, results); digestErr != nil {
+		log.WithError(digestErr).Warn("failed to record plugin image digests")
+	}
+
+	return results, err
+}
+
+// engineState identifies the plugin image and signature database a result came from
+type engineState struct {
+	digest       string
+	sigDBVersion string
 }
 
-// runPluginsWithSemaphore runs plugins with bounded concurrency
-func runPluginsWithSemaphore(ctx context.Context, docker *client.Docker, sha256, scanID string, logs, elasticsearchInDocker bool, pluginsForMime []plugins.Plugin) error {
+// resultCache returns the result cache and the policy for reusing its results,
+// the store is nil when caching is disabled in the config
+func resultCache(opts scanOptions) (*cache.Store, cache.Policy, error) {
+	if !config.Conf.Cache.Enabled {
+		return nil, cache.Policy{Force: true}, nil
+	}
+
+	policy := cache.Policy{Force: opts.force, TTL: opts.rescanOlderThan}
+	if policy.TTL == 0 && config.Conf.Cache.TTL != "" {
+		ttl, err := time.ParseDuration(config.Conf.Cache.TTL)
+		if err != nil {
+			return nil, policy, errors.Wrapf(err, "invalid cache ttl %q in config", config.Conf.Cache.TTL)
+		}
+		policy.TTL = ttl
+	}
+
+	return cache.NewStore(maldirs.GetCacheDir()), policy, nil
+}
+
+// lookupCachedResults splits plugins into those whose cached results can be
+// reused for this scan and those that need to run
+func lookupCachedResults(docker *client.Docker, store *cache.Store, policy cache.Policy, sha256, scanID string, pluginsForMime []plugins.Plugin) ([]plugins.Result, []plugins.Plugin, map[string]engineState) {
+	var cached []plugins.Result
+	var toRun []plugins.Plugin
+	engines := make(map[string]engineState)
+
+	if store == nil {
+		return nil, pluginsForMime, engines
+	}
+
+	for _, plugin := range pluginsForMime {
+		digest, sigDBVersion, err := plugin.ImageState(docker)
+		if err != nil {
+			log.WithError(err).Debug("unable to identify plugin engine, not using cache")
+			toRun = append(toRun, plugin)
+			continue
+		}
+		engines[plugin.Name] = engineState{digest: digest, sigDBVersion: sigDBVersion}
+
+		entry, hit := store.Lookup(sha256, plugin.Name, digest, sigDBVersion, policy)
+		if !hit {
+			toRun = append(toRun, plugin)
+			continue
+		}
+
+		log.WithFields(log.Fields{
+			"plugin":     plugin.Name,
+			"scanned_at": entry.ScannedAt,
+			"scan_id":    entry.ScanID,
+		}).Debug("reusing cached plugin result")
+
+		result := plugins.Result{
+			Plugin:   plugin.Name,
+			Category: plugin.Category,
+			ScanID:   scanID,
+			Data:     entry.Data,
+			Cached:   true,
+			Image:    plugin.ImageRef(),
+		}
+		if schema, ok := plugin.Schema(); ok {
+			result.Schema = schema.ID()
+		}
+		if result.ImageDigest, err = plugin.ResolvedDigest(docker); err != nil {
+			result.ImageDigest = digest
+		}
+		cached = append(cached, result)
+	}
+
+	return cached, toRun, engines
+}
+
+// cacheResults records valid plugin results so later scans of the sample can reuse them
+func cacheResults(store *cache.Store, sha256 string, engines map[string]engineState, results []plugins.Result) {
+	if store == nil {
+		return
+	}
+	for _, result := range results {
+		engine, ok := engines[result.Plugin]
+		if !ok || !result.Valid() || result.Data == nil {
+			continue
+		}
+		err := store.Put(sha256, result.Plugin, cache.Entry{
+			ScanID:       result.ScanID,
+			Category:     result.Category,
+			ImageDigest:  engine.digest,
+			SigDBVersion: engine.sigDBVersion,
+			ScannedAt:    time.Now(),
+			Data:         result.Data,
+		})
+		if err != nil {
+			log.WithError(err).WithField("plugin", result.Plugin).Warn("failed to cache plugin result")
+		}
+	}
+}
+
+// resultStatus returns a one word status of a plugin result for summaries
+func resultStatus(result plugins.Result) string {
+	switch {
+	case result.Err != nil:
+		return "error"
+	case len(result.Violations) > 0:
+		return "invalid"
+	case result.Cached:
+		return "cached"
+	}
+	return "ok"
+}
+
+// printScanSummary prints the status of every plugin that took part in the scan
+func printScanSummary(results []plugins.Result) {
+	if len(results) == 0 {
+		return
+	}
+
+	hits := 0
+	fmt.Println("#### Summary")
+	table := clitable.New([]string{"Plugin", "Category", "Status", "Schema"})
+	for _, result := range results {
+		status := resultStatus(result)
+		if result.Cached {
+			hits++
+		}
+		table.AddRow(map[string]interface{}{
+			"Plugin":   result.Plugin,
+			"Category": result.Category,
+			"Status":   status,
+			"Schema":   result.Schema,
+		})
+	}
+	table.Markdown = true
+	table.Print()
+	fmt.Printf("Cache hits: %d/%d\n", hits, len(results))
+}
+
+// runPluginsWithSemaphore runs plugins with concurrency bounded by sem, which
+// may be shared between the scans of several samples. Plugins run in the warm
+// containers of pool unless it is nil, then on the docker endpoints of hosts
+// unless it is nil too. sample delivers the sample to them. With logs the
+// results are printed as tables, they are returned all the same. When ctx is
+// done first the results of the plugins that finished are returned with the
+// others failed by the timeout.
+func runPluginsWithSemaphore(ctx context.Context, docker *client.Docker, pool *plugins.Pool, hosts *cluster.Cluster, sem chan struct{}, sample delivery.Strategy, sha256, scanID string, logs, elasticsearchInDocker bool, pluginsForMime []plugins.Plugin) ([]plugins.Result, error) {
 	if len(pluginsForMime) == 0 {
 		log.Debug("no plugins to run")
-		return nil
+		return nil, nil
 	}
 
-	// Semaphore to limit concurrent operations
-	sem := make(chan struct{}, maxConcurrentPlugins)
-	defer close(sem)
-
 	var wg sync.WaitGroup
-	errors := make(chan error, len(pluginsForMime))
-	defer close(errors)
+	var mu sync.Mutex
+	errors := make(chan error, 2*len(pluginsForMime))
+	results := make([]plugins.Result, len(pluginsForMime))
+	finished := make([]bool, len(pluginsForMime))
 
-	for _, plugin := range pluginsForMime {
+	for i, plugin := range pluginsForMime {
 		wg.Add(1)
-		go func(p plugins.Plugin) {
+		go func(i int, p plugins.Plugin) {
 			defer wg.Done()
 
 			// Acquire semaphore
@@ -185,7 +473,7 @@ func runPluginsWithSemaphore(ctx context.Context, docker *client.Docker, sha256,
 			}
 
 			// Add timeout per plugin
-			pluginCtx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+			pluginCtx, cancel := context.WithTimeout(ctx, p.Timeout())
 			defer cancel()
 
 			log.WithFields(log.Fields{
@@ -193,9 +481,23 @@ func runPluginsWithSemaphore(ctx context.Context, docker *client.Docker, sha256,
 				"file":   sha256,
 			}).Debug("running plugin")
 
-			// Note: StartPlugin needs to accept context parameter
-			// For now, we call it as before but with logging
-			p.StartPlugin(docker, sha256, scanID, logs, elasticsearchInDocker, &sync.WaitGroup{})
+			var result plugins.Result
+			if pool != nil {
+				result = pool.Run(pluginCtx, p, sha256, scanID)
+				if logs {
+					result.Print()
+				}
+			} else if hosts != nil {
+				result = runPluginOnCluster(pluginCtx, hosts, p, sample, sha256, scanID, logs)
+			} else {
+				result = p.StartPlugin(pluginCtx, docker, sha256, scanID, sample, logs, elasticsearchInDocker)
+			}
+			mu.Lock()
+			results[i], finished[i] = result, true
+			mu.Unlock()
+			if result.Err != nil {
+				errors <- result.Err
+			}
 
 			select {
 			case <-pluginCtx.Done():
@@ -203,7 +505,7 @@ func runPluginsWithSemaphore(ctx context.Context, docker *client.Docker, sha256,
 			default:
 				// Plugin completed
 			}
-		}(plugin)
+		}(i, plugin)
 	}
 
 	// Wait for all plugins to complete or timeout
@@ -223,10 +525,43 @@ func runPluginsWithSemaphore(ctx context.Context, docker *client.Docker, sha256,
 				log.WithError(err).Warn("plugin error")
 			}
 		}
-		return nil
+		return results, nil
 	case <-ctx.Done():
-		return fmt.Errorf("scan timeout: %w", ctx.Err())
+		// plugins still running keep writing their results, return a copy
+		mu.Lock()
+		defer mu.Unlock()
+		partial := make([]plugins.Result, len(results))
+		for i, p := range pluginsForMime {
+			partial[i] = results[i]
+			if !finished[i] {
+				partial[i] = plugins.Result{Plugin: p.Name, Category: p.Category, ScanID: scanID, Err: fmt.Errorf("plugin %s did not finish before the scan timeout", p.Name)}
+			}
+		}
+		return partial, fmt.Errorf("scan timeout: %w", ctx.Err())
+	}
+}
+
+// runPluginOnCluster runs the plugin on a docker endpoint of hosts that has its
+// host labels, on another one when the endpoint goes down during the run.
+// StartPlugin pulls the plugin's image on the endpoint if it is missing and
+// verifies the image the endpoint has. Plugins with secrets mounted as files
+// are refused.
+func runPluginOnCluster(ctx context.Context, hosts *cluster.Cluster, p plugins.Plugin, sample delivery.Strategy, sha256, scanID string, logs bool) plugins.Result {
+	if p.MountsSecrets() {
+		// like the store delivery, the bind mount needs the daemon on this host
+		err := fmt.Errorf("plugin %s mounts secrets as files from this host and can't run on docker endpoints, inject them with env instead", p.Name)
+		return plugins.Result{Plugin: p.Name, Category: p.Category, ScanID: scanID, Err: err}
+	}
+	var result plugins.Result
+	err := hosts.Run(ctx, p.HostLabels, func(docker *client.Docker) error {
+		// file plugins don't store their results, elasticsearch isn't needed
+		result = p.StartPlugin(ctx, docker, sha256, scanID, sample, logs, false)
+		return result.Err
+	})
+	if err != nil && result.Err == nil {
+		result = plugins.Result{Plugin: p.Name, Category: p.Category, ScanID: scanID, Err: errors.Wrapf(err, "failed to place plugin %s", p.Name)}
 	}
+	return result
 }
 
 // validateAndNormalizePath validates a file path for security and accessibility
@@ -260,7 +595,12 @@ func validateAndNormalizePath(path string) error {
 	return nil
 }
 
-// APIScan is an API wrapper for cmdScan
-func APIScan(file string) error {
-	return cmdScan(file, false)
+// APIScan is an API wrapper for cmdScan, it scans with the named profile or
+// the enabled plugins when profile is empty
+func APIScan(file, profile string) error {
+	p, err := plugins.GetProfile(profile)
+	if err != nil {
+		return err
+	}
+	return cmdScan(file, scanOptions{profile: p})
 }
@@ -1,9 +1,19 @@
 package commands
 
 import (
+	"context"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/maliceio/malice/malice/delivery"
+	"github.com/maliceio/malice/malice/docker/client/container"
+	"github.com/maliceio/malice/malice/docker/client/fake"
+	"github.com/maliceio/malice/malice/docker/cluster"
+	"github.com/maliceio/malice/malice/maldirs"
+	"github.com/maliceio/malice/plugins"
 )
 
 func TestValidateAndNormalizePath(t *testing.T) {
@@ -93,10 +103,157 @@ func TestCmdScan(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := cmdScan(tt.path, false)
+			err := cmdScan(tt.path, scanOptions{})
 			if (err != nil) != tt.wantError {
 				t.Errorf("got error %v, want error %v", err != nil, tt.wantError)
 			}
 		})
 	}
 }
+
+// avastStdout is the output of the avast plugin scanning the EICAR test file
+const avastStdout = `{"avast":{"infected":true,"result":"EICAR-Test-File","engine":"4.6.5","updated":"20261018"}}`
+
+// prepareTestSample returns a copy delivery with the sample testSHA256 prepared
+func prepareTestSample(t *testing.T) delivery.Strategy {
+	t.Helper()
+	sample, err := delivery.New(delivery.Copy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	samplePath := filepath.Join(t.TempDir(), "sample")
+	if err := os.WriteFile(samplePath, []byte("X5O!P%@AP"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := sample.Prepare(samplePath, testSHA256); err != nil {
+		t.Fatal(err)
+	}
+	return sample
+}
+
+func TestRunPluginsWithSemaphore(t *testing.T) {
+	runtime := fake.New()
+	for _, name := range []string{"avast", "clamav", "fprot"} {
+		runtime.AddImage("malice/"+name, "sha256:"+name, nil)
+	}
+	runtime.Script("malice/avast", fake.Behavior{Stdout: avastStdout})
+	runtime.Script("malice/clamav", fake.Behavior{Stdout: `{"clamav":{"infected":"yes"}}`, Delay: 20 * time.Millisecond})
+	runtime.Script("malice/fprot", fake.Behavior{Stderr: "license expired", ExitCode: 1})
+	docker := runtime.Docker()
+
+	toRun := []plugins.Plugin{
+		{Name: "avast", Category: "av", Image: "malice/avast"},
+		{Name: "clamav", Category: "av", Image: "malice/clamav"},
+		{Name: "fprot", Category: "av", Image: "malice/fprot"},
+	}
+	sample := prepareTestSample(t)
+	results, err := runPluginsWithSemaphore(context.Background(), docker, nil, nil, make(chan struct{}, 2), sample, testSHA256, "scan1", false, false, toRun)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{"avast": "ok", "clamav": "invalid", "fprot": "error"}
+	for _, result := range results {
+		if got := resultStatus(result); got != want[result.Plugin] {
+			t.Errorf("plugin %s status = %s (%v %v), want %s", result.Plugin, got, result.Err, result.Violations, want[result.Plugin])
+		}
+		if result.ImageDigest != "sha256:"+result.Plugin {
+			t.Errorf("plugin %s image digest = %q, want the digest of its image", result.Plugin, result.ImageDigest)
+		}
+	}
+	if len(results) != len(toRun) {
+		t.Errorf("got %d results, want %d", len(results), len(toRun))
+	}
+	if containers, _ := container.List(docker, true); len(containers) != 0 {
+		t.Errorf("plugin containers left behind: %+v", containers)
+	}
+	if copies := runtime.Count("CopyToContainer"); copies != len(toRun) {
+		t.Errorf("sample copied into %d containers, want %d", copies, len(toRun))
+	}
+}
+
+func TestRunPluginsWithSemaphoreLogs(t *testing.T) {
+	runtime := fake.New()
+	runtime.AddImage("malice/avast", "sha256:avast", nil)
+	runtime.Script("malice/avast", fake.Behavior{Stdout: avastStdout})
+	sample := prepareTestSample(t)
+
+	// file plugins can't store their results, with logs the scan still has
+	// to get them to store them
+	toRun := []plugins.Plugin{{Name: "avast", Category: "av", Image: "malice/avast"}}
+	results, err := runPluginsWithSemaphore(context.Background(), runtime.Docker(), nil, nil, make(chan struct{}, 1), sample, testSHA256, "scan1", true, false, toRun)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || !results[0].Valid() || results[0].Data["result"] != "EICAR-Test-File" {
+		t.Errorf("runPluginsWithSemaphore() = %+v, want the avast result to store", results)
+	}
+}
+
+func TestRunPluginsOnClusterPullsImage(t *testing.T) {
+	defer func(base string) { maldirs.BaseDir = base }(maldirs.BaseDir)
+	maldirs.BaseDir = t.TempDir()
+
+	local := fake.New()
+	local.AddImage("malice/avast", "sha256:avast", nil)
+	// the endpoint never pulled the plugin's image
+	node := fake.New()
+	node.Script("malice/avast", fake.Behavior{Stdout: avastStdout})
+	hosts := cluster.New(cluster.NewNode("node", node.Docker(), nil, 1))
+
+	sample := prepareTestSample(t)
+
+	toRun := []plugins.Plugin{{Name: "avast", Category: "av", Image: "malice/avast"}}
+	results, err := runPluginsWithSemaphore(context.Background(), local.Docker(), nil, hosts, make(chan struct{}, 1), sample, testSHA256, "scan1", false, false, toRun)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || !results[0].Valid() {
+		t.Fatalf("runPluginsWithSemaphore() = %+v, want the avast result", results)
+	}
+	if node.Count("ImagePull") != 1 || node.Count("ContainerCreate") != 1 || local.Count("ContainerCreate") != 0 {
+		t.Errorf("endpoint pulled %d images and created %d containers, want the image pulled and the plugin run there",
+			node.Count("ImagePull"), node.Count("ContainerCreate"))
+	}
+}
+
+func TestRunPluginsOnClusterRefusesFileSecrets(t *testing.T) {
+	node := fake.New()
+	node.AddImage("malice/kaspersky", "sha256:kaspersky", nil)
+	hosts := cluster.New(cluster.NewNode("node", node.Docker(), nil, 1))
+
+	p := plugins.Plugin{Name: "kaspersky", Category: "av", Image: "malice/kaspersky", Secrets: []plugins.SecretRef{{Name: "kaspersky-license", File: "/etc/kaspersky/license.key"}}}
+	result := runPluginOnCluster(context.Background(), hosts, p, nil, testSHA256, "scan1", false)
+	if result.Err == nil || !strings.Contains(result.Err.Error(), "docker endpoints") {
+		t.Errorf("runPluginOnCluster() error = %v, want the plugin refused", result.Err)
+	}
+	if node.Count("ContainerCreate") != 0 {
+		t.Error("the plugin was placed on the endpoint")
+	}
+}
+
+func TestRunPluginsWithSemaphoreTimeout(t *testing.T) {
+	runtime := fake.New()
+	runtime.AddImage("malice/avast", "sha256:avast", nil)
+	runtime.AddImage("malice/fprot", "sha256:fprot", nil)
+	runtime.Script("malice/avast", fake.Behavior{Stdout: avastStdout})
+	runtime.Script("malice/fprot", fake.Behavior{Stdout: `{"fprot":{}}`, Delay: time.Minute})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	toRun := []plugins.Plugin{
+		{Name: "avast", Category: "av", Image: "malice/avast"},
+		{Name: "fprot", Category: "av", Image: "malice/fprot"},
+	}
+	results, err := runPluginsWithSemaphore(ctx, runtime.Docker(), nil, nil, make(chan struct{}, 2), nil, testSHA256, "scan1", false, false, toRun)
+	if err == nil || !strings.Contains(err.Error(), "scan timeout") {
+		t.Fatalf("runPluginsWithSemaphore() error = %v, want a scan timeout", err)
+	}
+	// the finished plugin keeps its result, the other one is failed
+	if len(results) != 2 || results[0].Err != nil || results[0].Data == nil {
+		t.Fatalf("runPluginsWithSemaphore() = %+v, want the avast result", results)
+	}
+	if results[1].Plugin != "fprot" || results[1].Err == nil {
+		t.Errorf("unfinished plugin result = %+v, want a timeout error", results[1])
+	}
+}
@@ -0,0 +1,101 @@
+package commands
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/maliceio/malice/malice/maldirs"
+	"github.com/maliceio/malice/malice/secrets"
+	"github.com/moby/term"
+	"github.com/pkg/errors"
+)
+
+func secretStore() *secrets.Store {
+	return secrets.NewStore(maldirs.GetSecretsDir())
+}
+
+// cmdSecretSet stores a secret, the value is read from stdin so it never
+// shows up in the shell history or the process list
+func cmdSecretSet(name string) error {
+	if name == "" {
+		return fmt.Errorf("secret name required")
+	}
+	value, err := readSecretValue(os.Stdin, name)
+	if err != nil {
+		return err
+	}
+	if value == "" {
+		return fmt.Errorf("secret %s: empty value", name)
+	}
+	if err := secretStore().Set(name, value); err != nil {
+		return errors.Wrapf(err, "failed to set secret %s", name)
+	}
+	fmt.Printf("secret %s saved\n", name)
+	return nil
+}
+
+// cmdSecretGet prints a secret's value
+func cmdSecretGet(name string) error {
+	if name == "" {
+		return fmt.Errorf("secret name required")
+	}
+	value, err := secretStore().Get(name)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get secret %s", name)
+	}
+	fmt.Println(value)
+	return nil
+}
+
+// cmdSecretRemove deletes a stored secret
+func cmdSecretRemove(name string) error {
+	if name == "" {
+		return fmt.Errorf("secret name required")
+	}
+	if err := secretStore().Remove(name); err != nil {
+		return errors.Wrapf(err, "failed to remove secret %s", name)
+	}
+	fmt.Printf("secret %s removed\n", name)
+	return nil
+}
+
+// cmdSecretList prints the names of the stored secrets
+func cmdSecretList() error {
+	names, err := secretStore().List()
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		fmt.Println(name)
+	}
+	return nil
+}
+
+// readSecretValue prompts for the value without echo on a terminal,
+// otherwise it reads the first line piped to stdin
+func readSecretValue(stdin *os.File, name string) (string, error) {
+	fd := stdin.Fd()
+	if term.IsTerminal(fd) {
+		fmt.Printf("Value for %s: ", name)
+		state, err := term.SaveState(fd)
+		if err != nil {
+			return "", err
+		}
+		if err := term.DisableEcho(fd, state); err != nil {
+			return "", err
+		}
+		defer func() {
+			term.RestoreTerminal(fd, state)
+			fmt.Println()
+		}()
+	}
+
+	line, err := bufio.NewReader(stdin).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", errors.Wrap(err, "failed to read secret value")
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
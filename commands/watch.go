@@ -3,13 +3,16 @@ package commands
 import (
 	"context"
 	"os"
+	"os/signal"
+	"syscall"
 
 	log "github.com/Sirupsen/logrus"
 	"github.com/fsnotify/fsnotify"
 	"github.com/maliceio/malice/config"
+	"github.com/maliceio/malice/plugins"
 )
 
-func cmdWatch(folderName string, logs bool) error {
+func cmdWatch(folderName string, logs, warm bool) error {
 
 	log.WithFields(log.Fields{
 		"env": config.Conf.Environment.Run,
@@ -23,28 +26,56 @@ func cmdWatch(folderName string, logs bool) error {
 		return nil
 	}
 	// Check that path is a folder and not a file
-	if info.IsDir() {
-		if err := NewWatcher(folderName); err != nil {
+	if !info.IsDir() {
+		log.Error("error: path is not a folder")
+		return nil
+	}
+
+	// the scanner is shared by all samples dropped in the folder
+	s, err := newScanner(scanOptions{logs: logs})
+	if err != nil {
+		return err
+	}
+	if warm || config.Conf.Pool.Enabled {
+		opts, err := plugins.PoolOptionsFromConfig()
+		if err != nil {
 			return err
 		}
-	} else {
-		log.Error("error: path is not a folder")
+		opts.Delivery = s.delivery
+		s.pool = plugins.NewPool(s.docker, opts)
+		defer s.pool.Close()
+		// plugins that failed to warm up are retried on their first scan
+		if err := s.pool.Warm(s.opts.profile.FilePlugins()); err != nil {
+			log.WithError(err).Warn("not all plugins are warm")
+		}
 	}
 
-	return nil
+	// stop watching on ctrl-c, so the warm containers are removed
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	return NewWatcher(ctx, folderName, func(path string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), s.opts.scanTimeout())
+		defer cancel()
+		if err := validateAndNormalizePath(path); err != nil {
+			return err
+		}
+		results, err := s.scanFile(ctx, path)
+		printScanSummary(results)
+		return err
+	})
 }
 
-// NewWatcher creates a new watcher for the user supplied folder with proper graceful shutdown
-func NewWatcher(folder string) error {
+// NewWatcher creates a new watcher for the user supplied folder with proper
+// graceful shutdown, scan is called with every file created in it until ctx
+// is done
+func NewWatcher(ctx context.Context, folder string, scan func(path string) error) error {
 	watcher, err := fsnotify.NewWatcher()
 	if err != nil {
 		return err
 	}
 	defer watcher.Close()
 
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-
 	done := make(chan error, 1)
 	defer close(done)
 
@@ -66,7 +97,7 @@ func NewWatcher(folder string) error {
 				if event.Op&fsnotify.Create == fsnotify.Create {
 					log.WithField("file", event.Name).Debug("file created, scanning")
 					// Scan new sample in watch folder
-					if err := cmdScan(event.Name, false); err != nil {
+					if err := scan(event.Name); err != nil {
 						log.WithError(err).Error("scan failed")
 					}
 				}
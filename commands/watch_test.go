@@ -0,0 +1,96 @@
+package commands
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/maliceio/malice/malice/delivery"
+	"github.com/maliceio/malice/malice/docker/client/container"
+	"github.com/maliceio/malice/malice/docker/client/fake"
+	"github.com/maliceio/malice/malice/maldirs"
+	"github.com/maliceio/malice/plugins"
+)
+
+func TestWatchScansDroppedSamples(t *testing.T) {
+	defer func(base string) { maldirs.BaseDir = base }(maldirs.BaseDir)
+	maldirs.BaseDir = t.TempDir()
+
+	runtime := fake.New()
+	runtime.AddImage("malice/avast", "sha256:avast", nil)
+	runtime.Script("malice/avast", fake.Behavior{Stdout: avastStdout})
+	docker := runtime.Docker()
+
+	// like `malice watch --warm`, samples are scanned in a warm container
+	sample, err := delivery.New(delivery.Copy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := plugins.NewPool(docker, plugins.PoolOptions{Size: 1, Delivery: sample})
+	avast := plugins.Plugin{Name: "avast", Category: "av", Image: "malice/avast"}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	folder := t.TempDir()
+	results := make(chan plugins.Result, 50)
+	watching := make(chan error, 1)
+	go func() {
+		watching <- NewWatcher(ctx, folder, func(path string) error {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			sum := sha256.Sum256(data)
+			sha := hex.EncodeToString(sum[:])
+			if err := sample.Prepare(path, sha); err != nil {
+				return err
+			}
+			defer sample.Release(sha)
+			results <- pool.Run(ctx, avast, sha, "scan1")
+			return nil
+		})
+	}()
+
+	// the watcher may not watch the folder yet, drop samples until one is
+	// scanned. They are moved in so they are complete once created.
+	drops := t.TempDir()
+	var result plugins.Result
+	for i := 0; result.Plugin == ""; i++ {
+		if i == 50 {
+			t.Fatal("no dropped sample was scanned")
+		}
+		dropped := filepath.Join(drops, "sample")
+		if err := os.WriteFile(dropped, []byte(fmt.Sprintf("X5O!P%%@AP %d", i)), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Rename(dropped, filepath.Join(folder, fmt.Sprintf("sample-%d", i))); err != nil {
+			t.Fatal(err)
+		}
+		select {
+		case result = <-results:
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+	if result.Err != nil || result.Data["result"] != "EICAR-Test-File" {
+		t.Errorf("scan of the dropped sample = %+v, want the avast result", result)
+	}
+
+	cancel()
+	select {
+	case err := <-watching:
+		if err != nil {
+			t.Errorf("NewWatcher() = %v, want it stopped without an error", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the watcher did not stop once its context was done")
+	}
+	pool.Close()
+	if all, _ := container.List(docker, true); len(all) != 0 {
+		t.Errorf("warm containers left behind: %+v", all)
+	}
+}
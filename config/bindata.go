@@ -68,7 +68,7 @@ func (fi bindataFileInfo) Sys() interface{} {
 	return nil
 }
 
-var _configConfigToml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xac\x53\x49\x6f\xdb\x3c\x10\xbd\xf3\x57\x0c\xe4\xcb\xf7\x01\x8d\x2c\x4b\x59\x5c\x01\x3e\x04\x41\x0e\x29\x9a\x16\x48\x8e\x46\x50\x8c\xa4\xb1\x44\x98\x8b\x40\x52\xce\xf2\xeb\x8b\xa1\x2d\x27\x6a\x7d\x29\x10\xf9\x40\x93\x6f\x96\xf7\x1e\x87\x33\xb8\xb1\xfd\xab\x93\x6d\x17\xe0\xbf\xfa\x7f\xc8\xb3\x45\x01\x67\xbc\x2c\xa1\x52\x58\x6f\x83\xed\xe1\x9b\xf5\xdd\x80\x70\x8f\xd2\xd0\x17\xb8\x56\x0a\x1e\x38\xc1\xc3\x03\x79\x72\x3b\x6a\x52\x31\x83\x47\x22\xf8\x7e\x77\x73\xfb\xe3\xf1\x16\x36\xd6\x81\x92\x35\x19\x4f\x20\xcd\xc6\x3a\x8d\x41\x5a\x93\x0a\x31\xfb\x9c\x4f\xcc\xe0\xfe\x9a\xbb\xc1\x8d\x35\x1b\xd9\x0e\x2e\x36\x80\x7f\xaf\xf3\x49\x7c\x44\x90\x41\x11\xac\x20\xb9\x47\x56\x0e\x0f\x83\x09\x52\xd3\x94\x5f\x22\x76\xe4\x3c\x13\x5d\x41\xb2\xcb\xd2\x22\xcd\x97\x89\x10\x6b\x1c\x42\x67\xdd\x93\x00\x30\xa8\x63\x95\xd1\xfb\x44\x00\x58\xd7\xa2\x91\x6f\x7b\x85\xc7\x0e\x77\x3f\x39\xf3\x99\x2a\x4e\x1b\x9c\x62\x24\x4b\xe3\xaf\x5c\x66\x9c\x87\x8d\x96\xe6\xd7\x01\x5a\xe4\x57\x11\x5c\x94\x45\x51\x14\x9c\x4a\x1a\xa5\xe2\xe4\xce\xfa\xc0\x21\x5e\x87\x3e\xa5\x17\xd4\xbd\xa2\xb4\xb6\x9a\x6b\xf4\xd6\x31\x96\x5f\x70\x13\x4f\x8e\xe3\x78\x65\x9e\x11\x47\xef\xf9\x8c\xd7\x67\xeb\x1a\x2e\xdc\x60\xc0\x0a\x3d\x7d\xd4\xa3\x23\xe7\x33\x52\xe8\x83\xac\x39\x53\x6a\x6c\x3f\x40\xf3\x03\xe4\x09\x5d\xdd\x95\x97\xe9\x45\xf2\xae\xab\x0b\xa1\x2f\xe7\x73\x65\x6b\x54\xcc\xb6\xfc\x9a\x67\x51\xe2\xec\x8f\x88\x69\x91\x31\x6a\x24\xcc\x81\x23\x69\x26\x7b\xdc\x5b\x17\x58\xc5\x9a\x13\x98\x35\xdf\x9c\x1d\xa2\xf0\x4c\x00\x90\xc1\x4a\x11\x87\x07\x37\x90\x10\xeb\x41\x9e\xd0\xb6\x95\x15\x1a\x3c\x25\x6d\x8f\x8c\x9a\xe2\x93\x89\x46\x1e\xf5\x4c\x48\x9c\x9f\x17\x4f\xa7\x9a\x92\xd9\x49\x67\x8d\x26\x13\x18\x77\x43\x1c\x86\x86\x76\xa4\x6c\xcf\xa7\xd1\x7b\x5b\x6f\x29\x4e\x92\xc6\xba\x93\x86\xce\xa6\x2c\x93\x58\xb9\xe9\xad\x34\xf1\xce\x43\x3d\x35\x36\x2f\xae\x2e\x93\x89\x03\x8b\x68\x41\x25\x4d\xe3\xdf\xcb\x94\x73\x8d\xea\x19\x1d\x95\xce\x72\xb8\x92\x66\xeb\xff\xbe\xe7\x72\x72\x1f\x1c\x58\xf7\x03\xac\xe0\x22\x3b\x7c\xcc\x93\xb4\x75\xaf\x7c\x98\x9f\xe7\xcb\x25\x1f\x8a\xb5\xb2\x6d\xbb\x97\xb1\x91\x8a\xa6\x12\x52\x65\xdb\x24\x0a\x7c\xf1\xf2\x8d\x81\x45\xb6\xdf\xee\x5d\x2f\x0e\xbb\x0a\xeb\xed\xd0\x33\xab\x2b\x66\xc8\x12\xe3\x8b\x5c\xc1\x06\x95\x67\x47\x7b\x67\x5f\x5e\xdf\xbd\x3e\x22\x00\x3c\x4e\xe3\x74\xf0\x7f\xbf\xdf\xfc\x0e\x00\x00\xff\xff\xb4\xc7\xe8\x56\x2e\x05\x00\x00")
+var _configConfigToml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xad\x58\x5b\x6f\xdb\x38\x16\x7e\xf7\xaf\x20\x94\x87\x4d\x00\x47\xbe\xa4\x69\x33\x01\x0a\x4c\x30\xc8\x62\x0b\x4c\x67\x82\xb6\x03\xec\xa2\x08\x1c\x5a\xa2\x6c\x4e\x28\x4a\x2b\x52\x76\x3d\x0f\xf3\xdb\xf7\x3b\x87\xa4\x2c\xa7\x01\x76\x17\x98\xf4\xc1\x12\x79\xee\x97\xef\x1c\xf5\x4c\xfc\xd4\xb4\x87\x4e\x6f\xb6\x5e\x9c\x17\x17\x62\x39\x5f\x5c\x89\x4b\xfc\x2c\xaf\xc4\xda\xc8\xe2\xd9\x37\xed\x54\xdc\x19\x23\x3e\x11\x8d\x13\x9f\x94\x53\xdd\x4e\x95\xf9\xe4\x4c\x7c\x56\x4a\xfc\xfc\xe1\xa7\xfb\x5f\x3e\xdf\x8b\xaa\xe9\x84\xd1\x85\xb2\x4e\x09\x6d\xf1\x56\x4b\xaf\x1b\x9b\x4f\x26\x67\x7f\xcd\x1f\xf4\x7d\xbc\x23\x6d\xb0\xd8\x56\x7a\xd3\x77\xac\x40\xfc\xff\x72\xfe\x22\x7b\x26\x5e\x7b\xa3\xc4\x7b\x91\x7d\x94\xe4\xb9\xf8\xd4\x5b\xaf\x6b\x75\x6a\x5f\x36\xd9\xa9\xce\x91\xa1\x20\xdc\xcd\xf3\xab\x7c\x79\x93\x4d\x26\x5f\x65\xef\xb7\x4d\xf7\x38\x11\xc2\xca\x9a\xa5\xa4\x70\x67\x38\x6b\xba\x8d\xb4\xfa\x8f\xe0\xe1\xa0\xe1\xc3\xaf\xc4\xb9\x57\x6b\x62\xeb\x3b\x43\x37\xf3\x9c\xff\xdd\xde\xcc\x89\x4f\x96\xb5\xb6\xab\x78\xb5\x58\xbe\xe3\xcb\xc5\xed\x15\xfe\x88\x55\xd5\x52\x1b\x62\xde\x36\xce\x13\x89\xab\x7d\x9b\xab\x6f\xb2\x6e\x8d\xca\x8b\xa6\x26\x19\x6d\xd3\xd1\xdd\xf2\x9a\x94\x20\xd9\x44\x47\xbf\x64\x27\xdf\x4b\xe7\xe8\x8c\x7e\xf7\x4d\x57\x92\xe0\x52\x7a\xb9\x96\x4e\x8d\xfd\xa9\xd9\xe6\x4b\x65\xa4\xf3\xba\x20\x4e\x5d\xcb\xcd\xe8\x6a\x16\xaf\x9c\x92\x5d\xb1\xbd\x7d\x9b\x5f\x67\x47\xbf\xb6\xde\xb7\xb7\xb3\x99\x69\x0a\x69\xc8\xda\xdb\x1f\x96\x73\x76\xf1\xec\x05\xc5\xa9\x90\x44\x95\x0c\x26\xc2\x64\x34\x19\x3b\xbc\xc3\x49\xf2\xe2\x2b\x31\x90\xd5\x94\xb9\xa6\x67\xc7\xe7\x78\x55\x56\xae\x8d\x22\x72\xdf\xf5\x0a\x1e\xf6\xfa\x15\xdf\x9e\xf5\x5a\x5a\xf9\x9a\x6b\xe1\x26\xf9\xc4\x2d\xc3\x81\x1c\xfc\x39\x31\xe2\xcd\x9b\xab\xc7\xd7\x94\x2a\xbb\xd3\x5d\x63\x6b\x65\x3d\xdd\x77\x3d\x17\x43\xa9\x76\xca\x34\x2d\x9d\x72\xec\x9b\xe2\x59\x71\x25\xd5\xb2\xd8\x6a\xab\x2e\x4f\xad\xcc\x58\x72\xd9\x36\xda\x72\xce\x7d\x71\x1a\xd8\xe5\xd5\xbb\xb7\x21\xb0\x41\x12\x8a\x0f\x96\x95\xb5\xb4\xd3\xf8\x2b\xbc\x34\xcf\x4e\xf8\x46\xf8\xad\x4a\x67\x77\x0f\x1f\x84\x23\x06\x00\x47\xd7\x34\x68\x05\x94\x05\x58\xdd\xc1\x79\x55\x5f\x04\x73\xb9\x1f\xc8\x64\x96\xcc\xa1\x08\x2c\x31\x0d\xc7\xa8\x2f\x38\xec\x67\xa8\xcc\xbd\x70\x5c\x8f\x4e\x6c\x40\x08\xa3\x1b\xd1\x9a\x7e\xa3\xad\x28\x1a\xeb\x25\x1c\xec\xdc\x2d\x9e\xdb\x03\x99\x53\x07\x0a\x05\xd7\x8f\xf7\x53\x16\xe5\x7c\xd3\x29\x51\x37\x30\xc3\x89\x3f\x67\x79\x4c\x4d\x92\x7e\x4e\xce\x94\x52\xd5\x68\xb1\xba\x47\x3f\x50\x78\xf1\xec\xb7\xda\x71\x83\x5c\x08\x69\xcb\x28\xa9\x84\x7a\xe7\x3b\x25\x6b\xc7\x5a\xa7\xa0\x34\x07\x46\xbd\x60\x1c\x1d\x4b\xc8\x50\xb2\xe4\x28\x05\x2d\xa2\xea\x9a\x5a\x48\xd1\xea\x56\x41\x52\xa9\x8c\x46\x21\x1c\xc8\x7d\x72\x80\x42\x50\xb4\x3d\x5e\xaf\xe7\xf1\x8f\xd2\x08\x93\x98\xe6\x7a\xf9\x66\x79\x73\x13\x0e\xcf\xc4\xdf\x35\xe4\x25\x65\x83\xad\x80\xe3\x94\xb7\xe0\x8b\x43\x40\x90\x01\x98\xd1\x54\x6c\x09\x67\x1a\xc4\x4a\xec\xb7\xca\xb2\x28\x69\x0f\x42\x22\x36\x4e\xf9\x29\x30\xa3\x1c\xd1\xc5\x80\x48\x07\xa2\x63\xd9\x20\xc2\xcf\x4a\xb5\x68\x2b\x6d\x37\x42\xfb\x9c\x03\xc4\xb2\x10\xac\x50\x51\x43\x31\x89\xf3\xbd\xf6\xc8\x86\xea\xfc\xaa\x95\x78\xda\x36\xa6\x24\xbe\x42\xe6\x2d\x85\x8e\x6e\xe8\x89\xe2\x0b\xb9\x07\x7a\x66\x59\x14\xce\x2f\x3f\x7f\xbe\xe0\x3a\x72\x5b\x08\xa5\x3e\xfe\x91\xd1\x2a\x08\xc5\xe9\x0a\x1c\x53\xb6\x18\x0f\x74\x00\xcb\x37\xe4\xb5\x20\x8a\x90\xfa\xde\x72\x39\x12\x11\xfa\x12\x0e\x6c\xe1\x90\xf6\x17\xb9\x78\x88\x01\x64\x69\x24\x78\x65\xe4\x5a\x19\xe6\x8f\xc1\xcd\x7d\x53\x1b\x16\xc3\x39\x8e\xa1\x4e\xb1\x40\x6d\xc8\x1d\x79\x23\x31\x14\x43\x88\xc9\x25\xd9\xca\x42\xfb\x03\x87\x03\x5a\x6d\x5f\xaf\xa9\x95\x2a\x96\xf3\x5d\xfd\x9e\x04\x17\x0a\x70\xe0\xa1\xa4\x50\x39\xd3\x7f\x8d\x4d\x9d\x0f\x4a\x1f\x1f\xf9\xe2\x88\x40\x00\x33\x10\x2c\xb2\x78\x9c\x00\x3d\xa4\x62\x11\xa6\xc2\x72\x31\x6a\x6e\x31\x4a\x08\x08\x67\xca\x17\xb3\xd8\x11\x74\xe1\x66\x2f\x24\xc6\xb0\x00\x9d\xb2\x2d\x46\xff\x65\xa8\xca\x2c\xd9\x31\x38\xfc\x5e\xdc\xfc\xaf\x36\x97\xaa\xc2\x65\xc0\x81\xb1\xd1\x21\xd5\xc1\x98\x1f\x93\xed\xcb\x44\x15\x53\xfe\xd2\x68\x1c\xcf\x74\xb9\x52\xe5\xf2\xfa\x7a\xf1\xc3\x2b\x56\xef\xb5\x2d\x9b\xbd\xbb\x1c\xb4\xbe\x62\xfa\x12\xf8\x89\xea\xd9\x04\xfc\xac\xd0\x61\xa7\xd8\x99\xe3\x32\x63\x64\xfd\xe6\xf4\x1f\x74\xb1\x98\x87\xd7\x00\xf7\x57\xf1\x6d\x8d\xd1\xdd\xb7\xa4\xf7\x1d\x0e\xb8\x93\x22\xf4\x55\xd2\x38\x82\xf2\xb6\x6b\xbe\x1d\x8e\x20\x3f\xdc\x20\x0a\x98\x63\x09\x0f\xe9\xd9\x85\x97\xc9\xd7\x02\x98\xa6\x82\xd1\x9d\x42\x23\xa4\x32\xea\x94\xeb\x0d\xea\x90\xba\x25\x81\x99\x34\x84\x3c\x68\x87\x42\x5a\x8b\x11\xb2\x3e\x24\x14\x1a\xd8\x78\x44\xc5\xf6\x2f\x85\xd3\x1b\x2b\x7d\xdf\x11\x04\x86\xd9\x3d\xa5\xa6\x37\x0d\x55\x36\xd7\x70\x80\x88\x03\xe0\x13\xe1\x21\x74\x03\xdc\x78\xf3\xfd\x98\x12\x74\xcc\xfb\xc6\xdb\x9b\x2d\xd9\xdd\xa9\x8d\x06\x56\x1e\x1e\xc7\xb5\x8f\x64\xa8\x6f\x2a\x18\xfd\x14\x82\x9b\xae\xc2\xe8\x7e\x9a\x46\x04\xa2\x2c\xf0\x24\xa1\x60\x9c\xbb\x0b\x9a\xf7\x2e\xf4\x45\x6a\x5e\x30\x81\xc7\x68\xd8\x95\xe4\xba\xad\x44\xb6\x91\x7f\x4f\x6d\x67\x95\x9b\x0a\x95\x6f\x72\xd1\x62\xba\x48\x52\xef\x69\x21\x30\x81\x3e\x34\x77\x25\xda\x4e\xef\xc0\x30\xa0\x6a\xa5\x3b\x07\x6c\xbb\xaf\x5b\x54\x87\x81\x13\xa1\x99\xd3\xf5\xba\xb7\x25\x39\xce\xd8\x11\x7c\xa0\xd1\x1f\x2d\x40\xcd\x3d\xc2\x7b\xc4\xc4\xf9\xe0\x3a\xa0\x5e\x57\x87\x93\xf8\x23\x53\x1b\x49\x00\x4d\x82\x75\x07\x48\xa0\x44\x1c\xb3\xe1\x20\xb7\x54\x41\x43\xdb\xaf\xa1\x82\x6a\x9f\xa5\x9d\x4b\xf1\x70\xff\x91\xc3\x73\x21\xd6\xaa\xa2\xe1\x06\xe8\xb0\x84\x45\x04\x42\xb9\xf8\x02\x5b\xb3\xbd\xec\x6c\x86\x21\x0d\xd6\x43\x40\x46\xc0\x0b\xc4\xc3\xee\x60\x41\x40\xc8\x4c\xd1\x92\x8e\xed\x00\xe5\x54\xa1\xb8\x78\xbc\x13\xd4\xf1\x4c\xa5\x0a\xe9\xd4\xbf\x7b\x4d\x16\x9d\xd8\x11\x05\xbf\x8f\x8a\xe8\x64\xb8\x4e\x85\xdb\x36\x8d\x09\x11\xe0\xa1\xf1\x3d\xfa\x81\x35\xe8\x40\xe0\x8a\xa1\x86\x79\x90\xc7\x91\x3e\x4c\xb0\x02\x75\xed\xe1\x62\x28\xdc\xa3\x10\xd1\xaa\x34\x7a\x43\x3d\xb3\x10\x24\xdd\xe2\x44\x71\xde\x2c\xdc\x0f\xd3\x18\x81\x1e\x22\x1c\x36\x9d\x58\xf3\x2e\x80\xae\xf8\xcd\x85\x9e\x49\x95\xb9\x97\x9e\x2b\x72\xbf\xd5\xd8\x2b\xd0\xa8\x4d\xac\x7a\x9a\x22\x21\x3b\x97\x97\xe4\x45\xa8\x4b\xc6\x86\x97\xc8\x1f\xbc\x1c\x79\x3d\xb2\x18\xb9\x0c\x4f\x7f\xc3\x31\xa2\xb5\x0a\x12\xec\xa8\x63\xc2\x18\x12\x0d\x8a\xa8\xd3\x25\x2b\xbe\x98\x9e\x8c\x10\xca\xbf\x6a\xf1\xd5\x00\xdb\x65\x45\x75\x0f\x24\x5a\x11\x02\x38\xc6\x01\x37\x34\xbb\x46\xd9\x72\x53\x30\x13\x50\x05\x40\x8d\xe0\xf3\x2a\xb2\x55\xc0\xaa\xed\x8a\x1b\x64\x27\x4f\xfb\x3b\x41\x54\xc2\xbe\x00\x76\x51\x05\x61\x21\xc1\xdf\x0b\x01\x54\x04\x57\x73\x47\x75\x60\x95\xa7\xa1\x12\x4a\x81\xb7\xad\xb0\xa8\xc4\xf3\xd8\x42\x21\xc5\x11\x1a\xc2\x4e\x96\x00\x89\xde\x7e\xfb\x10\x5c\x80\x78\x33\x0e\x4f\x4a\x4e\xc7\xab\xdf\x98\x8f\xf0\x2b\x3b\xf9\x2c\xc8\x68\x80\xd3\xd6\x32\xde\xa1\xe2\x66\x27\x0f\xc8\x55\x45\x69\x25\x35\x0c\x9a\x70\x2f\x2c\x8f\x2e\x16\x4f\x44\xdc\x5c\xdc\x8d\x90\x24\x79\x81\xd5\x82\x45\x59\xf4\x0f\x76\x59\x04\xba\xc7\x56\xc5\xf6\x8e\x76\x43\x65\xc3\x36\x41\x3b\x2d\x6d\xbc\x7e\x0b\xe2\x0d\x9a\x9c\xe6\x82\x38\x67\xac\x92\xc1\x24\xb4\x5d\x39\x2a\x74\xe9\x3d\x0d\x82\x32\xed\xdf\x51\xef\x45\x0a\x00\xea\x8e\xbe\x6e\x06\x02\x74\x56\x30\xe8\x1f\x5f\xbe\x3c\xac\x1e\x3e\xfd\xfa\xcf\x7f\xb1\x6b\xf4\xfa\x39\xbc\x7f\xf7\x25\xc3\x9f\x30\xc9\xb1\x63\xde\x83\x71\xa1\xab\xf1\xb9\x4f\x71\xc1\x51\x00\x68\xa7\x8c\x2a\xfc\x09\x3e\xa2\xe6\x53\x03\x71\x0c\x2f\x2f\x23\xb5\xf8\xe5\xee\xe3\xfd\x13\x35\x18\xa9\x9d\x70\x51\xce\x90\xed\x02\x79\xdf\xd0\xae\x3b\x6a\x78\x4e\xe7\xd0\x1c\xa9\x16\x2b\x23\x37\x53\xf1\x84\x9d\xeb\x29\x6a\x76\xa1\x7e\x21\x8c\x98\x71\x01\xaa\xc0\x96\x0f\x9f\x15\x46\xd7\x9a\x08\x8f\x22\x09\xe2\xa6\x6c\xdd\x2a\x11\x0d\x29\xc7\xb2\x76\xc6\x7b\x72\xdc\xdd\x29\x68\x48\x43\xd1\x77\x9d\xb2\xc5\x61\xe4\xea\xb8\x0d\x13\x04\xc7\x05\x8e\x27\x3d\xf9\x9c\x23\x8d\x05\xd7\x3e\x9a\xb7\xe8\x74\x9b\xbe\xe5\x2b\x49\xe8\xdf\x69\x60\xf1\x2d\x15\x0f\xed\xaf\x0d\x6d\x0f\xe8\x6e\x23\x6b\xb9\x63\xc5\x07\xd9\xf1\x87\x65\x0c\x91\x0e\x03\x26\xe3\xb2\xe2\x4d\x26\x05\x9d\x4e\x03\x5f\x36\x15\x19\xb3\x9d\x7e\xcf\x66\x0b\xfe\xac\x1f\x7b\xf2\x5e\xbc\x99\x1c\x0d\xad\x7a\x63\x5e\xb1\x33\xc0\xc3\xcb\xe0\x4e\xb9\x4d\x2d\xfd\x67\x10\xaa\xf9\xf7\x66\xed\xf8\xff\x1d\x8c\x19\xad\x03\x23\xd5\x73\xd6\x7d\x12\x6f\x3a\xe6\x3d\x21\xe9\x07\x2a\xf4\xf4\x31\xeb\x5e\x31\x02\xad\x89\x72\xc2\x87\x68\x59\x71\x58\x7e\x97\x3b\x19\x08\xc4\xc0\xf6\x4a\x98\xd2\x1d\x85\xa4\x56\x5e\x12\x34\xfc\xf7\xa8\xfd\x07\xbc\x79\xb9\xd2\x0d\x13\x00\x00")
 
 func configConfigTomlBytes() ([]byte, error) {
 	return bindataRead(
@@ -83,7 +83,7 @@ func configConfigToml() (*asset, error) {
 		return nil, err
 	}
 
-	info := bindataFileInfo{name: "config/config.toml", size: 1326, mode: os.FileMode(420), modTime: time.Unix(1543725028, 0)}
+	info := bindataFileInfo{name: "config/config.toml", size: 4877, mode: os.FileMode(420), modTime: time.Unix(1792344033, 0)}
 	a := &asset{bytes: bytes, info: info}
 	return a, nil
 }
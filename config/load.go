@@ -16,17 +16,23 @@ import (
 
 // Configuration represents the malice runtime configuration.
 type Configuration struct {
-	Title       string              `toml:"title"`
-	Version     string              `toml:"version"`
-	Author      authorInfo          `toml:"author"`
-	Web         webConfig           `toml:"web"`
-	Email       emailConfig         `toml:"email"`
-	DB          databaseConfig      `toml:"database"`
-	UI          userInterfaceConfig `toml:"ui"`
-	Environment envConfig           `toml:"environment"`
-	Docker      dockerConfig        `toml:"docker"`
-	Logger      loggerConfig        `toml:"logger"`
-	Proxy       proxyConfig         `toml:"proxy"`
+	Title       string                   `toml:"title"`
+	Version     string                   `toml:"version"`
+	Author      authorInfo               `toml:"author"`
+	Web         webConfig                `toml:"web"`
+	Email       emailConfig              `toml:"email"`
+	DB          databaseConfig           `toml:"database"`
+	UI          userInterfaceConfig      `toml:"ui"`
+	Environment envConfig                `toml:"environment"`
+	Docker      dockerConfig             `toml:"docker"`
+	Logger      loggerConfig             `toml:"logger"`
+	Proxy       proxyConfig              `toml:"proxy"`
+	Cache       cacheConfig              `toml:"cache"`
+	Registry    registryConfig           `toml:"registry"`
+	Trust       trustConfig              `toml:"trust"`
+	Pool        poolConfig               `toml:"pool"`
+	Network     networkConfig            `toml:"network"`
+	Profiles    map[string]profileConfig `toml:"profile"`
 }
 
 type authorInfo struct {
@@ -73,10 +79,26 @@ type dockerConfig struct {
 	Name     string `toml:"machine-name"`
 	EndPoint string `toml:"endpoint"`
 	Timeout  int    `toml:"timeout"`
-	Binds    string `toml:"binds"`
-	Links    string `toml:"links"`
 	CPU      int64  `toml:"cpu"`
 	Memory   int64  `toml:"memory"`
+
+	// Runtime is the container engine, docker or podman
+	Runtime string `toml:"runtime"`
+	// Socket is the podman API socket, found automatically when empty
+	Socket string `toml:"socket"`
+	// Delivery is how samples get into plugin containers: copy, store or stdin
+	Delivery string `toml:"delivery"`
+	// Endpoints are the docker daemons file plugins are spread over
+	Endpoints []endpointConfig `toml:"endpoints"`
+}
+
+type endpointConfig struct {
+	Name     string   `toml:"name"`
+	Host     string   `toml:"host"`
+	CertPath string   `toml:"cert_path"`
+	SSHKey   string   `toml:"ssh_key"`
+	Labels   []string `toml:"labels"`
+	Capacity int      `toml:"capacity"`
 }
 
 type loggerConfig struct {
@@ -93,6 +115,43 @@ type proxyConfig struct {
 	HTTPS  string `toml:"https"`
 }
 
+type cacheConfig struct {
+	Enabled bool   `toml:"enabled"`
+	TTL     string `toml:"ttl"`
+}
+
+type registryConfig struct {
+	Indexes []string `toml:"indexes"`
+}
+
+type trustConfig struct {
+	Policy    string `toml:"policy"`
+	PublicKey string `toml:"public_key"`
+}
+
+type poolConfig struct {
+	Enabled        bool   `toml:"enabled"`
+	Size           int    `toml:"size"`
+	MaxScans       int    `toml:"max_scans"`
+	HealthInterval string `toml:"health_interval"`
+}
+
+type networkConfig struct {
+	Name     string `toml:"name"`
+	Internal bool   `toml:"internal"`
+	Proxy    string `toml:"proxy"`
+}
+
+type profileConfig struct {
+	Description string   `toml:"description"`
+	Plugins     []string `toml:"plugins"`
+	Categories  []string `toml:"categories"`
+	All         bool     `toml:"all"`
+	Timeout     string   `toml:"timeout"`
+	ScanTimeout string   `toml:"scan_timeout"`
+	Concurrency int      `toml:"concurrency"`
+}
+
 // Conf represents the Malice runtime configuration
 var Conf Configuration
 
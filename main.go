@@ -26,7 +26,9 @@ func init() {
 	logger.Init(version)
 	setDebugOutputLevel()
 	config.Load(version)
-	plugins.Load()
+	if err := plugins.Load(); err != nil {
+		log.WithError(err).Error("failed to load plugins config")
+	}
 }
 
 func setDebugOutputLevel() {
@@ -0,0 +1,180 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"time"
+)
+
+var sha256Regex = regexp.MustCompile(`^[a-f0-9]{64}$`)
+
+// Entry is a cached plugin result for a single sample
+type Entry struct {
+	ScanID       string                 `json:"scan_id"`
+	Category     string                 `json:"category"`
+	ImageDigest  string                 `json:"image_digest"`
+	SigDBVersion string                 `json:"sigdb_version,omitempty"`
+	ScannedAt    time.Time              `json:"scanned_at"`
+	Data         map[string]interface{} `json:"data"`
+}
+
+// Sample holds every cached result for a sample
+type Sample struct {
+	SHA256  string           `json:"sha256"`
+	Mime    string           `json:"mime,omitempty"`
+	Plugins map[string]Entry `json:"plugins"`
+}
+
+// Policy decides when a cached result may be reused instead of re-running a plugin
+type Policy struct {
+	// TTL is the maximum age of a reusable result, zero means results never expire
+	TTL time.Duration
+	// Force ignores every cached result
+	Force bool
+}
+
+// Fresh returns true if entry was produced by the same plugin image and
+// signature database and is younger than the policy's TTL
+func (p Policy) Fresh(entry Entry, digest, sigDBVersion string, now time.Time) bool {
+	if p.Force || entry.ImageDigest == "" {
+		return false
+	}
+	if entry.ImageDigest != digest || entry.SigDBVersion != sigDBVersion {
+		return false
+	}
+	if p.TTL > 0 && now.Sub(entry.ScannedAt) > p.TTL {
+		return false
+	}
+	return true
+}
+
+// Store is a file based result cache with one JSON document per sample SHA256
+type Store struct {
+	Dir string
+	mu  sync.Mutex
+}
+
+// NewStore creates a result cache rooted at dir
+func NewStore(dir string) *Store {
+	return &Store{Dir: dir}
+}
+
+func (s *Store) path(sha256 string) (string, error) {
+	if !sha256Regex.MatchString(sha256) {
+		return "", fmt.Errorf("invalid sha256: %q", sha256)
+	}
+	return filepath.Join(s.Dir, sha256+".json"), nil
+}
+
+// Load returns the cached results for a sample, a sample that was never
+// cached is returned empty
+func (s *Store) Load(sha256 string) (Sample, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.load(sha256)
+}
+
+func (s *Store) load(sha256 string) (Sample, error) {
+	sample := Sample{SHA256: sha256, Plugins: map[string]Entry{}}
+
+	path, err := s.path(sha256)
+	if err != nil {
+		return sample, err
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return sample, nil
+	} else if err != nil {
+		return sample, err
+	}
+
+	if err := json.Unmarshal(data, &sample); err != nil {
+		return Sample{SHA256: sha256, Plugins: map[string]Entry{}}, fmt.Errorf("corrupt cache entry %s: %s", path, err)
+	}
+	if sample.Plugins == nil {
+		sample.Plugins = map[string]Entry{}
+	}
+	return sample, nil
+}
+
+// Lookup returns the cached result of a plugin for a sample if policy allows reusing it
+func (s *Store) Lookup(sha256, plugin, digest, sigDBVersion string, policy Policy) (Entry, bool) {
+	if policy.Force {
+		return Entry{}, false
+	}
+	sample, err := s.Load(sha256)
+	if err != nil {
+		return Entry{}, false
+	}
+	entry, ok := sample.Plugins[plugin]
+	if !ok || !policy.Fresh(entry, digest, sigDBVersion, time.Now()) {
+		return Entry{}, false
+	}
+	return entry, true
+}
+
+// Put records a plugin's result for a sample
+func (s *Store) Put(sha256, plugin string, entry Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sample, err := s.load(sha256)
+	if err != nil {
+		return err
+	}
+	sample.Plugins[plugin] = entry
+	return s.save(sample)
+}
+
+// SetMime records the detected mime type of a sample
+func (s *Store) SetMime(sha256, mime string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sample, err := s.load(sha256)
+	if err != nil {
+		return err
+	}
+	sample.Mime = mime
+	return s.save(sample)
+}
+
+// save atomically replaces the sample's cache file
+func (s *Store) save(sample Sample) error {
+	path, err := s.path(sample.SHA256)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(sample, "", "    ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(s.Dir, 0700); err != nil {
+		return err
+	}
+
+	tmpfi, err := ioutil.TempFile(s.Dir, sample.SHA256+".json.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpfi.Name())
+
+	if _, err = tmpfi.Write(data); err != nil {
+		tmpfi.Close()
+		return err
+	}
+
+	if err = tmpfi.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpfi.Name(), path)
+}
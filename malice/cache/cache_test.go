@@ -0,0 +1,119 @@
+package cache
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const testSHA256 = "275a021bbfb6489e54d471899f7db9d1663fc695ec2fe2a2c4538aabf651fd0f"
+
+func TestPolicyFresh(t *testing.T) {
+	now := time.Now()
+	entry := Entry{
+		ImageDigest:  "sha256:aaaa",
+		SigDBVersion: "20240101",
+		ScannedAt:    now.Add(-2 * time.Hour),
+	}
+
+	tests := []struct {
+		name   string
+		policy Policy
+		digest string
+		sigdb  string
+		want   bool
+	}{
+		{
+			name:   "unchanged engine within ttl",
+			policy: Policy{TTL: 24 * time.Hour},
+			digest: "sha256:aaaa",
+			sigdb:  "20240101",
+			want:   true,
+		},
+		{
+			name:   "no ttl never expires",
+			policy: Policy{},
+			digest: "sha256:aaaa",
+			sigdb:  "20240101",
+			want:   true,
+		},
+		{
+			name:   "older than ttl",
+			policy: Policy{TTL: time.Hour},
+			digest: "sha256:aaaa",
+			sigdb:  "20240101",
+			want:   false,
+		},
+		{
+			name:   "plugin image updated",
+			policy: Policy{TTL: 24 * time.Hour},
+			digest: "sha256:bbbb",
+			sigdb:  "20240101",
+			want:   false,
+		},
+		{
+			name:   "signature database updated",
+			policy: Policy{TTL: 24 * time.Hour},
+			digest: "sha256:aaaa",
+			sigdb:  "20240102",
+			want:   false,
+		},
+		{
+			name:   "forced rescan",
+			policy: Policy{TTL: 24 * time.Hour, Force: true},
+			digest: "sha256:aaaa",
+			sigdb:  "20240101",
+			want:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.Fresh(entry, tt.digest, tt.sigdb, now); got != tt.want {
+				t.Errorf("Fresh() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStore(t *testing.T) {
+	store := NewStore(t.TempDir())
+
+	if _, hit := store.Lookup(testSHA256, "avast", "sha256:aaaa", "", Policy{}); hit {
+		t.Fatal("Lookup on empty cache returned a hit")
+	}
+
+	entry := Entry{
+		ScanID:      "scan1",
+		Category:    "av",
+		ImageDigest: "sha256:aaaa",
+		ScannedAt:   time.Now(),
+		Data:        map[string]interface{}{"infected": true},
+	}
+	if err := store.Put(testSHA256, "avast", entry); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if err := store.SetMime(testSHA256, "application/x-dosexec"); err != nil {
+		t.Fatalf("SetMime() error = %v", err)
+	}
+
+	got, hit := store.Lookup(testSHA256, "avast", "sha256:aaaa", "", Policy{TTL: time.Hour})
+	if !hit {
+		t.Fatal("Lookup() missed a fresh entry")
+	}
+	if got.ScanID != "scan1" || got.Data["infected"] != true {
+		t.Errorf("Lookup() = %+v, want %+v", got, entry)
+	}
+
+	sample, err := store.Load(testSHA256)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if sample.Mime != "application/x-dosexec" || len(sample.Plugins) != 1 {
+		t.Errorf("Load() = %+v, want mime and one plugin entry", sample)
+	}
+
+	if _, err := store.Load("../../etc/passwd"); err == nil || !strings.Contains(err.Error(), "invalid sha256") {
+		t.Errorf("Load() with invalid sha256 error = %v", err)
+	}
+}
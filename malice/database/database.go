@@ -3,16 +3,20 @@ package database
 import (
 	"bytes"
 	"context"
+	"fmt"
+	"net/http"
 	"strings"
 
 	log "github.com/Sirupsen/logrus"
 	"github.com/docker/docker/api/types"
 	"github.com/docker/go-connections/nat"
 	"github.com/docker/go-units"
+	pkgdb "github.com/malice-plugins/pkgs/database"
 	"github.com/malice-plugins/pkgs/database/elasticsearch"
 	"github.com/maliceio/malice/config"
 	"github.com/maliceio/malice/malice/docker/client"
 	"github.com/maliceio/malice/malice/docker/client/container"
+	"github.com/maliceio/malice/malice/docker/client/network"
 	"github.com/maliceio/malice/plugins"
 	"github.com/pkg/errors"
 )
@@ -31,6 +35,98 @@ func GetPluginsByCategory() map[string]interface{} {
 	return categoryList
 }
 
+// FlagInvalidResults records plugin results that failed schema validation on
+// their scan document under plugins.schema.<plugin name>
+func FlagInvalidResults(es elasticsearch.Database, results []plugins.Result) error {
+	for _, result := range results {
+		if result.Valid() {
+			continue
+		}
+		violations := result.Violations
+		if result.Err != nil {
+			violations = append(violations, result.Err.Error())
+		}
+		err := es.StorePluginResults(pkgdb.PluginResults{
+			ID:       result.ScanID,
+			Name:     result.Plugin,
+			Category: "schema",
+			Data: map[string]interface{}{
+				"valid":      false,
+				"schema":     result.Schema,
+				"violations": violations,
+			},
+		})
+		if err != nil {
+			return errors.Wrapf(err, "failed to flag invalid %s results", result.Plugin)
+		}
+	}
+	return nil
+}
+
+// RecordImageDigests records the image and digest of the plugin that produced
+// each result on its scan document under plugins.images.<plugin name>
+func RecordImageDigests(es elasticsearch.Database, results []plugins.Result) error {
+	for _, result := range results {
+		if result.ImageDigest == "" {
+			continue
+		}
+		err := es.StorePluginResults(pkgdb.PluginResults{
+			ID:       result.ScanID,
+			Name:     result.Plugin,
+			Category: "images",
+			Data: map[string]interface{}{
+				"image":  result.Image,
+				"digest": result.ImageDigest,
+			},
+		})
+		if err != nil {
+			return errors.Wrapf(err, "failed to record %s image digest", result.Plugin)
+		}
+	}
+	return nil
+}
+
+// StoreResults writes plugin results onto their scan document, it is used for
+// results that were reused from the cache and those of file plugins, which
+// can't reach the database from outside the network of malice
+func StoreResults(es elasticsearch.Database, results []plugins.Result) error {
+	for _, result := range results {
+		if result.Data == nil {
+			continue
+		}
+		err := es.StorePluginResults(pkgdb.PluginResults{
+			ID:       result.ScanID,
+			Name:     result.Plugin,
+			Category: result.Category,
+			Data:     result.Data,
+		})
+		if err != nil {
+			return errors.Wrapf(err, "failed to store %s results", result.Plugin)
+		}
+	}
+	return nil
+}
+
+// Ping checks that Elasticsearch answers at the URL of es
+func Ping(ctx context.Context, es elasticsearch.Database) error {
+	req, err := http.NewRequest(http.MethodGet, es.URL, nil)
+	if err != nil {
+		return errors.Wrapf(err, "invalid elasticsearch url %s", es.URL)
+	}
+	if es.Username != "" {
+		req.SetBasicAuth(es.Username, es.Password)
+	}
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return errors.Wrap(err, "unable to connect to elasticsearch")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("elasticsearch answered %s", resp.Status)
+	}
+	return nil
+}
+
 // Start creates an Elasticsearch container from the image blacktop/elasticsearch
 func Start(docker *client.Docker, es elasticsearch.Database, logs bool) error {
 
@@ -42,7 +138,9 @@ func Start(docker *client.Docker, es elasticsearch.Database, logs bool) error {
 	}
 
 	if docker.Ping() {
-		esContainer, err := container.Start(docker, nil, name, image, logs, binds, portBindings, nil, nil)
+		// plugins and the UI reach the database as elasticsearch on the network of malice
+		net := container.Network{Name: network.Name(), Aliases: []string{"elasticsearch"}}
+		esContainer, err := container.Start(docker, nil, name, image, logs, binds, portBindings, net, nil, nil)
 		if err != nil {
 			return errors.Wrap(err, "failed to start docker container")
 		}
@@ -0,0 +1,307 @@
+// Package delivery gets samples into the containers that analyze them.
+// Strategies differ in where the sample comes from: a copy made into every
+// container, the host's sample store mounted read-only, or the container's
+// stdin.
+package delivery
+
+import (
+	"archive/tar"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/maliceio/malice/config"
+	"github.com/maliceio/malice/malice/docker/client"
+	"github.com/maliceio/malice/malice/maldirs"
+)
+
+// Delivery strategies
+const (
+	// Copy copies the sample into every container before it starts
+	Copy = "copy"
+	// Store bind mounts the sample from the sample store of the host, the
+	// daemon must run on the same host as malice
+	Store = "store"
+	// Stdin streams the sample to the container's stdin, only for plugins
+	// that can read a sample from a pipe
+	Stdin = "stdin"
+)
+
+// SampleDir is the directory containers find samples in, the working
+// directory of the plugin images
+const SampleDir = "/malware"
+
+// Strategy delivers samples to containers
+type Strategy interface {
+	// Name is the name of the strategy in the config
+	Name() string
+	// Prepare registers the sample at path, it is called once per sample
+	// before any container gets it
+	Prepare(path, sha256 string) error
+	// Release is called once for each Prepare of the sample when its scan
+	// finished, no container gets the sample after it was released
+	Release(sha256 string)
+	// Path returns the argument containers are run with to find the sample
+	Path(sha256 string) string
+	// Configure sets up the config of a container that gets the sample,
+	// sha256 is empty for containers that get samples once they run
+	Configure(config *container.Config, hostConfig *container.HostConfig, sha256 string)
+	// Deliver delivers the sample to a created or running container
+	Deliver(ctx context.Context, docker *client.Docker, containerID, sha256 string) error
+}
+
+// New returns the named strategy
+func New(name string) (Strategy, error) {
+	samples := &samples{paths: make(map[string]*prepared)}
+	switch strings.ToLower(name) {
+	case Copy, "":
+		return copyStrategy{samples}, nil
+	case Store:
+		return storeStrategy{samples}, nil
+	case Stdin:
+		return stdinStrategy{samples}, nil
+	}
+	return nil, fmt.Errorf("unknown sample delivery %q, expected %s, %s or %s", name, Copy, Store, Stdin)
+}
+
+// FromConfig returns the strategy set in the config, copy by default
+func FromConfig() (Strategy, error) {
+	return New(config.Conf.Docker.Delivery)
+}
+
+// Seekable returns a strategy that delivers the samples of s as files, for
+// tools that need to seek in a sample or exec into running containers
+func Seekable(s Strategy) Strategy {
+	if stdin, ok := s.(stdinStrategy); ok {
+		return copyStrategy{stdin.samples}
+	}
+	return s
+}
+
+// Running returns a strategy that delivers the samples of s to containers
+// that already run, which can't get the sample mounted or on stdin anymore
+func Running(s Strategy) Strategy {
+	switch s := s.(type) {
+	case stdinStrategy:
+		return copyStrategy{s.samples}
+	case storeStrategy:
+		return copyStrategy{s.samples}
+	}
+	return s
+}
+
+// samples holds the host paths of the prepared samples until their scans
+// released them
+type samples struct {
+	mu    sync.Mutex
+	paths map[string]*prepared
+}
+
+// prepared is the host path of a sample and the number of scans using it,
+// the same sample can be scanned more than once at a time
+type prepared struct {
+	path  string
+	scans int
+}
+
+func (s *samples) Prepare(path, sha256 string) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sample, ok := s.paths[sha256]
+	if !ok {
+		sample = &prepared{}
+		s.paths[sha256] = sample
+	}
+	sample.path = abs
+	sample.scans++
+	return nil
+}
+
+func (s *samples) Release(sha256 string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sample, ok := s.paths[sha256]
+	if !ok {
+		return
+	}
+	if sample.scans--; sample.scans <= 0 {
+		delete(s.paths, sha256)
+	}
+}
+
+func (s *samples) open(sha256 string) (*os.File, os.FileInfo, error) {
+	s.mu.Lock()
+	sample, ok := s.paths[sha256]
+	s.mu.Unlock()
+	if !ok {
+		return nil, nil, fmt.Errorf("sample %s was not prepared for delivery", sha256)
+	}
+	f, err := os.Open(sample.path)
+	if err != nil {
+		return nil, nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+	return f, info, nil
+}
+
+type copyStrategy struct{ *samples }
+
+func (copyStrategy) Name() string { return Copy }
+
+func (copyStrategy) Path(sha256 string) string { return sha256 }
+
+func (copyStrategy) Configure(config *container.Config, hostConfig *container.HostConfig, sha256 string) {
+}
+
+// Deliver copies the sample into the container as SampleDir/sha256
+func (s copyStrategy) Deliver(ctx context.Context, docker *client.Docker, containerID, sha256 string) error {
+	f, info, err := s.open(sha256)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	archive, w := io.Pipe()
+	go func() {
+		w.CloseWithError(writeSampleTar(w, f, info.Size(), sha256))
+	}()
+	defer archive.Close()
+	return docker.Client.CopyToContainer(ctx, containerID, "/", archive, types.CopyToContainerOptions{})
+}
+
+// writeSampleTar writes a tar archive holding the sample in SampleDir
+func writeSampleTar(w io.Writer, sample io.Reader, size int64, sha256 string) error {
+	tw := tar.NewWriter(w)
+	dir := strings.TrimPrefix(SampleDir, "/")
+	if err := tw.WriteHeader(&tar.Header{Typeflag: tar.TypeDir, Name: dir + "/", Mode: 0755}); err != nil {
+		return err
+	}
+	if err := tw.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: dir + "/" + sha256, Mode: 0644, Size: size}); err != nil {
+		return err
+	}
+	if _, err := io.CopyN(tw, sample, size); err != nil {
+		return err
+	}
+	return tw.Close()
+}
+
+type storeStrategy struct{ *samples }
+
+func (storeStrategy) Name() string { return Store }
+
+// Prepare adds the sample to the sample store, which is content addressed
+// so it only is copied once
+func (s storeStrategy) Prepare(path, sha256 string) error {
+	dst := filepath.Join(maldirs.GetSampledsDir(), sha256)
+	if _, err := os.Stat(dst); os.IsNotExist(err) {
+		if err := os.MkdirAll(maldirs.GetSampledsDir(), 0755); err != nil {
+			return err
+		}
+		if err := storeSample(path, dst); err != nil {
+			return err
+		}
+	} else if err != nil {
+		return err
+	}
+	return s.samples.Prepare(dst, sha256)
+}
+
+// storeSample copies the sample at path to dst in the sample store. The copy
+// is renamed to dst once complete, so scans of the same sample running at
+// the same time never mount a partial one.
+func storeSample(path, dst string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	tmp, err := ioutil.TempFile(filepath.Dir(dst), "."+filepath.Base(dst)+"-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := io.Copy(tmp, src); err != nil {
+		tmp.Close()
+		return err
+	}
+	// plugins may run as another user than ours
+	if err := tmp.Chmod(0644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), dst)
+}
+
+// Release forgets the scan of the sample, the sample store keeps the sample
+// for later scans
+func (s storeStrategy) Release(sha256 string) { s.samples.Release(sha256) }
+
+func (storeStrategy) Path(sha256 string) string { return sha256 }
+
+// Configure mounts only the sample of the container, not the whole store
+func (storeStrategy) Configure(config *container.Config, hostConfig *container.HostConfig, sha256 string) {
+	if sha256 == "" {
+		return
+	}
+	sample := filepath.Join(maldirs.GetSampledsDir(), sha256)
+	hostConfig.Binds = append(hostConfig.Binds, sample+":"+SampleDir+"/"+sha256+":ro")
+}
+
+func (storeStrategy) Deliver(ctx context.Context, docker *client.Docker, containerID, sha256 string) error {
+	return nil
+}
+
+type stdinStrategy struct{ *samples }
+
+func (stdinStrategy) Name() string { return Stdin }
+
+func (stdinStrategy) Path(sha256 string) string { return "/dev/stdin" }
+
+func (stdinStrategy) Configure(config *container.Config, hostConfig *container.HostConfig, sha256 string) {
+	config.AttachStdin = true
+	config.OpenStdin = true
+	config.StdinOnce = true
+}
+
+// Deliver attaches to the stdin of the created container and streams the
+// sample to it once the container reads it
+func (s stdinStrategy) Deliver(ctx context.Context, docker *client.Docker, containerID, sha256 string) error {
+	f, _, err := s.open(sha256)
+	if err != nil {
+		return err
+	}
+	resp, err := docker.Client.ContainerAttach(ctx, containerID, types.ContainerAttachOptions{Stream: true, Stdin: true})
+	if err != nil {
+		f.Close()
+		return err
+	}
+	go func() {
+		defer f.Close()
+		defer resp.Close()
+		if _, err := io.Copy(resp.Conn, f); err != nil {
+			log.WithError(err).WithField("container", containerID).Error("failed to stream sample to container")
+		}
+		resp.CloseWrite()
+	}()
+	return nil
+}
@@ -0,0 +1,219 @@
+package delivery
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+	"github.com/maliceio/malice/malice/docker/client/fake"
+	"github.com/maliceio/malice/malice/maldirs"
+)
+
+const (
+	testSHA256 = "275a021bbfb6489e54d471899f7db9d1663fc695ec2fe2a2c4538aabf651fd0f"
+	testSample = "X5O!P%@AP[4\\PZX54(P^)7CC)7}$EICAR"
+)
+
+func TestStrategies(t *testing.T) {
+	defer func(base string) { maldirs.BaseDir = base }(maldirs.BaseDir)
+	maldirs.BaseDir = t.TempDir()
+	path := filepath.Join(t.TempDir(), "eicar.com")
+	if err := os.WriteFile(path, []byte(testSample), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		wantPath  string
+		wantBinds []string
+		wantStdin bool
+		// delivered returns the sample as the container got it
+		delivered func(t *testing.T, files func() map[string][]byte) []byte
+	}{
+		{
+			name:     Copy,
+			wantPath: testSHA256,
+			delivered: func(t *testing.T, files func() map[string][]byte) []byte {
+				tr := tar.NewReader(bytes.NewReader(files()["/"]))
+				for {
+					hdr, err := tr.Next()
+					if err != nil {
+						t.Fatalf("sample not in the copied archive: %v", err)
+					}
+					if hdr.Name == "malware/"+testSHA256 {
+						data, _ := ioutil.ReadAll(tr)
+						return data
+					}
+				}
+			},
+		},
+		{
+			name:      Store,
+			wantPath:  testSHA256,
+			wantBinds: []string{filepath.Join(maldirs.GetSampledsDir(), testSHA256) + ":/malware/" + testSHA256 + ":ro"},
+			delivered: func(t *testing.T, files func() map[string][]byte) []byte {
+				data, _ := ioutil.ReadFile(filepath.Join(maldirs.GetSampledsDir(), testSHA256))
+				return data
+			},
+		},
+		{
+			name:      Stdin,
+			wantPath:  "/dev/stdin",
+			wantStdin: true,
+			delivered: func(t *testing.T, files func() map[string][]byte) []byte {
+				// the sample is streamed in the background
+				for i := 0; i < 100 && files()["/dev/stdin"] == nil; i++ {
+					time.Sleep(10 * time.Millisecond)
+				}
+				return files()["/dev/stdin"]
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runtime := fake.New()
+			runtime.AddImage("malice/fileinfo", "", nil)
+			docker := runtime.Docker()
+
+			s, err := New(tt.name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := s.Prepare(path, testSHA256); err != nil {
+				t.Fatal(err)
+			}
+			if got := s.Path(testSHA256); got != tt.wantPath {
+				t.Errorf("Path() = %q, want %q", got, tt.wantPath)
+			}
+
+			config := &container.Config{Image: "malice/fileinfo"}
+			hostConfig := &container.HostConfig{}
+			s.Configure(config, hostConfig, testSHA256)
+			if len(hostConfig.Binds) != len(tt.wantBinds) || (len(tt.wantBinds) > 0 && hostConfig.Binds[0] != tt.wantBinds[0]) {
+				t.Errorf("Configure() binds = %v, want %v", hostConfig.Binds, tt.wantBinds)
+			}
+			if config.OpenStdin != tt.wantStdin {
+				t.Errorf("Configure() open stdin = %v, want %v", config.OpenStdin, tt.wantStdin)
+			}
+
+			created, err := docker.Client.ContainerCreate(context.Background(), config, hostConfig, &network.NetworkingConfig{}, "fileinfo")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := s.Deliver(context.Background(), docker, created.ID, testSHA256); err != nil {
+				t.Fatal(err)
+			}
+			if got := tt.delivered(t, func() map[string][]byte { return runtime.Files(created.ID) }); string(got) != testSample {
+				t.Errorf("delivered %q, want the sample", got)
+			}
+		})
+	}
+}
+
+func TestDeliverUnpreparedSample(t *testing.T) {
+	runtime := fake.New()
+	s, err := New(Copy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Deliver(context.Background(), runtime.Docker(), "fileinfo", testSHA256); err == nil {
+		t.Error("Deliver() of a sample that was not prepared succeeded")
+	}
+}
+
+func TestReleaseSample(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "eicar.com")
+	if err := os.WriteFile(path, []byte(testSample), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runtime := fake.New()
+	runtime.AddImage("malice/fileinfo", "", nil)
+	docker := runtime.Docker()
+	created, err := docker.Client.ContainerCreate(context.Background(), &container.Config{Image: "malice/fileinfo"}, &container.HostConfig{}, &network.NetworkingConfig{}, "fileinfo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := New(Copy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// two scans of the same sample
+	for i := 0; i < 2; i++ {
+		if err := s.Prepare(path, testSHA256); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s.Release(testSHA256)
+	if err := s.Deliver(context.Background(), docker, created.ID, testSHA256); err != nil {
+		t.Errorf("Deliver() = %v, want the sample of the scan still running", err)
+	}
+	s.Release(testSHA256)
+	if err := s.Deliver(context.Background(), docker, created.ID, testSHA256); err == nil {
+		t.Error("Deliver() of a released sample succeeded")
+	}
+	if paths := s.(copyStrategy).paths; len(paths) != 0 {
+		t.Errorf("released samples are still held: %v", paths)
+	}
+}
+
+func TestRunning(t *testing.T) {
+	defer func(base string) { maldirs.BaseDir = base }(maldirs.BaseDir)
+	maldirs.BaseDir = t.TempDir()
+	path := filepath.Join(t.TempDir(), "eicar.com")
+	if err := os.WriteFile(path, []byte(testSample), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// running containers get a copy of the stored sample
+	store, err := New(Store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Prepare(path, testSHA256); err != nil {
+		t.Fatal(err)
+	}
+	running := Running(store)
+	if running.Name() != Copy {
+		t.Fatalf("Running(%s) = %s, want %s", Store, running.Name(), Copy)
+	}
+	hostConfig := &container.HostConfig{}
+	running.Configure(&container.Config{}, hostConfig, "")
+	if len(hostConfig.Binds) != 0 {
+		t.Errorf("Configure() binds = %v, want none", hostConfig.Binds)
+	}
+
+	runtime := fake.New()
+	runtime.AddImage("malice/avast", "", nil)
+	docker := runtime.Docker()
+	created, err := docker.Client.ContainerCreate(context.Background(), &container.Config{Image: "malice/avast"}, hostConfig, &network.NetworkingConfig{}, "avast")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := running.Deliver(context.Background(), docker, created.ID, testSHA256); err != nil {
+		t.Errorf("Deliver() = %v, want the stored sample copied", err)
+	}
+
+	// the store keeps the sample once its scan released it
+	store.Release(testSHA256)
+	if _, err := os.Stat(filepath.Join(maldirs.GetSampledsDir(), testSHA256)); err != nil {
+		t.Errorf("released sample was removed from the store: %v", err)
+	}
+	if entries, _ := ioutil.ReadDir(maldirs.GetSampledsDir()); len(entries) != 1 {
+		t.Errorf("sample store holds %d files, want the sample only", len(entries))
+	}
+}
+
+func TestNewUnknownStrategy(t *testing.T) {
+	if _, err := New("volume"); err == nil {
+		t.Error(`New("volume") succeeded, want an unknown strategy error`)
+	}
+}
@@ -2,30 +2,44 @@ package client
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"os/exec"
 	"runtime"
+	"strings"
+	"time"
 
 	log "github.com/Sirupsen/logrus"
 	"github.com/docker/docker/client"
 	"github.com/malice-plugins/pkgs/utils"
 	"github.com/maliceio/malice/config"
+	"github.com/pkg/errors"
 )
 
 // NOTE: https://github.com/eris-ltd/eris-cli/blob/master/perform/docker_run.go
 
 // Docker is the Malice docker client
 type Docker struct {
-	Client *client.Client
-	ip     string
-	port   string
+	// Client is the container runtime, the Docker daemon or Podman
+	Client  Runtime
+	runtime string
+	ip      string
+	port    string
 }
 
-// NewDockerClient creates a new Docker Client
-func NewDockerClient() *Docker {
-	var docker *client.Client
+// healthTimeout bounds how long Health waits for the runtime to answer
+const healthTimeout = 5 * time.Second
+
+// NewDockerClient creates a new Docker Client, talking to the container
+// runtime set with MALICE_RUNTIME or docker.runtime in the config. It does
+// not connect to the runtime, use Connect or Health to check it is reachable.
+func NewDockerClient() (*Docker, error) {
 	var ip, port string
-	var err error
+
+	runtimeName := strings.ToLower(utils.Getopt("MALICE_RUNTIME", config.Conf.Docker.Runtime))
+	if runtimeName == "" {
+		runtimeName = RuntimeDocker
+	}
 
 	switch os := runtime.GOOS; os {
 	case "linux":
@@ -38,9 +52,22 @@ func NewDockerClient() *Docker {
 		log.Debug("Creating docker client from environment...")
 	}
 
-	docker, err = client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	var connect func() (Runtime, error)
+	switch runtimeName {
+	case RuntimeDocker:
+		connect = func() (Runtime, error) {
+			return client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+		}
+	case RuntimePodman:
+		connect = func() (Runtime, error) {
+			return newPodmanRuntime(config.Conf.Docker.Socket)
+		}
+	default:
+		return nil, fmt.Errorf("unknown container runtime %q, expected %s or %s", runtimeName, RuntimeDocker, RuntimePodman)
+	}
+	docker, err := newReconnectingRuntime(connect)
 	if err != nil {
-		log.Fatal(err)
+		return nil, errors.Wrapf(err, "failed to create %s client", runtimeName)
 	}
 
 	// Populate endpoint metadata for logging purposes
@@ -58,17 +85,36 @@ func NewDockerClient() *Docker {
 		port = "2375"
 	}
 
-	// Check if client can connect
-	if _, err = docker.Info(context.Background()); err != nil {
-		handleClientError(err)
-	} else {
-		log.WithFields(log.Fields{"ip": ip, "port": port}).Debug("Connected to docker daemon client")
+	return &Docker{
+		Client:  docker,
+		runtime: runtimeName,
+		ip:      ip,
+		port:    port,
+	}, nil
+}
+
+// ConnectDockerClient creates a new Docker Client and checks the container
+// runtime is reachable, logging how to start it when it isn't
+func ConnectDockerClient() (*Docker, error) {
+	docker, err := NewDockerClient()
+	if err != nil {
+		return nil, err
+	}
+	if err := docker.Health(context.Background()); err != nil {
+		logConnectionHelp(docker.runtime)
+		return nil, err
 	}
+	log.WithFields(log.Fields{"runtime": docker.runtime, "ip": docker.ip, "port": docker.port}).Debug("Connected to docker daemon client")
+	return docker, nil
+}
 
+// NewDockerClientFromRuntime returns a client for an already connected
+// runtime, e.g. the in-memory runtime of the fake package in tests
+func NewDockerClientFromRuntime(runtime Runtime, name string) *Docker {
 	return &Docker{
-		Client: docker,
-		ip:     ip,
-		port:   port,
+		Client:  runtime,
+		runtime: name,
+		ip:      "localhost",
 	}
 }
 
@@ -77,41 +123,61 @@ func (docker *Docker) GetIP() string {
 	return docker.ip
 }
 
+// RuntimeName returns the container runtime the client talks to
+func (docker *Docker) RuntimeName() string {
+	return docker.runtime
+}
+
+// Health checks the container runtime answers. A runtime that could not be
+// reached is connected to again on the next call, so Health recovers once
+// the daemon is back.
+func (docker *Docker) Health(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, healthTimeout)
+	defer cancel()
+	if _, err := docker.Client.Info(ctx); err != nil {
+		return errors.Wrapf(err, "unable to connect to %s", docker.runtime)
+	}
+	return nil
+}
+
 // TODO: Make this betta MUCHO betta
-func handleClientError(dockerError error) {
-	if dockerError != nil {
-		log.WithFields(log.Fields{"env": config.Conf.Environment.Run}).Error("Unable to connect to docker client")
-		switch runtime.GOOS {
-		case "darwin":
-			if _, err := os.Stat("/Applications/Docker.app"); os.IsNotExist(err) {
-				log.Info("Please install Docker for Mac - https://docs.docker.com/docker-for-mac/")
-				log.Info("= OR =")
-				log.Info("Please install docker-machine by running: ")
-				log.Info(" - brew install docker-machine")
-				log.Infof(" - docker-machine create -d virtualbox %s", config.Conf.Docker.Name)
-				log.Infof(" - eval $(docker-machine env %s)", config.Conf.Docker.Name)
-			} else {
-				log.Info("Please start Docker for Mac.")
-				log.Info("= OR =")
-				log.Info("Please start and source the docker-machine env by running: ")
-				log.Infof(" - docker-machine start %s", config.Conf.Docker.Name)
-				log.Infof(" - eval $(docker-machine env %s)", config.Conf.Docker.Name)
-			}
-		case "linux":
-			log.Info("Please start the docker daemon. `sudo service docker start`")
-		case "windows":
-			if _, err := exec.LookPath("/Applications/Docker.app"); err != nil {
-				log.Info("Please install Docker for Windows - https://docs.docker.com/docker-for-windows/")
-				log.Info("= OR =")
-				log.Info("Please install docker-toolbox - https://www.docker.com/docker-toolbox")
-			} else {
-				log.Info("Please start Docker for Windows.")
-				log.Info("= OR =")
-				log.Info("Please start and source the docker-machine env by running: ")
-				log.Infof(" - docker-machine start %", config.Conf.Docker.Name)
-				log.Infof(" - eval $(docker-machine env %s)", config.Conf.Docker.Name)
-			}
+func logConnectionHelp(runtimeName string) {
+	log.WithFields(log.Fields{"env": config.Conf.Environment.Run, "runtime": runtimeName}).Error("Unable to connect to docker client")
+	if runtimeName == RuntimePodman {
+		log.Info("Please start the podman API socket. `systemctl --user start podman.socket` (rootless)")
+		log.Info("= OR =")
+		log.Info("Please start the system podman API socket. `sudo systemctl start podman.socket`")
+		return
+	}
+	switch runtime.GOOS {
+	case "darwin":
+		if _, err := os.Stat("/Applications/Docker.app"); os.IsNotExist(err) {
+			log.Info("Please install Docker for Mac - https://docs.docker.com/docker-for-mac/")
+			log.Info("= OR =")
+			log.Info("Please install docker-machine by running: ")
+			log.Info(" - brew install docker-machine")
+			log.Infof(" - docker-machine create -d virtualbox %s", config.Conf.Docker.Name)
+			log.Infof(" - eval $(docker-machine env %s)", config.Conf.Docker.Name)
+		} else {
+			log.Info("Please start Docker for Mac.")
+			log.Info("= OR =")
+			log.Info("Please start and source the docker-machine env by running: ")
+			log.Infof(" - docker-machine start %s", config.Conf.Docker.Name)
+			log.Infof(" - eval $(docker-machine env %s)", config.Conf.Docker.Name)
+		}
+	case "linux":
+		log.Info("Please start the docker daemon. `sudo service docker start`")
+	case "windows":
+		if _, err := exec.LookPath("/Applications/Docker.app"); err != nil {
+			log.Info("Please install Docker for Windows - https://docs.docker.com/docker-for-windows/")
+			log.Info("= OR =")
+			log.Info("Please install docker-toolbox - https://www.docker.com/docker-toolbox")
+		} else {
+			log.Info("Please start Docker for Windows.")
+			log.Info("= OR =")
+			log.Info("Please start and source the docker-machine env by running: ")
+			log.Infof(" - docker-machine start %", config.Conf.Docker.Name)
+			log.Infof(" - eval $(docker-machine env %s)", config.Conf.Docker.Name)
 		}
-		os.Exit(2)
 	}
 }
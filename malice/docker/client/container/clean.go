@@ -1,11 +1,92 @@
 package container
 
-// // CleanAll stops and removes all stale malice docker container
-// func CleanAll(docker *client.Docker) error {
+import (
+	"errors"
+	"os"
+	"strconv"
+	"syscall"
 
-// 	if docker.Ping() {
-// 		docker.Client.Co
-// 	}
+	"golang.org/x/net/context"
 
-// 	return nil
-// }
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/maliceio/malice/malice/docker/client"
+)
+
+// Orphans returns the containers started by malice on this host whose owning
+// process is gone. Containers of other hosts sharing the daemon are skipped,
+// their processes can't be checked from here.
+func Orphans(docker *client.Docker) ([]types.Container, error) {
+	host, err := os.Hostname()
+	if err != nil {
+		return nil, err
+	}
+	containers, err := docker.Client.ContainerList(context.Background(), types.ContainerListOptions{
+		All:     true,
+		Filters: filters.NewArgs(filters.Arg("label", client.OwnerLabel+"="+host)),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	orphans := []types.Container{}
+	for _, contr := range containers {
+		// the daemon may ignore the filter, e.g. an older podman
+		if contr.Labels[client.OwnerLabel] != host {
+			continue
+		}
+		pid, err := strconv.Atoi(contr.Labels[client.PIDLabel])
+		if err != nil || !ProcessAlive(pid) {
+			orphans = append(orphans, contr)
+		}
+	}
+	return orphans, nil
+}
+
+// StoppedOrphans returns the orphans that are no longer running
+func StoppedOrphans(docker *client.Docker) ([]types.Container, error) {
+	orphans, err := Orphans(docker)
+	if err != nil {
+		return nil, err
+	}
+	stopped := []types.Container{}
+	for _, contr := range orphans {
+		if contr.State != "running" {
+			stopped = append(stopped, contr)
+		}
+	}
+	return stopped, nil
+}
+
+// RemoveOrphans removes the containers returned by Orphans and returns them
+func RemoveOrphans(docker *client.Docker) ([]types.Container, error) {
+	orphans, err := Orphans(docker)
+	if err != nil {
+		return nil, err
+	}
+	removed := []types.Container{}
+	for _, contr := range orphans {
+		if err := Remove(docker, contr.ID, true, false, true); err != nil {
+			return removed, err
+		}
+		removed = append(removed, contr)
+	}
+	return removed, nil
+}
+
+// ProcessAlive reports whether a process with the PID runs on this host,
+// processes that can't be signalled are assumed to be alive
+func ProcessAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	if pid == os.Getpid() {
+		return true
+	}
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	err = process.Signal(syscall.Signal(0))
+	return !errors.Is(err, os.ErrProcessDone)
+}
@@ -0,0 +1,65 @@
+package container_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	dockercontainer "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+	"github.com/maliceio/malice/malice/docker/client"
+	"github.com/maliceio/malice/malice/docker/client/container"
+	"github.com/maliceio/malice/malice/docker/client/fake"
+)
+
+// deadPID is above the largest PID linux hands out
+const deadPID = "4194305"
+
+func TestRemoveOrphans(t *testing.T) {
+	host, err := os.Hostname()
+	if err != nil {
+		t.Fatal(err)
+	}
+	runtime := fake.New()
+	runtime.AddImage("malice/avast", "sha256:avast", nil)
+	docker := runtime.Docker()
+
+	containers := map[string]map[string]string{
+		// the scan of this process is still running
+		"running-scan": client.OwnerLabels("scan1", "avast"),
+		"dead-scan":    {client.OwnerLabel: host, client.PIDLabel: deadPID, client.ScanLabel: "scan2"},
+		"bad-pid":      {client.OwnerLabel: host, client.PIDLabel: "avast"},
+		// the process may run on the other host
+		"other-host": {client.OwnerLabel: host + "-other", client.PIDLabel: deadPID},
+		// e.g. the database, it isn't owned by a scan
+		"unlabeled": nil,
+	}
+	for name, labels := range containers {
+		config := &dockercontainer.Config{Image: "malice/avast", Labels: labels}
+		if _, err := docker.Client.ContainerCreate(context.Background(), config, &dockercontainer.HostConfig{}, &network.NetworkingConfig{}, name); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	removed, err := container.RemoveOrphans(docker)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]bool{"/dead-scan": true, "/bad-pid": true}
+	if len(removed) != len(want) {
+		t.Errorf("removed %d containers, want %d", len(removed), len(want))
+	}
+	for _, contr := range removed {
+		if !want[contr.Names[0]] {
+			t.Errorf("removed %s, want only orphans removed", contr.Names[0])
+		}
+	}
+
+	left, err := container.List(docker, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(left) != len(containers)-len(want) {
+		t.Errorf("%d containers left, want %d", len(left), len(containers)-len(want))
+	}
+}
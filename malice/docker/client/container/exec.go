@@ -0,0 +1,113 @@
+package container
+
+import (
+	"bytes"
+	"io/ioutil"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/strslice"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/maliceio/malice/malice/delivery"
+	"github.com/maliceio/malice/malice/docker/client"
+)
+
+// keepAlive replaces the entrypoint of idle containers, tail is in busybox
+// and coreutils so it runs in every plugin image
+var keepAlive = strslice.StrSlice{"tail", "-f", "/dev/null"}
+
+// StartIdle starts a container of image that idles instead of running its
+// entrypoint, commands are run in it with Exec. It is set up to get samples
+// from sample unless that is nil, which has to deliver them to running
+// containers, see delivery.Running. It returns the container ID.
+func StartIdle(
+	ctx context.Context,
+	docker *client.Docker,
+	name string,
+	image string,
+	binds []string,
+	env []string,
+	labels map[string]string,
+	sample delivery.Strategy,
+) (string, error) {
+
+	createContConf := &container.Config{
+		Image:      image,
+		Entrypoint: keepAlive,
+		Env:        env,
+		Labels:     labels,
+	}
+	hostConfig := &container.HostConfig{
+		Binds:      binds,
+		Privileged: false,
+		Resources:  getResources(),
+	}
+	if sample != nil {
+		sample.Configure(createContConf, hostConfig, "")
+	}
+	contResponse, err := docker.Client.ContainerCreate(ctx, createContConf, hostConfig, &network.NetworkingConfig{}, name)
+	if err != nil {
+		return "", err
+	}
+	if err := docker.Client.ContainerStart(ctx, contResponse.ID, types.ContainerStartOptions{}); err != nil {
+		discard(docker, contResponse.ID)
+		return "", err
+	}
+	return contResponse.ID, nil
+}
+
+// Exec runs cmd in the running container with the extra env and returns
+// its exit code and everything it wrote to stdout. It stops waiting once
+// ctx is done, the command may still be running in the container then.
+func Exec(ctx context.Context, docker *client.Docker, contID string, cmd []string, env []string) (int64, []byte, error) {
+	exec, err := docker.Client.ContainerExecCreate(ctx, contID, types.ExecConfig{
+		Cmd:          cmd,
+		Env:          env,
+		AttachStdout: true,
+		AttachStderr: true,
+	})
+	if err != nil {
+		return -1, nil, err
+	}
+
+	resp, err := docker.Client.ContainerExecAttach(ctx, exec.ID, types.ExecStartCheck{})
+	if err != nil {
+		return -1, nil, err
+	}
+	defer resp.Close()
+
+	var stdout bytes.Buffer
+	copied := make(chan error, 1)
+	go func() {
+		_, err := stdcopy.StdCopy(&stdout, ioutil.Discard, resp.Reader)
+		copied <- err
+	}()
+	select {
+	case <-ctx.Done():
+		return -1, nil, ctx.Err()
+	case err := <-copied:
+		if err != nil {
+			return -1, nil, err
+		}
+	}
+
+	// the output ends before the exec is reported as exited
+	for {
+		inspect, err := docker.Client.ContainerExecInspect(ctx, exec.ID)
+		if err != nil {
+			return -1, nil, err
+		}
+		if !inspect.Running {
+			return int64(inspect.ExitCode), stdout.Bytes(), nil
+		}
+		select {
+		case <-ctx.Done():
+			return -1, nil, ctx.Err()
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
@@ -1,7 +1,10 @@
 package container
 
 import (
+	"bytes"
 	"errors"
+	"fmt"
+	"io/ioutil"
 	"os"
 
 	"golang.org/x/net/context"
@@ -14,10 +17,19 @@ import (
 	"github.com/docker/docker/pkg/stdcopy"
 	"github.com/docker/go-connections/nat"
 	"github.com/maliceio/malice/config"
+	"github.com/maliceio/malice/malice/delivery"
 	"github.com/maliceio/malice/malice/docker/client"
 	er "github.com/maliceio/malice/malice/errors"
 )
 
+// Network is the network a container joins
+type Network struct {
+	// Name of the network, the default bridge when empty
+	Name string
+	// Aliases other containers on the network reach the container by
+	Aliases []string
+}
+
 // Start starts a malice docker container
 func Start(
 	docker *client.Docker,
@@ -27,43 +39,108 @@ func Start(
 	logs bool,
 	binds []string,
 	portBindings nat.PortMap,
-	links []string,
+	net Network,
+	env []string,
+	labels map[string]string,
+) (types.ContainerJSONBase, error) {
+	return start(docker, cmd, name, image, logs, binds, portBindings, net, env, labels, nil, "")
+}
+
+// StartWithSample starts a malice docker container that gets the sample
+// delivered by sample before it starts
+func StartWithSample(
+	docker *client.Docker,
+	cmd strslice.StrSlice,
+	name string,
+	image string,
+	logs bool,
+	binds []string,
+	net Network,
+	env []string,
+	labels map[string]string,
+	sample delivery.Strategy,
+	sha256 string,
+) (types.ContainerJSONBase, error) {
+	return start(docker, cmd, name, image, logs, binds, nil, net, env, labels, sample, sha256)
+}
+
+func start(
+	docker *client.Docker,
+	cmd strslice.StrSlice,
+	name string,
+	image string,
+	logs bool,
+	binds []string,
+	portBindings nat.PortMap,
+	net Network,
 	env []string,
+	labels map[string]string,
+	sample delivery.Strategy,
+	sha256 string,
 ) (types.ContainerJSONBase, error) {
 
 	if docker.Ping() {
 		// Check that all requirements for the container to run are ready
-		if !checkContainerRequirements(docker, name, image) {
-			return types.ContainerJSONBase{}, errors.New("container is already running")
+		if err := checkContainerRequirements(docker, name, image); err != nil {
+			return types.ContainerJSONBase{}, err
 		}
 
 		createContConf := &container.Config{
-			Image: image,
-			Cmd:   cmd,
-			Env:   env,
+			Image:  image,
+			Cmd:    cmd,
+			Env:    env,
+			Labels: labels,
 		}
 		// resources := container.Resources{
 		// 	Memory:   config.Conf.Docker.Memory, // Memory: Memory limit (in bytes)
 		// 	NanoCPUs: config.Conf.Docker.CPU,    // NanoCPUs: CPU quota in units of 10<sup>-9</sup> CPUs.
 		// }
 		hostConfig := &container.HostConfig{
-			Binds: binds,
-			// NetworkMode:  "malice",
+			Binds:        binds,
 			PortBindings: portBindings,
-			Links:        links,
 			Privileged:   false,
 			// Resources:    resources,
 		}
 		networkingConfig := &network.NetworkingConfig{}
+		// ports aren't published on internal networks, containers with ports
+		// stay on the default bridge and are connected to the network after
+		// they were created
+		if net.Name != "" && len(portBindings) == 0 {
+			hostConfig.NetworkMode = container.NetworkMode(net.Name)
+			networkingConfig.EndpointsConfig = map[string]*network.EndpointSettings{
+				net.Name: {Aliases: net.Aliases},
+			}
+		}
+		if sample != nil {
+			sample.Configure(createContConf, hostConfig, sha256)
+		}
 
 		contResponse, err := docker.Client.ContainerCreate(context.Background(), createContConf, hostConfig, networkingConfig, name)
 		if err != nil {
 			log.WithFields(log.Fields{"env": config.Conf.Environment.Run}).Errorf("CreateContainer error = %s\n", err)
+			return types.ContainerJSONBase{}, err
+		}
+
+		if net.Name != "" && len(portBindings) > 0 {
+			err = docker.Client.NetworkConnect(context.Background(), net.Name, contResponse.ID, &network.EndpointSettings{Aliases: net.Aliases})
+			if err != nil {
+				discard(docker, contResponse.ID)
+				return types.ContainerJSONBase{}, fmt.Errorf("failed to connect container to network %s: %w", net.Name, err)
+			}
+		}
+
+		if sample != nil {
+			if err := sample.Deliver(context.Background(), docker, contResponse.ID, sha256); err != nil {
+				discard(docker, contResponse.ID)
+				return types.ContainerJSONBase{}, fmt.Errorf("failed to deliver sample: %w", err)
+			}
 		}
 
 		err = docker.Client.ContainerStart(context.Background(), contResponse.ID, types.ContainerStartOptions{})
 		if err != nil {
 			log.WithFields(log.Fields{"env": config.Conf.Environment.Run}).Errorf("StartContainer error = %s\n", err)
+			discard(docker, contResponse.ID)
+			return types.ContainerJSONBase{}, err
 		}
 
 		if logs {
@@ -71,7 +148,10 @@ func Start(
 		}
 
 		contJSON, err := Inspect(docker, contResponse.ID)
-		return *contJSON.ContainerJSONBase, err
+		if err != nil {
+			return types.ContainerJSONBase{ID: contResponse.ID}, err
+		}
+		return *contJSON.ContainerJSONBase, nil
 	}
 	return types.ContainerJSONBase{}, errors.New("Cannot connect to the Docker daemon. Is the docker daemon running on this host?")
 }
@@ -95,3 +175,101 @@ func LogContainer(docker *client.Docker, contID string) {
 	_, err = stdcopy.StdCopy(os.Stdout, os.Stderr, logs)
 	er.CheckError(err)
 }
+
+// Wait blocks until the container stops running and returns its exit code,
+// it stops waiting once ctx is done and the container keeps running then
+func Wait(ctx context.Context, docker *client.Docker, contID string) (int64, error) {
+	statusCh, errCh := docker.Client.ContainerWait(ctx, contID, container.WaitConditionNotRunning)
+	select {
+	case err := <-errCh:
+		return -1, err
+	case status := <-statusCh:
+		return status.StatusCode, nil
+	case <-ctx.Done():
+		return -1, ctx.Err()
+	}
+}
+
+// Output returns everything the container wrote to stdout
+func Output(docker *client.Docker, contID string) ([]byte, error) {
+
+	options := types.ContainerLogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+	}
+
+	logs, err := docker.Client.ContainerLogs(context.Background(), contID, options)
+	if err != nil {
+		return nil, err
+	}
+	defer logs.Close()
+
+	var stdout bytes.Buffer
+	if _, err = stdcopy.StdCopy(&stdout, ioutil.Discard, logs); err != nil {
+		return nil, err
+	}
+	return stdout.Bytes(), nil
+}
+
+// RunIsolated runs a container to completion with only the given binds and,
+// unless allowNetwork is set, without any networking. It stops waiting and
+// removes the container once ctx is done. It returns the container's exit
+// code and everything it wrote to stdout.
+func RunIsolated(
+	ctx context.Context,
+	docker *client.Docker,
+	name string,
+	image string,
+	cmd strslice.StrSlice,
+	binds []string,
+	env []string,
+	allowNetwork bool,
+) (int64, []byte, error) {
+
+	hostConfig := noNetHostConfig()
+	if allowNetwork {
+		hostConfig.NetworkMode = ""
+	}
+	hostConfig.Binds = binds
+	hostConfig.Resources = getResources()
+
+	createContConf := &container.Config{
+		Image:  image,
+		Cmd:    cmd,
+		Env:    env,
+		Labels: client.OwnerLabels("", ""),
+	}
+	contResponse, err := docker.Client.ContainerCreate(ctx, createContConf, hostConfig, &network.NetworkingConfig{}, name)
+	if err != nil {
+		return -1, nil, err
+	}
+	defer func() {
+		discard(docker, contResponse.ID)
+	}()
+
+	if err := docker.Client.ContainerStart(ctx, contResponse.ID, types.ContainerStartOptions{}); err != nil {
+		return -1, nil, err
+	}
+
+	statusCh, errCh := docker.Client.ContainerWait(ctx, contResponse.ID, container.WaitConditionNotRunning)
+	var exitCode int64
+	select {
+	case <-ctx.Done():
+		return -1, nil, ctx.Err()
+	case err := <-errCh:
+		return -1, nil, err
+	case status := <-statusCh:
+		exitCode = status.StatusCode
+	}
+
+	output, err := Output(docker, contResponse.ID)
+	return exitCode, output, err
+}
+
+// discard removes a container that failed to start or finished, a failure to
+// remove it is only logged
+func discard(docker *client.Docker, id string) {
+	if err := Remove(docker, id, true, false, true); err != nil {
+		log.WithError(err).WithField("container", id).Warn("failed to remove container")
+	}
+}
@@ -13,13 +13,11 @@ import (
 	"github.com/docker/docker/api/types/events"
 	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/api/types/versions"
-	er "github.com/maliceio/malice/malice/errors"
 
 	"github.com/maliceio/malice/config"
 	"github.com/maliceio/malice/malice/docker/client"
 	"github.com/maliceio/malice/malice/docker/client/image"
 	"github.com/maliceio/malice/malice/docker/client/network"
-	"github.com/maliceio/malice/malice/docker/client/volume"
 )
 
 func noNetHostConfig() *container.HostConfig {
@@ -150,23 +148,13 @@ func waitExitOrRemoved(ctx context.Context, docker *client.Docker, containerID s
 	return statusChan
 }
 
-func checkContainerRequirements(docker *client.Docker, containerName, img string) bool {
+// checkContainerRequirements makes sure the container can be started: the
+// network of malice exists, no container has its name and its image is pulled
+func checkContainerRequirements(docker *client.Docker, containerName, img string) error {
 	// Check for existance of malice network
-	if _, exists, _ := network.Exists(docker, "malice"); !exists {
-		log.WithFields(log.Fields{
-			"network": "malice",
-			"exisits": exists,
-			"env":     config.Conf.Environment.Run,
-		}).Error("Network malice does not exist, creating now...")
-		_, err := network.Create(docker, "malice")
-		er.CheckError(err)
+	if _, err := network.Ensure(docker); err != nil {
+		log.WithError(err).Warn("failed to create the malice network")
 	}
-	// Check for existance of malice volume
-	if _, exists, _ := volume.Exists(docker, "malice"); !exists {
-		log.Debug("Volume malice not found.")
-		er.CheckError(volume.Create(docker, "malice", "local", nil))
-	}
-	log.Debug("Volume malice found.")
 	// Check that the container isn't already running
 	if _, exists, _ := Exists(docker, containerName); exists {
 		log.WithFields(log.Fields{
@@ -174,7 +162,7 @@ func checkContainerRequirements(docker *client.Docker, containerName, img string
 			"name":    containerName,
 			"env":     config.Conf.Environment.Run,
 		}).Error("Container is already running...")
-		return false
+		return errors.New("container is already running")
 	}
 	// Check that we have already pulled the image
 	if _, exists, _ := image.Exists(docker, img); exists {
@@ -186,9 +174,11 @@ func checkContainerRequirements(docker *client.Docker, containerName, img string
 		log.WithFields(log.Fields{
 			"exisits": exists,
 			"env":     config.Conf.Environment.Run}).Debugf("Pulling Image `%s`", img)
-		image.Pull(docker, img, "latest")
+		if err := image.Pull(docker, img, "latest"); err != nil {
+			return fmt.Errorf("failed to pull image %s: %w", img, err)
+		}
 	}
-	return true
+	return nil
 }
 
 // ErrConnectionFailed is an error raised when the connection between the client and the server failed.
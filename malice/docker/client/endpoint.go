@@ -0,0 +1,145 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/url"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/docker/docker/client"
+	"github.com/pkg/errors"
+)
+
+// Endpoint is a Docker daemon, other than the one malice runs next to, that
+// plugin containers can be placed on
+type Endpoint struct {
+	Name string
+	// Host is the address of the daemon, tcp://host:2376 or ssh://user@host
+	Host string
+	// CertPath is the directory holding ca.pem, cert.pem and key.pem of a
+	// daemon that is reached over TLS
+	CertPath string
+	// SSHKey is the key ssh logs in with, ssh uses the agent and its
+	// defaults when it is empty
+	SSHKey string
+}
+
+// NewEndpointClient creates a client of the Docker daemon at the endpoint,
+// it does not connect to it. Daemons behind ssh are reached by running
+// `docker system dial-stdio` on the host, which needs docker 18.09 there.
+func NewEndpointClient(endpoint Endpoint) (*Docker, error) {
+	u, err := url.Parse(endpoint.Host)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid host of docker endpoint %s", endpoint.Name)
+	}
+
+	opts := []client.Opt{client.WithAPIVersionNegotiation()}
+	switch u.Scheme {
+	case "ssh":
+		// the host is only used to build the request URLs
+		opts = append(opts, client.WithHost("http://docker.example.com"), client.WithDialContext(dialSSH(u, endpoint.SSHKey)))
+	case "tcp", "unix":
+		opts = append(opts, client.WithHost(endpoint.Host))
+		if endpoint.CertPath != "" {
+			opts = append(opts, client.WithTLSClientConfig(
+				filepath.Join(endpoint.CertPath, "ca.pem"),
+				filepath.Join(endpoint.CertPath, "cert.pem"),
+				filepath.Join(endpoint.CertPath, "key.pem"),
+			))
+		}
+	default:
+		return nil, errors.Errorf("docker endpoint %s has host %s, expected tcp://, ssh:// or unix://", endpoint.Name, endpoint.Host)
+	}
+
+	docker, err := newReconnectingRuntime(func() (Runtime, error) {
+		return client.NewClientWithOpts(opts...)
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create client of docker endpoint %s", endpoint.Name)
+	}
+	return &Docker{
+		Client:  docker,
+		runtime: RuntimeDocker,
+		ip:      u.Hostname(),
+		port:    u.Port(),
+	}, nil
+}
+
+// dialSSH returns a dialer connecting to the daemon on the host of u over
+// ssh, logging in with key unless it is empty
+func dialSSH(u *url.URL, key string) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	host, args := sshArgs(u, key)
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		// the connection outlives ctx, which only bounds dialing
+		cmd := exec.Command("ssh", args...)
+		stdin, err := cmd.StdinPipe()
+		if err != nil {
+			return nil, err
+		}
+		stdout, err := cmd.StdoutPipe()
+		if err != nil {
+			return nil, err
+		}
+		if err := cmd.Start(); err != nil {
+			return nil, errors.Wrapf(err, "failed to run ssh to %s", host)
+		}
+		return &commandConn{cmd: cmd, WriteCloser: stdin, ReadCloser: stdout, host: host}, nil
+	}
+}
+
+// sshArgs returns the user@host and the ssh arguments running the docker
+// dial-stdio of the daemon on the host of u
+func sshArgs(u *url.URL, key string) (string, []string) {
+	args := []string{"-o", "BatchMode=yes"}
+	if u.Port() != "" {
+		args = append(args, "-p", u.Port())
+	}
+	if key != "" {
+		args = append(args, "-i", key, "-o", "IdentitiesOnly=yes")
+	}
+	host := u.Hostname()
+	if u.User != nil {
+		host = u.User.Username() + "@" + host
+	}
+	return host, append(args, "--", host, "docker", "system", "dial-stdio")
+}
+
+// commandConn is a connection over the stdin and stdout of a command
+type commandConn struct {
+	cmd *exec.Cmd
+	io.WriteCloser
+	io.ReadCloser
+	host string
+}
+
+func (c *commandConn) Close() error {
+	c.WriteCloser.Close()
+	c.ReadCloser.Close()
+	if c.cmd.Process != nil {
+		c.cmd.Process.Kill()
+	}
+	c.cmd.Wait()
+	return nil
+}
+
+// CloseWrite closes stdin, for hijacked connections like attached stdin
+func (c *commandConn) CloseWrite() error {
+	return c.WriteCloser.Close()
+}
+
+func (c *commandConn) LocalAddr() net.Addr  { return commandAddr("ssh") }
+func (c *commandConn) RemoteAddr() net.Addr { return commandAddr(c.host) }
+
+// deadlines aren't supported by pipes of commands, reads and writes only
+// end with the command
+func (c *commandConn) SetDeadline(t time.Time) error      { return nil }
+func (c *commandConn) SetReadDeadline(t time.Time) error  { return nil }
+func (c *commandConn) SetWriteDeadline(t time.Time) error { return nil }
+
+type commandAddr string
+
+func (a commandAddr) Network() string { return "ssh" }
+func (a commandAddr) String() string  { return string(a) }
@@ -0,0 +1,39 @@
+package client
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestSSHArgs(t *testing.T) {
+	tests := []struct {
+		host     string
+		key      string
+		wantHost string
+		wantArgs []string
+	}{
+		{
+			host:     "ssh://malice@10.0.0.22",
+			wantHost: "malice@10.0.0.22",
+			wantArgs: []string{"-o", "BatchMode=yes", "--", "malice@10.0.0.22", "docker", "system", "dial-stdio"},
+		},
+		{
+			host:     "ssh://malice@10.0.0.22:2222",
+			key:      "/etc/malice/ssh/id_ed25519",
+			wantHost: "malice@10.0.0.22",
+			wantArgs: []string{"-o", "BatchMode=yes", "-p", "2222", "-i", "/etc/malice/ssh/id_ed25519", "-o", "IdentitiesOnly=yes", "--", "malice@10.0.0.22", "docker", "system", "dial-stdio"},
+		},
+	}
+
+	for _, tt := range tests {
+		u, err := url.Parse(tt.host)
+		if err != nil {
+			t.Fatal(err)
+		}
+		host, args := sshArgs(u, tt.key)
+		if host != tt.wantHost || !reflect.DeepEqual(args, tt.wantArgs) {
+			t.Errorf("sshArgs(%s, %q) = %s, %q, want %s, %q", tt.host, tt.key, host, args, tt.wantHost, tt.wantArgs)
+		}
+	}
+}
@@ -0,0 +1,1011 @@
+// Package fake provides an in-memory container runtime for tests. Plugin
+// containers don't run anything, they print the output scripted for their
+// image after the scripted delay and exit with the scripted code. Containers
+// started with another entrypoint idle until they are removed, commands
+// exec'd in them behave as scripted instead.
+package fake
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/registry"
+	volumetypes "github.com/docker/docker/api/types/volume"
+	"github.com/docker/docker/errdefs"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/maliceio/malice/malice/docker/client"
+)
+
+// Behavior scripts what the containers of an image do
+type Behavior struct {
+	Stdout   string
+	Stderr   string
+	ExitCode int64
+	// Delay is how long the container runs before it exits
+	Delay time.Duration
+	// CreateErr, StartErr and WaitErr fail creating, starting and waiting on
+	// the container
+	CreateErr error
+	StartErr  error
+	WaitErr   error
+}
+
+type fakeContainer struct {
+	id       string
+	name     string
+	image    string
+	config   container.Config
+	behavior Behavior
+	started  bool
+	// idle containers run until they are killed instead of as scripted
+	idle   bool
+	killed bool
+	// exited is closed once the container stopped running
+	exited   chan struct{}
+	stopOnce sync.Once
+	files    map[string][]byte
+}
+
+// stop makes the container exit
+func (c *fakeContainer) stop() {
+	c.stopOnce.Do(func() { close(c.exited) })
+}
+
+func (c *fakeContainer) running() bool {
+	if !c.started {
+		return false
+	}
+	select {
+	case <-c.exited:
+		return false
+	default:
+		return true
+	}
+}
+
+// Runtime is an in-memory client.Runtime. The zero value is not usable, use New.
+type Runtime struct {
+	mu         sync.Mutex
+	behaviors  map[string]Behavior
+	failures   map[string]error
+	images     map[string]*types.ImageInspect
+	containers map[string]*fakeContainer
+	networks   map[string]types.NetworkResource
+	volumes    map[string]*types.Volume
+	execs      map[string]*fakeExec
+	calls      []string
+	lastID     int
+}
+
+// fakeExec is a command exec'd in a container, it behaves as scripted for
+// the image of the container
+type fakeExec struct {
+	container *fakeContainer
+	cmd       []string
+	// done is closed once the command exited
+	done     chan struct{}
+	exitCode int64
+}
+
+var _ client.Runtime = (*Runtime)(nil)
+
+// New returns an empty runtime without images or containers
+func New() *Runtime {
+	return &Runtime{
+		behaviors:  make(map[string]Behavior),
+		failures:   make(map[string]error),
+		images:     make(map[string]*types.ImageInspect),
+		containers: make(map[string]*fakeContainer),
+		networks:   make(map[string]types.NetworkResource),
+		volumes:    make(map[string]*types.Volume),
+		execs:      make(map[string]*fakeExec),
+	}
+}
+
+// Docker returns a malice docker client backed by the runtime
+func (r *Runtime) Docker() *client.Docker {
+	return client.NewDockerClientFromRuntime(r, "fake")
+}
+
+// Script sets what containers of image do, image matches any tag or digest
+// of the repository unless it has one itself
+func (r *Runtime) Script(image string, behavior Behavior) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.behaviors[image] = behavior
+}
+
+// Fail makes every call of the runtime method, e.g. "ImagePull", return err
+// until it is cleared with a nil err
+func (r *Runtime) Fail(method string, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err == nil {
+		delete(r.failures, method)
+		return
+	}
+	r.failures[method] = err
+}
+
+// AddImage adds a pulled image, with a registry digest unless digest is empty
+func (r *Runtime) AddImage(ref, digest string, labels map[string]string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.addImage(ref, digest, labels)
+}
+
+// Calls returns the runtime methods called so far, in order
+func (r *Runtime) Calls() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string{}, r.calls...)
+}
+
+// Count returns how often the runtime method was called
+func (r *Runtime) Count(method string) int {
+	count := 0
+	for _, call := range r.Calls() {
+		if call == method {
+			count++
+		}
+	}
+	return count
+}
+
+// Files returns the files copied into a container by path
+func (r *Runtime) Files(containerID string) map[string][]byte {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	c := r.find(containerID)
+	if c == nil {
+		return nil
+	}
+	files := make(map[string][]byte, len(c.files))
+	for path, data := range c.files {
+		files[path] = data
+	}
+	return files
+}
+
+// Kill makes a running container exit as if it crashed
+func (r *Runtime) Kill(containerID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if c := r.find(containerID); c != nil && c.running() {
+		c.killed = true
+		c.stop()
+	}
+}
+
+// Execs returns the commands exec'd in a container so far, in order
+func (r *Runtime) Execs(containerID string) [][]string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var cmds [][]string
+	ids := make([]string, 0, len(r.execs))
+	for id, exec := range r.execs {
+		if exec.container.id == containerID || exec.container.name == containerID {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		cmds = append(cmds, r.execs[id].cmd)
+	}
+	return cmds
+}
+
+// call records a call and returns the failure set for it
+func (r *Runtime) call(method string) error {
+	r.calls = append(r.calls, method)
+	return r.failures[method]
+}
+
+// repository strips the tag and digest from an image reference
+func repository(ref string) string {
+	if i := strings.Index(ref, "@"); i >= 0 {
+		ref = ref[:i]
+	}
+	if i := strings.LastIndex(ref, ":"); i > strings.LastIndex(ref, "/") {
+		ref = ref[:i]
+	}
+	return ref
+}
+
+// withTag adds the latest tag to references without a tag or digest
+func withTag(ref string) string {
+	if strings.Contains(ref, "@") || repository(ref) != ref {
+		return ref
+	}
+	return ref + ":latest"
+}
+
+func digestOf(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+func (r *Runtime) addImage(ref, digest string, labels map[string]string) *types.ImageInspect {
+	inspect := &types.ImageInspect{
+		ID:       digestOf("image " + ref + " " + digest),
+		RepoTags: []string{withTag(ref)},
+		Created:  time.Now().UTC().Format(time.RFC3339Nano),
+		Size:     1 << 20,
+		Config:   &container.Config{Image: ref, Labels: labels},
+	}
+	if digest != "" {
+		inspect.RepoDigests = []string{repository(ref) + "@" + digest}
+	}
+	if old, ok := r.images[withTag(ref)]; ok && old.ID != inspect.ID {
+		r.untag(withTag(ref))
+	}
+	r.images[withTag(ref)] = inspect
+	return inspect
+}
+
+// untag moves tag off the image holding it, like a pull of a newer image
+// does, the image is kept by its ID once it has no tags left
+func (r *Runtime) untag(tag string) {
+	old, ok := r.images[tag]
+	if !ok {
+		return
+	}
+	delete(r.images, tag)
+	var tags []string
+	for _, t := range old.RepoTags {
+		if t != tag {
+			tags = append(tags, t)
+		}
+	}
+	old.RepoTags = tags
+	if len(tags) == 0 {
+		r.images[old.ID] = old
+	}
+}
+
+// findImage looks an image up by reference, digest reference or ID
+func (r *Runtime) findImage(ref string) *types.ImageInspect {
+	if inspect, ok := r.images[withTag(ref)]; ok {
+		return inspect
+	}
+	for _, inspect := range r.images {
+		if inspect.ID == ref || strings.TrimPrefix(inspect.ID, "sha256:") == ref {
+			return inspect
+		}
+		for _, digest := range inspect.RepoDigests {
+			if strings.HasSuffix(ref, digest[strings.Index(digest, "@"):]) && repository(ref) == repository(digest) {
+				return inspect
+			}
+		}
+	}
+	return nil
+}
+
+// find looks a container up by ID or name
+func (r *Runtime) find(idOrName string) *fakeContainer {
+	if c, ok := r.containers[idOrName]; ok {
+		return c
+	}
+	for _, c := range r.containers {
+		if c.name == strings.TrimPrefix(idOrName, "/") {
+			return c
+		}
+	}
+	return nil
+}
+
+func (r *Runtime) behavior(image string) Behavior {
+	if behavior, ok := r.behaviors[image]; ok {
+		return behavior
+	}
+	return r.behaviors[repository(image)]
+}
+
+func (r *Runtime) pull(ref string) (io.ReadCloser, error) {
+	if r.findImage(ref) == nil {
+		digest := ""
+		if i := strings.Index(ref, "@"); i >= 0 {
+			digest = ref[i+1:]
+		} else {
+			digest = digestOf("manifest " + withTag(ref))
+		}
+		r.addImage(ref, digest, nil)
+	}
+	return ioutil.NopCloser(strings.NewReader(`{"status":"Downloaded newer image for ` + ref + `"}` + "\n")), nil
+}
+
+// ClientVersion returns the API version of the fake
+func (r *Runtime) ClientVersion() string {
+	return "1.39"
+}
+
+// Info describes the fake daemon
+func (r *Runtime) Info(ctx context.Context) (types.Info, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.call("Info"); err != nil {
+		return types.Info{}, err
+	}
+	return types.Info{ID: "fake", Name: "fake", ServerVersion: "fake", Containers: len(r.containers), Images: len(r.images)}, nil
+}
+
+// Events sends a die event for the container in the filters once it exits
+func (r *Runtime) Events(ctx context.Context, options types.EventsOptions) (<-chan events.Message, <-chan error) {
+	messages := make(chan events.Message)
+	errs := make(chan error, 1)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.call("Events"); err != nil {
+		errs <- err
+		return messages, errs
+	}
+	var watched []*fakeContainer
+	for _, id := range options.Filters.Get("container") {
+		if c := r.find(id); c != nil {
+			watched = append(watched, c)
+		}
+	}
+
+	go func() {
+		for _, c := range watched {
+			select {
+			case <-c.exited:
+			case <-ctx.Done():
+				return
+			}
+			msg := events.Message{
+				Status: "die",
+				ID:     c.id,
+				Type:   events.ContainerEventType,
+				Action: "die",
+				Actor:  events.Actor{ID: c.id, Attributes: map[string]string{"exitCode": strconv.FormatInt(c.behavior.ExitCode, 10)}},
+			}
+			select {
+			case messages <- msg:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return messages, errs
+}
+
+// ContainerCreate creates a container that behaves as scripted for its image
+func (r *Runtime) ContainerCreate(ctx context.Context, config *container.Config, hostConfig *container.HostConfig, networkingConfig *network.NetworkingConfig, containerName string) (container.ContainerCreateCreatedBody, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.call("ContainerCreate"); err != nil {
+		return container.ContainerCreateCreatedBody{}, err
+	}
+	behavior := r.behavior(config.Image)
+	if behavior.CreateErr != nil {
+		return container.ContainerCreateCreatedBody{}, behavior.CreateErr
+	}
+	if r.findImage(config.Image) == nil {
+		return container.ContainerCreateCreatedBody{}, errdefs.NotFound(fmt.Errorf("No such image: %s", config.Image))
+	}
+
+	r.lastID++
+	id := digestOf(fmt.Sprintf("container %d", r.lastID))[len("sha256:"):]
+	if containerName == "" {
+		containerName = fmt.Sprintf("fake_%d", r.lastID)
+	}
+	if r.find(containerName) != nil {
+		return container.ContainerCreateCreatedBody{}, errdefs.Conflict(fmt.Errorf("the container name %q is already in use", "/"+containerName))
+	}
+	r.containers[id] = &fakeContainer{
+		id:       id,
+		name:     containerName,
+		image:    config.Image,
+		config:   *config,
+		behavior: behavior,
+		idle:     len(config.Entrypoint) > 0,
+		exited:   make(chan struct{}),
+		files:    make(map[string][]byte),
+	}
+	return container.ContainerCreateCreatedBody{ID: id}, nil
+}
+
+// ContainerStart starts the container, it exits after the scripted delay
+func (r *Runtime) ContainerStart(ctx context.Context, containerID string, options types.ContainerStartOptions) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.call("ContainerStart"); err != nil {
+		return err
+	}
+	c := r.find(containerID)
+	if c == nil {
+		return errdefs.NotFound(fmt.Errorf("No such container: %s", containerID))
+	}
+	if c.behavior.StartErr != nil {
+		return c.behavior.StartErr
+	}
+	if c.started {
+		return nil
+	}
+	c.started = true
+	if !c.idle {
+		time.AfterFunc(c.behavior.Delay, c.stop)
+	}
+	return nil
+}
+
+// ContainerWait waits until the container exits
+func (r *Runtime) ContainerWait(ctx context.Context, containerID string, condition container.WaitCondition) (<-chan container.ContainerWaitOKBody, <-chan error) {
+	statusCh := make(chan container.ContainerWaitOKBody, 1)
+	errCh := make(chan error, 1)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.call("ContainerWait"); err != nil {
+		errCh <- err
+		return statusCh, errCh
+	}
+	c := r.find(containerID)
+	if c == nil {
+		errCh <- errdefs.NotFound(fmt.Errorf("No such container: %s", containerID))
+		return statusCh, errCh
+	}
+
+	started := c.started
+	go func() {
+		if started {
+			select {
+			case <-c.exited:
+			case <-ctx.Done():
+				errCh <- ctx.Err()
+				return
+			}
+		}
+		if c.behavior.WaitErr != nil {
+			errCh <- c.behavior.WaitErr
+			return
+		}
+		statusCh <- container.ContainerWaitOKBody{StatusCode: c.behavior.ExitCode}
+	}()
+	return statusCh, errCh
+}
+
+// ContainerLogs returns the scripted output multiplexed like the Docker API
+// does, following the logs waits until the container exits
+func (r *Runtime) ContainerLogs(ctx context.Context, containerID string, options types.ContainerLogsOptions) (io.ReadCloser, error) {
+	r.mu.Lock()
+	if err := r.call("ContainerLogs"); err != nil {
+		r.mu.Unlock()
+		return nil, err
+	}
+	c := r.find(containerID)
+	if c == nil {
+		r.mu.Unlock()
+		return nil, errdefs.NotFound(fmt.Errorf("No such container: %s", containerID))
+	}
+	started := c.started
+	r.mu.Unlock()
+
+	if options.Follow && started {
+		select {
+		case <-c.exited:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	if !started || c.running() {
+		// the container hasn't printed anything yet
+		return ioutil.NopCloser(new(bytes.Buffer)), nil
+	}
+
+	buf := new(bytes.Buffer)
+	if options.ShowStdout && c.behavior.Stdout != "" {
+		stdcopy.NewStdWriter(buf, stdcopy.Stdout).Write([]byte(c.behavior.Stdout))
+	}
+	if options.ShowStderr && c.behavior.Stderr != "" {
+		stdcopy.NewStdWriter(buf, stdcopy.Stderr).Write([]byte(c.behavior.Stderr))
+	}
+	return ioutil.NopCloser(buf), nil
+}
+
+// ContainerInspect describes a container
+func (r *Runtime) ContainerInspect(ctx context.Context, containerID string) (types.ContainerJSON, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.call("ContainerInspect"); err != nil {
+		return types.ContainerJSON{}, err
+	}
+	c := r.find(containerID)
+	if c == nil {
+		return types.ContainerJSON{}, errdefs.NotFound(fmt.Errorf("No such container: %s", containerID))
+	}
+	config := c.config
+	return types.ContainerJSON{
+		ContainerJSONBase: &types.ContainerJSONBase{
+			ID:    c.id,
+			Name:  "/" + c.name,
+			Image: c.image,
+			State: &types.ContainerState{
+				Status:   state(c),
+				Running:  c.running(),
+				ExitCode: int(exitCode(c)),
+			},
+		},
+		Config: &config,
+	}, nil
+}
+
+func state(c *fakeContainer) string {
+	switch {
+	case c.running():
+		return "running"
+	case c.started:
+		return "exited"
+	}
+	return "created"
+}
+
+func exitCode(c *fakeContainer) int64 {
+	switch {
+	case c.killed:
+		return 137
+	case c.started && !c.running():
+		return c.behavior.ExitCode
+	}
+	return 0
+}
+
+// ContainerList lists the containers sorted by name, only running ones
+// unless options.All is set
+func (r *Runtime) ContainerList(ctx context.Context, options types.ContainerListOptions) ([]types.Container, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.call("ContainerList"); err != nil {
+		return nil, err
+	}
+	containers := []types.Container{}
+	for _, c := range r.containers {
+		if !options.All && !c.running() {
+			continue
+		}
+		if !options.Filters.MatchKVList("label", c.config.Labels) {
+			continue
+		}
+		containers = append(containers, types.Container{
+			ID:     c.id,
+			Names:  []string{"/" + c.name},
+			Image:  c.image,
+			Labels: c.config.Labels,
+			State:  state(c),
+		})
+	}
+	sort.Slice(containers, func(i, j int) bool { return containers[i].Names[0] < containers[j].Names[0] })
+	return containers, nil
+}
+
+// ContainerRemove removes a container, running ones only with options.Force
+func (r *Runtime) ContainerRemove(ctx context.Context, containerID string, options types.ContainerRemoveOptions) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.call("ContainerRemove"); err != nil {
+		return err
+	}
+	c := r.find(containerID)
+	if c == nil {
+		return errdefs.NotFound(fmt.Errorf("No such container: %s", containerID))
+	}
+	if c.running() && !options.Force {
+		return errdefs.Conflict(fmt.Errorf("You cannot remove a running container %s", c.id))
+	}
+	if c.running() {
+		c.killed = true
+		c.stop()
+	}
+	delete(r.containers, c.id)
+	return nil
+}
+
+// ContainerStatPath describes a path copied into a container
+func (r *Runtime) ContainerStatPath(ctx context.Context, containerID, path string) (types.ContainerPathStat, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.call("ContainerStatPath"); err != nil {
+		return types.ContainerPathStat{}, err
+	}
+	c := r.find(containerID)
+	if c == nil {
+		return types.ContainerPathStat{}, errdefs.NotFound(fmt.Errorf("No such container: %s", containerID))
+	}
+	return types.ContainerPathStat{Name: path, Size: int64(len(c.files[path])), Mtime: time.Now()}, nil
+}
+
+// CopyToContainer records the copied archive under dstPath
+func (r *Runtime) CopyToContainer(ctx context.Context, containerID, dstPath string, content io.Reader, options types.CopyToContainerOptions) error {
+	data, err := ioutil.ReadAll(content)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.call("CopyToContainer"); err != nil {
+		return err
+	}
+	c := r.find(containerID)
+	if c == nil {
+		return errdefs.NotFound(fmt.Errorf("No such container: %s", containerID))
+	}
+	c.files[dstPath] = data
+	return nil
+}
+
+// ContainerAttach attaches to a container, what is written to its stdin is
+// recorded as the file /dev/stdin once the connection is closed
+func (r *Runtime) ContainerAttach(ctx context.Context, containerID string, options types.ContainerAttachOptions) (types.HijackedResponse, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.call("ContainerAttach"); err != nil {
+		return types.HijackedResponse{}, err
+	}
+	c := r.find(containerID)
+	if c == nil {
+		return types.HijackedResponse{}, errdefs.NotFound(fmt.Errorf("No such container: %s", containerID))
+	}
+
+	local, remote := net.Pipe()
+	go func() {
+		stdin, _ := ioutil.ReadAll(remote)
+		remote.Close()
+		r.mu.Lock()
+		c.files["/dev/stdin"] = stdin
+		r.mu.Unlock()
+	}()
+	return types.HijackedResponse{Conn: local, Reader: bufio.NewReader(strings.NewReader(""))}, nil
+}
+
+// ContainerExecCreate creates a command in a running container
+func (r *Runtime) ContainerExecCreate(ctx context.Context, containerID string, config types.ExecConfig) (types.IDResponse, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.call("ContainerExecCreate"); err != nil {
+		return types.IDResponse{}, err
+	}
+	c := r.find(containerID)
+	if c == nil {
+		return types.IDResponse{}, errdefs.NotFound(fmt.Errorf("No such container: %s", containerID))
+	}
+	if !c.running() {
+		return types.IDResponse{}, errdefs.Conflict(fmt.Errorf("Container %s is not running", c.id))
+	}
+	r.lastID++
+	id := fmt.Sprintf("exec%08d", r.lastID)
+	r.execs[id] = &fakeExec{container: c, cmd: config.Cmd, done: make(chan struct{})}
+	return types.IDResponse{ID: id}, nil
+}
+
+// ContainerExecAttach starts the command, its output can be read once it
+// exited after the scripted delay or the container was killed
+func (r *Runtime) ContainerExecAttach(ctx context.Context, execID string, config types.ExecStartCheck) (types.HijackedResponse, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.call("ContainerExecAttach"); err != nil {
+		return types.HijackedResponse{}, err
+	}
+	exec, ok := r.execs[execID]
+	if !ok {
+		return types.HijackedResponse{}, errdefs.NotFound(fmt.Errorf("No such exec instance: %s", execID))
+	}
+	c := exec.container
+	if c.behavior.StartErr != nil {
+		return types.HijackedResponse{}, c.behavior.StartErr
+	}
+
+	buf := new(bytes.Buffer)
+	if c.behavior.Stdout != "" {
+		stdcopy.NewStdWriter(buf, stdcopy.Stdout).Write([]byte(c.behavior.Stdout))
+	}
+	if c.behavior.Stderr != "" {
+		stdcopy.NewStdWriter(buf, stdcopy.Stderr).Write([]byte(c.behavior.Stderr))
+	}
+	go func() {
+		select {
+		case <-time.After(c.behavior.Delay):
+			r.mu.Lock()
+			exec.exitCode = c.behavior.ExitCode
+			r.mu.Unlock()
+		case <-c.exited:
+			r.mu.Lock()
+			exec.exitCode = 137
+			r.mu.Unlock()
+			buf.Reset()
+		}
+		close(exec.done)
+	}()
+
+	local, remote := net.Pipe()
+	remote.Close()
+	conn := &hijackedConn{Conn: local, closed: make(chan struct{})}
+	output := &execOutput{done: exec.done, closed: conn.closed, buf: buf}
+	return types.HijackedResponse{Conn: conn, Reader: bufio.NewReader(output)}, nil
+}
+
+// hijackedConn is the connection of an exec attach, closing it stops
+// reading the output
+type hijackedConn struct {
+	net.Conn
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+func (c *hijackedConn) Close() error {
+	c.closeOnce.Do(func() { close(c.closed) })
+	return c.Conn.Close()
+}
+
+// execOutput blocks reads until the exec exited
+type execOutput struct {
+	done   <-chan struct{}
+	closed <-chan struct{}
+	buf    *bytes.Buffer
+}
+
+func (o *execOutput) Read(p []byte) (int, error) {
+	select {
+	case <-o.done:
+		return o.buf.Read(p)
+	case <-o.closed:
+		return 0, io.ErrClosedPipe
+	}
+}
+
+// ContainerExecInspect describes a command exec'd in a container
+func (r *Runtime) ContainerExecInspect(ctx context.Context, execID string) (types.ContainerExecInspect, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.call("ContainerExecInspect"); err != nil {
+		return types.ContainerExecInspect{}, err
+	}
+	exec, ok := r.execs[execID]
+	if !ok {
+		return types.ContainerExecInspect{}, errdefs.NotFound(fmt.Errorf("No such exec instance: %s", execID))
+	}
+	inspect := types.ContainerExecInspect{ExecID: execID, ContainerID: exec.container.id, ExitCode: int(exec.exitCode)}
+	select {
+	case <-exec.done:
+	default:
+		inspect.Running = true
+	}
+	return inspect, nil
+}
+
+// ImageCreate pulls an image
+func (r *Runtime) ImageCreate(ctx context.Context, parentReference string, options types.ImageCreateOptions) (io.ReadCloser, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.call("ImageCreate"); err != nil {
+		return nil, err
+	}
+	return r.pull(parentReference)
+}
+
+// ImagePull pulls an image, pulled images get a digest derived from their reference
+func (r *Runtime) ImagePull(ctx context.Context, ref string, options types.ImagePullOptions) (io.ReadCloser, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.call("ImagePull"); err != nil {
+		return nil, err
+	}
+	return r.pull(ref)
+}
+
+// ImageBuild adds an image without a registry digest for each tag
+func (r *Runtime) ImageBuild(ctx context.Context, buildContext io.Reader, options types.ImageBuildOptions) (types.ImageBuildResponse, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.call("ImageBuild"); err != nil {
+		return types.ImageBuildResponse{}, err
+	}
+	for _, tag := range options.Tags {
+		r.addImage(tag, "", options.Labels)
+	}
+	return types.ImageBuildResponse{Body: ioutil.NopCloser(strings.NewReader(`{"stream":"Successfully built"}` + "\n"))}, nil
+}
+
+// ImageTag adds the target reference to the source image
+func (r *Runtime) ImageTag(ctx context.Context, source, target string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.call("ImageTag"); err != nil {
+		return err
+	}
+	inspect := r.findImage(source)
+	if inspect == nil {
+		return errdefs.NotFound(fmt.Errorf("No such image: %s", source))
+	}
+	tagged := *inspect
+	tagged.RepoTags = append([]string{}, inspect.RepoTags...)
+	if r.images[withTag(target)] != inspect {
+		tagged.RepoTags = append(tagged.RepoTags, withTag(target))
+	}
+	for _, tag := range tagged.RepoTags {
+		r.images[tag] = &tagged
+	}
+	return nil
+}
+
+// ImageInspectWithRaw describes an image
+func (r *Runtime) ImageInspectWithRaw(ctx context.Context, imageID string) (types.ImageInspect, []byte, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.call("ImageInspectWithRaw"); err != nil {
+		return types.ImageInspect{}, nil, err
+	}
+	inspect := r.findImage(imageID)
+	if inspect == nil {
+		return types.ImageInspect{}, nil, errdefs.NotFound(fmt.Errorf("No such image: %s", imageID))
+	}
+	return *inspect, nil, nil
+}
+
+// ImageList lists the images sorted by their first tag
+func (r *Runtime) ImageList(ctx context.Context, options types.ImageListOptions) ([]types.ImageSummary, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.call("ImageList"); err != nil {
+		return nil, err
+	}
+	seen := make(map[*types.ImageInspect]bool)
+	images := []types.ImageSummary{}
+	for _, inspect := range r.images {
+		if seen[inspect] {
+			continue
+		}
+		seen[inspect] = true
+		images = append(images, types.ImageSummary{
+			ID:          inspect.ID,
+			RepoTags:    inspect.RepoTags,
+			RepoDigests: inspect.RepoDigests,
+			Labels:      inspect.Config.Labels,
+			Size:        inspect.Size,
+		})
+	}
+	sort.Slice(images, func(i, j int) bool { return sortKey(images[i]) < sortKey(images[j]) })
+	return images, nil
+}
+
+// sortKey is the first tag of an image, or its ID for dangling images
+func sortKey(image types.ImageSummary) string {
+	if len(image.RepoTags) == 0 {
+		return image.ID
+	}
+	return image.RepoTags[0]
+}
+
+// ImageRemove untags an image reference, the image is deleted with its last
+// tag or when removed by ID. Images of containers are only removed with
+// options.Force.
+func (r *Runtime) ImageRemove(ctx context.Context, imageID string, options types.ImageRemoveOptions) ([]types.ImageDeleteResponseItem, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.call("ImageRemove"); err != nil {
+		return nil, err
+	}
+	inspect := r.findImage(imageID)
+	if inspect == nil {
+		return nil, errdefs.NotFound(fmt.Errorf("No such image: %s", imageID))
+	}
+	if tag := withTag(imageID); r.images[tag] == inspect && len(inspect.RepoTags) > 1 {
+		r.untag(tag)
+		return []types.ImageDeleteResponseItem{{Untagged: tag}}, nil
+	}
+	if !options.Force {
+		for _, c := range r.containers {
+			if r.findImage(c.image) == inspect {
+				return nil, errdefs.Conflict(fmt.Errorf("conflict: unable to delete %s, image is being used by container %s", imageID, c.id))
+			}
+		}
+	}
+	deleted := []types.ImageDeleteResponseItem{}
+	for key, image := range r.images {
+		if image == inspect {
+			delete(r.images, key)
+		}
+	}
+	for _, tag := range inspect.RepoTags {
+		deleted = append(deleted, types.ImageDeleteResponseItem{Untagged: tag})
+	}
+	return append(deleted, types.ImageDeleteResponseItem{Deleted: inspect.ID}), nil
+}
+
+// ImageSearch finds nothing
+func (r *Runtime) ImageSearch(ctx context.Context, term string, options types.ImageSearchOptions) ([]registry.SearchResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return nil, r.call("ImageSearch")
+}
+
+// NetworkCreate creates a network
+func (r *Runtime) NetworkCreate(ctx context.Context, name string, options types.NetworkCreate) (types.NetworkCreateResponse, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.call("NetworkCreate"); err != nil {
+		return types.NetworkCreateResponse{}, err
+	}
+	if _, ok := r.networks[name]; ok {
+		return types.NetworkCreateResponse{}, errdefs.Conflict(fmt.Errorf("network with name %s already exists", name))
+	}
+	id := digestOf("network " + name)[len("sha256:"):]
+	r.networks[name] = types.NetworkResource{Name: name, ID: id, Driver: options.Driver, Internal: options.Internal, Labels: options.Labels}
+	return types.NetworkCreateResponse{ID: id}, nil
+}
+
+// NetworkConnect connects a container to a network
+func (r *Runtime) NetworkConnect(ctx context.Context, networkID, containerID string, config *network.EndpointSettings) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.call("NetworkConnect"); err != nil {
+		return err
+	}
+	if r.find(containerID) == nil {
+		return errdefs.NotFound(fmt.Errorf("No such container: %s", containerID))
+	}
+	return nil
+}
+
+// NetworkList lists the networks sorted by name
+func (r *Runtime) NetworkList(ctx context.Context, options types.NetworkListOptions) ([]types.NetworkResource, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.call("NetworkList"); err != nil {
+		return nil, err
+	}
+	networks := []types.NetworkResource{}
+	for _, net := range r.networks {
+		networks = append(networks, net)
+	}
+	sort.Slice(networks, func(i, j int) bool { return networks[i].Name < networks[j].Name })
+	return networks, nil
+}
+
+// VolumeCreate creates a volume, an existing volume is returned as is
+func (r *Runtime) VolumeCreate(ctx context.Context, options volumetypes.VolumesCreateBody) (types.Volume, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.call("VolumeCreate"); err != nil {
+		return types.Volume{}, err
+	}
+	if vol, ok := r.volumes[options.Name]; ok {
+		return *vol, nil
+	}
+	vol := &types.Volume{Name: options.Name, Driver: options.Driver, Labels: options.Labels}
+	r.volumes[options.Name] = vol
+	return *vol, nil
+}
+
+// VolumeList lists the volumes sorted by name
+func (r *Runtime) VolumeList(ctx context.Context, filter filters.Args) (volumetypes.VolumesListOKBody, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.call("VolumeList"); err != nil {
+		return volumetypes.VolumesListOKBody{}, err
+	}
+	body := volumetypes.VolumesListOKBody{Volumes: []*types.Volume{}}
+	for _, vol := range r.volumes {
+		body.Volumes = append(body.Volumes, vol)
+	}
+	sort.Slice(body.Volumes, func(i, j int) bool { return body.Volumes[i].Name < body.Volumes[j].Name })
+	return body, nil
+}
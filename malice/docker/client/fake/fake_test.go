@@ -0,0 +1,82 @@
+package fake_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types/strslice"
+	apiclient "github.com/docker/docker/client"
+	"github.com/maliceio/malice/malice/docker/client/container"
+	"github.com/maliceio/malice/malice/docker/client/fake"
+	"github.com/maliceio/malice/malice/docker/client/image"
+)
+
+func TestRuntimeRunsScriptedContainers(t *testing.T) {
+	runtime := fake.New()
+	runtime.AddImage("malice/avast", "sha256:1111", nil)
+	runtime.Script("malice/avast", fake.Behavior{Stdout: `{"avast":{}}`, Stderr: "scanning", ExitCode: 3, Delay: 10 * time.Millisecond})
+	docker := runtime.Docker()
+
+	cont, err := container.Start(docker, strslice.StrSlice{"EICAR"}, "avast1", "malice/avast:latest", false, nil, nil, container.Network{}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, running, _ := container.Running(docker, "avast1"); !running {
+		t.Error("container is not running after start")
+	}
+
+	exitCode, err := container.Wait(context.Background(), docker, cont.ID)
+	if err != nil || exitCode != 3 {
+		t.Errorf("Wait() = %d, %v, want the scripted exit code 3", exitCode, err)
+	}
+	output, err := container.Output(docker, cont.ID)
+	if err != nil || string(output) != `{"avast":{}}` {
+		t.Errorf("Output() = %q, %v, want the scripted stdout", output, err)
+	}
+	if err := container.Remove(docker, cont.ID, true, false, true); err != nil {
+		t.Error(err)
+	}
+	if runtime.Count("ContainerRemove") != 1 {
+		t.Errorf("calls = %v, want one ContainerRemove", runtime.Calls())
+	}
+}
+
+func TestRuntimeFailures(t *testing.T) {
+	runtime := fake.New()
+	docker := runtime.Docker()
+
+	if _, err := image.Inspect(docker, "malice/avast"); !apiclient.IsErrImageNotFound(err) {
+		t.Errorf("Inspect() of a missing image = %v, want a not found error", err)
+	}
+
+	pullErr := errors.New("registry unreachable")
+	runtime.Fail("ImagePull", pullErr)
+	if err := image.Pull(docker, "malice/avast", "latest"); err != pullErr {
+		t.Errorf("Pull() = %v, want %v", err, pullErr)
+	}
+	runtime.Fail("ImagePull", nil)
+	if err := image.Pull(docker, "malice/avast", "latest"); err != nil {
+		t.Fatal(err)
+	}
+	if inspect, err := image.Inspect(docker, "malice/avast"); err != nil || image.LocalDigest(inspect, "malice/avast") == "" {
+		t.Errorf("pulled image = %+v, %v, want it with a registry digest", inspect, err)
+	}
+
+	startErr := errors.New("oci runtime error")
+	runtime.Script("malice/avast", fake.Behavior{StartErr: startErr})
+	if _, err := container.Start(docker, nil, "avast1", "malice/avast", false, nil, nil, container.Network{}, nil, nil); err != startErr {
+		t.Errorf("Start() = %v, want %v", err, startErr)
+	}
+	if _, exists, _ := container.Exists(docker, "avast1"); exists {
+		t.Error("a container that failed to start was left behind")
+	}
+
+	runtime.Script("malice/avast", fake.Behavior{Delay: time.Hour})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, _, err := container.RunIsolated(ctx, docker, "avast2", "malice/avast", nil, nil, nil, false); err != context.DeadlineExceeded {
+		t.Errorf("RunIsolated() of a hanging container = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
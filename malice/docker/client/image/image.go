@@ -23,24 +23,32 @@ import (
 	"github.com/docker/docker/registry"
 	"github.com/maliceio/malice/config"
 	"github.com/maliceio/malice/malice/docker/client"
-	er "github.com/maliceio/malice/malice/errors"
 
 	log "github.com/Sirupsen/logrus"
 )
 
-// Pull pulls docker image:tag
+// Pull pulls docker image:tag, the tag is ignored if id already has a tag or digest
 // TODO: add trusted pull for offcial malice plugins
-func Pull(docker *client.Docker, id string, tag string) {
+func Pull(docker *client.Docker, id string, tag string) error {
 
-	responseBody, err := docker.Client.ImagePull(context.Background(), id, types.ImagePullOptions{})
+	ref := WithTag(id, tag)
+	responseBody, err := docker.Client.ImagePull(context.Background(), ref, types.ImagePullOptions{})
+	if err != nil {
+		log.WithFields(log.Fields{"env": config.Conf.Environment.Run}).Errorf("failed to pull %s: %s", ref, err)
+		return err
+	}
 	defer responseBody.Close()
-	er.CheckError(err)
 
-	jsonmessage.DisplayJSONMessagesStream(responseBody, os.Stdout, os.Stdout.Fd(), true, nil)
+	return jsonmessage.DisplayJSONMessagesStream(responseBody, os.Stdout, os.Stdout.Fd(), true, nil)
+}
+
+// Tag adds the target reference to the source image
+func Tag(docker *client.Docker, source, target string) error {
+	return docker.Client.ImageTag(context.Background(), source, target)
 }
 
 // Build builds docker image from git repository
-func Build(docker *client.Docker, repository string, tags []string, buildArgs map[string]*string, labels map[string]string, quiet bool) {
+func Build(docker *client.Docker, repository string, tags []string, buildArgs map[string]*string, labels map[string]string, quiet bool) error {
 
 	var (
 		buildCtx io.ReadCloser
@@ -66,7 +74,10 @@ func Build(docker *client.Docker, repository string, tags []string, buildArgs ma
 	case urlutil.IsURL(repository):
 		buildCtx, relDockerfile, err = build.GetContextFromURL(progBuff, repository, "")
 	default:
-		_, relDockerfile, err = build.GetContextFromLocalDir(repository, "")
+		contextDir, relDockerfile, err = build.GetContextFromLocalDir(repository, "")
+	}
+	if err != nil {
+		return fmt.Errorf("unable to prepare build context %s: %w", repository, err)
 	}
 
 	if tempDir != "" {
@@ -78,25 +89,25 @@ func Build(docker *client.Docker, repository string, tags []string, buildArgs ma
 		// And canonicalize dockerfile name to a platform-independent one
 		relDockerfile, err = archive.CanonicalTarNameForPath(relDockerfile)
 		if err != nil {
-			log.Fatalf("cannot canonicalize dockerfile path %s: %v", relDockerfile, err)
+			return fmt.Errorf("cannot canonicalize dockerfile path %s: %w", relDockerfile, err)
 		}
 
 		f, err := os.Open(filepath.Join(contextDir, ".dockerignore"))
 		if err != nil && !os.IsNotExist(err) {
-			er.CheckError(err)
+			return err
 		}
-		defer f.Close()
 
 		var excludes []string
 		if err == nil {
 			excludes, err = dockerignore.ReadAll(f)
+			f.Close()
 			if err != nil {
-				er.CheckError(err)
+				return fmt.Errorf("failed to read .dockerignore: %w", err)
 			}
 		}
 
 		if err := build.ValidateContextDirectory(contextDir, excludes); err != nil {
-			log.Fatalf("Error checking context: '%s'.", err)
+			return fmt.Errorf("error checking context: %w", err)
 		}
 
 		// If .dockerignore mentions .dockerignore or the Dockerfile
@@ -118,7 +129,9 @@ func Build(docker *client.Docker, repository string, tags []string, buildArgs ma
 			ExcludePatterns: excludes,
 			IncludeFiles:    includes,
 		})
-		er.CheckError(err)
+		if err != nil {
+			return err
+		}
 	}
 
 	// Setup an upload progress bar
@@ -152,8 +165,10 @@ func Build(docker *client.Docker, repository string, tags []string, buildArgs ma
 		Labels: labels,
 	}
 	response, err := docker.Client.ImageBuild(context.Background(), body, buildOptions)
+	if err != nil {
+		return err
+	}
 	defer response.Body.Close()
-	er.CheckError(err)
 
 	err = jsonmessage.DisplayJSONMessagesStream(response.Body, buildBuff, os.Stdout.Fd(), true, nil)
 	if err != nil {
@@ -166,7 +181,9 @@ func Build(docker *client.Docker, repository string, tags []string, buildArgs ma
 				fmt.Fprintf(os.Stderr, "%s%s", progBuff, buildBuff)
 			}
 		}
+		return err
 	}
+	return nil
 }
 
 // Exists returns APIImages images list and true
@@ -194,6 +211,12 @@ func Exists(docker *client.Docker, name string) (types.ImageSummary, bool, error
 	return types.ImageSummary{}, false, nil
 }
 
+// Inspect returns low-level information about an image, including its ID and labels
+func Inspect(docker *client.Docker, name string) (types.ImageInspect, error) {
+	inspect, _, err := docker.Client.ImageInspectWithRaw(context.Background(), name)
+	return inspect, err
+}
+
 // List lists all images
 func List(docker *client.Docker, name string, all bool) ([]types.ImageSummary, error) {
 
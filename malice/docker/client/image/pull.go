@@ -0,0 +1,211 @@
+package image
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/pkg/jsonmessage"
+	"github.com/docker/go-units"
+	"github.com/maliceio/malice/malice/docker/client"
+	"github.com/moby/term"
+)
+
+// Progress is how far the pull of an image got, summed over its layers
+type Progress struct {
+	Ref string
+	// Layers is the number of layers seen so far, Done the ones pulled or
+	// already present
+	Layers int
+	Done   int
+	// Current and Total are the bytes downloaded and to download of the
+	// layers that reported their size
+	Current int64
+	Total   int64
+}
+
+// layerProgress is the state of one layer in a pull
+type layerProgress struct {
+	current, total int64
+	done           bool
+}
+
+// PullProgress pulls docker image:tag like Pull, but reports the summed
+// progress of its layers to report instead of printing the daemon's stream
+func PullProgress(docker *client.Docker, id string, tag string, report func(Progress)) error {
+	ref := WithTag(id, tag)
+	responseBody, err := docker.Client.ImagePull(context.Background(), ref, types.ImagePullOptions{})
+	if err != nil {
+		return err
+	}
+	defer responseBody.Close()
+
+	return decodeProgress(responseBody, ref, report)
+}
+
+// decodeProgress reads a pull's progress stream until it ends or reports an error
+func decodeProgress(stream io.Reader, ref string, report func(Progress)) error {
+	layers := make(map[string]*layerProgress)
+	decoder := json.NewDecoder(stream)
+	for {
+		var msg jsonmessage.JSONMessage
+		if err := decoder.Decode(&msg); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if msg.Error != nil {
+			return msg.Error
+		}
+		if msg.ID == "" || !updateLayer(layers, msg) {
+			continue
+		}
+
+		progress := Progress{Ref: ref, Layers: len(layers)}
+		for _, layer := range layers {
+			progress.Current += layer.current
+			progress.Total += layer.total
+			if layer.done {
+				progress.Done++
+			}
+		}
+		if report != nil {
+			report(progress)
+		}
+	}
+}
+
+// updateLayer applies a layer's status message, messages that aren't about
+// a layer, e.g. "Pulling from malice/avast", return false
+func updateLayer(layers map[string]*layerProgress, msg jsonmessage.JSONMessage) bool {
+	layer, ok := layers[msg.ID]
+	if !ok {
+		layer = &layerProgress{}
+	}
+	switch {
+	case msg.Status == "Pulling fs layer" || msg.Status == "Waiting":
+	case msg.Status == "Downloading":
+		if msg.Progress != nil {
+			layer.current = msg.Progress.Current
+			if msg.Progress.Total > 0 {
+				layer.total = msg.Progress.Total
+			}
+		}
+	case msg.Status == "Verifying Checksum" || msg.Status == "Download complete" || strings.HasPrefix(msg.Status, "Extracting"):
+		layer.current = layer.total
+	case msg.Status == "Pull complete" || msg.Status == "Already exists":
+		layer.current = layer.total
+		layer.done = true
+	default:
+		return false
+	}
+	layers[msg.ID] = layer
+	return true
+}
+
+// Remove deletes an image by reference or ID, images used by containers
+// are only deleted with force
+func Remove(docker *client.Docker, id string, force bool) error {
+	_, err := docker.Client.ImageRemove(context.Background(), id, types.ImageRemoveOptions{Force: force, PruneChildren: true})
+	return err
+}
+
+// PullBar summarizes pulls running at the same time on one line. On a
+// terminal the line is redrawn as the pulls advance, otherwise a line is
+// printed for each finished image.
+type PullBar struct {
+	mu       sync.Mutex
+	out      io.Writer
+	tty      bool
+	images   int
+	finished int
+	pulls    map[string]Progress
+	drawn    time.Time
+}
+
+// barWidth is the number of characters of the bar itself
+const barWidth = 30
+
+// NewPullBar returns a bar for pulling the number of images to out
+func NewPullBar(out io.Writer, images int) *PullBar {
+	_, tty := term.GetFdInfo(out)
+	return &PullBar{out: out, tty: tty, images: images, pulls: make(map[string]Progress)}
+}
+
+// Update records the progress of a pull
+func (b *PullBar) Update(progress Progress) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.pulls[progress.Ref] = progress
+	// the daemon reports progress far more often than it is worth redrawing
+	if time.Since(b.drawn) > 100*time.Millisecond {
+		b.draw()
+	}
+}
+
+// Finish records the end of the pull of ref, failed pulls are printed
+func (b *PullBar) Finish(ref string, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.finished++
+	progress := b.pulls[ref]
+	progress.Current = progress.Total
+	b.pulls[ref] = progress
+
+	switch {
+	case err != nil && b.tty:
+		fmt.Fprintf(b.out, "\r%-80s\n", fmt.Sprintf("failed to pull %s: %v", ref, err))
+	case err != nil:
+		fmt.Fprintf(b.out, "[%d/%d] failed to pull %s: %v\n", b.finished, b.images, ref, err)
+	case !b.tty:
+		fmt.Fprintf(b.out, "[%d/%d] pulled %s\n", b.finished, b.images, ref)
+	}
+	b.draw()
+}
+
+// Close ends the line of the bar
+func (b *PullBar) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.tty {
+		b.draw()
+		fmt.Fprintln(b.out)
+	}
+}
+
+// draw redraws the bar in place, it does nothing unless out is a terminal
+func (b *PullBar) draw() {
+	if !b.tty {
+		return
+	}
+	b.drawn = time.Now()
+
+	var current, total int64
+	for _, progress := range b.pulls {
+		current += progress.Current
+		total += progress.Total
+	}
+	ratio := 0.0
+	switch {
+	case b.finished == b.images:
+		ratio = 1
+	case total > 0:
+		ratio = float64(current) / float64(total)
+	case b.images > 0:
+		ratio = float64(b.finished) / float64(b.images)
+	}
+	filled := int(ratio * barWidth)
+	bar := strings.Repeat("=", filled) + strings.Repeat(" ", barWidth-filled)
+	if filled > 0 && filled < barWidth {
+		bar = strings.Repeat("=", filled-1) + ">" + strings.Repeat(" ", barWidth-filled)
+	}
+
+	line := fmt.Sprintf("[%d/%d] [%s] %s/%s", b.finished, b.images, bar, units.HumanSize(float64(current)), units.HumanSize(float64(total)))
+	fmt.Fprintf(b.out, "\r%-80s", line)
+}
@@ -0,0 +1,35 @@
+package image
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDecodeProgress(t *testing.T) {
+	stream := `{"status":"Pulling from malice/avast","id":"latest"}
+{"status":"Pulling fs layer","id":"a"}
+{"status":"Pulling fs layer","id":"b"}
+{"status":"Already exists","id":"c"}
+{"status":"Waiting","id":"b"}
+{"status":"Downloading","progressDetail":{"current":50,"total":100},"id":"a"}
+{"status":"Downloading","progressDetail":{"current":10,"total":200},"id":"b"}
+{"status":"Download complete","id":"a"}
+{"status":"Extracting","progressDetail":{"current":5,"total":100},"id":"a"}
+{"status":"Pull complete","id":"a"}
+{"status":"Digest: sha256:1111"}
+{"status":"Status: Downloaded newer image for malice/avast:latest"}
+`
+	var last Progress
+	if err := decodeProgress(strings.NewReader(stream), "malice/avast:latest", func(p Progress) { last = p }); err != nil {
+		t.Fatal(err)
+	}
+	want := Progress{Ref: "malice/avast:latest", Layers: 3, Done: 2, Current: 110, Total: 300}
+	if last != want {
+		t.Errorf("last progress = %+v, want %+v", last, want)
+	}
+
+	stream = `{"errorDetail":{"message":"manifest unknown"},"error":"manifest unknown"}`
+	if err := decodeProgress(strings.NewReader(stream), "malice/avast:0.9", nil); err == nil || err.Error() != "manifest unknown" {
+		t.Errorf("decodeProgress() = %v, want the error of the stream", err)
+	}
+}
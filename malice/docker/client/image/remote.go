@@ -0,0 +1,232 @@
+package image
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultRegistry is the registry of images without a registry host
+	DefaultRegistry = "registry-1.docker.io"
+	// DefaultTag is the tag of images referenced without one
+	DefaultTag = "latest"
+)
+
+// manifestMediaTypes are the manifest types a registry may answer with
+var manifestMediaTypes = []string{
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.oci.image.manifest.v1+json",
+}
+
+var registryClient = &http.Client{Timeout: 30 * time.Second}
+
+// Reference is a parsed image reference such as malice/avast:latest
+type Reference struct {
+	Registry   string
+	Repository string
+	Tag        string
+	Digest     string
+}
+
+// ParseReference splits an image name into registry, repository, tag and digest
+func ParseReference(name string) Reference {
+	ref := Reference{Registry: DefaultRegistry}
+
+	if i := strings.Index(name, "@"); i != -1 {
+		ref.Digest = name[i+1:]
+		name = name[:i]
+	}
+	if i := strings.LastIndex(name, ":"); i != -1 && !strings.Contains(name[i:], "/") {
+		ref.Tag = name[i+1:]
+		name = name[:i]
+	}
+
+	if i := strings.Index(name, "/"); i != -1 {
+		host := name[:i]
+		if strings.ContainsAny(host, ".:") || host == "localhost" {
+			ref.Registry = host
+			name = name[i+1:]
+		}
+	}
+	if ref.Registry == DefaultRegistry || ref.Registry == "docker.io" || ref.Registry == "index.docker.io" {
+		ref.Registry = DefaultRegistry
+		if !strings.Contains(name, "/") {
+			name = "library/" + name
+		}
+	}
+	ref.Repository = name
+
+	if ref.Tag == "" && ref.Digest == "" {
+		ref.Tag = DefaultTag
+	}
+	return ref
+}
+
+// WithTag appends tag to name unless name already has a tag or digest
+func WithTag(name, tag string) string {
+	if tag == "" || HasTagOrDigest(name) {
+		return name
+	}
+	return name + ":" + tag
+}
+
+// HasTagOrDigest returns true if the image name pins a tag or digest
+func HasTagOrDigest(name string) bool {
+	if strings.Contains(name, "@") {
+		return true
+	}
+	i := strings.LastIndex(name, ":")
+	return i != -1 && !strings.Contains(name[i:], "/")
+}
+
+// Name returns the repository without the default registry or library prefix
+func (r Reference) Name() string {
+	if r.Registry != DefaultRegistry {
+		return r.Registry + "/" + r.Repository
+	}
+	return strings.TrimPrefix(r.Repository, "library/")
+}
+
+// String returns the reference in its familiar form
+func (r Reference) String() string {
+	name := r.Name()
+	if r.Tag != "" {
+		name += ":" + r.Tag
+	}
+	if r.Digest != "" {
+		name += "@" + r.Digest
+	}
+	return name
+}
+
+// LocalDigest returns the registry digest a pulled image was resolved to,
+// images that were built locally have none
+func LocalDigest(inspect types.ImageInspect, name string) string {
+	want := ParseReference(name)
+	for _, repoDigest := range inspect.RepoDigests {
+		ref := ParseReference(repoDigest)
+		if ref.Registry == want.Registry && ref.Repository == want.Repository {
+			return ref.Digest
+		}
+	}
+	return ""
+}
+
+// RemoteDigest asks the image's registry for the manifest digest of its tag
+func RemoteDigest(name string) (string, error) {
+	return remoteDigest(registryClient, "https", ParseReference(name))
+}
+
+func remoteDigest(httpClient *http.Client, scheme string, ref Reference) (string, error) {
+	tagOrDigest := ref.Tag
+	if ref.Digest != "" {
+		tagOrDigest = ref.Digest
+	}
+	manifestURL := fmt.Sprintf("%s://%s/v2/%s/manifests/%s", scheme, ref.Registry, ref.Repository, tagOrDigest)
+
+	resp, err := headManifest(httpClient, manifestURL, "")
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		token, err := registryToken(httpClient, resp.Header.Get("Www-Authenticate"))
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to authenticate to %s", ref.Registry)
+		}
+		if resp, err = headManifest(httpClient, manifestURL, token); err != nil {
+			return "", err
+		}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("registry %s returned %s for %s", ref.Registry, resp.Status, ref)
+	}
+
+	digest := resp.Header.Get("Docker-Content-Digest")
+	if digest == "" {
+		return "", fmt.Errorf("registry %s did not return a digest for %s", ref.Registry, ref)
+	}
+	return digest, nil
+}
+
+func headManifest(httpClient *http.Client, manifestURL, token string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodHead, manifestURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", strings.Join(manifestMediaTypes, ", "))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "registry request failed")
+	}
+	resp.Body.Close()
+	return resp, nil
+}
+
+// registryToken fetches an anonymous pull token from the realm in a
+// `Bearer realm="...",service="...",scope="..."` challenge
+func registryToken(httpClient *http.Client, challenge string) (string, error) {
+	params := parseChallenge(challenge)
+	if params["realm"] == "" {
+		return "", fmt.Errorf("unsupported auth challenge %q", challenge)
+	}
+
+	realm, err := url.Parse(params["realm"])
+	if err != nil {
+		return "", err
+	}
+	query := realm.Query()
+	for _, key := range []string{"service", "scope"} {
+		if params[key] != "" {
+			query.Set(key, params[key])
+		}
+	}
+	realm.RawQuery = query.Encode()
+
+	resp, err := httpClient.Get(realm.String())
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("token request returned %s", resp.Status)
+	}
+
+	var body struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", err
+	}
+	if body.Token != "" {
+		return body.Token, nil
+	}
+	return body.AccessToken, nil
+}
+
+var challengeParamRegex = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+// parseChallenge parses the key="value" pairs of a Bearer WWW-Authenticate header
+func parseChallenge(challenge string) map[string]string {
+	params := make(map[string]string)
+	if !strings.HasPrefix(strings.ToLower(challenge), "bearer ") {
+		return params
+	}
+	for _, match := range challengeParamRegex.FindAllStringSubmatch(challenge, -1) {
+		params[strings.ToLower(match[1])] = match[2]
+	}
+	return params
+}
@@ -0,0 +1,96 @@
+package image
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+)
+
+func TestParseReference(t *testing.T) {
+	tests := []struct {
+		name string
+		want Reference
+	}{
+		{name: "busybox", want: Reference{Registry: DefaultRegistry, Repository: "library/busybox", Tag: "latest"}},
+		{name: "malice/avast:1.0", want: Reference{Registry: DefaultRegistry, Repository: "malice/avast", Tag: "1.0"}},
+		{name: "docker.io/malice/avast", want: Reference{Registry: DefaultRegistry, Repository: "malice/avast", Tag: "latest"}},
+		{name: "localhost:5000/avast:dev", want: Reference{Registry: "localhost:5000", Repository: "avast", Tag: "dev"}},
+		{name: "ghcr.io/org/avast@sha256:abcd", want: Reference{Registry: "ghcr.io", Repository: "org/avast", Digest: "sha256:abcd"}},
+		{name: "malice/avast:1.0@sha256:abcd", want: Reference{Registry: DefaultRegistry, Repository: "malice/avast", Tag: "1.0", Digest: "sha256:abcd"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseReference(tt.name); got != tt.want {
+				t.Errorf("ParseReference() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithTag(t *testing.T) {
+	tests := []struct {
+		name string
+		tag  string
+		want string
+	}{
+		{name: "malice/avast", tag: "latest", want: "malice/avast:latest"},
+		{name: "malice/avast:1.0", tag: "latest", want: "malice/avast:1.0"},
+		{name: "malice/avast@sha256:abcd", tag: "latest", want: "malice/avast@sha256:abcd"},
+		{name: "localhost:5000/avast", tag: "dev", want: "localhost:5000/avast:dev"},
+		{name: "malice/avast", tag: "", want: "malice/avast"},
+	}
+
+	for _, tt := range tests {
+		if got := WithTag(tt.name, tt.tag); got != tt.want {
+			t.Errorf("WithTag(%q, %q) = %q, want %q", tt.name, tt.tag, got, tt.want)
+		}
+	}
+}
+
+func TestLocalDigest(t *testing.T) {
+	inspect := types.ImageInspect{RepoDigests: []string{"malice/nsrl@sha256:1111", "malice/avast@sha256:2222"}}
+	if got := LocalDigest(inspect, "malice/avast:latest"); got != "sha256:2222" {
+		t.Errorf("LocalDigest() = %q, want sha256:2222", got)
+	}
+	if got := LocalDigest(types.ImageInspect{}, "malice/avast"); got != "" {
+		t.Errorf("LocalDigest() of a local build = %q, want none", got)
+	}
+}
+
+func TestRemoteDigest(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/token":
+			if r.URL.Query().Get("scope") != "repository:malice/avast:pull" {
+				t.Errorf("token scope = %q", r.URL.Query().Get("scope"))
+			}
+			w.Write([]byte(`{"token":"t0k3n"}`))
+		case r.Header.Get("Authorization") != "Bearer t0k3n":
+			w.Header().Set("Www-Authenticate", `Bearer realm="`+server.URL+`/token",service="registry",scope="repository:malice/avast:pull"`)
+			w.WriteHeader(http.StatusUnauthorized)
+		case r.URL.Path == "/v2/malice/avast/manifests/latest":
+			if !strings.Contains(r.Header.Get("Accept"), "manifest.list.v2+json") {
+				t.Errorf("Accept = %q", r.Header.Get("Accept"))
+			}
+			w.Header().Set("Docker-Content-Digest", "sha256:3333")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	host := strings.TrimPrefix(server.URL, "https://")
+	digest, err := remoteDigest(server.Client(), "https", ParseReference(host+"/malice/avast"))
+	if err != nil || digest != "sha256:3333" {
+		t.Errorf("remoteDigest() = %q, %v, want sha256:3333", digest, err)
+	}
+
+	if _, err := remoteDigest(server.Client(), "https", ParseReference(host+"/malice/missing")); err == nil {
+		t.Error("remoteDigest() of a missing image returned no error")
+	}
+}
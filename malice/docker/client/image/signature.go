@@ -0,0 +1,196 @@
+package image
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Signatures are read the way cosign stores them: an OCI manifest tagged
+// sha256-<digest>.sig next to the image with one layer per signature. Each
+// layer is a payload naming the signed digest, the signature of the payload
+// is in the layer's annotations.
+const (
+	signatureAnnotation = "dev.cosignproject.cosign/signature"
+	signatureType       = "cosign container image signature"
+)
+
+// ErrNotSigned is returned for images without a signature
+var ErrNotSigned = errors.New("image is not signed")
+
+// SignatureTag returns the tag the signatures of digest are stored under
+func SignatureTag(digest string) string {
+	return strings.Replace(digest, ":", "-", 1) + ".sig"
+}
+
+// ParsePublicKey parses a PEM encoded ECDSA, Ed25519 or RSA public key
+func ParsePublicKey(data []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM encoded public key found")
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse public key")
+	}
+	switch key.(type) {
+	case *ecdsa.PublicKey, ed25519.PublicKey, *rsa.PublicKey:
+		return key, nil
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T", key)
+	}
+}
+
+// VerifySignature checks that digest, the manifest digest of the image name,
+// carries a signature made with key
+func VerifySignature(name, digest string, key crypto.PublicKey) error {
+	return verifySignature(registryClient, "https", ParseReference(name), digest, key)
+}
+
+func verifySignature(httpClient *http.Client, scheme string, ref Reference, digest string, key crypto.PublicKey) error {
+	repoURL := fmt.Sprintf("%s://%s/v2/%s", scheme, ref.Registry, ref.Repository)
+
+	data, status, err := registryGet(httpClient, repoURL+"/manifests/"+SignatureTag(digest), strings.Join(manifestMediaTypes, ", "))
+	if err != nil {
+		return err
+	}
+	switch status {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return ErrNotSigned
+	default:
+		return fmt.Errorf("registry %s returned %d for the signature of %s", ref.Registry, status, ref.Name())
+	}
+
+	var manifest struct {
+		Layers []struct {
+			Digest      string            `json:"digest"`
+			Annotations map[string]string `json:"annotations"`
+		} `json:"layers"`
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return errors.Wrap(err, "failed to parse signature manifest")
+	}
+
+	// any valid signature will do
+	lastErr := ErrNotSigned
+	for _, layer := range manifest.Layers {
+		signature, ok := layer.Annotations[signatureAnnotation]
+		if !ok {
+			continue
+		}
+		payload, status, err := registryGet(httpClient, repoURL+"/blobs/"+layer.Digest, "")
+		if err == nil && status != http.StatusOK {
+			err = fmt.Errorf("registry %s returned %d for signature payload %s", ref.Registry, status, layer.Digest)
+		}
+		if err == nil {
+			err = verifyPayload(payload, layer.Digest, signature, digest, key)
+		}
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+	}
+	return lastErr
+}
+
+// verifyPayload checks that a signature payload names digest and that
+// signature is its signature made with key
+func verifyPayload(payload []byte, payloadDigest, signature, digest string, key crypto.PublicKey) error {
+	sum := sha256.Sum256(payload)
+	if "sha256:"+hex.EncodeToString(sum[:]) != payloadDigest {
+		return errors.New("signature payload does not match its digest")
+	}
+
+	var simpleSigning struct {
+		Critical struct {
+			Image struct {
+				Digest string `json:"docker-manifest-digest"`
+			} `json:"image"`
+			Type string `json:"type"`
+		} `json:"critical"`
+	}
+	if err := json.Unmarshal(payload, &simpleSigning); err != nil {
+		return errors.Wrap(err, "failed to parse signature payload")
+	}
+	if simpleSigning.Critical.Type != signatureType {
+		return fmt.Errorf("unsupported signature type %q", simpleSigning.Critical.Type)
+	}
+	if simpleSigning.Critical.Image.Digest != digest {
+		return fmt.Errorf("signature is for %s, not %s", simpleSigning.Critical.Image.Digest, digest)
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return errors.Wrap(err, "failed to decode signature")
+	}
+	var valid bool
+	switch key := key.(type) {
+	case *ecdsa.PublicKey:
+		valid = ecdsa.VerifyASN1(key, sum[:], raw)
+	case ed25519.PublicKey:
+		valid = ed25519.Verify(key, payload, raw)
+	case *rsa.PublicKey:
+		valid = rsa.VerifyPKCS1v15(key, crypto.SHA256, sum[:], raw) == nil
+	default:
+		return fmt.Errorf("unsupported public key type %T", key)
+	}
+	if !valid {
+		return errors.New("signature does not match the public key")
+	}
+	return nil
+}
+
+// registryGet fetches a registry url, authenticating with an anonymous pull
+// token when challenged, and returns the body and status code
+func registryGet(httpClient *http.Client, url, accept string) ([]byte, int, error) {
+	get := func(token string) (*http.Response, error) {
+		req, err := http.NewRequest(http.MethodGet, url, nil)
+		if err != nil {
+			return nil, err
+		}
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := httpClient.Do(req)
+		if err != nil {
+			return nil, errors.Wrap(err, "registry request failed")
+		}
+		return resp, nil
+	}
+
+	resp, err := get("")
+	if err != nil {
+		return nil, 0, err
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		resp.Body.Close()
+		token, err := registryToken(httpClient, resp.Header.Get("Www-Authenticate"))
+		if err != nil {
+			return nil, 0, errors.Wrap(err, "failed to authenticate to registry")
+		}
+		if resp, err = get(token); err != nil {
+			return nil, 0, err
+		}
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	return data, resp.StatusCode, err
+}
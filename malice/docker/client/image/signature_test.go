@@ -0,0 +1,91 @@
+package image
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const signedDigest = "sha256:4b8c5a0b5ed9c6d8ef2ae7e5ef3a8a6d6e2c1d0b9a8f7e6d5c4b3a2f1e0d9c8b"
+
+// signatureServer serves a cosign signature of digest made with key for malice/avast
+func signatureServer(t *testing.T, key *ecdsa.PrivateKey, digest string) *httptest.Server {
+	payload := []byte(fmt.Sprintf(`{"critical":{"identity":{"docker-reference":"malice/avast"},"image":{"docker-manifest-digest":%q},"type":"cosign container image signature"},"optional":null}`, digest))
+	sum := sha256.Sum256(payload)
+	sig, err := ecdsa.SignASN1(rand.Reader, key, sum[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	payloadDigest := "sha256:" + hex.EncodeToString(sum[:])
+	manifest := fmt.Sprintf(`{"schemaVersion":2,"layers":[{"mediaType":"application/vnd.dev.cosign.simplesigning.v1+json","digest":%q,"annotations":{%q:%q}}]}`,
+		payloadDigest, signatureAnnotation, base64.StdEncoding.EncodeToString(sig))
+
+	return httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/malice/avast/manifests/" + SignatureTag(signedDigest):
+			w.Write([]byte(manifest))
+		case "/v2/malice/avast/blobs/" + payloadDigest:
+			w.Write(payload)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestVerifySignature(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	other, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	tests := []struct {
+		name      string
+		signed    string
+		verifyKey crypto.PublicKey
+		digest    string
+		wantErr   string
+	}{
+		{name: "valid signature", signed: signedDigest, verifyKey: &key.PublicKey, digest: signedDigest},
+		{name: "other key", signed: signedDigest, verifyKey: &other.PublicKey, digest: signedDigest, wantErr: "does not match the public key"},
+		{name: "unsigned digest", signed: signedDigest, verifyKey: &key.PublicKey, digest: "sha256:1111", wantErr: ErrNotSigned.Error()},
+		{name: "signature for another digest", signed: "sha256:2222", verifyKey: &key.PublicKey, digest: signedDigest, wantErr: "signature is for sha256:2222"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := signatureServer(t, key, tt.signed)
+			defer server.Close()
+
+			ref := ParseReference(strings.TrimPrefix(server.URL, "https://") + "/malice/avast")
+			err := verifySignature(server.Client(), "https", ref, tt.digest, tt.verifyKey)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("verifySignature() = %v", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Errorf("verifySignature() = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParsePublicKey(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	der, _ := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	data := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+
+	if _, err := ParsePublicKey(data); err != nil {
+		t.Errorf("ParsePublicKey() = %v", err)
+	}
+	if _, err := ParsePublicKey([]byte("not a key")); err == nil {
+		t.Error("ParsePublicKey() of garbage returned no error")
+	}
+}
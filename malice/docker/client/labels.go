@@ -0,0 +1,49 @@
+package client
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"strconv"
+)
+
+// Labels of the containers malice starts for a scan, they tell which process
+// owns a container so ones left behind by a dead scan can be removed
+const (
+	// OwnerLabel holds the host of the owning process
+	OwnerLabel = "io.malice.owner"
+	// PIDLabel holds the PID of the owning process
+	PIDLabel = "io.malice.pid"
+	// ScanLabel holds the scan the container is part of
+	ScanLabel = "io.malice.scan"
+	// PluginLabel holds the plugin the container runs
+	PluginLabel = "io.malice.plugin"
+)
+
+// OwnerLabels returns the labels of a container owned by this process,
+// scanID and plugin are left out when empty
+func OwnerLabels(scanID, plugin string) map[string]string {
+	host, _ := os.Hostname()
+	labels := map[string]string{
+		OwnerLabel: host,
+		PIDLabel:   strconv.Itoa(os.Getpid()),
+	}
+	if scanID != "" {
+		labels[ScanLabel] = scanID
+	}
+	if plugin != "" {
+		labels[PluginLabel] = plugin
+	}
+	return labels
+}
+
+// ContainerName returns a name starting with prefix that no other container
+// uses, so scans running at the same time don't collide
+func ContainerName(prefix string) string {
+	suffix := make([]byte, 6)
+	if _, err := rand.Read(suffix); err != nil {
+		// the PID still keeps apart containers of different processes
+		return prefix + "-" + strconv.Itoa(os.Getpid())
+	}
+	return prefix + "-" + hex.EncodeToString(suffix)
+}
@@ -6,10 +6,9 @@ import (
 	"github.com/docker/docker/api/types/network"
 	"github.com/maliceio/malice/config"
 	"github.com/maliceio/malice/malice/docker/client"
+	"github.com/pkg/errors"
 	"golang.org/x/net/context"
 
-	"regexp"
-
 	log "github.com/Sirupsen/logrus"
 )
 
@@ -48,6 +47,41 @@ func Connect(docker *client.Docker, net types.NetworkResource, container types.C
 	return docker.Client.NetworkConnect(context.Background(), net.ID, container.ID, &netConfig)
 }
 
+// DefaultName is the network of malice when the config doesn't set one
+const DefaultName = "malice"
+
+// Name returns the name of the network of malice set in the config
+func Name() string {
+	if config.Conf.Network.Name == "" {
+		return DefaultName
+	}
+	return config.Conf.Network.Name
+}
+
+// Ensure returns the network of malice, it is created when it doesn't exist
+// yet. The database, the UI and intel plugins are attached to it.
+func Ensure(docker *client.Docker) (types.NetworkResource, error) {
+	name := Name()
+	if net, exists, err := Exists(docker, name); err != nil || exists {
+		return net, err
+	}
+	options := types.NetworkCreate{
+		CheckDuplicate: true,
+		Driver:         "bridge",
+		Internal:       config.Conf.Network.Internal,
+	}
+	resp, err := docker.Client.NetworkCreate(context.Background(), name, options)
+	if err != nil {
+		return types.NetworkResource{}, errors.Wrapf(err, "failed to create network %s", name)
+	}
+	log.WithFields(log.Fields{
+		"name":     name,
+		"internal": options.Internal,
+		"env":      config.Conf.Environment.Run,
+	}).Info("Created Network: ", name)
+	return types.NetworkResource{ID: resp.ID, Name: name, Driver: options.Driver, Internal: options.Internal}, nil
+}
+
 // parseNetworks parses the networks
 func parseNetworks(docker *client.Docker, name string, all bool) (types.NetworkResource, bool, error) {
 	// list networks
@@ -57,10 +91,9 @@ func parseNetworks(docker *client.Docker, name string, all bool) (types.NetworkR
 		return types.NetworkResource{}, false, err
 	}
 	// locate docker Network that matches name
-	r := regexp.MustCompile(name)
 	if len(networks) != 0 {
 		for _, network := range networks {
-			if r.MatchString(network.Name) {
+			if network.Name == name {
 				log.WithFields(log.Fields{"env": config.Conf.Environment.Run}).Debug("Network FOUND: ", name)
 				return network, true, nil
 			}
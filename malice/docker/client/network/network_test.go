@@ -0,0 +1,58 @@
+package network_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/maliceio/malice/config"
+	"github.com/maliceio/malice/malice/docker/client/fake"
+	"github.com/maliceio/malice/malice/docker/client/network"
+)
+
+func TestEnsure(t *testing.T) {
+	defer func(conf config.Configuration) { config.Conf = conf }(config.Conf)
+
+	tests := []struct {
+		name     string
+		config   string
+		internal bool
+		want     string
+	}{
+		{name: "default name", want: network.DefaultName},
+		{name: "configured", config: "malice-isolated", internal: true, want: "malice-isolated"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config.Conf.Network.Name = tt.config
+			config.Conf.Network.Internal = tt.internal
+			runtime := fake.New()
+			docker := runtime.Docker()
+
+			// a network whose name only contains the wanted one isn't it
+			if _, err := docker.Client.NetworkCreate(context.Background(), tt.want+"-old", types.NetworkCreate{}); err != nil {
+				t.Fatal(err)
+			}
+
+			created, err := network.Ensure(docker)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if created.Name != tt.want || created.Internal != tt.internal {
+				t.Errorf("Ensure() = %s (internal %v), want %s (internal %v)", created.Name, created.Internal, tt.want, tt.internal)
+			}
+
+			found, err := network.Ensure(docker)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if found.ID != created.ID {
+				t.Errorf("second Ensure() = %s, want the existing network %s", found.ID, created.ID)
+			}
+			// the first network was created by the test
+			if got := runtime.Count("NetworkCreate") - 1; got != 1 {
+				t.Errorf("created the network %d times, want once", got)
+			}
+		})
+	}
+}
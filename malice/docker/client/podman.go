@@ -0,0 +1,143 @@
+package client
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/client"
+	"github.com/pkg/errors"
+)
+
+// defaultRegistry is the registry Docker resolves short image names against
+const defaultRegistry = "docker.io/"
+
+// podman adapts Podman's Docker compatible API socket to the Runtime.
+// Podman refuses short image names without a TTY to ask on when short name
+// aliases are enforced, and reports images by their fully qualified names,
+// so image names are qualified on the way in and shortened on the way out.
+type podman struct {
+	*client.Client
+}
+
+// newPodmanRuntime connects to the Podman API socket at host, found with
+// podmanHost when empty
+func newPodmanRuntime(host string) (Runtime, error) {
+	if host == "" {
+		host = podmanHost()
+	}
+	cli, err := client.NewClientWithOpts(client.WithHost(host), client.WithAPIVersionNegotiation())
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create podman client for %s", host)
+	}
+	return podman{Client: cli}, nil
+}
+
+// podmanHost returns the Podman API socket: CONTAINER_HOST if set, then the
+// rootless socket of the user and then the system socket
+func podmanHost() string {
+	if host := os.Getenv("CONTAINER_HOST"); host != "" {
+		return host
+	}
+	runtimeDir := os.Getenv("XDG_RUNTIME_DIR")
+	if runtimeDir == "" && os.Getuid() > 0 {
+		runtimeDir = filepath.Join("/run/user", strconv.Itoa(os.Getuid()))
+	}
+	if runtimeDir != "" {
+		socket := filepath.Join(runtimeDir, "podman", "podman.sock")
+		if _, err := os.Stat(socket); err == nil {
+			return "unix://" + socket
+		}
+	}
+	return "unix:///run/podman/podman.sock"
+}
+
+// imageIDRegex matches image IDs, which must not be qualified
+var imageIDRegex = regexp.MustCompile(`^(sha256:)?[0-9a-f]{12,64}$`)
+
+// qualifyImage prefixes short image names with the Docker Hub registry the
+// way Docker resolves them, e.g. malice/avast becomes docker.io/malice/avast
+func qualifyImage(name string) string {
+	if name == "" || imageIDRegex.MatchString(name) {
+		return name
+	}
+	if i := strings.Index(name, "/"); i > 0 {
+		first := name[:i]
+		if strings.ContainsAny(first, ".:") || first == "localhost" {
+			return name
+		}
+		return defaultRegistry + name
+	}
+	return defaultRegistry + "library/" + name
+}
+
+// shortenImage reverses qualifyImage for images from the Docker Hub
+func shortenImage(name string) string {
+	name = strings.TrimPrefix(name, defaultRegistry)
+	return strings.TrimPrefix(name, "library/")
+}
+
+func shortenImages(names []string) []string {
+	for i, name := range names {
+		names[i] = shortenImage(name)
+	}
+	return names
+}
+
+func (p podman) ContainerCreate(ctx context.Context, config *container.Config, hostConfig *container.HostConfig, networkingConfig *network.NetworkingConfig, containerName string) (container.ContainerCreateCreatedBody, error) {
+	if config != nil {
+		qualified := *config
+		qualified.Image = qualifyImage(config.Image)
+		config = &qualified
+	}
+	return p.Client.ContainerCreate(ctx, config, hostConfig, networkingConfig, containerName)
+}
+
+func (p podman) ImageCreate(ctx context.Context, parentReference string, options types.ImageCreateOptions) (io.ReadCloser, error) {
+	return p.Client.ImageCreate(ctx, qualifyImage(parentReference), options)
+}
+
+func (p podman) ImagePull(ctx context.Context, ref string, options types.ImagePullOptions) (io.ReadCloser, error) {
+	return p.Client.ImagePull(ctx, qualifyImage(ref), options)
+}
+
+func (p podman) ImageTag(ctx context.Context, source, target string) error {
+	return p.Client.ImageTag(ctx, qualifyImage(source), qualifyImage(target))
+}
+
+func (p podman) ImageInspectWithRaw(ctx context.Context, imageID string) (types.ImageInspect, []byte, error) {
+	inspect, raw, err := p.Client.ImageInspectWithRaw(ctx, qualifyImage(imageID))
+	inspect.RepoTags = shortenImages(inspect.RepoTags)
+	inspect.RepoDigests = shortenImages(inspect.RepoDigests)
+	return inspect, raw, err
+}
+
+func (p podman) ImageList(ctx context.Context, options types.ImageListOptions) ([]types.ImageSummary, error) {
+	images, err := p.Client.ImageList(ctx, options)
+	for i := range images {
+		images[i].RepoTags = shortenImages(images[i].RepoTags)
+		images[i].RepoDigests = shortenImages(images[i].RepoDigests)
+	}
+	return images, err
+}
+
+func (p podman) ImageRemove(ctx context.Context, imageID string, options types.ImageRemoveOptions) ([]types.ImageDeleteResponseItem, error) {
+	return p.Client.ImageRemove(ctx, qualifyImage(imageID), options)
+}
+
+// ImageBuild tags builds like Docker would, Podman puts short names under localhost/
+func (p podman) ImageBuild(ctx context.Context, buildContext io.Reader, options types.ImageBuildOptions) (types.ImageBuildResponse, error) {
+	tags := make([]string, len(options.Tags))
+	for i, tag := range options.Tags {
+		tags[i] = qualifyImage(tag)
+	}
+	options.Tags = tags
+	return p.Client.ImageBuild(ctx, buildContext, options)
+}
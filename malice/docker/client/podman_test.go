@@ -0,0 +1,33 @@
+package client
+
+import "testing"
+
+func TestQualifyImage(t *testing.T) {
+	tests := []struct {
+		name  string
+		want  string
+		short string
+	}{
+		{name: "malice/avast", want: "docker.io/malice/avast"},
+		{name: "malice/avast:0.3.0", want: "docker.io/malice/avast:0.3.0"},
+		{name: "busybox", want: "docker.io/library/busybox"},
+		{name: "malice/avast@sha256:4b8c5a0b5ed9", want: "docker.io/malice/avast@sha256:4b8c5a0b5ed9"},
+		{name: "quay.io/malice/yara", want: "quay.io/malice/yara"},
+		{name: "localhost:5000/avast", want: "localhost:5000/avast"},
+		{name: "localhost/avast", want: "localhost/avast"},
+		{name: "sha256:4b8c5a0b5ed9a7ec2cd3c7eb", want: "sha256:4b8c5a0b5ed9a7ec2cd3c7eb"},
+		{name: "4b8c5a0b5ed9", want: "4b8c5a0b5ed9"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := qualifyImage(tt.name)
+			if got != tt.want {
+				t.Errorf("qualifyImage() = %q, want %q", got, tt.want)
+			}
+			if short := shortenImage(got); short != tt.name {
+				t.Errorf("shortenImage(%q) = %q, want %q", got, short, tt.name)
+			}
+		})
+	}
+}
@@ -0,0 +1,322 @@
+package client
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/registry"
+	volumetypes "github.com/docker/docker/api/types/volume"
+	"github.com/docker/docker/client"
+)
+
+// reconnectingRuntime is a Runtime that creates a new client of the runtime
+// once a call could not reach it. Clients negotiate the API version on first
+// use, so a daemon that restarted, or was upgraded, is talked to afresh
+// instead of with the state of the old connection.
+type reconnectingRuntime struct {
+	connect func() (Runtime, error)
+
+	mu      sync.Mutex
+	current Runtime
+}
+
+func newReconnectingRuntime(connect func() (Runtime, error)) (*reconnectingRuntime, error) {
+	current, err := connect()
+	if err != nil {
+		return nil, err
+	}
+	return &reconnectingRuntime{connect: connect, current: current}, nil
+}
+
+// get returns the current client, creating a new one after a lost connection
+func (r *reconnectingRuntime) get() (Runtime, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.current == nil {
+		current, err := r.connect()
+		if err != nil {
+			return nil, err
+		}
+		r.current = current
+	}
+	return r.current, nil
+}
+
+// check drops the client rt when err says it could not reach the runtime
+func (r *reconnectingRuntime) check(rt Runtime, err error) error {
+	if err == nil || !client.IsErrConnectionFailed(err) {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.current == rt {
+		if closer, ok := rt.(io.Closer); ok {
+			closer.Close()
+		}
+		r.current = nil
+	}
+	return err
+}
+
+func (r *reconnectingRuntime) ClientVersion() string {
+	rt, err := r.get()
+	if err != nil {
+		return ""
+	}
+	return rt.ClientVersion()
+}
+
+func (r *reconnectingRuntime) Info(ctx context.Context) (types.Info, error) {
+	rt, err := r.get()
+	if err != nil {
+		return types.Info{}, err
+	}
+	info, err := rt.Info(ctx)
+	return info, r.check(rt, err)
+}
+
+func (r *reconnectingRuntime) Events(ctx context.Context, options types.EventsOptions) (<-chan events.Message, <-chan error) {
+	rt, err := r.get()
+	if err != nil {
+		errs := make(chan error, 1)
+		errs <- err
+		return make(chan events.Message), errs
+	}
+	return rt.Events(ctx, options)
+}
+
+func (r *reconnectingRuntime) ContainerCreate(ctx context.Context, config *container.Config, hostConfig *container.HostConfig, networkingConfig *network.NetworkingConfig, containerName string) (container.ContainerCreateCreatedBody, error) {
+	rt, err := r.get()
+	if err != nil {
+		return container.ContainerCreateCreatedBody{}, err
+	}
+	body, err := rt.ContainerCreate(ctx, config, hostConfig, networkingConfig, containerName)
+	return body, r.check(rt, err)
+}
+
+func (r *reconnectingRuntime) ContainerStart(ctx context.Context, containerID string, options types.ContainerStartOptions) error {
+	rt, err := r.get()
+	if err != nil {
+		return err
+	}
+	return r.check(rt, rt.ContainerStart(ctx, containerID, options))
+}
+
+func (r *reconnectingRuntime) ContainerWait(ctx context.Context, containerID string, condition container.WaitCondition) (<-chan container.ContainerWaitOKBody, <-chan error) {
+	rt, err := r.get()
+	if err != nil {
+		errs := make(chan error, 1)
+		errs <- err
+		return make(chan container.ContainerWaitOKBody), errs
+	}
+	return rt.ContainerWait(ctx, containerID, condition)
+}
+
+func (r *reconnectingRuntime) ContainerLogs(ctx context.Context, containerID string, options types.ContainerLogsOptions) (io.ReadCloser, error) {
+	rt, err := r.get()
+	if err != nil {
+		return nil, err
+	}
+	logs, err := rt.ContainerLogs(ctx, containerID, options)
+	return logs, r.check(rt, err)
+}
+
+func (r *reconnectingRuntime) ContainerInspect(ctx context.Context, containerID string) (types.ContainerJSON, error) {
+	rt, err := r.get()
+	if err != nil {
+		return types.ContainerJSON{}, err
+	}
+	inspect, err := rt.ContainerInspect(ctx, containerID)
+	return inspect, r.check(rt, err)
+}
+
+func (r *reconnectingRuntime) ContainerList(ctx context.Context, options types.ContainerListOptions) ([]types.Container, error) {
+	rt, err := r.get()
+	if err != nil {
+		return nil, err
+	}
+	containers, err := rt.ContainerList(ctx, options)
+	return containers, r.check(rt, err)
+}
+
+func (r *reconnectingRuntime) ContainerRemove(ctx context.Context, containerID string, options types.ContainerRemoveOptions) error {
+	rt, err := r.get()
+	if err != nil {
+		return err
+	}
+	return r.check(rt, rt.ContainerRemove(ctx, containerID, options))
+}
+
+func (r *reconnectingRuntime) ContainerStatPath(ctx context.Context, containerID, path string) (types.ContainerPathStat, error) {
+	rt, err := r.get()
+	if err != nil {
+		return types.ContainerPathStat{}, err
+	}
+	stat, err := rt.ContainerStatPath(ctx, containerID, path)
+	return stat, r.check(rt, err)
+}
+
+func (r *reconnectingRuntime) CopyToContainer(ctx context.Context, containerID, dstPath string, content io.Reader, options types.CopyToContainerOptions) error {
+	rt, err := r.get()
+	if err != nil {
+		return err
+	}
+	return r.check(rt, rt.CopyToContainer(ctx, containerID, dstPath, content, options))
+}
+
+func (r *reconnectingRuntime) ContainerAttach(ctx context.Context, containerID string, options types.ContainerAttachOptions) (types.HijackedResponse, error) {
+	rt, err := r.get()
+	if err != nil {
+		return types.HijackedResponse{}, err
+	}
+	resp, err := rt.ContainerAttach(ctx, containerID, options)
+	return resp, r.check(rt, err)
+}
+
+func (r *reconnectingRuntime) ContainerExecCreate(ctx context.Context, containerID string, config types.ExecConfig) (types.IDResponse, error) {
+	rt, err := r.get()
+	if err != nil {
+		return types.IDResponse{}, err
+	}
+	resp, err := rt.ContainerExecCreate(ctx, containerID, config)
+	return resp, r.check(rt, err)
+}
+
+func (r *reconnectingRuntime) ContainerExecAttach(ctx context.Context, execID string, config types.ExecStartCheck) (types.HijackedResponse, error) {
+	rt, err := r.get()
+	if err != nil {
+		return types.HijackedResponse{}, err
+	}
+	resp, err := rt.ContainerExecAttach(ctx, execID, config)
+	return resp, r.check(rt, err)
+}
+
+func (r *reconnectingRuntime) ContainerExecInspect(ctx context.Context, execID string) (types.ContainerExecInspect, error) {
+	rt, err := r.get()
+	if err != nil {
+		return types.ContainerExecInspect{}, err
+	}
+	inspect, err := rt.ContainerExecInspect(ctx, execID)
+	return inspect, r.check(rt, err)
+}
+
+func (r *reconnectingRuntime) ImageCreate(ctx context.Context, parentReference string, options types.ImageCreateOptions) (io.ReadCloser, error) {
+	rt, err := r.get()
+	if err != nil {
+		return nil, err
+	}
+	body, err := rt.ImageCreate(ctx, parentReference, options)
+	return body, r.check(rt, err)
+}
+
+func (r *reconnectingRuntime) ImagePull(ctx context.Context, ref string, options types.ImagePullOptions) (io.ReadCloser, error) {
+	rt, err := r.get()
+	if err != nil {
+		return nil, err
+	}
+	body, err := rt.ImagePull(ctx, ref, options)
+	return body, r.check(rt, err)
+}
+
+func (r *reconnectingRuntime) ImageBuild(ctx context.Context, buildContext io.Reader, options types.ImageBuildOptions) (types.ImageBuildResponse, error) {
+	rt, err := r.get()
+	if err != nil {
+		return types.ImageBuildResponse{}, err
+	}
+	resp, err := rt.ImageBuild(ctx, buildContext, options)
+	return resp, r.check(rt, err)
+}
+
+func (r *reconnectingRuntime) ImageTag(ctx context.Context, source, target string) error {
+	rt, err := r.get()
+	if err != nil {
+		return err
+	}
+	return r.check(rt, rt.ImageTag(ctx, source, target))
+}
+
+func (r *reconnectingRuntime) ImageInspectWithRaw(ctx context.Context, imageID string) (types.ImageInspect, []byte, error) {
+	rt, err := r.get()
+	if err != nil {
+		return types.ImageInspect{}, nil, err
+	}
+	inspect, raw, err := rt.ImageInspectWithRaw(ctx, imageID)
+	return inspect, raw, r.check(rt, err)
+}
+
+func (r *reconnectingRuntime) ImageList(ctx context.Context, options types.ImageListOptions) ([]types.ImageSummary, error) {
+	rt, err := r.get()
+	if err != nil {
+		return nil, err
+	}
+	images, err := rt.ImageList(ctx, options)
+	return images, r.check(rt, err)
+}
+
+func (r *reconnectingRuntime) ImageRemove(ctx context.Context, imageID string, options types.ImageRemoveOptions) ([]types.ImageDeleteResponseItem, error) {
+	rt, err := r.get()
+	if err != nil {
+		return nil, err
+	}
+	deleted, err := rt.ImageRemove(ctx, imageID, options)
+	return deleted, r.check(rt, err)
+}
+
+func (r *reconnectingRuntime) ImageSearch(ctx context.Context, term string, options types.ImageSearchOptions) ([]registry.SearchResult, error) {
+	rt, err := r.get()
+	if err != nil {
+		return nil, err
+	}
+	results, err := rt.ImageSearch(ctx, term, options)
+	return results, r.check(rt, err)
+}
+
+func (r *reconnectingRuntime) NetworkCreate(ctx context.Context, name string, options types.NetworkCreate) (types.NetworkCreateResponse, error) {
+	rt, err := r.get()
+	if err != nil {
+		return types.NetworkCreateResponse{}, err
+	}
+	resp, err := rt.NetworkCreate(ctx, name, options)
+	return resp, r.check(rt, err)
+}
+
+func (r *reconnectingRuntime) NetworkConnect(ctx context.Context, networkID, containerID string, config *network.EndpointSettings) error {
+	rt, err := r.get()
+	if err != nil {
+		return err
+	}
+	return r.check(rt, rt.NetworkConnect(ctx, networkID, containerID, config))
+}
+
+func (r *reconnectingRuntime) NetworkList(ctx context.Context, options types.NetworkListOptions) ([]types.NetworkResource, error) {
+	rt, err := r.get()
+	if err != nil {
+		return nil, err
+	}
+	networks, err := rt.NetworkList(ctx, options)
+	return networks, r.check(rt, err)
+}
+
+func (r *reconnectingRuntime) VolumeCreate(ctx context.Context, options volumetypes.VolumesCreateBody) (types.Volume, error) {
+	rt, err := r.get()
+	if err != nil {
+		return types.Volume{}, err
+	}
+	vol, err := rt.VolumeCreate(ctx, options)
+	return vol, r.check(rt, err)
+}
+
+func (r *reconnectingRuntime) VolumeList(ctx context.Context, filter filters.Args) (volumetypes.VolumesListOKBody, error) {
+	rt, err := r.get()
+	if err != nil {
+		return volumetypes.VolumesListOKBody{}, err
+	}
+	body, err := rt.VolumeList(ctx, filter)
+	return body, r.check(rt, err)
+}
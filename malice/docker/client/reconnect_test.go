@@ -0,0 +1,54 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+)
+
+// infoRuntime answers Info with err, other calls are not used by the test
+type infoRuntime struct {
+	Runtime
+	err error
+}
+
+func (r infoRuntime) Info(ctx context.Context) (types.Info, error) {
+	return types.Info{}, r.err
+}
+
+func TestReconnectingRuntime(t *testing.T) {
+	connErr := client.ErrorConnectionFailed("unix:///var/run/docker.sock")
+	otherErr := errors.New("no such image")
+	// the first client can't reach the daemon, the second one can
+	answers := []error{connErr, otherErr}
+	connects := 0
+	rt, err := newReconnectingRuntime(func() (Runtime, error) {
+		connects++
+		return &infoRuntime{err: answers[connects-1]}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		err      error
+		connects int
+	}{
+		{err: connErr, connects: 1},
+		// the client that failed to connect was dropped
+		{err: otherErr, connects: 2},
+		// other errors keep the client
+		{err: otherErr, connects: 2},
+	}
+	for i, tt := range tests {
+		if _, err := rt.Info(context.Background()); err != tt.err {
+			t.Errorf("call %d: Info() = %v, want %v", i, err, tt.err)
+		}
+		if connects != tt.connects {
+			t.Errorf("call %d: connected %d times, want %d", i, connects, tt.connects)
+		}
+	}
+}
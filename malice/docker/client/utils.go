@@ -6,14 +6,13 @@ import (
 	"net/url"
 	"strings"
 
-	log "github.com/Sirupsen/logrus"
 	er "github.com/maliceio/malice/malice/errors"
 )
 
 // Ping pings docker client to see if it is up or not by checking Info.
 func (docker *Docker) Ping() bool {
 
-	_, err := docker.Client.Info(context.Background())
+	err := docker.Health(context.Background())
 	if err != nil {
 		er.CheckError(err)
 		return false
@@ -26,7 +25,7 @@ func parseDockerEndoint(endpoint string) (string, string, error) {
 
 	u, err := url.Parse(endpoint)
 	if err != nil {
-		log.Fatal(err)
+		return "", "", err
 	}
 
 	hostParts := strings.Split(u.Host, ":")
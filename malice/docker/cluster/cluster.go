@@ -0,0 +1,242 @@
+// Package cluster places plugin containers on the Docker endpoints set in the
+// config, so one malice spreads the scans of its samples over several hosts.
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/maliceio/malice/config"
+	"github.com/maliceio/malice/malice/docker/client"
+	"github.com/pkg/errors"
+)
+
+// DefaultCapacity is the number of containers an endpoint without a
+// capacity runs at once
+const DefaultCapacity = 4
+
+// retryInterval is how long an endpoint that failed its health check is
+// skipped before it is checked again
+const retryInterval = 30 * time.Second
+
+// Node is a Docker endpoint of the cluster
+type Node struct {
+	Name   string
+	Labels []string
+	Docker *client.Docker
+
+	capacity int
+	// running, down and checked are guarded by the mutex of the cluster
+	running int
+	down    bool
+	checked time.Time
+}
+
+// NewNode returns an endpoint running up to capacity containers at once
+func NewNode(name string, docker *client.Docker, labels []string, capacity int) *Node {
+	if capacity <= 0 {
+		capacity = DefaultCapacity
+	}
+	return &Node{Name: name, Labels: labels, Docker: docker, capacity: capacity}
+}
+
+// has returns true if the node has all labels
+func (n *Node) has(labels []string) bool {
+	for _, label := range labels {
+		found := false
+		for _, own := range n.Labels {
+			if own == label {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// Cluster runs functions on the least busy healthy node with the labels they
+// need and moves them to another node when theirs goes down
+type Cluster struct {
+	nodes []*Node
+
+	mu sync.Mutex
+	// freed is closed and replaced when a node has a free slot again
+	freed chan struct{}
+}
+
+// New returns a cluster of the nodes
+func New(nodes ...*Node) *Cluster {
+	return &Cluster{nodes: nodes, freed: make(chan struct{})}
+}
+
+// FromConfig returns the cluster of the docker endpoints in the config and
+// checks their health, it is nil when there are none
+func FromConfig(ctx context.Context) (*Cluster, error) {
+	endpoints := config.Conf.Docker.Endpoints
+	if len(endpoints) == 0 {
+		return nil, nil
+	}
+	nodes := make([]*Node, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		name := endpoint.Name
+		if name == "" {
+			name = endpoint.Host
+		}
+		docker, err := client.NewEndpointClient(client.Endpoint{Name: name, Host: endpoint.Host, CertPath: endpoint.CertPath, SSHKey: endpoint.SSHKey})
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, NewNode(name, docker, endpoint.Labels, endpoint.Capacity))
+	}
+	c := New(nodes...)
+	if healthy := c.CheckHealth(ctx); healthy == 0 {
+		return nil, errors.New("none of the docker endpoints answers")
+	}
+	return c, nil
+}
+
+// Nodes returns the nodes of the cluster
+func (c *Cluster) Nodes() []*Node {
+	return c.nodes
+}
+
+// CheckHealth checks every node answers and returns the number that do,
+// nodes that don't are skipped until they answer again
+func (c *Cluster) CheckHealth(ctx context.Context) int {
+	healthy := 0
+	for _, node := range c.nodes {
+		if c.check(ctx, node) {
+			healthy++
+		}
+	}
+	return healthy
+}
+
+// check checks the health of the node and records it
+func (c *Cluster) check(ctx context.Context, node *Node) bool {
+	err := node.Docker.Health(ctx)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	wasDown := node.down
+	node.down = err != nil
+	node.checked = time.Now()
+	switch {
+	case err != nil && !wasDown:
+		log.WithError(err).WithField("endpoint", node.Name).Warn("docker endpoint is down")
+	case err == nil && wasDown:
+		log.WithField("endpoint", node.Name).Info("docker endpoint is back up")
+		c.signal()
+	}
+	return err == nil
+}
+
+// signal wakes up the functions waiting for a node, c.mu must be held
+func (c *Cluster) signal() {
+	close(c.freed)
+	c.freed = make(chan struct{})
+}
+
+// Run runs fn on a healthy node having all labels, waiting for one that runs
+// less than its capacity. When fn fails and its node fails the health check
+// too, fn is run again on another node.
+func (c *Cluster) Run(ctx context.Context, labels []string, fn func(docker *client.Docker) error) error {
+	tried := make(map[*Node]bool)
+	for {
+		node, err := c.acquire(ctx, labels, tried)
+		if err != nil {
+			return err
+		}
+		log.WithFields(log.Fields{"endpoint": node.Name, "labels": labels}).Debug("placed container on docker endpoint")
+		err = fn(node.Docker)
+		c.release(node)
+		if err == nil {
+			return nil
+		}
+		// only a failure of the node moves fn, not one of fn itself
+		if c.check(ctx, node) {
+			return err
+		}
+		tried[node] = true
+		log.WithError(err).WithField("endpoint", node.Name).Warn("failing over to another docker endpoint")
+	}
+}
+
+// acquire takes a slot on the least busy healthy node having the labels that
+// wasn't tried yet
+func (c *Cluster) acquire(ctx context.Context, labels []string, tried map[*Node]bool) (*Node, error) {
+	for {
+		c.recheck(ctx)
+
+		c.mu.Lock()
+		var best *Node
+		eligible, up := 0, 0
+		for _, node := range c.nodes {
+			if tried[node] || !node.has(labels) {
+				continue
+			}
+			eligible++
+			if node.down {
+				continue
+			}
+			up++
+			if node.running >= node.capacity {
+				continue
+			}
+			if best == nil || node.running*best.capacity < best.running*node.capacity {
+				best = node
+			}
+		}
+		if best != nil {
+			best.running++
+			c.mu.Unlock()
+			return best, nil
+		}
+		freed := c.freed
+		c.mu.Unlock()
+
+		switch {
+		case eligible == 0 && len(tried) == 0:
+			return nil, fmt.Errorf("no docker endpoint has the labels %v", labels)
+		case eligible == 0:
+			return nil, fmt.Errorf("every docker endpoint with the labels %v failed", labels)
+		case up == 0:
+			// wait for a down node to be checked again
+			freed = nil
+		}
+		select {
+		case <-freed:
+		case <-time.After(retryInterval):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// recheck checks down nodes whose last check is older than retryInterval
+func (c *Cluster) recheck(ctx context.Context) {
+	c.mu.Lock()
+	var due []*Node
+	for _, node := range c.nodes {
+		if node.down && time.Since(node.checked) >= retryInterval {
+			due = append(due, node)
+		}
+	}
+	c.mu.Unlock()
+	for _, node := range due {
+		c.check(ctx, node)
+	}
+}
+
+// release frees the slot fn took on the node
+func (c *Cluster) release(node *Node) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	node.running--
+	c.signal()
+}
@@ -0,0 +1,161 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/maliceio/malice/malice/docker/client"
+	"github.com/maliceio/malice/malice/docker/client/fake"
+)
+
+func TestRunPlacement(t *testing.T) {
+	small, big, defender := fake.New(), fake.New(), fake.New()
+	nodes := map[*client.Docker]string{}
+	newNode := func(name string, runtime *fake.Runtime, labels []string, capacity int) *Node {
+		docker := runtime.Docker()
+		nodes[docker] = name
+		return NewNode(name, docker, labels, capacity)
+	}
+	c := New(
+		newNode("small", small, nil, 1),
+		newNode("big", big, []string{"high-memory"}, 3),
+		newNode("defender", defender, []string{"windows-defender"}, 1),
+	)
+
+	tests := []struct {
+		name   string
+		labels []string
+		// want lists the nodes the runs may be placed on
+		want    []string
+		wantErr bool
+	}{
+		{name: "any node", want: []string{"small", "big", "defender"}},
+		{name: "labelled node", labels: []string{"windows-defender"}, want: []string{"defender"}},
+		{name: "unknown label", labels: []string{"gpu"}, wantErr: true},
+		{name: "labels are all needed", labels: []string{"high-memory", "windows-defender"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			err := c.Run(context.Background(), tt.labels, func(docker *client.Docker) error {
+				got = nodes[docker]
+				return nil
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Run() error = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			for _, want := range tt.want {
+				if got == want {
+					return
+				}
+			}
+			t.Errorf("Run() ran on %s, want one of %v", got, tt.want)
+		})
+	}
+}
+
+func TestRunCapacity(t *testing.T) {
+	one, two := fake.New(), fake.New()
+	c := New(NewNode("one", one.Docker(), nil, 1), NewNode("two", two.Docker(), nil, 2))
+
+	var mu sync.Mutex
+	running := map[*client.Docker]int{}
+	peak := map[*client.Docker]int{}
+	var wg sync.WaitGroup
+	for i := 0; i < 9; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := c.Run(context.Background(), nil, func(docker *client.Docker) error {
+				mu.Lock()
+				running[docker]++
+				if running[docker] > peak[docker] {
+					peak[docker] = running[docker]
+				}
+				mu.Unlock()
+				time.Sleep(10 * time.Millisecond)
+				mu.Lock()
+				running[docker]--
+				mu.Unlock()
+				return nil
+			})
+			if err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	for _, node := range c.Nodes() {
+		if peak[node.Docker] > node.capacity {
+			t.Errorf("node %s ran %d at once, want at most its capacity of %d", node.Name, peak[node.Docker], node.capacity)
+		}
+	}
+}
+
+func TestRunFailover(t *testing.T) {
+	down, up := fake.New(), fake.New()
+	c := New(NewNode("down", down.Docker(), nil, 1), NewNode("up", up.Docker(), nil, 1))
+	// the first node looks healthy until the run on it fails
+	c.nodes[1].running = 1
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		c.release(c.nodes[1])
+	}()
+
+	var ran []string
+	err := c.Run(context.Background(), nil, func(docker *client.Docker) error {
+		if docker == c.nodes[0].Docker {
+			ran = append(ran, "down")
+			down.Fail("Info", errors.New("connection refused"))
+			return errors.New("failed to start plugin")
+		}
+		ran = append(ran, "up")
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ran) != 2 || ran[0] != "down" || ran[1] != "up" {
+		t.Errorf("ran on %v, want a failover from down to up", ran)
+	}
+	if !c.nodes[0].down {
+		t.Error("failed node isn't marked down")
+	}
+}
+
+func TestRunPluginError(t *testing.T) {
+	runtime := fake.New()
+	c := New(NewNode("worker", runtime.Docker(), nil, 1))
+
+	runs := 0
+	pluginErr := errors.New("plugin crashed")
+	err := c.Run(context.Background(), nil, func(docker *client.Docker) error {
+		runs++
+		return pluginErr
+	})
+	if err != pluginErr || runs != 1 {
+		t.Errorf("Run() = %v after %d runs, want the plugin error after one run", err, runs)
+	}
+}
+
+func TestRunAllDown(t *testing.T) {
+	runtime := fake.New()
+	runtime.Fail("Info", errors.New("connection refused"))
+	c := New(NewNode("worker", runtime.Docker(), nil, 1))
+	if healthy := c.CheckHealth(context.Background()); healthy != 0 {
+		t.Fatalf("CheckHealth() = %d, want 0", healthy)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := c.Run(ctx, nil, func(docker *client.Docker) error { return nil })
+	if err != context.DeadlineExceeded {
+		t.Errorf("Run() with every node down = %v, want to wait for one until %v", err, context.DeadlineExceeded)
+	}
+}
@@ -0,0 +1,103 @@
+package drivers
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// DefaultSSHUser and DefaultSSHPort are used by drivers when no user or
+// port is set
+const (
+	DefaultSSHUser = "root"
+	DefaultSSHPort = 22
+)
+
+// BaseDriver holds the fields of a host common to all drivers, drivers embed
+// it to get the SSH getters
+type BaseDriver struct {
+	IPAddress   string
+	MachineName string
+	SSHUser     string
+	SSHPort     int
+	SSHKeyPath  string
+	StorePath   string
+}
+
+// GetMachineName returns the name of the machine
+func (d *BaseDriver) GetMachineName() string {
+	return d.MachineName
+}
+
+// GetIP returns the IP or hostname of the machine
+func (d *BaseDriver) GetIP() (string, error) {
+	if d.IPAddress == "" {
+		return "", errors.New("IP address is not set")
+	}
+	return d.IPAddress, nil
+}
+
+// GetSSHHostname returns the hostname ssh connects to
+func (d *BaseDriver) GetSSHHostname() (string, error) {
+	return d.GetIP()
+}
+
+// GetSSHKeyPath returns the ssh key, ssh uses its defaults when empty
+func (d *BaseDriver) GetSSHKeyPath() string {
+	return d.SSHKeyPath
+}
+
+// GetSSHPort returns the ssh port
+func (d *BaseDriver) GetSSHPort() (int, error) {
+	if d.SSHPort == 0 {
+		d.SSHPort = DefaultSSHPort
+	}
+	return d.SSHPort, nil
+}
+
+// GetSSHUsername returns the ssh username
+func (d *BaseDriver) GetSSHUsername() string {
+	if d.SSHUser == "" {
+		d.SSHUser = DefaultSSHUser
+	}
+	return d.SSHUser
+}
+
+// SSHCommand returns the command running command on the host of d over ssh.
+// ssh never prompts, the key must not need a passphrase or be in the agent.
+func SSHCommand(d Driver, command string) (*exec.Cmd, error) {
+	host, err := d.GetSSHHostname()
+	if err != nil {
+		return nil, err
+	}
+	port, err := d.GetSSHPort()
+	if err != nil {
+		return nil, err
+	}
+	args := []string{
+		"-o", "BatchMode=yes",
+		"-o", "ConnectTimeout=10",
+		"-o", "StrictHostKeyChecking=accept-new",
+		"-p", strconv.Itoa(port),
+	}
+	if key := d.GetSSHKeyPath(); key != "" {
+		args = append(args, "-i", key, "-o", "IdentitiesOnly=yes")
+	}
+	args = append(args, "--", d.GetSSHUsername()+"@"+host, command)
+	return exec.Command("ssh", args...), nil
+}
+
+// RunSSHCommand runs command on the host of d and returns its output
+func RunSSHCommand(d Driver, command string) (string, error) {
+	cmd, err := SSHCommand(d, command)
+	if err != nil {
+		return "", err
+	}
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return string(output), fmt.Errorf("ssh command %q on %s failed: %v: %s", command, d.GetMachineName(), err, strings.TrimSpace(string(output)))
+	}
+	return string(output), nil
+}
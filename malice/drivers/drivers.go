@@ -2,10 +2,12 @@ package drivers
 
 import (
 	"errors"
+	"fmt"
+	"sort"
+	"sync"
 
-	"github.com/docker/machine/libmachine/log"
-	"github.com/docker/machine/libmachine/mcnflag"
-	"github.com/docker/machine/libmachine/state"
+	log "github.com/Sirupsen/logrus"
+	"github.com/urfave/cli/v2"
 )
 
 // Driver defines how a host is created and controlled. Different types of
@@ -18,9 +20,9 @@ type Driver interface {
 	// DriverName returns the name of the driver
 	DriverName() string
 
-	// GetCreateFlags returns the mcnflag.Flag slice representing the flags
-	// that can be set, their descriptions and defaults.
-	GetCreateFlags() []mcnflag.Flag
+	// GetCreateFlags returns the flags of `malice host create` that
+	// configure the driver, with their descriptions and defaults.
+	GetCreateFlags() []cli.Flag
 
 	// GetIP returns an IP or hostname that this host is available at
 	// e.g. 1.2.3.4 or docker-host-d60b70a14d3a.cloudapp.net
@@ -42,11 +44,11 @@ type Driver interface {
 	GetSSHUsername() string
 
 	// GetURL returns a Docker compatible host URL for connecting to this host
-	// e.g. tcp://1.2.3.4:2376
+	// e.g. tcp://1.2.3.4:2376 or ssh://malice@1.2.3.4
 	GetURL() (string, error)
 
 	// GetState returns the state that the host is in (running, stopped, etc)
-	GetState() (state.State, error)
+	GetState() (State, error)
 
 	// Kill stops a host forcefully
 	Kill() error
@@ -61,8 +63,8 @@ type Driver interface {
 	// have any special restart behaviour.
 	Restart() error
 
-	// SetConfigFromFlags configures the driver with the object that was returned
-	// by RegisterCreateFlags
+	// SetConfigFromFlags configures the driver with the values of the flags
+	// returned by GetCreateFlags
 	SetConfigFromFlags(opts DriverOptions) error
 
 	// Start a host
@@ -74,6 +76,7 @@ type Driver interface {
 
 var ErrHostIsNotRunning = errors.New("Host is not running")
 
+// DriverOptions are the values of the create flags, a *cli.Context
 type DriverOptions interface {
 	String(key string) string
 	StringSlice(key string) []string
@@ -81,7 +84,7 @@ type DriverOptions interface {
 	Bool(key string) bool
 }
 
-func MachineInState(d Driver, desiredState state.State) func() bool {
+func MachineInState(d Driver, desiredState State) func() bool {
 	return func() bool {
 		currentState, err := d.GetState()
 		if err != nil {
@@ -93,3 +96,43 @@ func MachineInState(d Driver, desiredState state.State) func() bool {
 		return false
 	}
 }
+
+// Factory returns a driver of the host machineName, whose files are kept
+// in storePath
+type Factory func(machineName, storePath string) Driver
+
+var (
+	mu        sync.Mutex
+	factories = make(map[string]Factory)
+)
+
+// Register makes a driver available by name, drivers register themselves
+// when their package is imported
+func Register(name string, factory Factory) {
+	mu.Lock()
+	defer mu.Unlock()
+	factories[name] = factory
+}
+
+// New returns the registered driver name for the host machineName
+func New(name, machineName, storePath string) (Driver, error) {
+	mu.Lock()
+	factory, ok := factories[name]
+	mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown driver %q, expected one of %v", name, Names())
+	}
+	return factory(machineName, storePath), nil
+}
+
+// Names returns the names of the registered drivers
+func Names() []string {
+	mu.Lock()
+	defer mu.Unlock()
+	names := make([]string, 0, len(factories))
+	for name := range factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,190 @@
+// Package generic is the driver of existing Linux hosts reached over ssh.
+// It doesn't create machines, it makes sure docker runs on them and malice
+// talks to that daemon over ssh.
+package generic
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/maliceio/malice/malice/drivers"
+	"github.com/urfave/cli/v2"
+)
+
+// DriverName is the name of the driver in `malice host create --driver`
+const DriverName = "generic"
+
+// installScript installs docker on hosts that don't have it
+const installScript = "curl -fsSL https://get.docker.com | sudo sh"
+
+// Driver provisions an existing host over ssh
+type Driver struct {
+	*drivers.BaseDriver
+	// InstallDocker installs docker when the host doesn't have it
+	InstallDocker bool
+}
+
+func init() {
+	drivers.Register(DriverName, func(machineName, storePath string) drivers.Driver {
+		return NewDriver(machineName, storePath)
+	})
+}
+
+// NewDriver returns the driver of the host machineName
+func NewDriver(machineName, storePath string) *Driver {
+	return &Driver{
+		BaseDriver: &drivers.BaseDriver{
+			MachineName: machineName,
+			SSHUser:     drivers.DefaultSSHUser,
+			SSHPort:     drivers.DefaultSSHPort,
+			StorePath:   storePath,
+		},
+	}
+}
+
+// DriverName returns the name of the driver
+func (d *Driver) DriverName() string {
+	return DriverName
+}
+
+// GetCreateFlags returns the flags configuring the host
+func (d *Driver) GetCreateFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:    "generic-ip-address",
+			Usage:   "IP address or hostname of the host",
+			EnvVars: []string{"GENERIC_IP_ADDRESS"},
+		},
+		&cli.StringFlag{
+			Name:    "generic-ssh-user",
+			Usage:   "SSH user, it must be allowed to run docker",
+			Value:   drivers.DefaultSSHUser,
+			EnvVars: []string{"GENERIC_SSH_USER"},
+		},
+		&cli.StringFlag{
+			Name:    "generic-ssh-key",
+			Usage:   "SSH private key `PATH`, the defaults of ssh are used when empty",
+			EnvVars: []string{"GENERIC_SSH_KEY"},
+		},
+		&cli.IntFlag{
+			Name:    "generic-ssh-port",
+			Usage:   "SSH port",
+			Value:   drivers.DefaultSSHPort,
+			EnvVars: []string{"GENERIC_SSH_PORT"},
+		},
+		&cli.BoolFlag{
+			Name:  "generic-install-docker",
+			Usage: "Install docker with get.docker.com when the host doesn't have it",
+		},
+	}
+}
+
+// SetConfigFromFlags configures the driver with the create flags
+func (d *Driver) SetConfigFromFlags(opts drivers.DriverOptions) error {
+	d.IPAddress = opts.String("generic-ip-address")
+	d.SSHUser = opts.String("generic-ssh-user")
+	d.SSHKeyPath = opts.String("generic-ssh-key")
+	d.SSHPort = opts.Int("generic-ssh-port")
+	d.InstallDocker = opts.Bool("generic-install-docker")
+	if d.IPAddress == "" {
+		return errors.New("generic driver requires the --generic-ip-address option")
+	}
+	return nil
+}
+
+// PreCreateCheck checks the ssh key exists
+func (d *Driver) PreCreateCheck() error {
+	if d.SSHKeyPath == "" {
+		return nil
+	}
+	if _, err := os.Stat(d.SSHKeyPath); err != nil {
+		return fmt.Errorf("ssh key %s: %v", d.SSHKeyPath, err)
+	}
+	return nil
+}
+
+// Create makes sure the docker daemon of the host answers over ssh
+func (d *Driver) Create() error {
+	log.WithField("host", d.IPAddress).Info("checking docker on the host")
+	version, err := d.dockerVersion()
+	if err != nil && d.InstallDocker {
+		log.WithField("host", d.IPAddress).Info("installing docker, this can take a few minutes")
+		if _, err := drivers.RunSSHCommand(d, installScript); err != nil {
+			return err
+		}
+		version, err = d.dockerVersion()
+	}
+	if err != nil {
+		return fmt.Errorf("docker doesn't run on %s, install it or pass --generic-install-docker: %v", d.IPAddress, err)
+	}
+	log.WithFields(log.Fields{"host": d.IPAddress, "docker": version}).Info("docker runs on the host")
+	return nil
+}
+
+// dockerVersion returns the version of the docker daemon of the host, run as
+// the ssh user so it fails when the user can't use docker
+func (d *Driver) dockerVersion() (string, error) {
+	output, err := drivers.RunSSHCommand(d, "docker version --format '{{.Server.Version}}'")
+	return strings.TrimSpace(output), err
+}
+
+// GetURL returns the ssh URL docker and malice reach the daemon at
+func (d *Driver) GetURL() (string, error) {
+	ip, err := d.GetIP()
+	if err != nil {
+		return "", err
+	}
+	port, _ := d.GetSSHPort()
+	host := net.JoinHostPort(ip, strconv.Itoa(port))
+	return fmt.Sprintf("ssh://%s@%s", d.GetSSHUsername(), host), nil
+}
+
+// GetState returns Running when the ssh port of the host accepts connections
+func (d *Driver) GetState() (drivers.State, error) {
+	ip, err := d.GetIP()
+	if err != nil {
+		return drivers.Error, err
+	}
+	port, _ := d.GetSSHPort()
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort(ip, strconv.Itoa(port)), 5*time.Second)
+	if err != nil {
+		return drivers.Stopped, nil
+	}
+	conn.Close()
+	return drivers.Running, nil
+}
+
+// Start can't power on hosts, it checks the host is up
+func (d *Driver) Start() error {
+	if state, _ := d.GetState(); state != drivers.Running {
+		return errors.New("generic driver can't start hosts, power on the host")
+	}
+	return nil
+}
+
+// Stop can't power off hosts
+func (d *Driver) Stop() error {
+	return errors.New("generic driver can't stop hosts")
+}
+
+// Restart reboots the host
+func (d *Driver) Restart() error {
+	_, err := drivers.RunSSHCommand(d, "sudo shutdown -r now")
+	return err
+}
+
+// Kill can't power off hosts
+func (d *Driver) Kill() error {
+	return errors.New("generic driver can't kill hosts")
+}
+
+// Remove leaves the host as it is, malice only forgets it
+func (d *Driver) Remove() error {
+	return nil
+}
@@ -0,0 +1,86 @@
+package generic
+
+import (
+	"testing"
+)
+
+// options are create flag values
+type options map[string]interface{}
+
+func (o options) String(key string) string {
+	s, _ := o[key].(string)
+	return s
+}
+
+func (o options) StringSlice(key string) []string {
+	s, _ := o[key].([]string)
+	return s
+}
+
+func (o options) Int(key string) int {
+	i, _ := o[key].(int)
+	return i
+}
+
+func (o options) Bool(key string) bool {
+	b, _ := o[key].(bool)
+	return b
+}
+
+func TestSetConfigFromFlags(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    options
+		wantURL string
+		wantErr bool
+	}{
+		{
+			name:    "defaults",
+			opts:    options{"generic-ip-address": "10.0.0.21", "generic-ssh-user": "root", "generic-ssh-port": 22},
+			wantURL: "ssh://root@10.0.0.21:22",
+		},
+		{
+			name:    "user and port",
+			opts:    options{"generic-ip-address": "worker1.lab", "generic-ssh-user": "malice", "generic-ssh-port": 2222},
+			wantURL: "ssh://malice@worker1.lab:2222",
+		},
+		{
+			name:    "ipv6",
+			opts:    options{"generic-ip-address": "fd00::21", "generic-ssh-user": "malice", "generic-ssh-port": 22},
+			wantURL: "ssh://malice@[fd00::21]:22",
+		},
+		{
+			name:    "no address",
+			opts:    options{"generic-ssh-user": "root"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewDriver("worker1", t.TempDir())
+			err := d.SetConfigFromFlags(tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SetConfigFromFlags() error = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			url, err := d.GetURL()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if url != tt.wantURL {
+				t.Errorf("GetURL() = %q, want %q", url, tt.wantURL)
+			}
+		})
+	}
+}
+
+func TestPreCreateCheck(t *testing.T) {
+	d := NewDriver("worker1", t.TempDir())
+	d.IPAddress = "10.0.0.21"
+	d.SSHKeyPath = "/nonexistent/id_ed25519"
+	if err := d.PreCreateCheck(); err == nil {
+		t.Error("PreCreateCheck() with a missing ssh key succeeded")
+	}
+}
@@ -0,0 +1,36 @@
+package drivers
+
+// State represents the state of a host
+type State int
+
+const (
+	None State = iota
+	Running
+	Paused
+	Saved
+	Stopped
+	Stopping
+	Starting
+	Error
+	Timeout
+)
+
+var states = []string{
+	"",
+	"Running",
+	"Paused",
+	"Saved",
+	"Stopped",
+	"Stopping",
+	"Starting",
+	"Error",
+	"Timeout",
+}
+
+// String returns the name of the state, empty for None
+func (s State) String() string {
+	if int(s) >= 0 && int(s) < len(states) {
+		return states[s]
+	}
+	return ""
+}
@@ -0,0 +1,87 @@
+// Package host holds the analysis hosts malice provisioned with a driver,
+// they are kept in the persist.Filestore.
+package host
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/maliceio/malice/malice/drivers"
+)
+
+// ConfigVersion is the version of the config.json of hosts
+const ConfigVersion = 1
+
+// Host is an analysis host and the driver controlling it
+type Host struct {
+	ConfigVersion int
+	Name          string
+	DriverName    string
+	Created       time.Time
+	Driver        drivers.Driver `json:"-"`
+	// RawDriver is the config of the driver as saved
+	RawDriver json.RawMessage `json:"Driver"`
+}
+
+// validHostName matches the names hosts can have, they name the directory
+// of the host in the store
+var validHostName = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._-]*$`)
+
+// ValidateHostName returns an error unless name can be the name of a host
+func ValidateHostName(name string) error {
+	if !validHostName.MatchString(name) {
+		return fmt.Errorf("invalid host name %q, names start with a letter or digit followed by letters, digits, '.', '_' or '-'", name)
+	}
+	return nil
+}
+
+// NewHost returns the host of driver
+func NewHost(driver drivers.Driver) *Host {
+	return &Host{
+		ConfigVersion: ConfigVersion,
+		Name:          driver.GetMachineName(),
+		DriverName:    driver.DriverName(),
+		Created:       time.Now().UTC(),
+		Driver:        driver,
+	}
+}
+
+// MarshalJSON saves the config of the driver with the host
+func (h *Host) MarshalJSON() ([]byte, error) {
+	type plain Host
+	saved := plain(*h)
+	if h.Driver != nil {
+		raw, err := json.Marshal(h.Driver)
+		if err != nil {
+			return nil, err
+		}
+		saved.RawDriver = raw
+	}
+	return json.Marshal(saved)
+}
+
+// LoadDriver creates the driver of a loaded host from its saved config
+func (h *Host) LoadDriver(storePath string) error {
+	driver, err := drivers.New(h.DriverName, h.Name, storePath)
+	if err != nil {
+		return err
+	}
+	if len(h.RawDriver) > 0 {
+		if err := json.Unmarshal(h.RawDriver, driver); err != nil {
+			return fmt.Errorf("invalid config of driver %s of host %s: %v", h.DriverName, h.Name, err)
+		}
+	}
+	h.Driver = driver
+	return nil
+}
+
+// ErrHostDoesNotExist is returned when a host isn't in the store
+type ErrHostDoesNotExist struct {
+	Name string
+}
+
+func (e ErrHostDoesNotExist) Error() string {
+	return fmt.Sprintf("Host does not exist: %q", e.Name)
+}
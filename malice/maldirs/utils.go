@@ -35,6 +35,14 @@ func GetLogsDir() string {
 	return filepath.Join(GetBaseDir(), "logs")
 }
 
+func GetCacheDir() string {
+	return filepath.Join(GetBaseDir(), "cache")
+}
+
+func GetSecretsDir() string {
+	return filepath.Join(GetBaseDir(), "secrets")
+}
+
 func MakeDirs() {
 	// Make .malice directory if it doesn't exist
 	if _, err := os.Stat(GetSampledsDir()); os.IsNotExist(err) {
@@ -49,4 +57,10 @@ func MakeDirs() {
 	if _, err := os.Stat(GetLogsDir()); os.IsNotExist(err) {
 		os.MkdirAll(GetLogsDir(), 0777)
 	}
+	if _, err := os.Stat(GetCacheDir()); os.IsNotExist(err) {
+		os.MkdirAll(GetCacheDir(), 0700)
+	}
+	if _, err := os.Stat(GetSecretsDir()); os.IsNotExist(err) {
+		os.MkdirAll(GetSecretsDir(), 0700)
+	}
 }
@@ -23,11 +23,13 @@ import (
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/errdefs"
 	"github.com/docker/docker/pkg/stdcopy"
 	"github.com/docker/go-units"
 	"github.com/dustin/go-jsonpointer"
 	"github.com/malice-plugins/pkgs/utils"
 	"github.com/maliceio/malice/config"
+	"github.com/maliceio/malice/malice/delivery"
 	"github.com/maliceio/malice/malice/docker/client"
 	er "github.com/maliceio/malice/malice/errors"
 	"github.com/maliceio/malice/malice/maldirs"
@@ -70,13 +72,18 @@ func (file *File) Init() {
 	file.GetSHA512(dat)
 }
 
-// GetMimeType returns file's mime type
-func GetMimeType(docker *client.Docker, arg string) (string, error) {
+// GetMimeType returns the mime type of the sample with SHA256 arg, which
+// gets delivered to the container by sample
+func GetMimeType(ctx context.Context, docker *client.Docker, arg string, sample delivery.Strategy) (string, error) {
+
+	// libmagic needs a file it can seek in
+	sample = delivery.Seekable(sample)
 
 	// Create Container
 	createContConf := &container.Config{
-		Image: "malice/fileinfo",
-		Cmd:   []string{"-m", arg},
+		Image:  "malice/fileinfo",
+		Cmd:    []string{"-m", sample.Path(arg)},
+		Labels: client.OwnerLabels("", "fileinfo"),
 	}
 	resources := container.Resources{
 		Memory:   config.Conf.Docker.Memory, // Memory    int64 // Memory limit (in bytes)
@@ -89,20 +96,24 @@ func GetMimeType(docker *client.Docker, arg string) (string, error) {
 	}).Debug("setting container resources")
 	hostConfig := &container.HostConfig{
 		Privileged:  false,
-		Binds:       []string{config.Conf.Docker.Binds},
 		NetworkMode: "none",
 		Resources:   resources,
 		AutoRemove:  true,
 	}
+	sample.Configure(createContConf, hostConfig, arg)
 	networkingConfig := &network.NetworkingConfig{}
 
-	contResponse, err := docker.Client.ContainerCreate(context.Background(), createContConf, hostConfig, networkingConfig, "getmimetype")
+	contResponse, err := docker.Client.ContainerCreate(ctx, createContConf, hostConfig, networkingConfig, client.ContainerName("getmimetype"))
 	if err != nil {
 		return "", err
 	}
+	if err := sample.Deliver(ctx, docker, contResponse.ID, arg); err != nil {
+		discard(context.Background(), docker, contResponse.ID, types.ContainerRemoveOptions{Force: true})
+		return "", err
+	}
 
 	// Start Container
-	err = docker.Client.ContainerStart(context.Background(), contResponse.ID, types.ContainerStartOptions{})
+	err = docker.Client.ContainerStart(ctx, contResponse.ID, types.ContainerStartOptions{})
 	if err != nil {
 		return "", err
 	}
@@ -120,7 +131,7 @@ func GetMimeType(docker *client.Docker, arg string) (string, error) {
 			RemoveLinks:   false,
 			Force:         true,
 		}
-		er.CheckError(docker.Client.ContainerRemove(context.Background(), "getmimetype", contRmOpts))
+		discard(context.Background(), docker, contResponse.ID, contRmOpts)
 		log.WithFields(log.Fields{
 			"id":   contResponse.ID,
 			"env":  config.Conf.Environment.Run,
@@ -128,7 +139,7 @@ func GetMimeType(docker *client.Docker, arg string) (string, error) {
 		}).Debug("malice/fileinfo Container Removed")
 	}()
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
 	options := types.ContainerLogsOptions{
@@ -157,25 +168,41 @@ func GetMimeType(docker *client.Docker, arg string) (string, error) {
 	return strings.TrimSpace(buf1.String()), nil
 }
 
+// discard removes a malice/fileinfo container, failing to is only logged. A
+// container that is gone or being removed already was auto removed.
+func discard(ctx context.Context, docker *client.Docker, id string, opts types.ContainerRemoveOptions) {
+	err := docker.Client.ContainerRemove(ctx, id, opts)
+	if err != nil && !errdefs.IsNotFound(err) && !errdefs.IsConflict(err) {
+		log.WithError(err).WithField("id", id).Warn("failed to remove malice/fileinfo container")
+	}
+}
+
 // GetFileInfo start malice/fileinfo container and extract certain fields with a search string
-func GetFileInfo(docker *client.Docker, arg string, search string) (string, error) {
+func GetFileInfo(docker *client.Docker, arg string, search string, sample delivery.Strategy) (string, error) {
+
+	sample = delivery.Seekable(sample)
 
 	// Create Container
 	createContConf := &container.Config{
-		Image: "malice/fileinfo",
-		Cmd:   []string{arg},
+		Image:  "malice/fileinfo",
+		Cmd:    []string{sample.Path(arg)},
+		Labels: client.OwnerLabels("", "fileinfo"),
 	}
 	hostConfig := &container.HostConfig{
 		Privileged:  false,
-		Binds:       []string{config.Conf.Docker.Binds},
 		NetworkMode: "none",
 	}
+	sample.Configure(createContConf, hostConfig, arg)
 	networkingConfig := &network.NetworkingConfig{}
 
 	contResponse, err := docker.Client.ContainerCreate(context.Background(), createContConf, hostConfig, networkingConfig, "")
 	if err != nil {
 		return "", err
 	}
+	if err := sample.Deliver(context.Background(), docker, contResponse.ID, arg); err != nil {
+		discard(context.Background(), docker, contResponse.ID, types.ContainerRemoveOptions{Force: true})
+		return "", err
+	}
 
 	// Start Container
 	err = docker.Client.ContainerStart(context.Background(), contResponse.ID, types.ContainerStartOptions{})
@@ -221,7 +248,7 @@ func GetFileInfo(docker *client.Docker, arg string, search string) (string, erro
 			RemoveLinks:   true,
 			Force:         true,
 		}
-		er.CheckError(docker.Client.ContainerRemove(ctx, contResponse.ID, contRmOpts))
+		discard(ctx, docker, contResponse.ID, contRmOpts)
 		log.WithFields(log.Fields{
 			"id":   contResponse.ID,
 			"env":  config.Conf.Environment.Run,
@@ -8,10 +8,10 @@ import (
 	"path/filepath"
 	"strings"
 
-	"github.com/docker/machine/libmachine/host"
-	"github.com/docker/machine/libmachine/mcnerror"
+	"github.com/maliceio/malice/malice/host"
 )
 
+// Filestore keeps the config of every host in its own directory under Path
 type Filestore struct {
 	Path             string
 	CaCertPath       string
@@ -30,6 +30,19 @@ func (s Filestore) GetMachinesDir() string {
 	return filepath.Join(s.Path, "machines")
 }
 
+// hostPath returns the directory of the host, names that aren't valid host
+// names or would leave the machines directory are refused
+func (s Filestore) hostPath(name string) (string, error) {
+	if err := host.ValidateHostName(name); err != nil {
+		return "", err
+	}
+	dir := filepath.Join(s.GetMachinesDir(), name)
+	if rel, err := filepath.Rel(s.GetMachinesDir(), dir); err != nil || rel != name {
+		return "", fmt.Errorf("host %s is outside of %s", name, s.GetMachinesDir())
+	}
+	return dir, nil
+}
+
 func (s Filestore) saveToFile(data []byte, file string) error {
 	if _, err := os.Stat(file); os.IsNotExist(err) {
 		return ioutil.WriteFile(file, data, 0600)
@@ -65,7 +78,10 @@ func (s Filestore) Save(host *host.Host) error {
 		return err
 	}
 
-	hostPath := filepath.Join(s.GetMachinesDir(), host.Name)
+	hostPath, err := s.hostPath(host.Name)
+	if err != nil {
+		return err
+	}
 
 	// Ensure that the directory we want to save to exists.
 	if err := os.MkdirAll(hostPath, 0700); err != nil {
@@ -76,7 +92,10 @@ func (s Filestore) Save(host *host.Host) error {
 }
 
 func (s Filestore) Remove(name string) error {
-	hostPath := filepath.Join(s.GetMachinesDir(), name)
+	hostPath, err := s.hostPath(name)
+	if err != nil {
+		return err
+	}
 	return os.RemoveAll(hostPath)
 }
 
@@ -98,7 +117,11 @@ func (s Filestore) List() ([]string, error) {
 }
 
 func (s Filestore) Exists(name string) (bool, error) {
-	_, err := os.Stat(filepath.Join(s.GetMachinesDir(), name))
+	hostPath, err := s.hostPath(name)
+	if err != nil {
+		return false, err
+	}
+	_, err = os.Stat(hostPath)
 
 	if os.IsNotExist(err) {
 		return false, nil
@@ -115,38 +138,26 @@ func (s Filestore) loadConfig(h *host.Host) error {
 		return err
 	}
 
-	// Remember the machine name so we don't have to pass it through each
-	// struct in the migration.
+	// Remember the machine name so a renamed directory keeps its name
 	name := h.Name
 
-	migratedHost, migrationPerformed, err := host.MigrateHost(h, data)
-	if err != nil {
-		return fmt.Errorf("Error getting migrated host: %s", err)
+	if err := json.Unmarshal(data, h); err != nil {
+		return fmt.Errorf("Error loading config of host %s: %s", name, err)
 	}
 
-	*h = *migratedHost
-
 	h.Name = name
 
-	// If we end up performing a migration, we should save afterwards so we don't have to do it again on subsequent invocations.
-	if migrationPerformed {
-		if err := s.saveToFile(data, filepath.Join(s.GetMachinesDir(), h.Name, "config.json.bak")); err != nil {
-			return fmt.Errorf("Error attempting to save backup after migration: %s", err)
-		}
-
-		if err := s.Save(h); err != nil {
-			return fmt.Errorf("Error saving config after migration was performed: %s", err)
-		}
-	}
-
-	return nil
+	return h.LoadDriver(s.Path)
 }
 
 func (s Filestore) Load(name string) (*host.Host, error) {
-	hostPath := filepath.Join(s.GetMachinesDir(), name)
+	hostPath, err := s.hostPath(name)
+	if err != nil {
+		return nil, err
+	}
 
 	if _, err := os.Stat(hostPath); os.IsNotExist(err) {
-		return nil, mcnerror.ErrHostDoesNotExist{
+		return nil, host.ErrHostDoesNotExist{
 			Name: name,
 		}
 	}
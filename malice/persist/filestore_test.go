@@ -0,0 +1,72 @@
+package persist
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/maliceio/malice/malice/drivers/generic"
+	"github.com/maliceio/malice/malice/host"
+)
+
+func TestFilestore(t *testing.T) {
+	store := NewFilestore(t.TempDir(), "", "")
+
+	driver := generic.NewDriver("worker1", store.Path)
+	driver.IPAddress = "10.0.0.21"
+	driver.SSHUser = "malice"
+	driver.SSHKeyPath = "/home/malice/.ssh/id_ed25519"
+	if err := store.Save(host.NewHost(driver)); err != nil {
+		t.Fatal(err)
+	}
+
+	names, err := store.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(names, []string{"worker1"}) {
+		t.Errorf("List() = %v, want [worker1]", names)
+	}
+
+	loaded, err := store.Load("worker1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.DriverName != generic.DriverName {
+		t.Errorf("loaded driver %s, want %s", loaded.DriverName, generic.DriverName)
+	}
+	if !reflect.DeepEqual(loaded.Driver, driver) {
+		t.Errorf("loaded driver %+v, want %+v", loaded.Driver, driver)
+	}
+
+	if err := store.Remove("worker1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Load("worker1"); err == nil {
+		t.Error("Load() of a removed host succeeded")
+	} else if _, ok := err.(host.ErrHostDoesNotExist); !ok {
+		t.Errorf("Load() of a removed host = %v, want %T", err, host.ErrHostDoesNotExist{})
+	}
+}
+
+func TestFilestoreRefusesPaths(t *testing.T) {
+	store := NewFilestore(t.TempDir(), "", "")
+	driver := generic.NewDriver("worker1", store.Path)
+	if err := store.Save(host.NewHost(driver)); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"..", ".", "", "../plugins", "worker1/..", "-rf", ".hidden"} {
+		if err := store.Remove(name); err == nil {
+			t.Errorf("Remove(%q) succeeded", name)
+		}
+		if _, err := store.Load(name); err == nil {
+			t.Errorf("Load(%q) succeeded", name)
+		}
+		if _, err := store.Exists(name); err == nil {
+			t.Errorf("Exists(%q) succeeded", name)
+		}
+	}
+	if exists, err := store.Exists("worker1"); err != nil || !exists {
+		t.Errorf("Exists(worker1) = %t, %v, want the host kept", exists, err)
+	}
+}
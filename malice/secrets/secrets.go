@@ -0,0 +1,300 @@
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// MasterKeyEnv holds a base64 encoded 32 byte master key, it replaces the master.key file
+	MasterKeyEnv = "MALICE_MASTER_KEY"
+	// EnvPrefix prefixes the environment variables that override stored secrets
+	EnvPrefix = "MALICE_SECRET_"
+
+	secretsFile   = "secrets.json"
+	masterKeyFile = "master.key"
+	keySize       = 32
+)
+
+// ErrNotFound is returned when a secret is neither stored nor set in the environment
+var ErrNotFound = errors.New("secret not found")
+
+var nameRegex = regexp.MustCompile(`^[a-zA-Z0-9_.-]+$`)
+
+// Store is a file based secret store, each value is encrypted with
+// AES-256-GCM using the master key
+type Store struct {
+	Dir string
+	mu  sync.Mutex
+}
+
+// secretsDoc is the on-disk format of the store
+type secretsDoc struct {
+	Secrets map[string]string `json:"secrets"`
+}
+
+// NewStore creates a secret store rooted at dir
+func NewStore(dir string) *Store {
+	return &Store{Dir: dir}
+}
+
+// EnvName returns the environment variable that overrides a secret, e.g. vt_api => MALICE_SECRET_VT_API
+func EnvName(name string) string {
+	return EnvPrefix + strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(name))
+}
+
+func validName(name string) error {
+	if !nameRegex.MatchString(name) {
+		return fmt.Errorf("invalid secret name %q, use letters, digits, '_', '-' and '.'", name)
+	}
+	return nil
+}
+
+// Has returns true if the secret is set in the environment or stored
+func (s *Store) Has(name string) bool {
+	if os.Getenv(EnvName(name)) != "" {
+		return true
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	doc, err := s.load()
+	if err != nil {
+		return false
+	}
+	_, ok := doc.Secrets[name]
+	return ok
+}
+
+// Get returns a secret's value, the environment takes precedence over the store
+func (s *Store) Get(name string) (string, error) {
+	if err := validName(name); err != nil {
+		return "", err
+	}
+	if value := os.Getenv(EnvName(name)); value != "" {
+		return value, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	doc, err := s.load()
+	if err != nil {
+		return "", err
+	}
+	sealed, ok := doc.Secrets[name]
+	if !ok {
+		return "", ErrNotFound
+	}
+
+	key, err := s.masterKey(false)
+	if err != nil {
+		return "", err
+	}
+	return decrypt(key, name, sealed)
+}
+
+// Set encrypts and stores a secret, creating the master key on first use
+func (s *Store) Set(name, value string) error {
+	if err := validName(name); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	doc, err := s.load()
+	if err != nil {
+		return err
+	}
+	key, err := s.masterKey(true)
+	if err != nil {
+		return err
+	}
+	sealed, err := encrypt(key, name, value)
+	if err != nil {
+		return err
+	}
+	doc.Secrets[name] = sealed
+	return s.save(doc)
+}
+
+// Remove deletes a stored secret
+func (s *Store) Remove(name string) error {
+	if err := validName(name); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	doc, err := s.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := doc.Secrets[name]; !ok {
+		return ErrNotFound
+	}
+	delete(doc.Secrets, name)
+	return s.save(doc)
+}
+
+// List returns the names of the stored secrets
+func (s *Store) List() ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	doc, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(doc.Secrets))
+	for name := range doc.Secrets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (s *Store) load() (secretsDoc, error) {
+	doc := secretsDoc{Secrets: map[string]string{}}
+
+	data, err := ioutil.ReadFile(filepath.Join(s.Dir, secretsFile))
+	if os.IsNotExist(err) {
+		return doc, nil
+	} else if err != nil {
+		return doc, errors.Wrap(err, "failed to read secrets")
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return doc, errors.Wrap(err, "corrupt secrets file")
+	}
+	if doc.Secrets == nil {
+		doc.Secrets = map[string]string{}
+	}
+	return doc, nil
+}
+
+// save atomically replaces the secrets file
+func (s *Store) save(doc secretsDoc) error {
+	data, err := json.MarshalIndent(doc, "", "    ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(s.Dir, secretsFile, data)
+}
+
+// masterKey returns the master key from the environment or the key file,
+// generating the key file if create is set and it does not exist yet
+func (s *Store) masterKey(create bool) ([]byte, error) {
+	if encoded := os.Getenv(MasterKeyEnv); encoded != "" {
+		key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+		if err != nil || len(key) != keySize {
+			return nil, fmt.Errorf("%s must be a base64 encoded %d byte key", MasterKeyEnv, keySize)
+		}
+		return key, nil
+	}
+
+	path := filepath.Join(s.Dir, masterKeyFile)
+	encoded, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) && create {
+		key := make([]byte, keySize)
+		if _, err := io.ReadFull(rand.Reader, key); err != nil {
+			return nil, errors.Wrap(err, "failed to generate master key")
+		}
+		if err := writeFileAtomic(s.Dir, masterKeyFile, []byte(base64.StdEncoding.EncodeToString(key))); err != nil {
+			return nil, errors.Wrap(err, "failed to write master key")
+		}
+		return key, nil
+	} else if os.IsNotExist(err) {
+		return nil, fmt.Errorf("no master key found, set %s or restore %s", MasterKeyEnv, path)
+	} else if err != nil {
+		return nil, errors.Wrap(err, "failed to read master key")
+	}
+
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(encoded)))
+	if err != nil || len(key) != keySize {
+		return nil, fmt.Errorf("invalid master key in %s", path)
+	}
+	return key, nil
+}
+
+func encrypt(key []byte, name, value string) (string, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+	// the name is authenticated so a value can't be swapped to another secret
+	sealed := gcm.Seal(nonce, nonce, []byte(value), []byte(name))
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func decrypt(key []byte, name, encoded string) (string, error) {
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", fmt.Errorf("corrupt secret %s", name)
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return "", fmt.Errorf("corrupt secret %s", name)
+	}
+	value, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], []byte(name))
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt secret %s, wrong master key?", name)
+	}
+	return string(value), nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// writeFileAtomic writes a 0600 file through a temp file and rename
+func writeFileAtomic(dir, name string, data []byte) error {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+
+	tmpfi, err := ioutil.TempFile(dir, name+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpfi.Name())
+
+	if err = tmpfi.Chmod(0600); err != nil {
+		tmpfi.Close()
+		return err
+	}
+	if _, err = tmpfi.Write(data); err != nil {
+		tmpfi.Close()
+		return err
+	}
+	if err = tmpfi.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpfi.Name(), filepath.Join(dir, name))
+}
@@ -0,0 +1,94 @@
+package secrets
+
+import (
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStore(t *testing.T) {
+	t.Setenv(MasterKeyEnv, "")
+	t.Setenv(EnvName("vt_api"), "")
+	dir := t.TempDir()
+	store := NewStore(dir)
+
+	if _, err := store.Get("vt_api"); err != ErrNotFound {
+		t.Fatalf("Get() on empty store error = %v, want %v", err, ErrNotFound)
+	}
+	if err := store.Set("vt_api", "s3cr3t-key"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	got, err := store.Get("vt_api")
+	if err != nil || got != "s3cr3t-key" {
+		t.Fatalf("Get() = %q, %v, want %q", got, err, "s3cr3t-key")
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, secretsFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "s3cr3t-key") {
+		t.Error("secrets file contains the plaintext value")
+	}
+	for _, name := range []string{secretsFile, masterKeyFile} {
+		info, err := os.Stat(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != 0600 {
+			t.Errorf("%s mode = %v, want 0600", name, info.Mode().Perm())
+		}
+	}
+
+	t.Setenv(EnvName("vt_api"), "from-env")
+	if got, _ := store.Get("vt_api"); got != "from-env" {
+		t.Errorf("Get() with env override = %q, want %q", got, "from-env")
+	}
+	t.Setenv(EnvName("vt_api"), "")
+
+	if names, _ := store.List(); len(names) != 1 || names[0] != "vt_api" {
+		t.Errorf("List() = %v, want [vt_api]", names)
+	}
+	if err := store.Remove("vt_api"); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	if store.Has("vt_api") {
+		t.Error("Has() after Remove() = true")
+	}
+	if err := store.Remove("vt_api"); err != ErrNotFound {
+		t.Errorf("Remove() of missing secret error = %v, want %v", err, ErrNotFound)
+	}
+	if err := store.Set("../vt_api", "x"); err == nil {
+		t.Error("Set() accepted an invalid name")
+	}
+}
+
+func TestWrongMasterKey(t *testing.T) {
+	t.Setenv(EnvName("vt_api"), "")
+	store := NewStore(t.TempDir())
+
+	t.Setenv(MasterKeyEnv, base64.StdEncoding.EncodeToString([]byte(strings.Repeat("a", keySize))))
+	if err := store.Set("vt_api", "s3cr3t-key"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	t.Setenv(MasterKeyEnv, base64.StdEncoding.EncodeToString([]byte(strings.Repeat("b", keySize))))
+	if _, err := store.Get("vt_api"); err == nil || !strings.Contains(err.Error(), "wrong master key") {
+		t.Errorf("Get() with wrong master key error = %v", err)
+	}
+
+	t.Setenv(MasterKeyEnv, "short")
+	if _, err := store.Get("vt_api"); err == nil || !strings.Contains(err.Error(), MasterKeyEnv) {
+		t.Errorf("Get() with invalid master key error = %v", err)
+	}
+}
+
+func TestEnvName(t *testing.T) {
+	if got := EnvName("vt-api.key"); got != "MALICE_SECRET_VT_API_KEY" {
+		t.Errorf("EnvName() = %q", got)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/maliceio/malice/config"
 	"github.com/maliceio/malice/malice/docker/client"
 	"github.com/maliceio/malice/malice/docker/client/container"
+	"github.com/maliceio/malice/malice/docker/client/network"
 )
 
 // Start creates an Kibana container from the image blacktop/kibana:malice
@@ -18,6 +19,9 @@ func Start(docker *client.Docker, logs bool) (types.ContainerJSONBase, error) {
 		"5601/tcp": {{HostIP: "0.0.0.0", HostPort: "80"}},
 	}
 
+	// Kibana reaches the database as elasticsearch on the network of malice
+	net := container.Network{Name: network.Name()}
+
 	if docker.Ping() {
 		contJSON, err := container.Start(
 			docker,                             // docker *client.Docker,
@@ -27,8 +31,9 @@ func Start(docker *client.Docker, logs bool) (types.ContainerJSONBase, error) {
 			logs,                               // logs bool,
 			nil,                                // binds []string,
 			portBindings,                       // portBindings nat.PortMap,
-			[]string{config.Conf.Docker.Links}, // links []string,
+			net,                                // net container.Network,
 			nil, // env []string,
+			nil, // labels map[string]string,
 		)
 		log.WithFields(log.Fields{
 			"ip":   docker.GetIP(),
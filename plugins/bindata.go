@@ -1,6 +1,18 @@
 // Code generated by go-bindata.
 // sources:
 // plugins/plugins.toml
+// plugins/templates/go/.dockerignore
+// plugins/templates/go/.gitignore.template
+// plugins/templates/go/CHANGELOG.md
+// plugins/templates/go/Dockerfile
+// plugins/templates/go/LICENSE.md
+// plugins/templates/go/README-short.txt
+// plugins/templates/go/README.md
+// plugins/templates/go/VERSION
+// plugins/templates/go/circle.yml
+// plugins/templates/go/go.mod
+// plugins/templates/go/plugin.toml
+// plugins/templates/go/scan.go
 // plugins/templates/python/.dockerignore
 // plugins/templates/python/.gitignore.template
 // plugins/templates/python/CHANGELOG.md
@@ -79,7 +91,7 @@ func (fi bindataFileInfo) Sys() interface{} {
 	return nil
 }
 
-var _pluginsPluginsToml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xb4\x98\x5b\x53\xdb\xba\x16\xc7\xdf\xf3\x29\xd6\x98\x97\x73\xce\x90\x0b\x81\x90\xb6\x33\x3c\xe4\x40\x68\x69\xa1\x30\x49\x4a\xa7\xbb\xc3\x50\xd9\x5e\x8e\xb5\xb1\x2d\x6f\x49\x4e\x08\x0f\xfb\xb3\xef\x91\xec\xc4\xb9\x88\xd6\x4a\xbd\xfb\xd0\x10\x2d\xad\xcb\xef\xaf\x25\xcb\xd1\x01\x9c\xb3\x74\xc1\xe9\x34\x94\xf0\x1f\xef\xbf\xd0\xed\x1c\x1d\x43\x53\x7d\xbc\x01\x37\x22\xde\x93\x64\x29\x7c\x64\x22\xcc\x08\xdc\x10\x9a\xe0\x21\x0c\xa2\x08\x46\xca\x41\xc0\x08\x05\xf2\x19\xfa\xad\xc6\x01\x8c\x11\xe1\xfa\xea\x7c\xf8\x79\x3c\x84\x80\x71\x88\xa8\x87\x89\x40\xa0\x49\xc0\x78\x4c\x24\x65\x49\xab\xd1\x38\xa8\xe7\x5f\xe3\x00\xee\xae\xbf\xbc\xbf\xfa\x0c\xe7\x2c\x09\xe8\x34\xe3\x3a\x01\xd8\xc7\xa9\xa9\x9e\x86\xa4\x32\x42\x38\x03\xe7\x86\x28\x72\xb8\x8b\xb2\x29\x4d\x36\xcb\x13\x4e\xa3\xf1\xfd\x7b\xaa\x2d\x0f\x0f\x0d\x00\x4c\x88\x1b\xa1\x0f\x67\x20\x79\x86\x0d\x80\x84\xc4\x3a\x48\x22\x78\xe4\x34\x00\x7c\x14\x1e\xa7\xa9\x66\x3b\x03\xe7\xf3\x78\x74\x0d\x17\x44\x12\x97\x08\x84\x0f\x44\x84\x30\x46\xc2\xbd\x50\xcd\xf5\x88\xc4\x29\xe3\x0b\x35\x91\x26\x12\x75\x00\x1a\x93\xa9\x8e\x18\xeb\xb2\xda\x2a\xf0\x3b\x11\x92\x23\x65\xe4\x98\x32\x41\x65\xe1\x13\x4a\x99\x8a\x77\xed\xf6\x94\xca\x30\x73\x5b\x1e\x8b\xdb\xb9\x53\x33\xaf\x58\x68\xe7\xd6\x94\x4a\xe5\xeb\x66\x34\x52\x85\x07\x24\x12\xaa\x72\x2f\x56\xdf\x9c\x88\xb1\xa7\x2c\x55\x13\x62\x9a\xa3\x84\x44\xe8\xf2\xd4\xa7\x5c\xa4\x28\xe0\x0c\xbe\x83\xa3\x6b\x80\x87\xaa\x82\xcc\x28\xcf\x84\x64\x92\x98\x64\xb9\x57\xc6\x89\x32\x42\x13\x02\x1a\xa1\x00\xe1\x91\x04\x48\xe2\xeb\xb4\x50\x56\x55\x49\xa4\xcd\x64\xb6\x2a\x95\xde\xaf\x69\x95\xa5\x11\x23\xab\x01\x50\x7b\x47\x2e\x07\x25\x83\x1f\x8a\xfd\x07\xd0\x00\x16\x2c\x83\x39\x49\xa4\x1a\x2d\xec\x82\xc4\x69\x84\x6a\x60\x2d\x8f\xc7\xe2\x06\x00\x49\xe9\x13\xea\x22\xbb\xbd\xe3\xb7\xbd\xa3\x53\xff\xa4\x7f\xe4\xf7\x5d\x74\x4f\xdd\xee\x1b\xd2\xef\x76\xfc\x3e\xe9\xf6\x3a\xdd\x13\xf4\xbd\x4e\xd0\xef\xbd\xed\xf8\xc7\x27\xbd\xc0\xeb\x9f\xf4\x4f\x8e\xde\x9c\x9e\xf4\x3a\x9d\x2e\xf1\x4e\xfa\xae\x03\x07\x30\x09\xa9\x00\x2a\x80\x80\x44\x21\xe1\x09\x17\x87\x90\x46\xa8\x1a\x8f\x63\x1a\x11\x0f\x61\x4e\x65\xa8\x6a\xe4\xc0\xe6\xc9\x1e\x3d\x10\xfb\x3d\xe7\xb0\x68\x85\xfc\xb3\xdb\x3b\x55\x4d\xa1\x1a\x61\xa6\xe6\x38\x37\x03\xf5\x4c\x79\xbc\x9f\x3c\x0e\xee\xae\xd4\xa4\x62\x60\x72\x75\x33\xbc\xfd\x32\x71\x5e\x6f\xa0\xa5\xd8\xcb\x0e\xd2\x4a\x2d\x0b\xd9\x6a\xa0\x83\x95\x11\x9a\x7b\x75\xcc\x46\x70\xdb\x86\x59\x39\xff\xbb\xfd\x52\xa6\xf1\x16\x31\xcf\x8a\xa6\xc9\x04\x72\x55\xa6\xca\x5b\x74\x8f\xf3\x1b\xdb\x79\x67\xe5\x26\x1f\x1e\xbf\x8c\x87\xa3\xf5\xa5\xfb\xf0\xf8\x69\xf8\xed\x27\x2b\xb7\xb5\xf5\x45\x48\x7c\x36\x6f\xea\x93\x86\x1b\x16\x6f\xac\xed\x63\x6d\xde\x73\xf9\x76\x52\xd8\x2e\xe1\x46\x80\xba\x1e\x91\xeb\xdb\xe3\x27\x4f\xca\x9d\x46\x47\x12\x37\xf5\x12\x1b\xc4\x9a\x20\x89\xcf\x95\x6d\xdf\x46\xdf\x08\x6e\xdd\xe9\x2b\xef\xd7\x34\xaa\x47\x92\xad\x0e\x52\x47\x82\x7a\x0d\x31\xe8\x21\x84\x8f\x98\xb6\x27\x23\x7a\xd1\xc6\x67\x1a\x48\xc6\xa2\x6d\x31\x62\x94\xc4\x27\x92\x98\xf4\x58\x0f\x6d\xab\xc6\xd2\xf7\x57\x5a\xfc\xaf\xf2\x6b\xc3\x82\x70\x62\x80\xfc\x36\x18\x0d\x60\xec\x91\x64\x9b\x8c\xcc\x4c\x4c\x2a\xca\xbb\x04\x59\xf7\xf8\xb9\xb3\x0f\x97\xf2\xaf\x8f\x89\xcc\x88\x90\x06\xa8\x81\x1a\x87\x41\x22\xa9\x3e\xfe\xab\xa1\xad\x82\xd9\x32\x69\xc7\x3a\xa1\xa6\x26\xa4\xfb\xf7\xf6\x40\xd3\xfd\x70\xa6\xbf\x01\xb3\xfd\xc4\x21\x33\x6a\xec\xbb\x81\x1a\xb7\x27\x2a\x82\xd9\x33\xd1\x4a\x7d\xa7\xcf\x39\xc1\x3d\xf5\xf5\xef\x76\xab\x48\xbb\x11\xa5\x1d\xba\xe8\xcf\x5a\x4f\xb8\x28\xa6\xfb\x42\xaa\xe9\x6d\x96\xca\xdd\x19\x15\xd7\xdc\xa5\xd2\xc7\x00\x13\xdf\x78\x8a\xfd\xbf\xb4\xda\x2a\xb6\x15\xd8\x56\xb7\x35\xf7\xfa\x1a\xdc\x8b\x48\x9c\x57\xbb\xc5\x79\x1e\x91\x78\x70\x5f\x0d\xac\x0c\x62\xcb\x94\x7b\xd6\x88\xc3\x62\xe6\x9b\xce\x8f\x73\x6d\xb0\x5d\xb1\x32\x9c\x35\x98\xf6\xac\x0f\xcc\xe7\x73\x74\x0d\x5c\x17\xbc\xf5\x15\xdd\xca\x5c\x7f\x65\x64\xd1\xa2\xac\xbd\xbc\x32\x68\xaf\xe2\xda\x02\x6a\xc7\xfa\xf8\x50\x14\xc7\xde\x16\x1f\xaa\xe3\xd0\x76\xd9\x56\xc1\x6c\xa1\xb4\x63\x7d\x50\x41\xca\x99\xe9\x48\xbc\x6c\xde\x8d\x6e\x27\xb6\x54\xab\x68\xd6\xef\x2f\xca\xb1\x46\x2a\x81\x5e\xc6\xd1\xc8\x35\xd6\x26\x6b\xb2\x32\xa2\x35\x5b\xee\x5a\x1f\xdd\x13\x11\x29\x72\xf1\xb4\x30\xf0\x7d\x5a\xda\x6c\x01\x37\x82\xda\x22\xae\x9c\xeb\x83\x8c\x3d\x12\xa0\x69\x05\x6f\xbc\x41\x80\xd6\xeb\x57\x86\xb3\x65\xcb\x3d\xeb\x03\x13\x2c\x0d\x99\x30\xfd\xf8\xd4\x06\x5b\xb0\x32\x9c\xf5\x0f\x4d\xed\x59\x1f\xd8\x9c\x26\x3e\x9b\x8b\xe6\x4f\xde\x4c\xbe\xe6\x53\xe0\x62\xcf\xd7\x13\x53\x0a\x5b\xec\xed\x18\xf5\x09\xf0\xc2\x12\x23\xf5\x1f\x6a\xdc\x92\x74\x15\xcb\x16\x4f\x3b\xd6\xc7\x94\xbe\x76\xea\xdd\x0d\xa1\x09\xea\x27\xae\xbe\x0f\xe2\x54\x95\x9f\x32\x2e\x55\x1c\xc0\x67\xf4\x32\xfd\xe7\x0e\x2e\x3e\xa3\x89\x37\xdd\xfb\x40\x4c\x2b\x9d\x88\x24\x4d\x23\xea\xe9\xab\xf3\xf6\x73\xd3\x67\x42\x95\xb8\x76\x3b\x95\x67\xaf\x7a\xbc\x44\x4c\x98\x76\xf0\x25\xe5\x38\x5c\x20\x5c\x13\x57\xc0\xad\x1b\x64\x42\x91\xfb\x30\x96\x9c\x26\x53\x18\xb3\xa8\xb8\x13\xaa\x20\xc8\x2a\x87\xf5\x71\xa3\x1c\xf7\x94\xa3\xea\xaf\x34\x16\x04\xd4\x33\x3d\x9c\x6f\xb5\x61\xb7\x33\x6e\xaf\x87\xed\xd1\xe4\x12\x7c\xe6\x65\x31\x26\x72\xa7\x2d\x96\x06\x93\x14\x65\x36\x5b\x2d\x72\xcf\x1a\x77\x83\x1f\x98\xb6\xc2\xc5\xe5\x2e\xb1\x1a\xdc\x8b\xb6\xc8\x61\xbd\x0d\xfc\xc0\x66\xd1\x8b\x2c\x95\xba\x7f\x7b\xf5\xff\x24\x33\x92\x0b\x60\x10\xe3\xe3\xca\xb8\xab\xc9\xc7\x31\xe4\x26\x2b\x41\x36\xd3\xd9\xea\x52\x7a\xdb\xc8\xb3\x9e\xb3\xf2\xd5\x05\xf7\x42\x3a\x33\xed\x8a\x41\x6e\x59\x13\x24\x4b\x8a\xd9\x50\x7c\xee\x1e\x0b\x65\xb4\x9d\x6b\x8d\xd2\x64\x7d\xb1\x91\xbb\xfe\x52\x8a\x32\xc3\x0b\x4d\xd7\x2e\x4d\x25\xe1\xce\x21\x38\xd3\x17\xf5\x7f\xda\x7f\x71\xe0\xa1\xf1\x4f\x00\x00\x00\xff\xff\xbc\x66\x09\x65\xf8\x1d\x00\x00")
+var _pluginsPluginsToml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xb5\x58\xdb\x6e\xe3\x36\x10\x7d\xf7\x57\x10\xce\x4b\x5b\xac\xad\xdd\xec\xa5\x40\x80\x3e\xb8\x89\xb3\xc9\x6e\x2e\x86\xed\x64\xb1\x0d\x02\x87\x96\x28\x9b\x8d\x24\x6a\x49\xca\x89\xf3\xd0\x6f\xef\x0c\x25\x5b\xbe\xd0\x59\xd1\x55\x03\x24\x8a\x38\x9c\xcb\x39\x33\xe4\x50\x3c\x20\xc7\x22\x9d\x4b\x3e\x99\x6a\xf2\x8b\xff\x2b\x39\x7c\xfb\xee\x3d\x69\xc1\xe3\xf0\x3d\x19\x47\xd4\x7f\xd4\x22\x7d\x43\x3a\x51\x44\xfa\x38\x47\x91\x3e\x53\x4c\xce\x58\xd0\x6e\x1c\x90\x01\x63\xe4\xe2\xfc\xb8\x7b\x35\xe8\x92\x50\x48\x12\x71\x9f\x25\x8a\x11\x9e\xc0\x5b\x4c\x35\x17\x49\xbb\xd1\x38\xa8\xe7\x07\xfc\xf5\x2e\x6e\x3e\x9f\x5f\x41\xc4\x49\xc8\x27\x99\x34\x0e\x88\xbb\x9d\x9a\xe2\x69\x68\xae\x23\x46\xfe\x20\xcd\x4b\x8a\xc8\x49\x2f\xca\x26\x3c\x59\x0f\x4f\x35\x1b\x8d\xbb\xbb\xd4\x48\xee\xef\x1b\x84\xb0\x84\x8e\x23\x16\x80\x9a\x96\x19\x83\x81\x84\xc6\xc6\x48\xa2\x64\xd4\x84\xf7\x80\x29\x5f\xf2\xd4\x60\x83\xe1\xab\x41\xff\x82\x9c\x50\x4d\xc7\x14\x98\x3d\xa3\x6a\x0a\xb4\x53\xe9\x4f\x71\xae\x4f\x35\x9b\x08\x39\xc7\x89\x3c\xd1\xcc\x18\xe0\x31\x9d\x18\x8b\xb1\x09\xcb\x43\xc3\x47\x6a\x4a\xdf\xa1\x50\xb2\x54\x28\xae\x0b\x9d\xa9\xd6\xa9\x3a\xf2\xbc\x09\xd7\xd3\x6c\xdc\xf6\x45\xec\xe5\x4a\xad\x3c\x62\x65\x94\xdb\x20\x46\xdd\x71\xc6\x23\x0c\x3c\xa4\x91\xc2\xc8\xfd\x18\xdf\x9a\x91\x10\x8f\x59\x8a\x13\x62\x9e\x43\x99\x42\x94\xf8\x8e\x4f\x3d\x4f\x99\x82\xc1\x3b\xd2\x34\x31\x90\xfb\xaa\x84\xcc\xb8\xcc\x94\x16\x9a\xda\x68\xb9\x45\xe1\x10\x85\x50\xad\x21\x8f\xc0\x87\xf2\x69\x42\x68\x12\x18\xb7\xa4\x8c\xaa\x12\x49\xeb\xce\x5c\x59\x2a\xb5\x77\x71\x95\xa5\x91\xa0\xcb\x01\x82\x6b\x47\x2f\x06\xb5\x20\x0f\x88\xfd\x81\xf0\x90\xcc\x45\x46\x9e\x68\xa2\x71\xb4\x90\x2b\x1a\xa7\x50\x68\x30\xb0\xe2\x07\xa2\xd8\x23\x05\x71\xf0\xb1\xf9\xa6\xc8\x44\xfe\x3c\xfc\xf8\x09\x73\x02\x98\x81\xa6\x51\xc4\x63\xae\x51\xff\x83\x17\x37\x21\xca\x5e\x36\x06\xa0\xa4\xd3\x3b\x27\x8f\x6c\xae\x08\x95\x8c\x98\x39\xcc\x84\xfd\x01\x98\xfa\x91\x31\xa5\x95\x17\xf3\x24\xd3\xcc\xe4\x73\x86\xbe\x9a\x97\x1d\xdc\x1a\x46\xb7\xc3\x11\x68\xa3\xb3\x62\x60\x78\x7e\xd9\xbd\xbe\x19\x36\x8d\x4f\xd0\xe6\x32\x0f\x6e\x43\x01\xc5\xc0\x12\xe4\x80\x21\x27\x12\xfd\x93\x27\xc8\x00\x79\xc8\xc9\x27\x8a\xf9\x12\x48\x54\xf0\x3b\xd3\x23\x9a\xf2\x07\x50\x59\x94\x56\x3b\x97\x9a\x0a\x5b\x29\x29\x33\xaf\x69\xc6\xf2\x38\x37\xbc\xee\xac\xcd\x45\x1e\x17\x96\x4c\x12\x16\x24\x6f\xd4\xe6\xc1\x52\x08\xb5\xb9\x4f\x31\xae\x19\x77\xad\xc5\xa5\xf2\xff\x5b\x8a\xa5\x1b\x7f\x1e\xcb\xac\xa8\xc7\x0c\x9a\x03\x86\x89\x7e\x31\x61\xc5\xbf\xfb\xee\x14\x5b\xd5\x34\x3c\x1b\xdd\x0c\xba\xfd\xd5\x72\x3a\x1b\x7d\xed\x7e\xdf\x59\x4d\xaf\x28\x54\xdc\x86\x20\x96\x40\x3c\xb5\x4c\xd7\x93\x96\x6c\x0f\x8c\x7c\x60\xc4\x7b\xe6\x7b\xcb\x85\x6b\xce\xd7\x0c\xd4\xb5\x5d\xaf\xee\x15\xaf\xec\xda\x5b\x2b\x83\xd1\xb8\x65\x6a\xc2\x42\xd6\x10\x84\xc7\x28\xdb\x77\x65\xac\x19\x77\x5e\x1a\x4b\xed\x5d\x1c\xd5\x43\xc9\x46\x05\x61\x7b\xc2\x23\x91\x85\x0f\xa5\x02\xc6\x52\x6f\xd8\xe7\x27\x1e\x7b\xe6\xa1\x16\x22\xda\x24\x23\x66\x9a\x06\x70\x00\xb0\xf1\xb1\x6a\xda\x95\x8d\x85\xee\xcf\xb8\xf8\xad\xf2\x11\x66\x4e\x25\xb5\x80\xfc\xde\xe9\x77\xc8\x00\xba\xf3\x26\x32\x3a\xb3\x61\x42\x2b\x47\x09\x13\x87\xef\x9f\xdf\xee\x83\x0b\xf5\x77\x61\x52\xfe\x94\xc5\xd4\x1c\xb7\x44\xc2\x9a\x7b\xa1\xa4\x33\xaa\xb4\x05\x66\x07\xc7\x49\x27\xd1\xdc\x1c\x4e\xaa\x81\x5d\x1a\x73\x45\x69\x14\xeb\x4b\x1d\x9d\x4d\x6c\x90\x6e\x3f\xbb\x03\x9a\xec\x07\x67\xf2\x1f\xc0\x6c\xee\x41\x14\x0e\x4a\xb6\x4a\xec\xe0\xb8\x3b\xa2\xc2\x98\x3b\x26\xbe\xbb\x12\x57\x50\x99\x56\xa9\xa4\x8f\xaf\xff\x78\xed\xc2\xed\x9a\x15\x6f\x3a\x66\xc1\xac\x0d\xf3\x8a\xe9\x81\x32\xc7\x34\x4f\xa4\x7a\x7b\x46\xc5\x9c\x8f\xb9\x0e\x58\xc8\x92\xc0\xda\xd7\xfe\x2c\xa5\xae\x8c\x6d\x18\x76\xe5\x6d\x45\xbd\xbe\x02\xf7\x23\x1a\xe7\xd1\x6e\xe0\x3c\x06\x41\xe7\xb6\x1a\xb0\xd2\x88\x2b\xa6\x5c\xb3\x46\x38\x22\x16\x81\xad\xa3\x1c\x1b\x81\x6b\xc6\x4a\x73\xce\xc0\x8c\x66\x7d\xc0\x02\xf9\xc4\xc6\x16\x5c\x27\xb2\xfd\x8d\x8d\x2b\xe3\xfa\x91\xd1\x79\x9b\x0b\x6f\x71\x87\xe1\x2d\xed\xba\x02\x34\x8a\xf5\xe1\x63\xaa\x68\x84\x1b\xf8\x18\x36\x48\xd7\xb4\x2d\x8d\xb9\x82\x32\x8a\xf5\x81\x0a\x53\x29\x6c\x2d\xf1\xb4\xd5\xeb\x5f\x0f\x5d\x51\x2d\xad\x39\x9f\x68\x50\xb1\x46\x54\xf0\x0d\x99\x49\x66\xc5\x35\x30\x22\x67\x64\xa5\x45\x67\x6c\xb9\x6a\x7d\xe8\x1e\xa9\x4a\x99\x54\x8f\x73\x0b\xbe\xaf\x0b\x99\x2b\xc0\x35\xa3\xae\x10\x97\xca\xf5\x81\x8c\x7d\x1a\x32\x5b\x06\x2f\xfd\x0e\x08\x5c\xe1\x95\xe6\x5c\xb1\xe5\x9a\xf5\x01\x53\x22\x9d\x0a\x65\xfb\x1c\x35\x02\x57\x60\xa5\x39\xe7\x4f\x4f\xa3\x59\x1f\xb0\x27\x9e\xc0\xa7\xac\x6a\xbd\x72\x32\xf9\x96\x4f\x21\x27\x7b\x1e\x4f\x6c\x2e\x5c\x61\x6f\xda\xa8\x8f\x80\x17\xf8\x30\xb1\xa1\xfe\x0b\xc7\x1d\x91\x2e\x6d\xb9\xc2\x33\x8a\xf5\x61\x4a\x77\x75\xbd\x5e\x97\xb4\x08\x7e\xf4\x9a\x2b\x25\xc9\x31\xfc\x54\x48\x8d\x76\x08\x7b\x86\x2d\xcf\xfc\xbb\x05\x17\x44\x36\xbc\xe9\xde\x0d\x31\xad\xd4\x11\x69\x9a\x82\x96\xb9\xd8\xf7\x9e\x5b\x81\x50\x18\xe2\xca\x05\x57\xee\xbd\x6a\x7b\x89\x84\xb2\xad\xe0\x53\x2e\x59\x77\xce\xc8\x05\x1d\x2b\x72\x3d\x0e\x33\x85\xc8\x03\x32\x00\x7e\x92\x09\x19\x88\xa8\xb8\x25\xaa\x40\xc8\xd2\x87\x73\xbb\x41\x45\xf7\xaf\xe8\x1d\x04\x55\xfd\x6e\x13\x61\x08\x41\x58\x38\xb9\x36\x82\xed\x5a\xb9\xbe\xe8\x7a\xfd\xe1\x29\x09\x84\x9f\xc5\x2c\xd1\x5b\x85\xb2\x10\xd8\xc8\x29\xbd\xb9\xb2\x93\x6b\xd6\xb8\x3e\x82\xd0\xb6\x38\x4e\x4e\xb7\x11\xe3\xe0\x5e\x68\x0b\x1f\xce\x0b\x23\x08\x5d\x56\x45\xe1\xa5\xd2\x7a\xd8\xcc\xfe\xdf\x78\xa7\x61\x08\xb0\x90\xf1\x65\x29\xdc\xe6\xe4\xcb\x80\xe4\x22\x27\x42\xd6\xdd\xb9\xf2\x52\x6a\xbb\xd0\xb3\xea\xb3\xf2\x65\x86\xf4\xa7\x7c\x66\x5b\x15\x9d\x5c\xb2\x42\x48\x96\x14\xb3\x49\xf1\xdc\x6e\x14\xa5\xb5\xad\x8b\x8e\x52\xe4\x7c\xd5\x91\xab\xfe\x94\x8a\xd2\xc3\x0b\xc0\x28\x2f\x56\x35\x95\x78\xb1\x3a\x79\xc1\xbf\xe9\xef\x2f\x78\xbb\xfa\x2f\xb7\x57\xf1\xb7\x9b\x1e\x00\x00")
 
 func pluginsPluginsTomlBytes() ([]byte, error) {
 	return bindataRead(
@@ -94,7 +106,247 @@ func pluginsPluginsToml() (*asset, error) {
 		return nil, err
 	}
 
-	info := bindataFileInfo{name: "plugins/plugins.toml", size: 7672, mode: os.FileMode(420), modTime: time.Unix(1543196673, 0)}
+	info := bindataFileInfo{name: "plugins/plugins.toml", size: 7835, mode: os.FileMode(420), modTime: time.Unix(1792343704, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _pluginsTemplatesGoDockerignore = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x53\x56\xf0\x4c\xcf\xcb\x2f\x4a\x55\xd0\x4b\xcf\x2c\x51\x48\xcb\xcf\x49\x49\x2d\xe2\x02\xb1\xb5\xb8\xc0\x54\x26\x58\x96\x2b\xc8\xd5\xd1\xc5\xd7\x55\x2f\x37\x85\xcb\xc7\xd3\xd9\xd5\x2f\x18\xcc\x74\xf6\x70\xf4\x73\x77\xf5\xf1\x77\x07\x71\x92\x4a\x33\x73\x52\xb8\x8a\x52\x73\x52\x13\x8b\x53\xb9\x7c\x13\xb3\x53\xd3\x32\x73\x52\xb9\x92\x33\x8b\x92\x73\x52\xf5\x2a\x73\x73\x00\xba\xc0\x2a\xa8\x6a\x00\x00\x00")
+
+func pluginsTemplatesGoDockerignoreBytes() ([]byte, error) {
+	return bindataRead(
+		_pluginsTemplatesGoDockerignore,
+		"plugins/templates/go/.dockerignore",
+	)
+}
+
+func pluginsTemplatesGoDockerignore() (*asset, error) {
+	bytes, err := pluginsTemplatesGoDockerignoreBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "plugins/templates/go/.dockerignore", size: 106, mode: os.FileMode(436), modTime: time.Unix(1792338564, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _pluginsTemplatesGoGitignoreTemplate = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x3d\x8e\x4d\x4b\x04\x31\x0c\x86\xef\xf9\x15\x05\x2f\x3a\x48\x45\x41\xf1\x2a\xbb\x78\xf5\x30\x47\x91\xa1\x1f\xe9\x6c\x97\x99\xa6\x36\xed\x32\xfb\xef\x4d\x77\xd5\x43\x3e\xde\xe4\xcd\x43\xf4\x7e\x9c\xc6\x4a\x05\x01\x6e\xd4\x8e\xd6\x1c\x17\xf4\xea\xc3\x1e\xd1\x55\x15\x44\xf0\xbd\x1a\xab\xa9\xd1\x29\x93\xbc\xda\x9f\x93\x59\xa5\x5f\xa2\x65\x75\x3b\x1e\x4c\xf9\x77\xf3\x1d\x0c\x9a\x24\x8c\x04\x53\xe7\xbd\xd3\xe2\xb1\x30\x4c\x64\x8f\x30\x55\xe4\xda\xa7\x6f\xc5\x1d\x62\x95\x8b\x56\x50\x71\x46\x17\x83\x10\x71\xab\x98\x38\x52\xe2\x87\x5c\x30\xc4\x0d\x59\x38\x9f\xcf\x2f\xaf\xa7\xef\x2f\xf8\xad\x9a\x9a\x20\x06\xed\x66\x7a\xd4\x33\x5d\xbb\x27\xed\x60\x92\x3a\x79\x0c\x2d\xfd\x89\x99\xea\x39\x23\x77\xd7\x45\xe3\x96\xa9\x54\x3d\xc0\xf5\x91\xd5\xc4\xd4\x77\x82\xc0\x0d\x25\x5f\xbe\x1b\x74\x2e\x14\x00\x3c\xda\x36\x43\xc1\x05\x0d\x23\xd8\x16\x17\x0f\xfa\xc4\x8e\x7c\xb7\xda\x06\xc0\xce\xa4\x1f\x63\x2c\xba\xa8\x3c\x01\x00\x00")
+
+func pluginsTemplatesGoGitignoreTemplateBytes() ([]byte, error) {
+	return bindataRead(
+		_pluginsTemplatesGoGitignoreTemplate,
+		"plugins/templates/go/.gitignore.template",
+	)
+}
+
+func pluginsTemplatesGoGitignoreTemplate() (*asset, error) {
+	bytes, err := pluginsTemplatesGoGitignoreTemplateBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "plugins/templates/go/.gitignore.template", size: 316, mode: os.FileMode(509), modTime: time.Unix(1792338564, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _pluginsTemplatesGoChangelogMd = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x53\x56\x56\x30\xd0\x33\xd4\x33\x50\xd0\x55\x70\xcb\x2c\x2a\x2e\x51\x08\x4a\xcd\x49\x4d\x2c\x4e\xe5\xd2\x52\x70\x2d\x4b\x2d\xaa\x54\x48\x4b\x4d\x2c\x29\x2d\x4a\x55\x48\x4c\x49\x49\x4d\x81\x8b\x26\x95\xa6\x2b\xa4\x65\x56\x00\x45\x00\x82\x96\x39\xb5\x41\x00\x00\x00")
+
+func pluginsTemplatesGoChangelogMdBytes() ([]byte, error) {
+	return bindataRead(
+		_pluginsTemplatesGoChangelogMd,
+		"plugins/templates/go/CHANGELOG.md",
+	)
+}
+
+func pluginsTemplatesGoChangelogMd() (*asset, error) {
+	bytes, err := pluginsTemplatesGoChangelogMdBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "plugins/templates/go/CHANGELOG.md", size: 65, mode: os.FileMode(509), modTime: time.Unix(1792338564, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _pluginsTemplatesGoDockerfile = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x75\x91\xc1\x4e\x02\x31\x10\x86\xef\x7d\x8a\x3f\x8d\x10\x89\x76\xf7\x4e\xe2\x01\x05\x0c\x31\xb0\x64\x41\x94\x28\x31\xa5\x2d\xa5\x71\xb7\xdd\x74\x17\x95\x10\xde\xdd\x2e\x48\xe0\xe2\xa1\x93\xce\x74\xe6\x9b\x99\xbf\xfd\x34\x19\x42\xbb\x8c\x5b\xdd\xe6\x59\x61\xac\x42\x67\x82\xe5\xc6\x64\x52\x79\x42\x1e\x92\xf1\x1c\x11\x62\xed\xe2\xd2\x8b\x78\xb7\x43\x91\x6d\xb4\xb1\x1f\x5e\x15\x0e\xfb\x3d\x49\x9f\x47\xe0\xc5\x27\xb8\x94\x60\xcc\x3a\x26\xb8\x58\x2b\x68\x53\xe1\x9d\x00\xcd\x26\x4a\x55\x81\xfd\x9c\x3c\x25\xd6\x0e\xf4\xbe\xe6\x1b\xab\x71\x06\x5a\x9e\xab\x00\x44\x29\xb8\xc5\xa3\xc3\xd2\x58\xee\xb7\x51\x14\xd1\x53\xa9\x90\xff\xcf\x71\xca\xd1\x0e\x5f\xca\x97\xc6\xd9\x8b\x48\xee\x24\x2a\x23\xb7\x17\xa1\xc3\x82\x60\x99\x5c\x65\x5c\x97\xa0\xec\x15\x39\x37\x36\x9a\x1d\x8b\xef\xae\xae\x05\xaf\x30\xeb\xa5\x93\x41\x32\x6a\xe1\xf4\x7c\x98\x7b\x6a\x72\x15\x12\x24\xaf\x14\xd8\x06\x37\x8d\x79\x23\x6f\xc8\x16\x05\x73\x88\xc3\xd8\x71\xbd\x02\x21\xfd\x5a\xd9\x9c\x67\x46\xa8\xf8\xa8\x6c\xbb\x32\xd6\x10\x32\xec\x0c\x46\xd3\x70\x7a\x69\xbd\xbe\xf0\x8a\x57\xce\xd7\x5a\x1e\xd5\x66\x6c\xe5\x5d\x7e\xf7\xf7\x05\x67\xe2\x25\xfb\x25\x49\x9f\xba\x83\x14\x71\xe0\x7f\x73\xaf\x08\xe9\x8d\xa6\xe9\x7c\x9c\x04\x32\xde\xa8\x76\xe5\x86\xde\xd2\x63\xf3\x70\x89\xcb\xba\xb4\xee\x1e\x1c\xc6\x82\xa9\x31\x74\x11\x3a\x0e\xbb\x21\x9f\xb1\xb5\xca\x0a\xba\xf8\x05\x98\x1a\x57\xf5\x0d\x02\x00\x00")
+
+func pluginsTemplatesGoDockerfileBytes() ([]byte, error) {
+	return bindataRead(
+		_pluginsTemplatesGoDockerfile,
+		"plugins/templates/go/Dockerfile",
+	)
+}
+
+func pluginsTemplatesGoDockerfile() (*asset, error) {
+	bytes, err := pluginsTemplatesGoDockerfileBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "plugins/templates/go/Dockerfile", size: 525, mode: os.FileMode(436), modTime: time.Unix(1792338564, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _pluginsTemplatesGoLicenseMd = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x5d\x51\x4f\x6f\x9b\x30\x14\xbf\xf3\x29\x9e\x72\x6a\x25\xd4\xdd\x77\x73\xc0\x34\xd6\x08\x46\xc6\x69\x96\xd3\x44\xc0\x09\x9e\x08\x8e\x6c\xb3\x28\xaa\xfa\xdd\xf7\xec\xa4\x6b\x3b\x09\x09\x3d\xfb\xfd\xfe\x3a\x33\xe7\xab\xd5\xc7\xc1\xc3\x43\xf7\x08\xaf\xaf\xd0\xcd\xd6\xaa\xc9\xff\xba\xaa\xd6\xc2\xdb\x5b\x3c\xb2\xaa\xf5\x26\x4c\x49\x52\x2b\x7b\xd2\xce\x69\x33\x81\x76\x30\x28\xab\xf6\x57\x38\xda\x76\xf2\xaa\x4f\xe1\x60\x95\x02\x73\x80\x6e\x68\xed\x51\xa5\xe0\x0d\xb4\xd3\x15\xce\xca\x3a\x04\x98\xbd\x6f\xf5\xa4\xa7\x63\xd2\x42\x87\xba\x61\xd3\x0f\x48\xe3\xcc\xc1\x5f\x5a\xab\x70\xb9\x87\xd6\x39\xd3\xe9\x16\xf9\xa0\x37\xdd\x7c\x42\x33\xad\x0f\x7a\x07\x3d\x2a\x07\x0f\x7e\x50\xc9\xa2\xb9\x23\x16\x8f\x51\xa4\x57\xed\x08\x7a\x42\x36\x05\xef\x57\x70\xd1\x7e\x30\xb3\x07\xab\x9c\xb7\xba\x0b\x1c\x29\x2e\x75\xe3\xdc\x07\x0f\xef\xd7\xa3\x3e\xe9\xbb\x42\x80\xc7\x32\x5c\x20\x9d\x1d\x26\x08\x3e\x53\x38\x99\x5e\x1f\xc2\x5f\xc5\x58\xe7\x79\x3f\x6a\x37\xa4\x49\xaf\x03\xf5\x7e\xf6\x78\xe8\xc2\x61\xa7\xa6\x80\xc2\x1c\xdf\xb0\x30\xa7\xc6\x31\x30\x68\xf4\x1d\xb3\x7e\xb8\x8b\x3b\xa8\x92\x9c\x43\xa1\xfe\x5e\x51\xd4\xbd\x0c\xe6\xf4\x35\x09\x56\x74\x98\xed\x84\x92\xaa\x8f\x71\x0d\x56\x16\x15\x7f\xab\xce\x07\x96\xb0\x7e\x30\xe3\x68\x2e\x18\x0d\x25\xa7\x5e\x87\x44\xee\x7b\x92\x48\xbc\x6a\xf7\xe6\x8f\x8a\x59\x6e\x6f\x3d\x19\x8f\x56\x6f\x16\xc2\x03\x9c\x3f\x5e\xf5\x7e\xe5\x86\x16\xbd\xef\x55\x72\x2b\x0c\x75\xb1\xde\xf6\x53\x1c\x1b\xe4\x9d\xc7\x87\xd7\xd8\xfd\xd9\xd8\xa8\xf7\x7f\xcc\x27\xd4\x5f\x51\x68\x78\x21\xb7\x44\x50\x60\x0d\xd4\x82\xbf\xb0\x9c\xe6\xb0\x20\x0d\xce\x8b\x14\xb6\x4c\xae\xf8\x46\x02\x6e\x08\x52\xc9\x1d\xf0\x02\x48\xb5\x83\x1f\xac\xca\xd3\x84\xfe\xac\x05\x6d\x1a\xe0\x02\xd8\xba\x2e\x19\xcd\x53\x60\x55\x56\x6e\x72\x56\x3d\xc3\x12\x71\x15\x97\x50\xb2\x35\x93\x48\x2a\x39\x04\xc1\x3b\x15\xa3\x88\x2b\x92\x35\x15\xd9\x0a\x47\xb2\x64\x25\x93\xbb\x14\x0a\x26\xab\xc0\x59\x20\x29\x81\x9a\x08\xc9\xb2\x4d\x49\x04\xd4\x1b\x51\xf3\x86\xa2\x7c\x9e\x54\xbc\x62\x55\x21\x50\x85\xae\x69\x25\x9f\x50\x15\xa5\x80\xbe\xe0\x00\xcd\x8a\x94\x65\x94\x22\x1b\x74\x2f\xa2\xbf\x8c\xd7\x3b\xc1\x9e\x57\x12\x56\xbc\xcc\x29\x1e\x2e\x69\x52\x32\xb2\x2c\xe9\x4d\x0a\x43\x65\x25\x61\xeb\x14\x72\xb2\x26\xcf\x34\xa2\x38\xb2\x08\x08\x6b\x77\x77\xdb\x15\x8d\x47\xa8\x47\xf0\xcb\x24\xe3\x55\x82\x9d\x64\xbc\x92\x02\xc7\x14\x53\x0a\xf9\x0f\xba\x65\x0d\x4d\x81\x08\xd6\x84\x42\x0a\xc1\x91\x3e\xd4\x89\x08\x1e\x49\x10\x57\xd1\x1b\x4b\xa8\x1a\xbe\xbc\x08\xae\x84\x79\xd3\xd0\x0f\x2f\x39\x25\x25\x72\x35\x01\xfc\x79\xf9\x29\xf9\x0b\xe4\x3f\x0e\xa2\x2f\x04\x00\x00")
+
+func pluginsTemplatesGoLicenseMdBytes() ([]byte, error) {
+	return bindataRead(
+		_pluginsTemplatesGoLicenseMd,
+		"plugins/templates/go/LICENSE.md",
+	)
+}
+
+func pluginsTemplatesGoLicenseMd() (*asset, error) {
+	bytes, err := pluginsTemplatesGoLicenseMdBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "plugins/templates/go/LICENSE.md", size: 1071, mode: os.FileMode(509), modTime: time.Unix(1792338564, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _pluginsTemplatesGoReadmeShortTxt = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xab\xae\x56\x28\xc8\x29\x4d\xcf\xcc\x8b\x4f\x49\x2d\x4e\x2e\xca\x2c\x28\xc9\xcc\xcf\x53\xa8\xad\x05\x00\xf7\x36\xbe\xfa\x18\x00\x00\x00")
+
+func pluginsTemplatesGoReadmeShortTxtBytes() ([]byte, error) {
+	return bindataRead(
+		_pluginsTemplatesGoReadmeShortTxt,
+		"plugins/templates/go/README-short.txt",
+	)
+}
+
+func pluginsTemplatesGoReadmeShortTxt() (*asset, error) {
+	bytes, err := pluginsTemplatesGoReadmeShortTxtBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "plugins/templates/go/README-short.txt", size: 24, mode: os.FileMode(436), modTime: time.Unix(1792338564, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _pluginsTemplatesGoReadmeMd = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x4d\x8c\xbb\x0e\xc2\x20\x18\x46\x77\x9e\x02\xe3\xa2\x03\x50\xda\xf4\x82\x9b\x0f\x60\xe2\x6e\x8c\xe1\xf2\xd3\x92\xb4\xd0\x14\x18\x4c\xd3\x77\xb7\x31\x0e\xae\xe7\x9c\xef\x3b\xe2\x75\xc5\xf3\x98\x7b\xe7\x5f\x5e\x4e\x80\xb7\x0d\xa1\x9b\x1c\x9d\x86\x3f\x63\x20\xea\xc5\xcd\xc9\x05\xbf\x07\xf8\xfe\xa5\x14\xa1\xc3\xe3\x8a\x77\x03\xe0\xe3\x10\x12\x0e\x16\xbf\x43\x5e\x7e\xab\xe7\x69\x48\x69\x8e\x17\xc6\x2c\xd5\x63\xc8\x86\xf6\x2e\x0d\x59\x51\x1d\x26\x26\x63\x84\x14\x59\x23\x78\x23\x58\x59\x8a\xa2\xac\x0b\xa6\xab\xda\x74\x4d\x2b\x89\x2c\x78\x4b\x38\x87\x8a\x74\x8d\x02\xa2\x4d\xab\x6b\x65\x2b\x6b\x85\xda\x4f\xec\x19\x7d\x00\x6d\x30\xd9\xc7\xb7\x00\x00\x00")
+
+func pluginsTemplatesGoReadmeMdBytes() ([]byte, error) {
+	return bindataRead(
+		_pluginsTemplatesGoReadmeMd,
+		"plugins/templates/go/README.md",
+	)
+}
+
+func pluginsTemplatesGoReadmeMd() (*asset, error) {
+	bytes, err := pluginsTemplatesGoReadmeMdBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "plugins/templates/go/README.md", size: 183, mode: os.FileMode(509), modTime: time.Unix(1792338564, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _pluginsTemplatesGoVersion = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xab\xae\x56\x28\xc8\x29\x4d\xcf\xcc\x8b\x2f\x4b\x2d\x2a\xce\xcc\xcf\x53\xa8\xad\xe5\x02\x00\x3d\x83\xf7\xbd\x15\x00\x00\x00")
+
+func pluginsTemplatesGoVersionBytes() ([]byte, error) {
+	return bindataRead(
+		_pluginsTemplatesGoVersion,
+		"plugins/templates/go/VERSION",
+	)
+}
+
+func pluginsTemplatesGoVersion() (*asset, error) {
+	bytes, err := pluginsTemplatesGoVersionBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "plugins/templates/go/VERSION", size: 21, mode: os.FileMode(420), modTime: time.Unix(1792338564, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _pluginsTemplatesGoCircleYml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x7d\x90\xcd\x6e\xc2\x30\x10\x84\xef\x79\x8a\x15\xed\xa1\x3d\x84\xdc\x13\xa9\x2f\x82\x10\x5a\xec\x4d\x58\xc5\x3f\xd1\xda\x89\x54\x21\xfa\xec\x75\x82\x43\x41\xa2\xdc\x56\xe3\xfd\x66\xc6\x6b\x51\x9d\xd8\x51\x5d\x00\x04\x92\x89\x15\x85\x79\x06\x28\x41\x7b\xd5\x93\x14\x85\xa6\x81\x9c\x26\xa7\xf8\xfa\xa6\x12\x42\x07\xcd\x42\x2a\x7a\xe1\x3f\x60\xf3\x53\x5d\x99\x4d\x12\xfc\x44\x22\xac\xe9\xd1\x0d\xd8\xb5\x3e\x2b\xdc\xc2\x6e\x07\x25\xc1\x8a\x55\x6c\xb1\xa3\x6d\x44\x81\xfd\xbe\x81\x78\x22\xb7\x62\xc6\xa3\x86\xb2\x64\x37\x8c\xf1\xc9\x7e\x03\x2d\x3f\xe6\x1c\x47\x36\x89\x88\xa0\x58\x94\x21\xc5\xd5\xf9\x0c\x83\x19\x3b\x76\x07\x87\x96\xe0\x72\x81\x6d\x46\x6c\x9f\x3e\x03\xe5\x70\x33\x6e\x56\x97\x80\x13\xbd\x72\xf8\x7a\xd2\xa5\x28\x22\x85\x58\xff\x7f\x02\x19\xdd\x2b\xcf\x99\x5e\x8e\x6e\xfc\xb7\x25\xb7\x38\xbd\x81\xc5\x10\x49\xae\x33\xc0\x51\xd0\xa9\x53\x9d\xd5\x2c\x2a\x6f\x2d\x3a\x1d\xd6\xa5\x39\x92\x26\x34\xf0\xfe\x71\x97\xdc\x99\xd4\x48\x0c\x1e\x43\x35\xa4\xc6\xcb\x9d\xd2\xd3\x5a\xa8\x54\x56\x7f\x26\x03\x21\x43\x18\x72\xf5\x35\x2e\x8b\x8b\x76\x9f\x96\xcf\x88\x3d\xdd\x56\x7e\x01\x5b\x80\xf9\x05\x58\x02\x00\x00")
+
+func pluginsTemplatesGoCircleYmlBytes() ([]byte, error) {
+	return bindataRead(
+		_pluginsTemplatesGoCircleYml,
+		"plugins/templates/go/circle.yml",
+	)
+}
+
+func pluginsTemplatesGoCircleYml() (*asset, error) {
+	bytes, err := pluginsTemplatesGoCircleYmlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "plugins/templates/go/circle.yml", size: 600, mode: os.FileMode(436), modTime: time.Unix(1792338564, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _pluginsTemplatesGoGoMod = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xcb\xcd\x4f\x29\xcd\x49\x55\xa8\xae\x56\x28\xc8\x29\x4d\xcf\xcc\x8b\x2f\x4a\x2d\xc8\x57\xa8\xad\xe5\xe2\x4a\xcf\x57\x30\xd4\x33\x32\xe4\x02\x00\x3d\x94\x7c\x94\x22\x00\x00\x00")
+
+func pluginsTemplatesGoGoModBytes() ([]byte, error) {
+	return bindataRead(
+		_pluginsTemplatesGoGoMod,
+		"plugins/templates/go/go.mod",
+	)
+}
+
+func pluginsTemplatesGoGoMod() (*asset, error) {
+	bytes, err := pluginsTemplatesGoGoModBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "plugins/templates/go/go.mod", size: 34, mode: os.FileMode(420), modTime: time.Unix(1792338564, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _pluginsTemplatesGoPluginToml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xad\x92\x4d\x6b\xc2\x40\x10\x86\xef\xfe\x8a\x61\x73\x15\xa1\x05\x7b\xeb\x41\xa4\x14\xc1\x16\x2f\x3d\x05\x09\x6b\x32\x49\x96\xee\x17\xbb\x1b\x8b\x88\xff\xbd\xfb\x91\x58\x8d\xa5\x50\x30\x97\x85\xf7\x7d\x32\xfb\xce\xec\x64\xd9\x5d\xbe\x49\x06\x9b\xf5\xc7\xeb\xea\x1d\x96\x4a\xd6\xac\xe9\x0c\x75\x4c\x49\xf8\x7f\x9d\x3b\xe5\x99\xe4\xb9\xe6\x5d\xc3\xe4\x76\x3b\x01\x90\x54\x20\x3c\x03\x39\x1e\x21\xa9\x45\x54\x4e\x27\xe2\xcd\x3d\x1a\x1b\xb2\x5e\xf9\x83\x98\x90\x0a\x6d\x69\x98\x76\x37\xd8\xa5\x91\xd0\x92\x3a\x6c\x94\x39\x5c\x73\x67\x35\x41\x9f\x78\xf8\x52\xa6\xb2\x1e\xca\x2f\xa8\xb3\xec\xa9\x10\x9b\x09\xda\x8c\x72\x27\x29\x55\x31\xa8\x95\x65\xee\xe6\xb2\x0b\x3d\x81\x9c\x95\x28\xed\xa8\xd2\x20\x26\x04\xa5\xd7\xd0\xf6\x88\xa0\xc1\x2d\x7a\xb1\xb0\x9d\xd6\xca\x38\xac\x7a\x78\xd7\x31\x5e\x79\xb4\xa6\xdc\x62\xe8\x59\x54\xa3\x76\xc5\x80\x0a\x36\x9e\x7c\x54\x92\xd9\x52\xdb\xba\x83\x8e\xd7\xe6\x40\x44\x35\x27\x53\x20\xb6\xa5\x0f\xfd\xf9\x38\x7f\x22\xb0\x8d\xf1\xf6\x71\x56\x6f\x8b\xf5\x6a\xf9\x52\xfc\x54\xf3\x46\xb1\xa7\x66\x98\x18\x4a\xba\xe3\x18\xd2\x38\xd3\x85\x68\xfd\x16\xcc\x34\xd3\x3c\xf4\x12\x20\x00\xa7\xae\x33\x79\xd7\xb7\xe9\xd5\x94\x0b\xa0\x36\x4a\xfc\x86\x44\x3d\x41\x19\x2c\x36\xab\xf0\x92\x16\xa8\x41\xff\x18\xb4\x4a\xbf\xb9\x16\xc1\x62\x69\xd0\x81\xf5\x8f\x80\x53\x90\xe8\xd7\x69\x30\x99\x85\x9a\x71\x8c\x15\x86\x25\x9d\x25\x3e\xee\x6a\x06\x7f\xec\x6b\x41\x35\x23\x3d\x94\x66\xf2\xe7\x48\xbe\x01\xfa\xe1\x00\xe4\xde\x03\x00\x00")
+
+func pluginsTemplatesGoPluginTomlBytes() ([]byte, error) {
+	return bindataRead(
+		_pluginsTemplatesGoPluginToml,
+		"plugins/templates/go/plugin.toml",
+	)
+}
+
+func pluginsTemplatesGoPluginToml() (*asset, error) {
+	bytes, err := pluginsTemplatesGoPluginTomlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "plugins/templates/go/plugin.toml", size: 990, mode: os.FileMode(436), modTime: time.Unix(1792338564, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _pluginsTemplatesGoScanGo = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x95\x57\x6d\x6f\xe3\x36\x0c\xfe\x9c\xfc\x0a\xcd\xc3\x6e\xf6\x96\x3a\x5d\x81\xdd\x87\x0c\xf9\x90\x6b\xd2\x5d\x86\xbe\xa1\xe9\xf5\x36\xf4\x8a\x9e\xe2\x28\x89\x56\x47\x36\x2c\xb9\x2f\x68\xf3\xdf\x47\x52\x92\x63\xa7\x39\x5c\xd7\x0f\x8d\x2d\x91\x14\xf9\x88\x7c\x48\xe7\x3c\xb9\xe3\x0b\xc1\x56\x5c\xaa\x76\x5b\xae\xf2\xac\x30\x2c\x6c\xb7\x02\xa1\x92\x6c\x26\xd5\xa2\xfb\xaf\xce\x54\x00\x0b\xf3\x95\xc1\x9f\x4c\xdb\xff\x5d\xf1\x28\x12\x7c\xd4\xa0\x41\xbf\xa6\x00\x71\xda\x35\x72\x25\x82\x36\x3c\x2c\xa4\x59\x96\xd3\x38\xc9\x56\xdd\x39\x37\x72\xd9\x05\xa1\x32\x31\x24\x54\xdb\x5b\xf1\x54\x26\x62\x2f\x4f\xcb\x85\x54\xba\x9b\xdf\x2d\x74\x77\xc6\x0d\x9f\x72\x2d\xfe\x87\x68\x57\xa4\x5c\x1b\x99\x68\xc1\x8b\x64\xf9\x06\xc5\xd2\xc8\x14\x7d\x49\xb3\x05\xab\xcb\x6a\x59\x94\xb9\x16\xaa\x0b\x1b\x45\xb9\xed\x6d\x59\xcc\xf9\xbd\xe8\x26\xa9\xec\xde\x1f\x04\xed\xa8\xdd\xee\x76\xd9\x95\x28\xb4\xcc\x14\xd3\x26\x2b\x84\x66\x66\x29\x98\x3d\xea\x67\xcd\xee\xed\x5e\xfb\x9e\x17\x35\x39\x44\x8b\x54\x3f\x94\x32\x9d\x5d\x02\x64\x3b\x95\xa7\xb8\xcb\x10\x51\xd2\xaf\x0b\x5b\x0b\x49\xa6\x34\x5d\x99\xe2\xb0\x8a\x7f\x7d\x16\x3c\x3f\x3b\x0b\xb7\xb4\xba\x5e\x43\x0c\x09\x37\x62\x91\x15\x4f\xcd\xfd\x6a\x15\x65\x6c\x2c\x76\xe7\x42\xe8\x32\x35\x9a\x49\xeb\xd0\x2c\x4b\xca\x95\x50\x86\x59\x20\x99\x78\xcc\x05\xdc\x24\xa3\x58\x66\x59\x69\x3a\xec\x4e\x3c\x89\x19\x9b\x3e\x39\x03\x0c\x8f\x6e\x9b\xa7\x5c\x6c\x59\xb4\x49\xc0\x9e\xdb\xad\xf1\x90\x1c\x76\xb1\xd8\xe7\xaf\x98\x70\xbd\x40\xce\x02\x27\xa8\xf1\xa5\x93\xad\xa4\x11\xab\xdc\x3c\x05\x5f\xdb\x2d\x6f\xa9\xb0\xbf\x43\x48\x00\xaf\xf7\x3a\xf2\x8d\x99\xd7\x7b\x5f\xdb\x6b\x0a\xb9\x6e\x68\x99\xa5\x33\x1b\xf3\x5c\x0a\x7c\xcc\xe6\xf4\xb6\x13\x33\xa7\xc9\x74\xb2\x14\x2b\x6e\xa3\xad\x1b\xab\x62\xdd\x68\xbb\xed\x5b\x67\x7d\xbd\x46\x1f\xe6\xa5\x4a\x58\x0e\x28\x98\x13\x5e\xdc\x0d\xb3\x07\x75\xc9\xa7\xa9\x08\x9d\x70\x13\xc1\x08\xb1\x73\xea\xbd\xbe\x8f\x2f\x3e\xe1\xb9\x97\x8f\xbd\x64\xbb\x05\xb7\x42\x52\x2b\x7e\x27\xc2\xeb\x1b\x0b\x75\x87\xed\x77\x58\x2a\x54\x68\xad\x44\x20\x37\xcf\x0a\xbc\x41\x14\x2d\xb8\x5a\x54\xd1\xc3\x51\xd6\x46\x9f\xf1\x3c\x17\x6a\x16\xe2\x1b\xdd\x36\x68\xad\xdb\x2d\x24\x80\x78\x62\xab\x9f\xf6\x20\x8b\x5a\xc0\x16\xf1\x39\x86\x33\x0f\x83\x1f\xe1\x8f\xfd\xa4\xbf\xa8\x2f\x2a\xe8\x50\x56\x44\x4d\x81\x17\xd8\x65\x2f\xb4\xeb\x58\x24\xfe\x2b\x93\xca\x1d\x14\xb0\x17\x16\x44\xdb\x2a\x68\xaf\x26\x7f\x21\x72\xc1\x4d\x18\xb0\xbd\xbd\x3d\xf6\x12\xd8\xe0\xc8\x19\xd4\xbc\xe7\x69\x29\x76\xa1\xb0\x91\xb2\x00\xc8\x4e\x13\x03\x0a\x1c\x11\xb0\x16\xae\xe5\x0d\xc0\x80\x7e\x4c\xe8\xae\x1c\x7c\xd7\x20\x76\x63\xc1\x78\x4b\x58\xd6\xd6\x26\x30\x9b\x82\x39\x2f\xb4\x38\xa7\x6b\x3e\x2b\x4d\x5e\x1a\x66\xca\x42\xd9\x44\xd4\x09\x57\x4a\x14\x40\x07\x99\xdd\x82\x03\xb2\x26\x4f\xb8\x8b\x77\x89\xb4\x6d\x2b\x74\x7a\xd6\x8f\xa8\x91\xa2\x10\x9e\xcf\x32\x88\xfb\x75\x9e\x4a\x25\x0d\x66\xa9\x45\xe8\x16\x40\x93\x4a\x6c\x20\xf2\xa1\x4d\xf2\x54\xfa\x73\x20\x36\x08\x9b\xd2\xb4\x25\xe7\x84\xb2\x17\xbb\x2c\xe4\x6a\x92\xf3\x44\x84\x68\x26\x8a\x58\xbf\xcf\xf6\x49\xb0\x05\x4c\x66\xa4\x2a\x05\x3c\x03\x92\x2d\xc0\xe4\xf2\x6c\x78\xd6\x83\x44\x4c\x53\xef\x31\x9b\x17\xd9\x6a\x27\x26\x08\x3f\x46\x82\xa8\x55\x68\x58\x68\x51\xf4\x48\xa6\x56\x47\xa3\x3d\x61\x71\xf2\x1b\x61\xce\xcd\xb2\x02\x27\x6c\x14\x5b\x87\x89\xa2\xc8\x0a\x0a\xa6\x72\xa9\x10\x79\x0a\x31\xb0\x07\x68\x0b\xe4\x0d\x34\x86\x15\x57\x40\xd4\x4b\x6e\xdc\x31\x96\x41\xe0\xa8\x96\x07\x05\x0c\x21\x6e\xd8\x39\xe3\x43\xab\x10\x06\xdd\xa9\x54\x5d\xa8\x60\x01\x69\x82\x6e\x44\xb8\x05\x6b\x62\xe6\xae\x0e\x12\x0b\x30\x44\xdd\x1f\xfa\x4c\xc9\x94\xc0\x72\x71\x36\x3c\x7d\x5e\x77\x28\x37\x47\xe8\x2f\xe4\x1f\xba\xc1\xe6\x1c\x5c\x98\xf5\x20\x15\x03\x72\xc0\xa6\xe9\x4e\x75\xf7\xdb\xdb\x91\x3e\x16\x1a\x77\xbb\x51\x04\x07\x81\x23\x15\x6f\xe1\xf0\x10\x12\x40\xc0\x10\x18\x21\xf4\xc6\xf8\x54\x3c\x0c\xf2\x1c\xbd\x87\xc5\xf8\x14\x09\xb7\x6f\x5b\x02\x2d\x0c\x4a\xb3\xcc\x0a\x64\x95\xeb\x9b\x5f\x50\xde\x2e\x3c\x3f\xa3\x64\x8f\x3a\x14\xa7\x15\xa4\xe9\x0e\x1b\xc1\x19\xa9\x5d\x16\xf8\x88\xab\x90\x93\x64\xca\xf7\xd4\x7e\xd5\x5d\x7f\x65\xa0\x52\xb5\x4a\x50\x83\x95\xea\xd5\x2a\x01\xc8\x39\x22\xd3\x61\xb7\xa0\x88\xfd\x35\x3e\xc7\xb0\xc3\xe0\x60\x7f\xff\xfd\xfe\x6f\xfb\x07\x75\x13\x2e\x8a\x4f\x1a\x67\x25\xdb\x3f\x4b\x7a\xa6\xce\x8a\x8d\xd9\x20\x5d\xb3\x69\x96\xa5\xf6\xbd\x31\x8b\x7c\xba\x38\xf6\xfd\x9a\xec\x1c\xa5\x7c\x61\x43\xc7\xc8\xf1\x0d\xef\xf4\x1d\xbe\x7c\x00\x0b\x7e\xa1\x65\xb1\x80\xbf\x00\x66\x88\x69\x06\xd3\x50\x07\x97\x07\xa9\x84\x71\x07\x2e\xca\x13\xd9\x73\x70\x15\xac\x69\x8b\x3c\xec\xd5\x34\x5c\x75\x90\x22\x89\xd0\x29\x96\xae\x5f\x9f\x43\x67\x35\xc7\x28\x32\x7b\x85\x94\xe5\x25\x82\xa0\x79\xd4\x6b\x25\x56\x16\x29\x43\xb6\x38\xb1\xc3\x02\x90\x15\xcd\x36\xbe\x2e\xad\x81\x91\xba\xbf\x02\xc8\xad\x89\x4d\x28\x27\x83\xe3\xf1\xe1\xe8\x76\x74\x3c\x98\x5c\x8e\x0f\x27\xa3\xc1\xc5\xe1\xc7\x5b\x40\xd0\x45\x38\x14\x70\x8e\x82\x69\x12\xba\x3d\x7b\xb7\x0d\x73\x33\xcc\xdd\x60\x92\xbf\x74\x5f\x5b\x70\x36\xfd\x30\xdb\x90\x92\x9e\x23\x52\xae\x19\x36\xea\x19\x34\x6a\x56\x33\xd5\x74\x8e\x36\xbc\x47\x2e\x59\x07\xc5\x42\x57\x69\x74\x34\x3e\x1e\x11\x38\x58\xa9\xc4\x25\x98\xa9\xb5\x22\x49\x8c\x4d\x6c\x2c\xb3\x30\x61\x54\x28\x87\x40\x93\xe2\xd1\x44\x96\x97\x3c\xc7\x26\x64\x39\x8c\xe2\x63\x81\xb5\xb8\x61\x55\x57\xea\x75\x62\xc8\x53\x01\x01\x33\x5d\xe6\x79\xfa\xc4\x38\xb1\x54\xd3\x0d\x62\x0b\xd7\xa9\x89\x8c\x89\x20\xb1\xb2\xfd\x31\x47\xb2\xd0\xc4\x4d\x78\xf8\x6d\xc5\x6d\x19\x34\x02\x03\x8d\x98\x98\xec\x8f\x6d\xd6\xf2\xce\xc0\x32\xd9\xf5\xae\xe3\x3d\x85\x55\x8e\xdb\xbe\x81\x53\x79\x3c\x11\xe6\x58\xdc\x8b\x34\xc4\x97\xa1\x98\x96\x0b\x7a\x8d\xbc\x76\x51\xe3\x68\x1a\x85\xea\x84\xee\x9c\xfb\x8e\x0f\x95\x91\x18\xa6\xd1\x3e\xa3\x6f\x82\xf8\x4f\x61\xb2\x1c\xc6\x09\x97\x8b\x93\xc3\xc1\xe9\x78\x08\x90\x54\xbb\x93\x8f\x83\x83\xdf\xdf\xdb\x63\x70\xde\xc1\xd6\x80\xdf\x0c\x85\xeb\xcd\x43\xf7\x4d\xe2\x5c\xd8\x26\x03\xf0\x27\x08\xac\x3b\x76\x2e\x69\x48\xc4\x5e\x9b\x04\x5a\x63\x35\x13\x8f\x94\x80\x3b\x9d\x6b\x16\xca\xf8\x74\x38\xfa\x1b\x3c\x0d\xec\x9c\x1e\x44\x94\x97\xad\x4b\x18\x4c\x6d\x0e\xbf\xc1\xc6\xe5\x3f\xe7\x23\x34\xa1\xf9\x0a\x52\x45\x7b\x1b\xe0\xb8\x2b\x83\x5d\x55\x87\x85\x22\x0a\x45\x45\x96\xd1\x01\x42\xdd\x7f\xe3\x80\x4f\x93\xd1\xc5\xe9\xe0\x64\xe4\x2d\x9f\x73\xad\x1f\xb2\x62\xf6\x7d\xcd\xf3\xc1\x64\xf2\xf9\xec\x62\xe8\x34\xd7\x16\xc1\x78\x0c\x33\x0a\x25\x63\xcb\xf7\x58\xcc\x43\x60\x9c\xf3\x7a\x87\x0b\xfd\x97\x62\xdc\x58\x76\x28\x0f\x7d\x8d\x6f\xf2\xc1\x7a\xb7\x21\x0e\x8c\xce\xae\x1d\xba\x4f\x80\x1e\xf3\x1f\x03\x76\x1d\x6f\xae\xe7\xbf\x69\xbe\x39\x95\x5b\xdf\xc9\xdf\x1d\x09\x4a\x99\xff\x19\xca\x90\xea\x35\xc4\xa6\x6d\x4b\x37\x0c\x6c\x37\x7f\xcd\xa7\x91\x07\xc3\xd7\x94\x6d\x47\x64\xee\xdb\x5f\x15\x51\xad\x1c\xb0\x9f\x5b\x13\x59\x6d\x54\xc1\x6f\x2a\x88\xa0\xd0\x4b\x9e\xd6\xb5\xde\x52\x56\xd5\x28\x9c\xaa\xda\xfc\x80\x93\x76\xe3\x4c\xf4\xd8\x99\xeb\xd1\x27\x46\x7c\x51\xaa\x10\xf2\x00\x89\xe6\x35\x85\x20\x36\x47\x00\x72\x1a\xfa\x61\x66\xdd\xfe\x0f\x7f\x77\x16\xe7\xc5\x10\x00\x00")
+
+func pluginsTemplatesGoScanGoBytes() ([]byte, error) {
+	return bindataRead(
+		_pluginsTemplatesGoScanGo,
+		"plugins/templates/go/scan.go",
+	)
+}
+
+func pluginsTemplatesGoScanGo() (*asset, error) {
+	bytes, err := pluginsTemplatesGoScanGoBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "plugins/templates/go/scan.go", size: 4293, mode: os.FileMode(436), modTime: time.Unix(1792343820, 0)}
 	a := &asset{bytes: bytes, info: info}
 	return a, nil
 }
@@ -159,7 +411,7 @@ func pluginsTemplatesPythonChangelogMd() (*asset, error) {
 	return a, nil
 }
 
-var _pluginsTemplatesPythonDockerfile = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x7c\x90\x41\xab\xe2\x30\x14\x85\xf7\xf9\x15\x97\x2e\x5c\x0c\xa4\xdd\x0f\xb8\xe8\xb4\x1d\x0c\x8e\x6d\x89\xce\x0c\xe2\x48\x89\xed\xb5\x06\xd3\x34\x93\xa6\xa8\x14\xff\xfb\x50\xe7\x55\x1e\x3c\x79\xbb\xe4\xdc\xef\x72\xcf\x39\xdf\x79\xb6\x82\x46\x28\x59\x62\x20\x94\x91\x1a\xbf\x3a\xa9\x25\x21\xab\x90\xa5\x9b\x90\xa5\x09\x87\x61\x80\xd2\xa2\x70\xad\x85\xfb\x9d\x90\x28\xcb\xb7\xe0\x43\xe0\x1a\x13\x74\xb6\x0c\x86\x01\x8c\xea\x6b\xa9\x0b\x2d\x1a\x1c\x11\xfe\x33\x05\x61\xce\x54\xea\xce\x09\xa5\xc0\xdc\xdc\xa9\xd5\x1f\x64\xea\xc0\x3f\xf4\x52\x55\xb4\x42\xd3\x41\x2d\x1d\x34\x68\xcb\xde\x4a\x31\xee\x50\x23\x0d\xfc\x21\x00\xb3\x19\x74\xe8\x80\x5e\xa7\x5f\x59\x7d\x72\x7d\x82\xf0\x6a\x5a\xeb\x20\x67\x79\x91\x66\x45\x14\x46\x8b\xa4\x88\x19\x9f\xb7\xc7\xe3\x0b\x24\x66\xeb\xf0\xdb\x8f\xa4\x18\xdf\xbf\x12\xbe\x66\x59\x5a\x44\x8b\x24\x5a\xce\x5b\x3d\xe1\xa3\x9f\xa7\x77\xda\x9b\xda\x8a\x0a\x1f\xea\xe5\x84\xa8\x5e\x62\x16\x2c\xfe\xed\xa5\xc5\x06\xb5\xeb\x7c\x77\x75\x4f\xec\x51\xca\x98\xac\x37\xbe\xb9\x3d\x57\xde\xc6\xb6\x01\x6a\x8f\xff\x73\x7e\x99\x44\x61\xce\x50\xe1\x78\xdd\xf4\xb6\xc6\xf7\xf5\x11\xf2\x3b\xe3\xcb\x98\x71\x08\x1a\xa1\x2e\xc2\x22\x21\x49\xba\xe1\xdb\x3c\x63\xe9\x06\x76\x5e\x70\x90\x3a\xe8\x4a\xa1\xbd\x3d\x21\xd1\x2a\x86\x9d\x47\xe9\x09\x95\xf1\xf6\xff\x02\x00\x00\xff\xff\x22\x74\x40\xdc\x05\x02\x00\x00")
+var _pluginsTemplatesPythonDockerfile = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x7d\x91\x5d\x6b\xc2\x30\x14\x86\xef\xf3\x2b\x0e\x5e\x78\x31\x48\x33\x10\x19\x0c\xbc\x70\xb5\x63\xc5\xd9\x96\xe8\x36\x64\x93\x12\xdb\xa3\x86\xa5\x69\x96\xa6\x4c\x11\xff\xfb\x22\xa3\x32\xd8\xd8\x45\x20\xe7\xcd\x73\x72\x3e\xde\x7b\x9e\xce\xa0\x12\x4a\x16\xc8\x84\x32\x52\xe3\xad\x93\x5a\x12\x32\x1b\xc7\xc9\xc2\x9f\x88\xc3\xf1\x08\x85\x45\xe1\x6a\x0b\xa7\x13\x21\x61\x9a\x2d\x21\x00\xe6\x2a\xc3\x1a\x5b\x30\xff\x6c\x54\xbb\x95\x3a\xd7\xa2\xc2\x33\xc2\x9f\x12\x10\xe6\x9d\x4a\xdd\x38\xa1\x14\x98\x83\xdb\xd5\x7a\xf0\x4b\xa7\x0e\x82\x75\x2b\x55\x49\x4b\x34\x0d\x6c\xa5\xf3\xe8\x80\x1a\x69\xe0\x8d\x00\xf4\xfb\xd0\xa0\x03\xba\xef\xa2\xa2\xfc\xa7\x6a\x07\xe1\xde\xd4\xd6\x41\x16\x67\x79\x92\xe6\xe1\x38\x7c\x88\xf2\x49\xcc\x47\xf5\x66\xf3\x07\x32\x89\xe7\xe3\xbb\xc7\x28\x3f\xdf\x9f\x23\x3e\x8f\xd3\x24\xf7\x19\xe1\x74\x54\xeb\x0e\xf7\xfd\x0c\xe0\xd2\xb3\x05\x8b\x1f\xad\xb4\x58\xa1\x76\x4d\xe0\xf6\xae\xe3\x2e\x48\x05\xd7\x37\xc3\x21\x34\x85\xd0\x81\x39\x00\x5b\x4b\xcd\xce\x41\x07\xda\xca\x7f\xb3\xf9\x9e\xe5\xaa\x13\xfd\x62\xa0\x44\x9f\x4d\x4d\x6b\xb7\xf8\x73\x33\x84\xbc\xa4\x7c\xea\x87\x00\xe6\xad\xfa\x14\x16\x09\x89\x92\x05\x5f\x66\xa9\x37\x09\x5e\x7b\x97\x0a\xbd\x95\xf7\x67\x36\xf1\x12\xa5\x3b\x54\xa6\xb7\xfa\x02\x0f\x8e\x2a\x4a\xe1\x01\x00\x00")
 
 func pluginsTemplatesPythonDockerfileBytes() ([]byte, error) {
 	return bindataRead(
@@ -174,7 +426,7 @@ func pluginsTemplatesPythonDockerfile() (*asset, error) {
 		return nil, err
 	}
 
-	info := bindataFileInfo{name: "plugins/templates/python/Dockerfile", size: 517, mode: os.FileMode(420), modTime: time.Unix(1504646603, 0)}
+	info := bindataFileInfo{name: "plugins/templates/python/Dockerfile", size: 481, mode: os.FileMode(420), modTime: time.Unix(1792338564, 0)}
 	a := &asset{bytes: bytes, info: info}
 	return a, nil
 }
@@ -259,7 +511,7 @@ func pluginsTemplatesPythonCircleYml() (*asset, error) {
 	return a, nil
 }
 
-var _pluginsTemplatesPythonPluginToml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xac\x91\xcb\x6a\xeb\x30\x10\x86\xf7\x7e\x8a\x41\xd9\x1e\x0e\xb4\x90\xee\xb2\x28\xa1\x94\x40\x5a\xba\xe9\xca\x04\xa3\xc4\x13\x67\x88\x6e\x48\xb2\x4b\x08\x7e\xf7\xa2\x8b\xd3\xd8\xe9\xa6\x10\x6f\x0c\xdf\x7c\x1a\xfd\x9a\x99\xdd\xe7\x2b\x66\xf0\xb1\xfe\x7c\x5d\xbd\xc3\x52\xab\x3d\x35\xad\xe5\x9e\xb4\x82\xbf\xf7\xb9\x53\x9e\xa2\x2c\x8d\x68\x1b\x52\x9b\x4d\x01\xa0\xb8\x44\x58\x00\x3b\x9f\x21\xd1\x2a\x92\xbe\x67\x05\x40\x87\xd6\x85\xac\xa3\xfa\x00\x93\x52\xa3\xdb\x59\x32\xfe\x46\xbb\x2e\x24\x75\xc7\x3d\x36\xda\x9e\xc6\xde\x85\x26\xe9\x88\xa7\x2f\x6d\x6b\x07\x0b\x28\xaf\xac\x0b\xee\x7b\x16\x62\x93\xe4\xcd\x24\x77\x42\xa9\x8b\x45\xa3\x1d\xf9\x9b\xcb\xae\x78\x12\x05\xed\x50\xb9\x49\xa7\x01\x26\x05\x55\x43\x0a\x5d\x56\x24\x0f\xd5\x2a\xc3\xca\xb5\xc6\x68\xeb\xb1\xce\x72\x1e\xed\x7f\x43\x46\x04\x21\x64\x05\xf0\x7a\x7c\x81\x21\x83\xae\xf2\x3a\x1f\x02\xd8\x5b\x2d\x7f\x53\x22\x4f\xd2\xb6\x25\x51\xc3\x02\xf6\x5c\x38\x2c\x00\xb8\xa1\x23\xc6\xe7\xc5\xd9\xca\x7a\x32\x56\x39\x44\x92\x34\xdd\x70\x24\xa9\x78\xe0\xee\xe0\x4f\x26\x3e\xaf\x04\x26\xeb\x39\xfb\x07\xcc\x1d\xf8\x43\xfe\x3f\xce\x9f\x18\x6c\xe2\x18\xba\xb8\x93\xb7\xe7\xf5\x6a\xf9\x52\xfd\x74\x43\xd5\x55\x1d\xb7\xc3\x66\x50\xf1\xad\xc0\x90\xc6\xdb\x16\xbf\x03\x00\x00\xff\xff\xab\x02\x2d\x63\x45\x03\x00\x00")
+var _pluginsTemplatesPythonPluginToml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xad\x92\x4d\x6b\xc2\x40\x10\x86\xef\xfe\x8a\x61\x73\x15\xa1\x05\x7b\xeb\x41\xa4\x14\xc1\x16\x2f\x3d\x05\x09\x6b\x32\x49\x96\xee\x17\xbb\x1b\x8b\x88\xff\xbd\xfb\x91\x58\x8d\xa5\x50\x30\x97\x85\xf7\x7d\x32\xfb\xce\xec\x64\xd9\x5d\xbe\x49\x06\x9b\xf5\xc7\xeb\xea\x1d\x96\x4a\xd6\xac\xe9\x0c\x75\x4c\x49\xf8\x7f\x9d\x3b\xe5\x99\xe4\xb9\xe6\x5d\xc3\xe4\x76\x3b\x01\x90\x54\x20\x3c\x03\x39\x1e\x21\xa9\x45\x54\x4e\x27\xe2\xcd\x3d\x1a\x1b\xb2\x5e\xf9\x83\x98\x90\x0a\x6d\x69\x98\x76\x37\xd8\xa5\x91\xd0\x92\x3a\x6c\x94\x39\x5c\x73\x67\x35\x41\x9f\x78\xf8\x52\xa6\xb2\x1e\xca\x2f\xa8\xb3\xec\xa9\x10\x9b\x09\xda\x8c\x72\x27\x29\x55\x31\xa8\x95\x65\xee\xe6\xb2\x0b\x3d\x81\x9c\x95\x28\xed\xa8\xd2\x20\x26\x04\xa5\xd7\xd0\xf6\x88\xa0\xc1\x2d\x7a\xb1\xb0\x9d\xd6\xca\x38\xac\x7a\x78\xd7\x31\x5e\x79\xb4\xa6\xdc\x62\xe8\x59\x54\xa3\x76\xc5\x80\x0a\x36\x9e\x7c\x54\x92\xd9\x52\xdb\xba\x83\x8e\xd7\xe6\x40\x44\x35\x27\x53\x20\xb6\xa5\x0f\xfd\xf9\x38\x7f\x22\xb0\x8d\xf1\xf6\x71\x56\x6f\x8b\xf5\x6a\xf9\x52\xfc\x54\xf3\x46\xb1\xa7\x66\x98\x18\x4a\xba\xe3\x18\xd2\x38\xd3\x85\x68\xfd\x16\xcc\x34\xd3\x3c\xf4\x12\x20\x00\xa7\xae\x33\x79\xd7\xb7\xe9\xd5\x94\x0b\xa0\x36\x4a\xfc\x86\x44\x3d\x41\x19\x2c\x36\xab\xf0\x92\x16\xa8\x41\xff\x18\xb4\x4a\xbf\xb9\x16\xc1\x62\x69\xd0\x81\xf5\x8f\x80\x53\x90\xe8\xd7\x69\x30\x99\x85\x9a\x71\x8c\x15\x86\x25\x9d\x25\x3e\xee\x6a\x06\x7f\xec\x6b\x41\x35\x23\x3d\x94\x66\xf2\xe7\x48\xbe\x01\xfa\xe1\x00\xe4\xde\x03\x00\x00")
 
 func pluginsTemplatesPythonPluginTomlBytes() ([]byte, error) {
 	return bindataRead(
@@ -274,7 +526,7 @@ func pluginsTemplatesPythonPluginToml() (*asset, error) {
 		return nil, err
 	}
 
-	info := bindataFileInfo{name: "plugins/templates/python/plugin.toml", size: 837, mode: os.FileMode(420), modTime: time.Unix(1504646603, 0)}
+	info := bindataFileInfo{name: "plugins/templates/python/plugin.toml", size: 990, mode: os.FileMode(420), modTime: time.Unix(1792337955, 0)}
 	a := &asset{bytes: bytes, info: info}
 	return a, nil
 }
@@ -299,7 +551,7 @@ func pluginsTemplatesPythonRequirementsTxt() (*asset, error) {
 	return a, nil
 }
 
-var _pluginsTemplatesPythonScanPy = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x74\x52\x4f\x8b\xdb\x3e\x10\xbd\xeb\x53\xbc\x9f\x96\xc5\xc9\x6f\x63\xa7\x2d\x6c\x29\x06\x1d\x0a\xd9\x42\x0f\x0b\x3d\xf4\x6e\x14\x7b\x62\x0b\x62\x49\x1d\xc9\xd9\x1a\x93\x7e\xf6\x22\xc5\xed\x16\x4a\x7d\x11\xf3\xe6\xbd\x79\xf3\xc7\x77\xff\xed\xa7\xc0\xfb\xa3\xb1\x7b\xb2\x17\xf8\x39\x0e\xce\x8a\x3b\x94\xff\x97\x68\x5d\x67\x6c\x5f\x63\x8a\xa7\xf2\x43\x42\x84\x94\x52\x84\x56\xdb\xca\xcf\xe2\xc7\xfa\x89\x65\x41\x47\xa1\x65\xe3\xa3\x71\x16\xd7\x6b\x25\xea\xd6\xf9\x99\x4d\x3f\xc4\x1a\x9b\x76\x8b\x77\x6f\xde\xbe\xc7\x71\xc6\xb2\xa0\x65\xd2\xd1\xf1\x8d\x76\x36\x2d\xd9\x40\x35\x9e\x3f\x7f\xcd\xc5\x85\x19\xbd\xe3\x08\xcd\xbd\xd7\x1c\x48\x08\xd1\xd1\x09\xa3\x36\x76\xb3\xad\x05\x00\x64\x9c\xa1\x7e\x73\xaa\x8f\xdc\x4f\x23\xd9\xf8\x25\x67\x36\x9e\x5d\xaf\x8a\x65\x81\x3f\x4f\xbd\xb1\x8d\xd5\x23\xe1\x7a\x2d\xb6\x7f\xc8\x2b\xdd\x75\x8d\x5e\x75\x1b\x59\x5e\xe4\x0e\xb2\x2c\x2f\xc4\x47\x17\x48\xee\x30\xd0\xd9\x2b\x79\x30\xc1\x9f\xf5\x8c\x15\x87\x9b\xa2\x9f\x22\x46\x0a\x41\xf7\x89\xa6\xdb\x34\xb3\x92\x21\x3a\xa6\x26\xf2\x94\x40\xa6\x6f\x93\x61\xea\xd4\x27\x7d\x0e\xf4\x6f\xdb\x62\xd0\x61\x28\x76\x18\x29\xea\x8b\x66\x55\x3c\x1f\x1e\x8b\x1d\xe2\xec\x49\x85\xc8\x3b\x58\xcd\x7d\x50\xc5\x43\xb1\xf6\x53\x68\x8c\xdd\x23\x92\x0c\xd1\x21\x90\xe6\x76\xc0\xc9\x71\xb5\x0e\x97\xf8\x50\xbf\xcc\xf2\x93\xec\xc2\x66\x2b\x72\xde\x9c\x32\xa5\x4a\x15\x6e\xdb\xcc\xbd\xb1\xb1\x11\xf2\xe0\x10\xdc\x48\x71\x30\xb6\x87\x7e\xa1\x14\xe0\xc5\xc4\x21\x1b\xd6\x90\x78\x78\x55\x67\x31\x53\x9c\xd8\x0a\x21\xcc\x09\x4d\xde\x73\xd3\x40\x29\xc8\xa6\x49\x17\x6b\x1a\x79\x33\x89\x3c\xbf\xba\xdd\x6e\x99\x43\xfa\xde\x92\x8f\x78\xca\x4f\xfa\x77\x74\x00\xfd\xd5\xd7\x13\xb3\xe3\x1a\xf7\x41\xe2\x1e\x24\x7e\x06\x00\x00\xff\xff\xe2\x82\x12\x89\xb2\x02\x00\x00")
+var _pluginsTemplatesPythonScanPy = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xa5\x54\x4d\x6f\xd4\x30\x10\xbd\xe7\x57\x0c\x5d\x55\xc9\x56\x4d\x2a\xc4\x05\x45\xca\xa1\xa2\x5b\x54\x89\x52\x04\xbd\x20\x40\x91\x37\x99\xdd\x75\x9b\xd8\xc1\x76\xb6\x8d\xda\xe5\xb7\x33\xe3\x24\xbb\xdb\x82\xca\x81\x5c\x62\xcf\x3c\xcf\x9b\x8f\x67\x4f\x5e\x9d\xb4\xd6\x9c\xcc\xa5\x3a\x41\xb5\x86\xa6\x73\x2b\xad\xde\x04\x13\x88\x8f\x62\x28\x74\x29\xd5\x32\x85\xd6\x2d\xe2\xb7\x6c\x09\x0e\x0e\x0e\x02\x5b\x08\x95\x34\x5d\xf0\x6b\xf8\x82\x87\x07\x28\xd1\x16\x46\x36\x4e\x6a\x05\x9b\x4d\x12\xa4\x85\x6e\x3a\x23\x97\x2b\x97\x42\x54\x4c\x81\x20\x45\x6b\x0c\x2a\x97\x77\x28\x0c\x61\x60\xde\x79\xab\x41\xe1\xb4\xe9\x0f\x55\xb2\x40\x65\x31\x85\xcb\x8b\x6b\x4f\x15\xc8\xba\xd1\xc6\x81\x30\xcb\x46\x18\x8b\xe3\xfe\xc6\x6a\x35\xae\xb5\x1d\x57\xb6\xb3\x41\xf0\xf1\xf4\x72\x06\x19\x84\x14\xbb\xa9\xda\xa5\x54\xb9\x12\x35\x52\xfc\x30\x78\x77\x7a\x3d\x7b\x7f\xf5\xf9\xeb\x53\x77\x21\x1c\x2e\xb5\xe9\x3c\x24\x08\x4a\x5c\x00\x57\x18\x35\xc2\xad\xa6\x69\x00\xf4\x51\x2a\x5f\xc8\x04\x6e\x85\xb0\x90\x15\x82\x50\x25\x18\x74\xad\x19\x6d\x58\x95\x16\xf4\xc2\xef\xfe\x1a\x9a\xe0\xb6\xad\x28\xc7\x62\x85\xb5\x48\xb8\x38\x8e\xdc\x5b\x2d\x65\xb4\x3b\x35\xda\x36\x1b\x0f\x99\xc0\xf5\xd5\xd9\x55\xca\xc4\xd5\x16\x4f\xcd\xe3\x24\x15\x8d\x07\x38\xd1\x21\x98\xcf\x68\xc0\x0c\xb5\x34\x46\x52\xd3\x6b\x61\x6e\x4b\x7d\xa7\x72\x27\xe6\x15\x46\x03\x64\x28\xef\x16\x3b\xce\xc0\x52\x0f\xb1\xdc\xfa\xbc\xcb\x9f\x8e\xc2\x09\x7d\x70\x68\xbf\xab\x10\x0e\x81\x3b\xfc\xc4\xfb\x48\x2e\x78\x64\x57\x08\x8f\x10\x26\x37\x5a\xaa\x88\x83\x4e\x9f\xc2\x0e\x2d\x63\xa2\x10\xe2\x38\x66\xfc\x11\x54\x38\x02\xff\x1d\xd0\x3a\x33\xe6\xf6\x8d\xce\xfc\x98\xc2\x82\x74\x43\x2b\x90\x0a\x06\xb6\xbe\xe4\x5a\x10\x7c\xa8\xcd\xcb\xc6\x50\x75\xa3\x84\x92\x53\xb3\x6c\x6b\x12\xe2\x27\xef\x89\x1a\xa3\x97\x19\x97\x74\xbc\x2f\xe2\x8c\x05\xd2\x5a\xb1\xf4\xca\x99\xee\x85\x4a\x44\x59\xe6\x62\x88\x11\x85\xf1\x3a\x3c\x86\x30\x8e\xd7\x68\xe6\xda\x22\x6d\x56\x58\x35\x59\x78\x26\x6d\x53\x89\x0e\x06\x3b\xe8\xd6\x35\xad\x83\x1a\x2d\x07\x25\x98\x28\x7a\x1e\x4b\xea\xc7\xdc\x99\x96\x8d\x06\x7f\xb6\xd2\x60\x99\x9d\x8b\xca\xe2\x4b\xb4\xae\xa7\xf5\xe3\xdc\x92\x0e\x24\xc2\xc2\xe5\x30\x6f\x18\x01\xff\x47\xc7\xa2\x27\x7c\x8d\x4e\xac\x85\xc9\xc2\xf3\x8b\x0f\x33\xda\xbb\xae\xc1\x8c\xe6\x32\xf2\xfb\xbb\xe1\xb4\x97\x26\xdc\x49\xb7\x82\x7e\xe4\x3b\xc5\x50\x48\x96\xda\x40\xe2\x7f\x4c\x63\x23\x9a\x1d\xfb\xe5\x02\x94\xe6\x3b\x9d\xb0\xaa\x13\x69\x39\x64\xc4\x88\x84\x57\xc3\x50\xf7\xf2\x44\x63\xb4\x89\x42\xd2\x8b\xb4\xfe\xa8\xf0\x37\x94\x59\x77\xa7\x82\x67\x77\xcd\xdf\xef\x3d\xf7\xc0\xec\x2d\xbe\x61\x7b\x34\x2f\x5d\x1f\x8f\x42\x6a\xdd\x33\x7c\xc4\xef\x53\x52\xb6\x75\x63\xa3\x07\x2e\x3e\x1d\xc9\x37\x5e\xa4\xc4\x95\xfb\x67\x29\xcf\x21\xa3\xc7\x28\xcf\x59\xb2\x79\x1e\xf6\x71\x9c\xe9\x76\x01\x7b\x31\xf7\x4c\xf7\x05\x36\x0e\x66\xfe\xc7\x2f\x2d\x0d\xfa\x0f\xea\x70\xc6\x1d\x49\x87\xce\xe3\xb1\x6f\x47\x46\x4f\x63\x62\x5d\x49\xdd\x9a\x6e\xf1\x6c\xc3\x7b\xe9\xa2\xd7\xd3\xdf\xed\x26\x1b\x72\x06\x06\x00\x00")
 
 func pluginsTemplatesPythonScanPyBytes() ([]byte, error) {
 	return bindataRead(
@@ -314,7 +566,7 @@ func pluginsTemplatesPythonScanPy() (*asset, error) {
 		return nil, err
 	}
 
-	info := bindataFileInfo{name: "plugins/templates/python/scan.py", size: 690, mode: os.FileMode(420), modTime: time.Unix(1504646603, 0)}
+	info := bindataFileInfo{name: "plugins/templates/python/scan.py", size: 1542, mode: os.FileMode(420), modTime: time.Unix(1792338564, 0)}
 	a := &asset{bytes: bytes, info: info}
 	return a, nil
 }
@@ -372,6 +624,18 @@ func AssetNames() []string {
 // _bindata is a table, holding each asset generator, mapped to its name.
 var _bindata = map[string]func() (*asset, error){
 	"plugins/plugins.toml": pluginsPluginsToml,
+	"plugins/templates/go/.dockerignore": pluginsTemplatesGoDockerignore,
+	"plugins/templates/go/.gitignore.template": pluginsTemplatesGoGitignoreTemplate,
+	"plugins/templates/go/CHANGELOG.md": pluginsTemplatesGoChangelogMd,
+	"plugins/templates/go/Dockerfile": pluginsTemplatesGoDockerfile,
+	"plugins/templates/go/LICENSE.md": pluginsTemplatesGoLicenseMd,
+	"plugins/templates/go/README-short.txt": pluginsTemplatesGoReadmeShortTxt,
+	"plugins/templates/go/README.md": pluginsTemplatesGoReadmeMd,
+	"plugins/templates/go/VERSION": pluginsTemplatesGoVersion,
+	"plugins/templates/go/circle.yml": pluginsTemplatesGoCircleYml,
+	"plugins/templates/go/go.mod": pluginsTemplatesGoGoMod,
+	"plugins/templates/go/plugin.toml": pluginsTemplatesGoPluginToml,
+	"plugins/templates/go/scan.go": pluginsTemplatesGoScanGo,
 	"plugins/templates/python/.dockerignore": pluginsTemplatesPythonDockerignore,
 	"plugins/templates/python/.gitignore.template": pluginsTemplatesPythonGitignoreTemplate,
 	"plugins/templates/python/CHANGELOG.md": pluginsTemplatesPythonChangelogMd,
@@ -428,6 +692,20 @@ var _bintree = &bintree{nil, map[string]*bintree{
 	"plugins": &bintree{nil, map[string]*bintree{
 		"plugins.toml": &bintree{pluginsPluginsToml, map[string]*bintree{}},
 		"templates": &bintree{nil, map[string]*bintree{
+			"go": &bintree{nil, map[string]*bintree{
+				".dockerignore": &bintree{pluginsTemplatesGoDockerignore, map[string]*bintree{}},
+				".gitignore.template": &bintree{pluginsTemplatesGoGitignoreTemplate, map[string]*bintree{}},
+				"CHANGELOG.md": &bintree{pluginsTemplatesGoChangelogMd, map[string]*bintree{}},
+				"Dockerfile": &bintree{pluginsTemplatesGoDockerfile, map[string]*bintree{}},
+				"LICENSE.md": &bintree{pluginsTemplatesGoLicenseMd, map[string]*bintree{}},
+				"README-short.txt": &bintree{pluginsTemplatesGoReadmeShortTxt, map[string]*bintree{}},
+				"README.md": &bintree{pluginsTemplatesGoReadmeMd, map[string]*bintree{}},
+				"VERSION": &bintree{pluginsTemplatesGoVersion, map[string]*bintree{}},
+				"circle.yml": &bintree{pluginsTemplatesGoCircleYml, map[string]*bintree{}},
+				"go.mod": &bintree{pluginsTemplatesGoGoMod, map[string]*bintree{}},
+				"plugin.toml": &bintree{pluginsTemplatesGoPluginToml, map[string]*bintree{}},
+				"scan.go": &bintree{pluginsTemplatesGoScanGo, map[string]*bintree{}},
+			}},
 			"python": &bintree{nil, map[string]*bintree{
 				".dockerignore": &bintree{pluginsTemplatesPythonDockerignore, map[string]*bintree{}},
 				".gitignore.template": &bintree{pluginsTemplatesPythonGitignoreTemplate, map[string]*bintree{}},
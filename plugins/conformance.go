@@ -0,0 +1,271 @@
+package plugins
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/maliceio/malice/malice/docker/client"
+	"github.com/maliceio/malice/malice/docker/client/container"
+	"github.com/maliceio/malice/malice/maldirs"
+	"github.com/maliceio/malice/malice/secrets"
+	"github.com/pkg/errors"
+)
+
+// EICAR is the standard anti-virus test file every av plugin must detect
+const EICAR = `X5O!P%@AP[4\PZX54(P^)7CC)7}$EICAR-STANDARD-ANTIVIRUS-TEST-FILE!$H+H*`
+
+// benignSample is a harmless file no plugin should flag
+const benignSample = "malice plugin conformance test: this file is harmless\n"
+
+// Expected detections of a conformance test sample
+const (
+	ExpectInfected = "infected"
+	ExpectClean    = "clean"
+)
+
+// detectionFields is the result field reporting a detection, by category
+var detectionFields = map[string]string{
+	"av":    "infected",
+	"intel": "found",
+}
+
+// TestSample is a file a plugin is tested against
+type TestSample struct {
+	Path string
+	// Expect is ExpectInfected, ExpectClean or empty to skip the detection check
+	Expect string
+	data   []byte
+}
+
+// ParseTestSample parses a PATH[=infected|clean] sample argument
+func ParseTestSample(arg string) (TestSample, error) {
+	sample := TestSample{Path: arg}
+	if i := strings.LastIndex(arg, "="); i != -1 {
+		sample.Path, sample.Expect = arg[:i], arg[i+1:]
+		if sample.Expect != ExpectInfected && sample.Expect != ExpectClean {
+			return sample, fmt.Errorf("invalid sample %q, expected PATH[=%s|%s]", arg, ExpectInfected, ExpectClean)
+		}
+	}
+	if sample.Path == "" {
+		return sample, fmt.Errorf("invalid sample %q, missing path", arg)
+	}
+	return sample, nil
+}
+
+// builtinSamples returns the EICAR and benign samples every plugin is tested
+// against, only av plugins reporting detections are expected to tell them apart
+func builtinSamples(plugin Plugin) []TestSample {
+	eicar := TestSample{Path: "eicar.com", data: []byte(EICAR)}
+	benign := TestSample{Path: "benign.txt", data: []byte(benignSample)}
+	if strings.EqualFold(plugin.Category, "av") && plugin.SchemaVersion != NoSchema {
+		eicar.Expect = ExpectInfected
+		benign.Expect = ExpectClean
+	}
+	return []TestSample{eicar, benign}
+}
+
+// Check is the outcome of a single conformance check
+type Check struct {
+	Name   string `json:"name"`
+	Passed bool   `json:"passed"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// SampleReport holds the checks run against one sample
+type SampleReport struct {
+	Sample   string  `json:"sample"`
+	Expect   string  `json:"expect,omitempty"`
+	ExitCode int64   `json:"exit_code"`
+	Seconds  float64 `json:"seconds"`
+	Checks   []Check `json:"checks"`
+}
+
+// Passed returns true if every check passed
+func (r SampleReport) Passed() bool {
+	for _, check := range r.Checks {
+		if !check.Passed {
+			return false
+		}
+	}
+	return true
+}
+
+// TestReport is the conformance report of a plugin
+type TestReport struct {
+	Plugin  string         `json:"plugin"`
+	Image   string         `json:"image"`
+	Offline bool           `json:"offline"`
+	Passed  bool           `json:"passed"`
+	Samples []SampleReport `json:"samples"`
+}
+
+// Failed returns the number of samples with a failed check
+func (r TestReport) Failed() int {
+	failed := 0
+	for _, sample := range r.Samples {
+		if !sample.Passed() {
+			failed++
+		}
+	}
+	return failed
+}
+
+// TestPlugin runs the plugin's image against the EICAR and benign samples and
+// any extra samples, one container per sample. Every run must exit cleanly
+// within timeout and print a result document matching the plugin's schema.
+// Plugins other than intel plugins run without network access. The image is
+// verified and run pinned like in a scan.
+func TestPlugin(docker *client.Docker, plugin Plugin, samples []TestSample, timeout time.Duration) (TestReport, error) {
+	offline := !strings.EqualFold(plugin.Category, "intel")
+	report := TestReport{Plugin: plugin.Name, Image: plugin.ImageRef(), Offline: offline}
+
+	image, err := plugin.VerifyImage(docker)
+	if err != nil {
+		return report, err
+	}
+	report.Image = image
+
+	dir, err := ioutil.TempDir("", "malice-plugin-test")
+	if err != nil {
+		return report, err
+	}
+	defer os.RemoveAll(dir)
+	// plugins usually scan as an unprivileged user
+	if err := os.Chmod(dir, 0755); err != nil {
+		return report, err
+	}
+
+	secretEnv, secretBinds, cleanup, err := plugin.injectSecrets(secrets.NewStore(maldirs.GetSecretsDir()))
+	if err != nil {
+		return report, errors.Wrapf(err, "failed to inject plugin %s secrets", plugin.Name)
+	}
+	defer cleanup()
+
+	env := append(plugin.getPluginEnv(), secretEnv...)
+	env = append(env, "MALICE_TIMEOUT="+strconv.Itoa(int(timeout.Seconds())))
+	binds := append([]string{dir + ":/malware:ro"}, secretBinds...)
+
+	for _, sample := range append(builtinSamples(plugin), samples...) {
+		data := sample.data
+		if data == nil {
+			if data, err = ioutil.ReadFile(sample.Path); err != nil {
+				return report, errors.Wrap(err, "failed to read sample")
+			}
+		}
+		hashes := sampleHashes(data)
+		if err := ioutil.WriteFile(filepath.Join(dir, hashes["sha256"]), data, 0644); err != nil {
+			return report, err
+		}
+
+		// samples are handed over like in a scan, intel plugins get a hash
+		arg := hashes["sha256"]
+		if !offline {
+			var ok bool
+			if arg, ok = plugin.PickHash(hashes); !ok {
+				return report, fmt.Errorf("plugin %s supports none of the sample hash types", plugin.Name)
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		start := time.Now()
+		exitCode, output, runErr := container.RunIsolated(
+			ctx,
+			docker,
+			client.ContainerName("malice-test-"+plugin.Name),
+			image,
+			plugin.buildCmd(arg),
+			binds,
+			env,
+			!offline,
+		)
+		cancel()
+
+		result := plugin.evaluateSample(sample, exitCode, output, runErr, timeout)
+		result.Seconds = time.Since(start).Seconds()
+		report.Samples = append(report.Samples, result)
+	}
+
+	report.Passed = report.Failed() == 0
+	return report, nil
+}
+
+// evaluateSample runs the conformance checks on one run of the plugin
+func (plugin Plugin) evaluateSample(sample TestSample, exitCode int64, output []byte, runErr error, timeout time.Duration) SampleReport {
+	report := SampleReport{Sample: sample.Path, Expect: sample.Expect, ExitCode: exitCode}
+	add := func(name string, passed bool, format string, args ...interface{}) {
+		report.Checks = append(report.Checks, Check{Name: name, Passed: passed, Detail: fmt.Sprintf(format, args...)})
+	}
+
+	if errors.Cause(runErr) == context.DeadlineExceeded {
+		add("timeout", false, "did not finish within %s", timeout)
+		return report
+	}
+	add("timeout", true, "finished within %s", timeout)
+	if runErr != nil {
+		add("run", false, "%s", runErr)
+		return report
+	}
+
+	add("exit code", exitCode == 0, "exited with %d", exitCode)
+
+	data, violations, err := plugin.ValidateResults(output)
+	switch {
+	case err != nil:
+		add("output", false, "%s", err)
+	case len(violations) > 0:
+		add("output", false, "%s", strings.Join(violations, "; "))
+	default:
+		schema := "keyed by plugin name"
+		if s, ok := plugin.Schema(); ok {
+			schema = "matches " + s.ID()
+		}
+		add("output", true, "%s", schema)
+	}
+
+	if sample.Expect != "" {
+		field, ok := detectionFields[strings.ToLower(plugin.Category)]
+		if plugin.SchemaVersion == NoSchema {
+			add("detection", false, "%s opted out of the %s result schema", plugin.Name, plugin.Category)
+			return report
+		}
+		if !ok {
+			add("detection", false, "%s plugins do not report detections", plugin.Category)
+			return report
+		}
+		detected, ok := data[field].(bool)
+		switch want := sample.Expect == ExpectInfected; {
+		case !ok:
+			add("detection", false, "no boolean %q in output", field)
+		case detected != want:
+			add("detection", false, "expected %s, got %s=%t", sample.Expect, field, detected)
+		default:
+			add("detection", true, "%s", sample.Expect)
+		}
+	}
+	return report
+}
+
+// sampleHashes returns the md5, sha1, sha256 and sha512 of a sample
+func sampleHashes(data []byte) map[string]string {
+	md5sum := md5.Sum(data)
+	sha1sum := sha1.Sum(data)
+	sha256sum := sha256.Sum256(data)
+	sha512sum := sha512.Sum512(data)
+	return SampleHashes(
+		hex.EncodeToString(md5sum[:]),
+		hex.EncodeToString(sha1sum[:]),
+		hex.EncodeToString(sha256sum[:]),
+		hex.EncodeToString(sha512sum[:]),
+	)
+}
@@ -0,0 +1,171 @@
+package plugins
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/maliceio/malice/config"
+	"github.com/maliceio/malice/malice/docker/client/fake"
+	"github.com/maliceio/malice/malice/maldirs"
+)
+
+func TestParseTestSample(t *testing.T) {
+	tests := []struct {
+		arg     string
+		want    TestSample
+		wantErr bool
+	}{
+		{arg: "fixtures/putty.exe", want: TestSample{Path: "fixtures/putty.exe"}},
+		{arg: "fixtures/eicar.com=infected", want: TestSample{Path: "fixtures/eicar.com", Expect: ExpectInfected}},
+		{arg: "a=b/clean.pdf=clean", want: TestSample{Path: "a=b/clean.pdf", Expect: ExpectClean}},
+		{arg: "fixtures/eicar.com=bad", wantErr: true},
+		{arg: "=clean", wantErr: true},
+		{arg: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseTestSample(tt.arg)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseTestSample(%q) error = %v, wantErr %v", tt.arg, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseTestSample(%q) = %+v, want %+v", tt.arg, got, tt.want)
+		}
+	}
+}
+
+func TestEvaluateSample(t *testing.T) {
+	avast := Plugin{Name: "avast", Category: "av"}
+	nsrl := Plugin{Name: "nsrl", Category: "intel"}
+	fileinfo := Plugin{Name: "fileinfo", Category: "metadata"}
+	infected := `{"avast":{"infected":true,"result":"EICAR Test-NOT virus!!!","engine":"4.7.4","updated":"20240101"}}`
+	clean := `{"avast":{"infected":false,"result":"","engine":"4.7.4","updated":"20240101"}}`
+
+	tests := []struct {
+		name     string
+		plugin   Plugin
+		expect   string
+		exitCode int64
+		output   string
+		runErr   error
+		want     map[string]bool
+	}{
+		{
+			name:   "av detects eicar",
+			plugin: avast,
+			expect: ExpectInfected,
+			output: infected,
+			want:   map[string]bool{"timeout": true, "exit code": true, "output": true, "detection": true},
+		},
+		{
+			name:   "av misses eicar",
+			plugin: avast,
+			expect: ExpectInfected,
+			output: clean,
+			want:   map[string]bool{"timeout": true, "exit code": true, "output": true, "detection": false},
+		},
+		{
+			name:     "av fails offline",
+			plugin:   avast,
+			expect:   ExpectClean,
+			exitCode: 1,
+			output:   "could not update definitions",
+			want:     map[string]bool{"timeout": true, "exit code": false, "output": false, "detection": false},
+		},
+		{
+			name:   "output violates schema",
+			plugin: avast,
+			output: `{"avast":{"infected":"yes"}}`,
+			want:   map[string]bool{"timeout": true, "exit code": true, "output": false},
+		},
+		{
+			name:   "intel runs online without expectation",
+			plugin: nsrl,
+			output: `{"nsrl":{"found":false}}`,
+			want:   map[string]bool{"timeout": true, "exit code": true, "output": true},
+		},
+		{
+			name:   "category without detections",
+			plugin: fileinfo,
+			expect: ExpectClean,
+			output: `{"fileinfo":{"magic":"ASCII text"}}`,
+			want:   map[string]bool{"timeout": true, "exit code": true, "output": true, "detection": false},
+		},
+		{
+			name:   "av plugin opting out of its schema",
+			plugin: Plugin{Name: "yara", Category: "av", SchemaVersion: NoSchema},
+			expect: ExpectInfected,
+			output: `{"yara":{"matches":[]}}`,
+			want:   map[string]bool{"timeout": true, "exit code": true, "output": true, "detection": false},
+		},
+		{
+			name:   "timed out",
+			plugin: avast,
+			expect: ExpectInfected,
+			runErr: context.DeadlineExceeded,
+			want:   map[string]bool{"timeout": false},
+		},
+		{
+			name:   "container failed to start",
+			plugin: avast,
+			runErr: errors.New("no such image"),
+			want:   map[string]bool{"timeout": true, "run": false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sample := TestSample{Path: "sample", Expect: tt.expect}
+			report := tt.plugin.evaluateSample(sample, tt.exitCode, []byte(tt.output), tt.runErr, time.Minute)
+
+			got := make(map[string]bool, len(report.Checks))
+			for _, check := range report.Checks {
+				got[check.Name] = check.Passed
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("checks = %v, want %v (%+v)", got, tt.want, report.Checks)
+			}
+
+			passed := true
+			for _, ok := range tt.want {
+				passed = passed && ok
+			}
+			if report.Passed() != passed {
+				t.Errorf("Passed() = %t, want %t", report.Passed(), passed)
+			}
+		})
+	}
+}
+
+func TestTestPluginVerifiesImage(t *testing.T) {
+	saved, savedBase := config.Conf.Trust, maldirs.BaseDir
+	defer func() { config.Conf.Trust, maldirs.BaseDir = saved, savedBase }()
+	maldirs.BaseDir = t.TempDir()
+
+	runtime := fake.New()
+	runtime.AddImage("malice/avast", "sha256:avast", nil)
+	runtime.Script("malice/avast", fake.Behavior{Stdout: `{"avast":{"infected":false,"result":"","engine":"4.7.4","updated":"20240101"}}`})
+	avast := Plugin{Name: "avast", Category: "av", Image: "malice/avast"}
+
+	config.Conf.Trust.Policy, config.Conf.Trust.PublicKey = TrustEnforce, ""
+	if _, err := TestPlugin(runtime.Docker(), avast, nil, time.Minute); err == nil || !strings.Contains(err.Error(), "requires trust.public_key") {
+		t.Errorf("TestPlugin() error = %v, want the image refused", err)
+	}
+	if n := runtime.Count("ContainerCreate"); n != 0 {
+		t.Errorf("created %d containers of an image that wasn't verified", n)
+	}
+
+	config.Conf.Trust.Policy = TrustWarn
+	report, err := TestPlugin(runtime.Docker(), avast, nil, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Image != "malice/avast" || len(report.Samples) != 2 || runtime.Count("ContainerCreate") != 2 {
+		t.Errorf("report = %+v, want both samples run from malice/avast", report)
+	}
+}
@@ -0,0 +1,188 @@
+package plugins
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/maliceio/malice/malice/maldirs"
+	"github.com/pkg/errors"
+)
+
+var (
+	pluginHeaderRegex = regexp.MustCompile(`^\s*\[\[\s*plugin\s*\]\]\s*(#.*)?$`)
+	tableHeaderRegex  = regexp.MustCompile(`^\s*\[`)
+	pluginNameRegex   = regexp.MustCompile(`^\s*name\s*=\s*"([^"]*)"`)
+	// pluginSubTableRegex matches the headers of a plugin's sub-tables, e.g. [[plugin.secret]]
+	pluginSubTableRegex = regexp.MustCompile(`^\s*\[\[?\s*plugin\.`)
+)
+
+// ConfigPath returns the path of the user's plugins.toml
+func ConfigPath() string {
+	return path.Join(maldirs.GetPluginsDir(), "./plugins.toml")
+}
+
+// SetPluginOption sets a top-level key of a plugin in the user's
+// plugins.toml. The file is edited in place so comments and plugin order are
+// kept, and it is only replaced if the result still validates.
+func SetPluginOption(name, key string, value interface{}) error {
+	return editConfig(func(data []byte, _ Configuration) ([]byte, error) {
+		return setPluginKey(data, name, key, value)
+	})
+}
+
+// AddPlugin appends a plugin to the user's plugins.toml. The config is
+// replaced atomically and only if it still validates with the new plugin.
+func AddPlugin(plugin Plugin) error {
+	return editConfig(func(data []byte, _ Configuration) ([]byte, error) {
+		return appendPlugin(data, plugin)
+	})
+}
+
+// appendPlugin encodes plugin as a [[plugin]] table at the end of the config
+func appendPlugin(data []byte, plugin Plugin) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	if err := toml.NewEncoder(buf).Encode(Configuration{Plugins: []Plugin{plugin}}); err != nil {
+		return nil, errors.Wrapf(err, "failed to encode plugin %s", plugin.Name)
+	}
+
+	updated := append([]byte{}, data...)
+	if len(updated) > 0 && !bytes.HasSuffix(updated, []byte("\n")) {
+		updated = append(updated, '\n')
+	}
+	updated = append(updated, '\n')
+	return append(updated, buf.Bytes()...), nil
+}
+
+// setPluginKey sets key = value in the [[plugin]] table named name, keeping
+// the line's indentation and trailing comment. A missing key is added after
+// the plugin's last top-level key.
+func setPluginKey(data []byte, name, key string, value interface{}) ([]byte, error) {
+	encoded, err := tomlValue(value)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := strings.Split(string(data), "\n")
+	start, end, ok := findPluginBlock(lines, name)
+	if !ok {
+		return nil, fmt.Errorf("plugin %s not found in plugins config", name)
+	}
+
+	keyRegex := regexp.MustCompile(`^(\s*)` + regexp.QuoteMeta(key) + `\s*=\s*("(?:[^"\\]|\\.)*"|\[[^\]]*\]|[^#\s]*)(.*)$`)
+	lastKey := start
+	indent := "  "
+	for i := start + 1; i < end; i++ {
+		if match := keyRegex.FindStringSubmatch(lines[i]); match != nil {
+			lines[i] = match[1] + key + " = " + encoded + match[3]
+			return []byte(strings.Join(lines, "\n")), nil
+		}
+		trimmed := strings.TrimSpace(lines[i])
+		if trimmed != "" && !strings.HasPrefix(trimmed, "#") {
+			lastKey = i
+			indent = lines[i][:len(lines[i])-len(strings.TrimLeft(lines[i], " \t"))]
+		}
+	}
+
+	newLine := indent + key + " = " + encoded
+	lines = append(lines[:lastKey+1], append([]string{newLine}, lines[lastKey+1:]...)...)
+	return []byte(strings.Join(lines, "\n")), nil
+}
+
+// findPluginBlock returns the line range holding the top-level keys of the
+// plugin named name, from its [[plugin]] header up to its first sub-table or
+// the next plugin
+func findPluginBlock(lines []string, name string) (int, int, bool) {
+	for i := 0; i < len(lines); i++ {
+		if !pluginHeaderRegex.MatchString(lines[i]) {
+			continue
+		}
+		end := i + 1
+		for end < len(lines) && !tableHeaderRegex.MatchString(lines[end]) {
+			end++
+		}
+		for j := i + 1; j < end; j++ {
+			if match := pluginNameRegex.FindStringSubmatch(lines[j]); match != nil && strings.EqualFold(match[1], name) {
+				return i, end, true
+			}
+		}
+		i = end - 1
+	}
+	return 0, 0, false
+}
+
+// removePlugin deletes the [[plugin]] table named name and its sub-tables.
+// Comments in front of the next table are kept.
+func removePlugin(data []byte, name string) ([]byte, error) {
+	lines := strings.Split(string(data), "\n")
+	start, end, ok := findPluginBlock(lines, name)
+	if !ok {
+		return nil, fmt.Errorf("plugin %s not found in plugins config", name)
+	}
+	for end < len(lines) && pluginSubTableRegex.MatchString(lines[end]) {
+		end++
+		for end < len(lines) && !tableHeaderRegex.MatchString(lines[end]) {
+			end++
+		}
+	}
+
+	// comments at the end of the block describe whatever follows it
+	for end > start+1 {
+		trimmed := strings.TrimSpace(lines[end-1])
+		if trimmed != "" && !strings.HasPrefix(trimmed, "#") {
+			break
+		}
+		end--
+	}
+	for end < len(lines) && strings.TrimSpace(lines[end]) == "" {
+		end++
+	}
+
+	rest := lines[end:]
+	lines = lines[:start]
+	if len(rest) == 0 {
+		// the plugin was the last table, end the file with a single newline
+		for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+			lines = lines[:len(lines)-1]
+		}
+		rest = []string{""}
+	}
+	return []byte(strings.Join(append(lines, rest...), "\n")), nil
+}
+
+// tomlValue encodes a single value as TOML
+func tomlValue(value interface{}) (string, error) {
+	buf := new(bytes.Buffer)
+	if err := toml.NewEncoder(buf).Encode(map[string]interface{}{"v": value}); err != nil {
+		return "", errors.Wrap(err, "failed to encode plugin option")
+	}
+	return strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(buf.String()), "v =")), nil
+}
+
+// writeConfig atomically replaces the plugins config
+func writeConfig(configPath string, data []byte) error {
+	tmpfi, err := ioutil.TempFile(filepath.Dir(configPath), "plugins.toml.tmp")
+	if err != nil {
+		return errors.Wrap(err, "failed to write plugins config")
+	}
+	defer os.Remove(tmpfi.Name())
+
+	if _, err = tmpfi.Write(data); err != nil {
+		tmpfi.Close()
+		return errors.Wrap(err, "failed to write plugins config")
+	}
+	if err = tmpfi.Chmod(0644); err != nil {
+		tmpfi.Close()
+		return err
+	}
+	if err = tmpfi.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpfi.Name(), configPath)
+}
@@ -0,0 +1,89 @@
+package plugins
+
+import (
+	"strings"
+	"testing"
+)
+
+const testConfig = `# Malice plugins
+[[plugin]]
+  enabled = true # keep me
+  name = "nsrl"
+  hashtypes = [ "sha1" ]
+
+[[plugin]]
+  name = "virustotal"
+  image = "malice/virustotal"
+  [[plugin.secret]]
+    name = "vt_api"
+    env = "MALICE_VT_API"
+
+[[plugin]]
+  enabled = false
+  name = "fileinfo"
+`
+
+func TestSetPluginKey(t *testing.T) {
+	tests := []struct {
+		name      string
+		plugin    string
+		key       string
+		value     interface{}
+		want      string
+		wantError bool
+	}{
+		{
+			name:   "replace keeps comment",
+			plugin: "nsrl",
+			key:    "enabled",
+			value:  false,
+			want:   "  enabled = false # keep me\n  name = \"nsrl\"",
+		},
+		{
+			name:   "add before sub-table",
+			plugin: "virustotal",
+			key:    "enabled",
+			value:  false,
+			want:   "  image = \"malice/virustotal\"\n  enabled = false\n  [[plugin.secret]]",
+		},
+		{
+			name:   "string value",
+			plugin: "fileinfo",
+			key:    "image",
+			value:  "malice/fileinfo:1.0",
+			want:   "  name = \"fileinfo\"\n  image = \"malice/fileinfo:1.0\"\n",
+		},
+		{
+			name:   "array value",
+			plugin: "nsrl",
+			key:    "hashtypes",
+			value:  []string{"md5", "sha1"},
+			want:   "  hashtypes = [\"md5\", \"sha1\"]\n",
+		},
+		{
+			name:      "unknown plugin",
+			plugin:    "avast",
+			key:       "enabled",
+			value:     true,
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := setPluginKey([]byte(testConfig), tt.plugin, tt.key, tt.value)
+			if (err != nil) != tt.wantError {
+				t.Fatalf("setPluginKey() error = %v, want error %v", err, tt.wantError)
+			}
+			if tt.wantError {
+				return
+			}
+			if !strings.Contains(string(got), tt.want) {
+				t.Errorf("setPluginKey() =\n%s\nwant it to contain\n%s", got, tt.want)
+			}
+			if !strings.HasPrefix(string(got), "# Malice plugins\n") {
+				t.Error("setPluginKey() dropped the leading comment")
+			}
+		})
+	}
+}
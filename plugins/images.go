@@ -0,0 +1,154 @@
+package plugins
+
+import (
+	"fmt"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/errdefs"
+	"github.com/maliceio/malice/malice/docker/client"
+	"github.com/maliceio/malice/malice/docker/client/image"
+	"github.com/pkg/errors"
+)
+
+// ImageStatus is the local image of a plugin
+type ImageStatus struct {
+	Plugin string
+	Image  string
+	// ID is empty when the image isn't installed
+	ID      string
+	Size    int64
+	Created string
+}
+
+// ImageStatuses inspects the local image of each plugin
+func ImageStatuses(docker *client.Docker, plugins []Plugin) ([]ImageStatus, error) {
+	statuses := make([]ImageStatus, 0, len(plugins))
+	for _, plugin := range plugins {
+		status := ImageStatus{Plugin: plugin.Name, Image: plugin.ImageRef()}
+		inspect, err := image.Inspect(docker, status.Image)
+		switch {
+		case errdefs.IsNotFound(err):
+		case err != nil:
+			return nil, errors.Wrapf(err, "failed to inspect plugin %s image", plugin.Name)
+		default:
+			status.ID, status.Size, status.Created = inspect.ID, inspect.Size, inspect.Created
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// EnsureImage pulls the plugin's image on docker unless it is there already.
+// Plugins in plugins.lock are pulled by their locked digest, so the docker
+// endpoints run the image the local daemon runs. Plugins built from source
+// have to be built on the endpoint beforehand.
+func (plugin Plugin) EnsureImage(docker *client.Docker) error {
+	_, err := image.Inspect(docker, plugin.ImageRef())
+	if err == nil {
+		return nil
+	} else if !errdefs.IsNotFound(err) {
+		return errors.Wrapf(err, "failed to inspect plugin %s image", plugin.Name)
+	}
+
+	lock, err := LoadLock()
+	if err != nil {
+		log.WithError(err).Warn("ignoring plugins lock")
+	}
+	entry, locked := lock.Get(plugin.Name)
+	locked = locked && entry.Image == plugin.ImageRef()
+	if (locked && entry.Built) || (!locked && plugin.Build) {
+		return fmt.Errorf("plugin %s is built from source and its image %s is missing", plugin.Name, plugin.ImageRef())
+	}
+
+	pull := imagePull{ref: image.WithTag(plugin.ImageRef(), "latest")}
+	if locked {
+		pull = lockedPull(plugin, entry.Digest, nil)
+	}
+	log.WithFields(log.Fields{"plugin": plugin.Name, "image": pull.ref}).Info("pulling plugin image")
+	if err := image.PullProgress(docker, pull.ref, "", nil); err != nil {
+		return errors.Wrapf(err, "failed to pull plugin %s image", plugin.Name)
+	}
+	if pull.tagAs != "" {
+		if err := image.Tag(docker, pull.ref, pull.tagAs); err != nil {
+			return errors.Wrapf(err, "failed to tag plugin %s image", plugin.Name)
+		}
+	}
+	return nil
+}
+
+// StaleImage is a local image of a plugin's repository the plugin doesn't run
+type StaleImage struct {
+	types.ImageSummary
+	Plugin string
+	// Dangling images lost their tags to newer pulls, the others are tagged
+	// with a version or digest the plugin moved away from
+	Dangling bool
+}
+
+// StaleImages returns the images of the plugins' repositories that none of
+// the plugins runs. Only repositories with a plugin whose current image is
+// installed are looked at, and images also tagged outside the plugins'
+// repositories are kept.
+func StaleImages(docker *client.Docker, plugins []Plugin) ([]StaleImage, error) {
+	current := make(map[string]bool)
+	repositories := make(map[string]string)
+	for _, plugin := range plugins {
+		inspect, err := image.Inspect(docker, plugin.ImageRef())
+		if errdefs.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return nil, errors.Wrapf(err, "failed to inspect plugin %s image", plugin.Name)
+		}
+		current[inspect.ID] = true
+		repositories[repository(plugin.Image)] = plugin.Name
+	}
+
+	images, err := image.List(docker, "", false)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list images")
+	}
+	stale := []StaleImage{}
+	for _, summary := range images {
+		if current[summary.ID] {
+			continue
+		}
+		tags := imageTags(summary)
+		names := tags
+		if len(names) == 0 {
+			// pulled images keep their digest once they lost their tag
+			names = summary.RepoDigests
+		}
+		plugin := ""
+		for _, name := range names {
+			owner, ok := repositories[repository(name)]
+			if !ok {
+				plugin = ""
+				break
+			}
+			plugin = owner
+		}
+		if plugin != "" {
+			stale = append(stale, StaleImage{ImageSummary: summary, Plugin: plugin, Dangling: len(tags) == 0})
+		}
+	}
+	return stale, nil
+}
+
+// imageTags returns the tags of an image, without the <none>:<none> tag
+// dangling images are listed with
+func imageTags(summary types.ImageSummary) []string {
+	tags := []string{}
+	for _, tag := range summary.RepoTags {
+		if tag != "<none>:<none>" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// repository returns the registry and repository of an image name
+func repository(name string) string {
+	ref := image.ParseReference(name)
+	return ref.Registry + "/" + ref.Repository
+}
@@ -0,0 +1,99 @@
+package plugins
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/maliceio/malice/malice/docker/client/fake"
+	"github.com/maliceio/malice/malice/docker/client/image"
+	"github.com/maliceio/malice/malice/maldirs"
+)
+
+func TestStaleImages(t *testing.T) {
+	runtime := fake.New()
+	runtime.AddImage("malice/avast:0.1", "sha256:1111", nil)
+	runtime.AddImage("malice/avast:0.2", "sha256:2222", nil)
+	runtime.AddImage("malice/yara", "sha256:3333", nil)
+	// a newer pull takes the tag and leaves the old image dangling
+	runtime.AddImage("malice/yara", "sha256:4444", nil)
+	// the plugin's current image isn't installed, the old one is all there is
+	runtime.AddImage("malice/nsrl:0.1", "sha256:5555", nil)
+	// not an image of a plugin
+	runtime.AddImage("blacktop/elasticsearch", "sha256:6666", nil)
+	docker := runtime.Docker()
+
+	plugins := []Plugin{
+		{Name: "avast", Image: "malice/avast", Version: "0.2"},
+		{Name: "yara", Image: "malice/yara"},
+		{Name: "nsrl", Image: "malice/nsrl", Version: "0.2"},
+	}
+	stale, err := StaleImages(docker, plugins)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantDangling := map[string]bool{"avast": false, "yara": true}
+	if len(stale) != len(wantDangling) {
+		t.Fatalf("StaleImages() = %+v, want the old avast and yara images", stale)
+	}
+	for _, img := range stale {
+		if dangling, ok := wantDangling[img.Plugin]; !ok || img.Dangling != dangling {
+			t.Errorf("stale image %+v, want dangling %v", img, dangling)
+		}
+		if err := image.Remove(docker, img.ID, false); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if stale, err = StaleImages(docker, plugins); err != nil || len(stale) != 0 {
+		t.Errorf("StaleImages() after removing them = %+v, %v, want none", stale, err)
+	}
+	statuses, err := ImageStatuses(docker, plugins)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, status := range statuses {
+		if installed := status.ID != ""; installed != (status.Plugin != "nsrl") {
+			t.Errorf("status %+v, want only nsrl missing", status)
+		}
+	}
+}
+
+func TestEnsureImage(t *testing.T) {
+	defer func(base string) { maldirs.BaseDir = base }(maldirs.BaseDir)
+	maldirs.BaseDir = t.TempDir()
+	lock := Lock{}
+	lock.Set(LockEntry{Name: "avast", Image: "malice/avast", Digest: "sha256:aaaa"})
+	lock.Set(LockEntry{Name: "custom", Image: "malice/custom", Digest: "sha256:bbbb", Built: true})
+	if err := lock.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	// an endpoint that has none of the plugins' images
+	node := fake.New()
+	docker := node.Docker()
+
+	avast := Plugin{Name: "avast", Image: "malice/avast"}
+	if err := avast.EnsureImage(docker); err != nil {
+		t.Fatal(err)
+	}
+	if digest, err := avast.ResolvedDigest(docker); err != nil || digest != "sha256:aaaa" {
+		t.Errorf("ResolvedDigest() = %q, %v, want the locked digest pulled", digest, err)
+	}
+	if err := avast.EnsureImage(docker); err != nil || node.Count("ImagePull") != 1 {
+		t.Errorf("EnsureImage() = %v after %d pulls, want the image pulled once", err, node.Count("ImagePull"))
+	}
+
+	// plugins that aren't locked get their image's latest tag
+	yara := Plugin{Name: "yara", Image: "malice/yara"}
+	if err := yara.EnsureImage(docker); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := image.Inspect(docker, "malice/yara:latest"); err != nil {
+		t.Errorf("malice/yara:latest was not pulled: %v", err)
+	}
+
+	custom := Plugin{Name: "custom", Image: "malice/custom", Build: true}
+	if err := custom.EnsureImage(docker); err == nil || !strings.Contains(err.Error(), "built from source") {
+		t.Errorf("EnsureImage() = %v, want the built image reported missing", err)
+	}
+}
@@ -0,0 +1,251 @@
+package plugins
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	log "github.com/Sirupsen/logrus"
+	"github.com/maliceio/malice/malice/maldirs"
+	"github.com/pkg/errors"
+)
+
+// BuiltinIndex is the index source of the plugins bundled with malice
+const BuiltinIndex = "builtin"
+
+var indexClient = &http.Client{Timeout: 30 * time.Second}
+
+// IndexEntry is a plugin listed in a registry index
+type IndexEntry struct {
+	Name        string   `toml:"name"`
+	Description string   `toml:"description"`
+	Category    string   `toml:"category"`
+	Image       string   `toml:"image"`
+	Repository  string   `toml:"repository,omitempty"`
+	Mime        string   `toml:"mime"`
+	HashTypes   []string `toml:"hashtypes,omitempty"`
+	// Version is the latest released version, the image tag to pin
+	Version string `toml:"version,omitempty"`
+	// Versions lists every released version
+	Versions    []string `toml:"versions,omitempty"`
+	Maintainers []string `toml:"maintainers,omitempty"`
+	// Index is the source the entry was loaded from
+	Index string `toml:"-"`
+}
+
+// Index is a registry index file listing installable plugins
+type Index struct {
+	Title   string       `toml:"title"`
+	Plugins []IndexEntry `toml:"plugin"`
+}
+
+// LoadIndexes loads every index in order, an entry in an earlier index
+// shadows entries of the same name in later ones. The bundled plugin list is
+// used when no index is given. Indexes that fail to load are skipped and
+// returned as errors.
+func LoadIndexes(sources []string) ([]IndexEntry, []error) {
+	if len(sources) == 0 {
+		sources = []string{BuiltinIndex}
+	}
+
+	var entries []IndexEntry
+	var errs []error
+	seen := make(map[string]bool)
+	for _, source := range sources {
+		index, err := LoadIndex(source)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for _, entry := range index.Plugins {
+			if seen[entry.Name] {
+				continue
+			}
+			seen[entry.Name] = true
+			entry.Index = source
+			entries = append(entries, entry)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+	return entries, errs
+}
+
+// LoadIndex reads an index from a local file or http(s) url. Fetched indexes
+// are cached and the cached copy is used when the url is unreachable.
+func LoadIndex(source string) (Index, error) {
+	var data []byte
+	var err error
+	switch {
+	case source == BuiltinIndex:
+		return builtinIndex()
+	case strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://"):
+		data, err = fetchIndex(source)
+	default:
+		data, err = ioutil.ReadFile(source)
+	}
+	if err != nil {
+		return Index{}, errors.Wrapf(err, "failed to load plugin index %s", source)
+	}
+	return ParseIndex(data)
+}
+
+// ParseIndex decodes and validates an index file
+func ParseIndex(data []byte) (Index, error) {
+	var index Index
+	if _, err := toml.Decode(string(data), &index); err != nil {
+		return index, errors.Wrap(err, "failed to parse plugin index")
+	}
+	for i, entry := range index.Plugins {
+		if entry.Name == "" || entry.Image == "" {
+			return index, fmt.Errorf("plugin index entry %d is missing a name or image", i+1)
+		}
+		if entry.Version == "" && len(entry.Versions) > 0 {
+			index.Plugins[i].Version = latestVersion(entry.Versions)
+		}
+	}
+	return index, nil
+}
+
+// builtinIndex lists the plugins bundled with malice
+func builtinIndex() (Index, error) {
+	data, err := Asset("plugins/plugins.toml")
+	if err != nil {
+		return Index{}, err
+	}
+	return ParseIndex(data)
+}
+
+// fetchIndex downloads an index, falling back to the last cached copy
+func fetchIndex(url string) ([]byte, error) {
+	sum := sha256.Sum256([]byte(url))
+	cachePath := filepath.Join(maldirs.GetPluginsDir(), "index-cache", hex.EncodeToString(sum[:])[:16]+".toml")
+
+	data, err := download(url)
+	if err != nil {
+		cached, cacheErr := ioutil.ReadFile(cachePath)
+		if cacheErr != nil {
+			return nil, err
+		}
+		log.WithError(err).Warnf("using cached plugin index for %s", url)
+		return cached, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(cachePath), 0755); err == nil {
+		if err := ioutil.WriteFile(cachePath, data, 0644); err != nil {
+			log.WithError(err).Debug("failed to cache plugin index")
+		}
+	}
+	return data, nil
+}
+
+func download(url string) ([]byte, error) {
+	resp, err := indexClient.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	return ioutil.ReadAll(resp.Body)
+}
+
+// SearchIndex returns the entries whose name, description, category, mime or
+// maintainers contain term, case insensitive. An empty term matches everything.
+func SearchIndex(entries []IndexEntry, term string) []IndexEntry {
+	term = strings.ToLower(term)
+	var found []IndexEntry
+	for _, entry := range entries {
+		fields := append([]string{entry.Name, entry.Description, entry.Category, entry.Mime}, entry.Maintainers...)
+		for _, field := range fields {
+			if strings.Contains(strings.ToLower(field), term) {
+				found = append(found, entry)
+				break
+			}
+		}
+	}
+	return found
+}
+
+// FindIndexEntry returns the entry for a plugin name
+func FindIndexEntry(entries []IndexEntry, name string) (IndexEntry, bool) {
+	for _, entry := range entries {
+		if entry.Name == name {
+			return entry, true
+		}
+	}
+	return IndexEntry{}, false
+}
+
+// IndexOutdated returns the plugins pinned to a version older than the
+// latest version in the index
+func IndexOutdated(plugins []Plugin, entries []IndexEntry) []Outdated {
+	var outdated []Outdated
+	for _, plugin := range plugins {
+		entry, ok := FindIndexEntry(entries, plugin.Name)
+		if !ok || plugin.Version == "" || entry.Version == "" {
+			continue
+		}
+		if compareVersions(plugin.Version, entry.Version) < 0 {
+			outdated = append(outdated, Outdated{Plugin: plugin, Local: plugin.Version, Remote: entry.Version})
+		}
+	}
+	return outdated
+}
+
+// latestVersion returns the highest of versions
+func latestVersion(versions []string) string {
+	latest := ""
+	for _, version := range versions {
+		if latest == "" || compareVersions(version, latest) > 0 {
+			latest = version
+		}
+	}
+	return latest
+}
+
+// compareVersions compares dotted versions like 0.3.10 and v1.2.0-rc1 part
+// by part, numerically where both parts are numbers
+func compareVersions(a, b string) int {
+	split := func(v string) []string {
+		return strings.FieldsFunc(strings.TrimPrefix(v, "v"), func(r rune) bool { return r == '.' || r == '-' })
+	}
+	pa, pb := split(a), split(b)
+	for i := 0; i < len(pa) && i < len(pb); i++ {
+		na, errA := strconv.Atoi(pa[i])
+		nb, errB := strconv.Atoi(pb[i])
+		switch {
+		case errA == nil && errB == nil:
+			if na != nb {
+				if na < nb {
+					return -1
+				}
+				return 1
+			}
+		case pa[i] != pb[i]:
+			return strings.Compare(pa[i], pb[i])
+		}
+	}
+	if len(pa) == len(pb) {
+		return 0
+	}
+	// an extra number is a newer release: 1.0 < 1.0.1, anything else is a
+	// pre-release: 1.0.0-rc1 < 1.0.0
+	longer, sign := pa, 1
+	if len(pb) > len(pa) {
+		longer, sign = pb, -1
+	}
+	if _, err := strconv.Atoi(longer[len(pa)+len(pb)-len(longer)]); err != nil {
+		sign = -sign
+	}
+	return sign
+}
@@ -0,0 +1,157 @@
+package plugins
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/maliceio/malice/malice/maldirs"
+)
+
+const testIndex = `
+title = "Internal Plugins"
+
+[[plugin]]
+  name = "yara-internal"
+  description = "YARA rules of the detection team"
+  category = "av"
+  image = "registry.example.com/malice/yara-internal"
+  mime = "*"
+  versions = ["0.9.0", "0.10.0", "0.10.0-rc1"]
+  maintainers = ["Detection Team <detection@example.com>"]
+
+[[plugin]]
+  name = "nsrl"
+  description = "NSRL mirror"
+  category = "intel"
+  image = "registry.example.com/malice/nsrl"
+  version = "2.0.0"
+  mime = "hash"
+`
+
+func TestLoadIndexes(t *testing.T) {
+	dir := t.TempDir()
+	defer func(base string) { maldirs.BaseDir = base }(maldirs.BaseDir)
+	maldirs.BaseDir = dir
+
+	local := filepath.Join(dir, "index.toml")
+	if err := ioutil.WriteFile(local, []byte(testIndex), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("[[plugin]]\n  name = \"nsrl\"\n  image = \"malice/nsrl\"\n\n[[plugin]]\n  name = \"pdf\"\n  image = \"malice/pdf\"\n"))
+	}))
+	defer server.Close()
+
+	entries, errs := LoadIndexes([]string{local, filepath.Join(dir, "missing.toml"), server.URL + "/index.toml"})
+	if len(errs) != 1 {
+		t.Errorf("got errors %v, want only the missing index to fail", errs)
+	}
+
+	var names, sources []string
+	for _, entry := range entries {
+		names = append(names, entry.Name)
+		sources = append(sources, entry.Index)
+	}
+	if want := []string{"nsrl", "pdf", "yara-internal"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("names = %v, want %v", names, want)
+	}
+	// the internal index comes first and shadows the public nsrl entry
+	if want := []string{local, server.URL + "/index.toml", local}; !reflect.DeepEqual(sources, want) {
+		t.Errorf("sources = %v, want %v", sources, want)
+	}
+
+	yara, _ := FindIndexEntry(entries, "yara-internal")
+	if yara.Version != "0.10.0" {
+		t.Errorf("latest version = %q, want 0.10.0", yara.Version)
+	}
+
+	// an unreachable index falls back to its cached copy
+	server.Close()
+	index, err := LoadIndex(server.URL + "/index.toml")
+	if err != nil || len(index.Plugins) != 2 {
+		t.Errorf("LoadIndex() from cache = %+v, %v", index, err)
+	}
+}
+
+func TestParseIndexInvalid(t *testing.T) {
+	if _, err := ParseIndex([]byte("[[plugin]]\n  name = \"noimage\"\n")); err == nil {
+		t.Error("expected an error for an entry without image")
+	}
+	if _, err := ParseIndex([]byte("[[plugin]\n")); err == nil {
+		t.Error("expected an error for invalid toml")
+	}
+}
+
+func TestSearchIndex(t *testing.T) {
+	index, err := ParseIndex([]byte(testIndex))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		term string
+		want []string
+	}{
+		{term: "yara", want: []string{"yara-internal"}},
+		{term: "DETECTION@example", want: []string{"yara-internal"}},
+		{term: "intel", want: []string{"nsrl"}},
+		{term: "", want: []string{"yara-internal", "nsrl"}},
+		{term: "clamav"},
+	}
+
+	for _, tt := range tests {
+		var got []string
+		for _, entry := range SearchIndex(index.Plugins, tt.term) {
+			got = append(got, entry.Name)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("SearchIndex(%q) = %v, want %v", tt.term, got, tt.want)
+		}
+	}
+}
+
+func TestIndexOutdated(t *testing.T) {
+	entries := []IndexEntry{
+		{Name: "avast", Version: "0.3.10"},
+		{Name: "nsrl", Version: "2.0.0"},
+		{Name: "pdf"},
+	}
+	installed := []Plugin{
+		{Name: "avast", Version: "0.3.9"},
+		{Name: "nsrl", Version: "v2.0.0"},
+		{Name: "pdf", Version: "0.1.0"},
+		{Name: "fileinfo", Version: "0.1.0"},
+		{Name: "clamav"},
+	}
+
+	outdated := IndexOutdated(installed, entries)
+	if len(outdated) != 1 || outdated[0].Plugin.Name != "avast" || outdated[0].Local != "0.3.9" || outdated[0].Remote != "0.3.10" {
+		t.Errorf("IndexOutdated() = %+v, want only avast 0.3.9 -> 0.3.10", outdated)
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"0.3.9", "0.3.10", -1},
+		{"1.0.0", "v1.0.0", 0},
+		{"1.2", "1.2.1", -1},
+		{"1.0.0-rc1", "1.0.0", -1},
+		{"1.0.0", "1.0.0-rc1", 1},
+		{"1.0.0-rc1", "1.0.0-rc2", -1},
+		{"2.0.0", "10.0.0", -1},
+	}
+
+	for _, tt := range tests {
+		if got := compareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("compareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package plugins
+
+import (
+	"time"
+
+	apiclient "github.com/docker/docker/client"
+	"github.com/maliceio/malice/malice/docker/client"
+	"github.com/maliceio/malice/malice/docker/client/image"
+	"github.com/pkg/errors"
+)
+
+// Info is the installation state of a plugin's image
+type Info struct {
+	Plugin    Plugin
+	Installed bool
+	ImageID   string
+	// Digest is the registry digest the image was pulled as, empty for local builds
+	Digest       string
+	SigDBVersion string
+	Updated      time.Time
+	Size         int64
+}
+
+// Info inspects the plugin's docker image
+func (plugin Plugin) Info(docker *client.Docker) (Info, error) {
+	info := Info{Plugin: plugin}
+
+	inspect, err := image.Inspect(docker, plugin.ImageRef())
+	if apiclient.IsErrImageNotFound(err) {
+		return info, nil
+	} else if err != nil {
+		return info, errors.Wrapf(err, "failed to inspect plugin %s image", plugin.Name)
+	}
+
+	info.Installed = true
+	info.ImageID = inspect.ID
+	info.Digest = image.LocalDigest(inspect, plugin.Image)
+	info.Size = inspect.Size
+	if inspect.Config != nil {
+		info.SigDBVersion = inspect.Config.Labels[SigDBLabel]
+	}
+	if created, err := time.Parse(time.RFC3339Nano, inspect.Created); err == nil {
+		info.Updated = created
+	}
+	return info, nil
+}
+
+// Outdated is a plugin whose image in the registry differs from the pulled one
+type Outdated struct {
+	Plugin Plugin
+	Local  string
+	Remote string
+}
+
+// OutdatedPlugins compares the pulled image digest of each plugin with its
+// registry. Plugins that are not installed, pinned to a digest or built from
+// source are skipped.
+func OutdatedPlugins(docker *client.Docker, plugins []Plugin) ([]Outdated, []error) {
+	var outdated []Outdated
+	var errs []error
+	for _, plugin := range plugins {
+		if plugin.Build || plugin.Digest != "" {
+			continue
+		}
+		info, err := plugin.Info(docker)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if !info.Installed || info.Digest == "" {
+			continue
+		}
+		remote, err := image.RemoteDigest(plugin.ImageRef())
+		if err != nil {
+			errs = append(errs, errors.Wrapf(err, "failed to check plugin %s for updates", plugin.Name))
+			continue
+		}
+		if remote != info.Digest {
+			outdated = append(outdated, Outdated{Plugin: plugin, Local: info.Digest, Remote: remote})
+		}
+	}
+	return outdated, errs
+}
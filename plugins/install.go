@@ -0,0 +1,272 @@
+package plugins
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	log "github.com/Sirupsen/logrus"
+	runconfigopts "github.com/docker/docker/runconfig/opts"
+	"github.com/maliceio/malice/config"
+	"github.com/maliceio/malice/malice/docker/client"
+	"github.com/maliceio/malice/malice/docker/client/image"
+	"github.com/pkg/errors"
+)
+
+// ManifestLabel is the image label holding a plugin's plugin.toml
+const ManifestLabel = "io.malice.plugin.manifest"
+
+// ManifestFile is the name of the manifest in a plugin's repository
+const ManifestFile = "plugin.toml"
+
+// Categories are the plugin categories malice knows how to schedule
+var Categories = []string{"av", "intel", "exe", "metadata", "document", "archive"}
+
+var (
+	pluginNameValidRegex = regexp.MustCompile(`^[a-z0-9][a-z0-9_.-]*$`)
+	gitURLRegex          = regexp.MustCompile(`^(git@|git://|ssh://|https?://.+\.git(#.*)?$|(https?://)?(github\.com|gitlab\.com|bitbucket\.org)/)`)
+)
+
+// ParseManifest decodes a plugin.toml manifest holding a single [[plugin]]
+func ParseManifest(data []byte) (Plugin, error) {
+	var manifest Configuration
+	if _, err := toml.Decode(string(data), &manifest); err != nil {
+		return Plugin{}, errors.Wrap(err, "invalid plugin manifest")
+	}
+	if len(manifest.Plugins) != 1 {
+		return Plugin{}, fmt.Errorf("plugin manifest must define exactly one [[plugin]], found %d", len(manifest.Plugins))
+	}
+	plugin := manifest.Plugins[0]
+	plugin.Installed = false
+	return plugin, plugin.Validate()
+}
+
+// Validate checks that a plugin has everything malice needs to run it
+func (plugin Plugin) Validate() error {
+	var problems []string
+	for field, value := range map[string]string{
+		"name": plugin.Name, "category": plugin.Category, "image": plugin.Image, "mime": plugin.Mime,
+		"description": plugin.Description, "cmd": plugin.Cmd, "repository": plugin.Repository,
+	} {
+		if strings.Contains(value, "{{") {
+			problems = append(problems, fmt.Sprintf("%s still contains a template placeholder", field))
+		}
+	}
+	if !pluginNameValidRegex.MatchString(plugin.Name) {
+		problems = append(problems, fmt.Sprintf("invalid name %q, use lower case letters, digits, '_', '-' and '.'", plugin.Name))
+	}
+	if plugin.Image == "" {
+		problems = append(problems, "image is required")
+	}
+	if plugin.Mime == "" {
+		problems = append(problems, "mime is required")
+	}
+	if !stringInSlice(plugin.Category, Categories) {
+		problems = append(problems, fmt.Sprintf("unknown category %q, expected one of %s", plugin.Category, strings.Join(Categories, ", ")))
+	}
+	if plugin.Build && plugin.Repository == "" {
+		problems = append(problems, "build requires a repository")
+	}
+	for _, hashType := range plugin.HashTypes {
+		if !stringInSlice(hashType, hashPreference) {
+			problems = append(problems, fmt.Sprintf("unknown hash type %q", hashType))
+		}
+	}
+	if _, err := plugin.RateInterval(); err != nil {
+		problems = append(problems, err.Error())
+	}
+	if plugin.PoolSize < 0 {
+		problems = append(problems, "pool_size can't be negative")
+	}
+	for _, ref := range plugin.Secrets {
+		if ref.Name == "" || (ref.Env == "" && ref.File == "") {
+			problems = append(problems, "secrets need a name and an env or file")
+		}
+	}
+	if plugin.APIKey != "" {
+		problems = append(problems, "apikey is not allowed in a manifest, use a [[plugin.secret]]")
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid plugin %s: %s", plugin.Name, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// checkDuplicate returns an error if a plugin with the same name or image is registered
+func checkDuplicate(registered []Plugin, plugin Plugin) error {
+	for _, existing := range registered {
+		if strings.EqualFold(existing.Name, plugin.Name) {
+			return fmt.Errorf("plugin %s is already installed, use `malice plugin update %s`", plugin.Name, plugin.Name)
+		}
+		if image.ParseReference(existing.Image).Name() == image.ParseReference(plugin.Image).Name() {
+			return fmt.Errorf("image %s is already registered as plugin %s", plugin.Image, existing.Name)
+		}
+	}
+	return nil
+}
+
+// InstallFromSource installs a plugin from a local directory, a git
+// repository or a docker image. The plugin's manifest is read from the
+// plugin.toml in the directory/repository or the image's manifest label.
+// The plugin is only registered once its image is ready.
+func InstallFromSource(docker *client.Docker, source string) (Plugin, error) {
+	var plugin Plugin
+	var err error
+
+	switch {
+	case isLocalPath(source):
+		plugin, err = installFromDir(docker, source, "path")
+	case gitURLRegex.MatchString(source):
+		plugin, err = installFromGit(docker, source)
+	default:
+		plugin, err = installFromImage(docker, source)
+	}
+	if err != nil {
+		return plugin, err
+	}
+
+	return plugin, InstallPlugin(&plugin)
+}
+
+func isLocalPath(source string) bool {
+	_, err := os.Stat(source)
+	return err == nil
+}
+
+// ReadManifest parses the manifest at path, or the ManifestFile in path if it
+// is a directory, and returns the plugin with the raw manifest and its directory
+func ReadManifest(path string) (Plugin, []byte, string, error) {
+	dir, manifestPath := path, path
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		manifestPath = filepath.Join(path, ManifestFile)
+	} else {
+		dir = filepath.Dir(path)
+	}
+
+	data, err := ioutil.ReadFile(manifestPath)
+	if err != nil {
+		return Plugin{}, nil, dir, errors.Wrap(err, "failed to read plugin manifest")
+	}
+	plugin, err := ParseManifest(data)
+	return plugin, data, dir, err
+}
+
+func installFromDir(docker *client.Docker, path, from string) (Plugin, error) {
+	plugin, data, dir, err := ReadManifest(path)
+	if err != nil {
+		return plugin, err
+	}
+	if err := checkDuplicate(Plugs.Plugins, plugin); err != nil {
+		return plugin, err
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "Dockerfile")); err == nil {
+		log.Info("[Building Plugin] ===> ", plugin.Name)
+		labels := map[string]string{
+			"io.malice.plugin.installed.from": from,
+			ManifestLabel:                     string(data),
+		}
+		if err := image.Build(docker, dir, []string{image.WithTag(plugin.Image, plugin.Version)}, proxyBuildArgs(), labels, false); err != nil {
+			return plugin, errors.Wrapf(err, "failed to build plugin %s", plugin.Name)
+		}
+		return plugin, imageReady(docker, image.WithTag(plugin.Image, plugin.Version))
+	}
+
+	log.Info("[Pulling Plugin] ===> ", plugin.Name)
+	if err := image.Pull(docker, plugin.ImageRef(), "latest"); err != nil {
+		return plugin, err
+	}
+	return plugin, imageReady(docker, plugin.ImageRef())
+}
+
+func installFromGit(docker *client.Docker, repository string) (Plugin, error) {
+	tmpDir, err := ioutil.TempDir("", "malice-plugin")
+	if err != nil {
+		return Plugin{}, err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	url, ref := repository, ""
+	if i := strings.Index(repository, "#"); i != -1 {
+		url, ref = repository[:i], repository[i+1:]
+	}
+	if !strings.Contains(url, "://") && !strings.HasPrefix(url, "git@") {
+		url = "https://" + url
+	}
+	args := []string{"clone", "--depth", "1"}
+	if ref != "" {
+		args = append(args, "--branch", ref)
+	}
+	cmd := exec.Command("git", append(args, url, tmpDir)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return Plugin{}, errors.Wrapf(err, "git clone %s failed: %s", url, strings.TrimSpace(stderr.String()))
+	}
+
+	return installFromDir(docker, tmpDir, "repository")
+}
+
+func installFromImage(docker *client.Docker, name string) (Plugin, error) {
+	if _, err := image.Inspect(docker, name); err != nil {
+		log.Info("[Pulling Plugin] ===> ", name)
+		if err := image.Pull(docker, name, "latest"); err != nil {
+			return Plugin{}, errors.Wrapf(err, "failed to pull image %s", name)
+		}
+	}
+	inspect, err := image.Inspect(docker, name)
+	if err != nil {
+		return Plugin{}, errors.Wrapf(err, "failed to inspect image %s", name)
+	}
+
+	var manifest string
+	if inspect.Config != nil {
+		manifest = inspect.Config.Labels[ManifestLabel]
+	}
+	if manifest == "" {
+		return Plugin{}, fmt.Errorf("image %s has no %s label, install it from its repository instead", name, ManifestLabel)
+	}
+
+	plugin, err := ParseManifest([]byte(manifest))
+	if err != nil {
+		return plugin, err
+	}
+	// run the image the user asked for, even if it was pulled by tag or digest
+	plugin.Image = name
+	plugin.Version, plugin.Digest = "", ""
+	return plugin, checkDuplicate(Plugs.Plugins, plugin)
+}
+
+// imageReady makes sure a pull or build actually produced the image
+func imageReady(docker *client.Docker, name string) error {
+	if _, err := image.Inspect(docker, name); err != nil {
+		return errors.Wrapf(err, "plugin image %s is not available", name)
+	}
+	return nil
+}
+
+func proxyBuildArgs() map[string]*string {
+	if !config.Conf.Proxy.Enable {
+		return nil
+	}
+	return runconfigopts.ConvertKVStringsToMapWithNil([]string{
+		"HTTP_PROXY=" + config.Conf.Proxy.HTTP,
+		"HTTPS_PROXY=" + config.Conf.Proxy.HTTPS,
+	})
+}
+
+func stringInSlice(a string, list []string) bool {
+	for _, b := range list {
+		if strings.EqualFold(a, b) {
+			return true
+		}
+	}
+	return false
+}
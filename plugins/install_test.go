@@ -0,0 +1,129 @@
+package plugins
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/BurntSushi/toml"
+	"github.com/maliceio/malice/malice/docker/client/fake"
+)
+
+const testManifest = `# rendered from plugins/templates
+[[plugin]]
+  name = "clamav"
+  description = "ClamAV"
+  category = "av"
+  image = "malice/clamav:1.0"
+  repository = "https://github.com/malice-plugins/clamav.git"
+  build = false
+  mime = "*"
+  enabled = true
+  [plugin.piplines]
+    to = ""
+`
+
+func TestParseManifest(t *testing.T) {
+	tests := []struct {
+		name      string
+		manifest  string
+		wantError string
+	}{
+		{name: "valid manifest", manifest: testManifest},
+		{
+			name:      "unrendered template",
+			manifest:  strings.Replace(testManifest, `"clamav"`, `"{{ plugin_name }}"`, 1),
+			wantError: "template placeholder",
+		},
+		{
+			name:      "unknown category",
+			manifest:  strings.Replace(testManifest, `"av"`, `"crypto"`, 1),
+			wantError: `unknown category "crypto"`,
+		},
+		{
+			name:      "api key in manifest",
+			manifest:  strings.Replace(testManifest, `  mime = "*"`, "  mime = \"*\"\n  apikey = \"secret\"", 1),
+			wantError: "apikey is not allowed",
+		},
+		{
+			name:      "two plugins",
+			manifest:  testManifest + strings.Replace(testManifest, `"clamav"`, `"clamav2"`, 1),
+			wantError: "exactly one [[plugin]]",
+		},
+		{
+			name:      "not toml",
+			manifest:  "[[plugin]\nname=",
+			wantError: "invalid plugin manifest",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin, err := ParseManifest([]byte(tt.manifest))
+			if tt.wantError == "" {
+				if err != nil {
+					t.Fatalf("ParseManifest() error = %v", err)
+				}
+				if plugin.Name != "clamav" || plugin.Mime != "*" || !plugin.Enabled {
+					t.Errorf("ParseManifest() = %+v", plugin)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantError) {
+				t.Errorf("ParseManifest() error = %v, want %q", err, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestCheckDuplicate(t *testing.T) {
+	registered := []Plugin{{Name: "clamav", Image: "malice/clamav"}}
+
+	if err := checkDuplicate(registered, Plugin{Name: "ClamAV", Image: "me/clamav"}); err == nil {
+		t.Error("checkDuplicate() accepted a duplicate name")
+	}
+	if err := checkDuplicate(registered, Plugin{Name: "clamav2", Image: "docker.io/malice/clamav:1.0"}); err == nil {
+		t.Error("checkDuplicate() accepted a duplicate image")
+	}
+	if err := checkDuplicate(registered, Plugin{Name: "avast", Image: "malice/avast"}); err != nil {
+		t.Errorf("checkDuplicate() error = %v", err)
+	}
+}
+
+func TestAppendPlugin(t *testing.T) {
+	config := "# my plugins\n[[plugin]]\n  name = \"nsrl\"\n  enabled = true"
+	plugin, err := ParseManifest([]byte(testManifest))
+	if err != nil {
+		t.Fatal(err)
+	}
+	plugin.Installed = true
+
+	updated, err := appendPlugin([]byte(config), plugin)
+	if err != nil {
+		t.Fatalf("appendPlugin() error = %v", err)
+	}
+	if !strings.HasPrefix(string(updated), config+"\n") {
+		t.Error("appendPlugin() changed the existing config")
+	}
+
+	var decoded Configuration
+	if _, err := toml.Decode(string(updated), &decoded); err != nil {
+		t.Fatalf("appended config does not decode: %v", err)
+	}
+	if len(decoded.Plugins) != 2 || decoded.Plugins[1].Name != "clamav" || !decoded.Plugins[1].Installed {
+		t.Errorf("appended config = %+v", decoded.Plugins)
+	}
+	if strings.Contains(string(updated), "apikey") {
+		t.Error("appendPlugin() wrote empty optional fields")
+	}
+}
+
+func TestInstallFromImagePullError(t *testing.T) {
+	runtime := fake.New()
+	runtime.Fail("ImagePull", errors.New("manifest unknown"))
+
+	_, err := installFromImage(runtime.Docker(), "malice/clamav:1.0")
+	if err == nil || !strings.Contains(err.Error(), "manifest unknown") {
+		t.Errorf("installFromImage() error = %v, want the pull error", err)
+	}
+}
@@ -0,0 +1,105 @@
+package plugins
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/malice-plugins/pkgs/utils"
+)
+
+// hashPreference is the order hashes are handed to plugins that don't declare hashtypes
+var hashPreference = []string{"sha256", "sha1", "md5", "sha512"}
+
+// notices remembers which skip notices were already shown so bulk
+// lookups only report a skipped plugin once
+var notices sync.Map
+
+func notice(key, format string, args ...interface{}) {
+	if _, shown := notices.LoadOrStore(key, true); !shown {
+		log.Warnf(format, args...)
+	}
+}
+
+// MissingRequirements returns the required environment variables that are
+// neither set nor provided by one of the plugin's secrets
+func (plugin Plugin) MissingRequirements() []string {
+	var missing []string
+	for _, name := range plugin.Requires {
+		if os.Getenv(name) == "" && !plugin.hasSecretFor(name) {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
+
+// PickHash returns the hash the plugin should look up out of the sample's
+// hashes by type, honouring the order of the plugin's hashtypes
+func (plugin Plugin) PickHash(hashes map[string]string) (string, bool) {
+	preference := plugin.HashTypes
+	if len(preference) == 0 {
+		preference = hashPreference
+	}
+	for _, hashType := range preference {
+		if hash := hashes[strings.ToLower(hashType)]; hash != "" {
+			return hash, true
+		}
+	}
+	return "", false
+}
+
+// selectIntelPlugins drops the intel plugins that can't use any of the
+// available hash types (any type if empty) or miss a required credential
+func selectIntelPlugins(intelPlugs []Plugin, hashTypes []string) []Plugin {
+	hashes := make(map[string]string)
+	for _, hashType := range hashTypes {
+		hashes[hashType] = hashType
+	}
+
+	selected := []Plugin{}
+	for _, plugin := range intelPlugs {
+		if len(hashes) > 0 {
+			if _, ok := plugin.PickHash(hashes); !ok {
+				log.Debugf("skipping intel plugin %s: it only supports %s hashes", plugin.Name, strings.Join(plugin.HashTypes, "/"))
+				continue
+			}
+		}
+		if missing := plugin.MissingRequirements(); len(missing) > 0 {
+			notice("requires:"+plugin.Name, "skipping intel plugin %s: %s not set, export it or store it with `malice secret set`", plugin.Name, strings.Join(missing, ", "))
+			continue
+		}
+		selected = append(selected, plugin)
+	}
+	return selected
+}
+
+// SampleHashes returns a sample's hashes keyed by hash type
+func SampleHashes(md5, sha1, sha256, sha512 string) map[string]string {
+	hashes := make(map[string]string)
+	for hashType, hash := range map[string]string{"md5": md5, "sha1": sha1, "sha256": sha256, "sha512": sha512} {
+		if hash != "" {
+			hashes[hashType] = hash
+		}
+	}
+	return hashes
+}
+
+// hashTypes returns the hash types present in hashes
+func hashTypes(hashes map[string]string) []string {
+	types := make([]string, 0, len(hashes))
+	for hashType := range hashes {
+		types = append(types, hashType)
+	}
+	return types
+}
+
+// hashesFor returns a single hash keyed by its detected type
+func hashesFor(hash string) (map[string]string, error) {
+	hashType, err := utils.GetHashType(hash)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %q", err, hash)
+	}
+	return map[string]string{hashType: hash}, nil
+}
@@ -0,0 +1,175 @@
+package plugins
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/maliceio/malice/malice/docker/client/container"
+	"github.com/maliceio/malice/malice/docker/client/fake"
+	"github.com/maliceio/malice/malice/maldirs"
+)
+
+func TestPickHash(t *testing.T) {
+	hashes := map[string]string{"md5": "md5-hash", "sha1": "sha1-hash", "sha256": "sha256-hash"}
+
+	tests := []struct {
+		name      string
+		hashTypes []string
+		hashes    map[string]string
+		want      string
+		wantOK    bool
+	}{
+		{name: "converts to supported type", hashTypes: []string{"sha1"}, hashes: hashes, want: "sha1-hash", wantOK: true},
+		{name: "honours hashtypes order", hashTypes: []string{"md5", "sha1", "sha256"}, hashes: hashes, want: "md5-hash", wantOK: true},
+		{name: "no hashtypes prefers sha256", hashes: hashes, want: "sha256-hash", wantOK: true},
+		{name: "unsupported type", hashTypes: []string{"sha1"}, hashes: map[string]string{"md5": "md5-hash"}, wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Plugin{Name: "intel", HashTypes: tt.hashTypes}.PickHash(tt.hashes)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("PickHash() = %q, %v, want %q, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestSelectIntelPlugins(t *testing.T) {
+	t.Setenv("MALICE_VT_API", "")
+	t.Setenv("MALICE_TH_USER", "user")
+	t.Setenv("MALICE_SECRET_SS_API", "key")
+
+	intel := []Plugin{
+		{Name: "nsrl", HashTypes: []string{"sha1"}},
+		{Name: "virustotal", HashTypes: []string{"md5", "sha1", "sha256"}, Requires: []string{"MALICE_VT_API"}},
+		{Name: "shadow-server", HashTypes: []string{"md5", "sha1"}},
+		{Name: "totalhash", HashTypes: []string{"sha1"}, Requires: []string{"MALICE_TH_USER"}},
+		{Name: "stored-secret", HashTypes: []string{"sha256"}, Requires: []string{"MALICE_SS_API"}, Secrets: []SecretRef{{Name: "ss_api", Env: "MALICE_SS_API"}}},
+	}
+
+	tests := []struct {
+		name      string
+		hashTypes []string
+		want      []string
+	}{
+		{name: "md5 lookup", hashTypes: []string{"md5"}, want: []string{"shadow-server"}},
+		{name: "sha256 lookup", hashTypes: []string{"sha256"}, want: []string{"stored-secret"}},
+		{name: "sample with every hash", hashTypes: []string{"md5", "sha1", "sha256"}, want: []string{"nsrl", "shadow-server", "totalhash", "stored-secret"}},
+		{name: "any hash type", want: []string{"nsrl", "shadow-server", "totalhash", "stored-secret"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := []string{}
+			for _, plugin := range selectIntelPlugins(intel, tt.hashTypes) {
+				got = append(got, plugin.Name)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("selectIntelPlugins() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStartPluginChecksLoggedOutput(t *testing.T) {
+	runtime := fake.New()
+	runtime.AddImage("malice/nsrl", "sha256:nsrl", nil)
+	runtime.AddImage("malice/shadow-server", "sha256:shadow", nil)
+	runtime.Script("malice/nsrl", fake.Behavior{Stdout: `{"nsrl":{"found":true}}`})
+	runtime.Script("malice/shadow-server", fake.Behavior{Stdout: `{"shadow-server":{"found":"yes"}}`})
+	docker := runtime.Docker()
+
+	// with logs the result is printed, the output is still read and checked
+	nsrl := Plugin{Name: "nsrl", Category: "intel", Image: "malice/nsrl"}
+	result := nsrl.StartPlugin(context.Background(), docker, "md5-hash", "scan1", nil, true, false)
+	if !result.Valid() || result.Data["found"] != true {
+		t.Errorf("StartPlugin() = %+v, want the valid nsrl result", result)
+	}
+	shadow := Plugin{Name: "shadow-server", Category: "intel", Image: "malice/shadow-server"}
+	if result := shadow.StartPlugin(context.Background(), docker, "md5-hash", "scan1", nil, true, false); result.Valid() || len(result.Violations) != 1 {
+		t.Errorf("StartPlugin() = %+v, want the found field flagged", result)
+	}
+}
+
+func TestStartPluginTimeout(t *testing.T) {
+	runtime := fake.New()
+	runtime.AddImage("malice/avast", "sha256:avast", nil)
+	runtime.Script("malice/avast", fake.Behavior{Stdout: `{"avast":{}}`, Delay: time.Minute})
+	docker := runtime.Docker()
+
+	avast := Plugin{Name: "avast", Category: "av", Image: "malice/avast", timeout: 50 * time.Millisecond}
+	result := avast.StartPlugin(context.Background(), docker, "sha256-hash", "scan1", nil, false, false)
+	if result.Err == nil || !strings.Contains(result.Err.Error(), "timed out") {
+		t.Fatalf("StartPlugin() error = %v, want a timeout", result.Err)
+	}
+	if all, _ := container.List(docker, true); len(all) != 0 {
+		t.Errorf("timed out plugin container left behind: %+v", all)
+	}
+}
+
+func TestRunIntelPluginsForSampleSemaphore(t *testing.T) {
+	defer func(saved Configuration) { Plugs = saved }(Plugs)
+	Plugs = Configuration{Plugins: []Plugin{
+		{Name: "nsrl", Category: "intel", Image: "malice/nsrl", HashTypes: []string{"sha1"}, Enabled: true, Installed: true},
+		{Name: "shadow-server", Category: "intel", Image: "malice/shadow-server", HashTypes: []string{"md5"}, Enabled: true, Installed: true},
+	}}
+	runtime := fake.New()
+	for _, name := range []string{"nsrl", "shadow-server"} {
+		runtime.AddImage("malice/"+name, "sha256:"+name, nil)
+		runtime.Script("malice/"+name, fake.Behavior{Stdout: `{"` + name + `":{}}`, Delay: 100 * time.Millisecond})
+	}
+	hashes := map[string]string{"md5": "md5-hash", "sha1": "sha1-hash"}
+
+	// with one slot the plugins run one after the other
+	start := time.Now()
+	results := RunIntelPluginsForSample(context.Background(), runtime.Docker(), Profile{}, hashes, "scan1", make(chan struct{}, 1), nil, false, false)
+	if len(results) != 2 || results[0].Err != nil || results[1].Err != nil {
+		t.Fatalf("RunIntelPluginsForSample() = %+v, want both results", results)
+	}
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+		t.Errorf("plugins took %v, want them to wait for the slot", elapsed)
+	}
+
+	// no plugin starts while the slots are taken by other scans
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	sem := make(chan struct{}, 1)
+	sem <- struct{}{}
+	for _, result := range RunIntelPluginsForSample(ctx, runtime.Docker(), Profile{}, hashes, "scan2", sem, nil, false, false) {
+		if result.Err == nil {
+			t.Errorf("RunIntelPluginsForSample() = %+v, want it failed waiting for a slot", result)
+		}
+	}
+}
+
+func TestStartPluginPullsMissingImage(t *testing.T) {
+	defer func(base string) { maldirs.BaseDir = base }(maldirs.BaseDir)
+	maldirs.BaseDir = t.TempDir()
+	runtime := fake.New()
+	runtime.Script("malice/nsrl", fake.Behavior{Stdout: `{"nsrl":{"found":true}}`})
+	docker := runtime.Docker()
+
+	// the image is pulled before it is verified and run
+	nsrl := Plugin{Name: "nsrl", Category: "intel", Image: "malice/nsrl"}
+	if result := nsrl.StartPlugin(context.Background(), docker, "md5-hash", "scan1", nil, false, false); result.Err != nil {
+		t.Fatalf("StartPlugin() = %+v, want the image pulled and run", result)
+	}
+	if pulls := runtime.Count("ImagePull"); pulls != 1 {
+		t.Errorf("pulled %d times, want once", pulls)
+	}
+
+	runtime.Fail("ImagePull", errors.New("registry unavailable"))
+	shadow := Plugin{Name: "shadow-server", Category: "intel", Image: "malice/shadow-server"}
+	result := shadow.StartPlugin(context.Background(), docker, "md5-hash", "scan1", nil, false, false)
+	if result.Err == nil || !strings.Contains(result.Err.Error(), "registry unavailable") {
+		t.Errorf("StartPlugin() error = %v, want the pull error", result.Err)
+	}
+	if created := runtime.Count("ContainerCreate"); created != 1 {
+		t.Errorf("created %d containers, want none for the missing image", created-1)
+	}
+}
@@ -60,31 +60,24 @@ func GetPluginByName(name string) Plugin {
 	return Plugin{}
 }
 
-// GetIntelPlugins will return all Intel plugins
+// GetIntelPlugins will return the Intel plugins that support hashType (any
+// hash type if empty) and have their required credentials set
 func GetIntelPlugins(hashType string, enabled bool) []Plugin {
+	var hashTypes []string
+	if hashType != "" {
+		hashTypes = []string{hashType}
+	}
+	return getIntelPlugins(hashTypes, enabled)
+}
+
+func getIntelPlugins(hashTypes []string, enabled bool) []Plugin {
 	var intelPlugs []Plugin
 	if enabled {
 		intelPlugs = getIntel(getEnabled(getInstalled()))
 	} else {
 		intelPlugs = getIntel(getInstalled())
 	}
-	// filter down to intel plugins with apikey's set in ENV
-	// var allSet bool
-	// var hasEnvPlugs []Plugin
-	// for _, plugin := range intelPlugs {
-	// 	allSet = true
-	// 	for _, pluginEnv := range plugin.Env {
-	// 		if os.Getenv(pluginEnv) == "" {
-	// 			allSet = false
-	// 		}
-	// 	}
-	// 	if allSet {
-	// 		if utils.StringInSlice(hashType, plugin.HashTypes) {
-	// 			hasEnvPlugs = append(hasEnvPlugs, plugin)
-	// 		}
-	// 	}
-	// }
-	return intelPlugs
+	return selectIntelPlugins(intelPlugs, hashTypes)
 }
 
 // GetPluginsForMime will return all plugins that can consume the mime type file
@@ -1,14 +1,12 @@
 package plugins
 
 import (
-	"io/ioutil"
 	"os"
-	"path"
+	"time"
 
-	"github.com/BurntSushi/toml"
 	log "github.com/Sirupsen/logrus"
-	er "github.com/maliceio/malice/malice/errors"
 	"github.com/maliceio/malice/malice/maldirs"
+	"github.com/pkg/errors"
 )
 
 // Plugin represents a single plugin setting.
@@ -18,14 +16,33 @@ type Plugin struct {
 	Category    string   `toml:"category"`
 	Description string   `toml:"description"`
 	Image       string   `toml:"image"`
-	Repository  string   `toml:"repository"`
+	Repository  string   `toml:"repository,omitempty"`
 	Build       bool     `toml:"build"`
-	APIKey      string   `toml:"apikey"`
+	APIKey      string   `toml:"apikey,omitempty"` // deprecated, use a [[plugin.secret]] instead
 	Mime        string   `toml:"mime"`
-	HashTypes   []string `toml:"hashtypes"`
-	Cmd         string   `toml:"cmd"`
-	Env         []string `toml:"env"`
-	Installed   bool
+	HashTypes   []string `toml:"hashtypes,omitempty"`
+	Cmd         string   `toml:"cmd,omitempty"`
+	Env         []string `toml:"env,omitempty"`
+	// SchemaVersion pins the result schema version, defaults to SchemaVersion,
+	// NoSchema only checks the results are keyed by plugin name
+	SchemaVersion string `toml:"schema,omitempty"`
+	// Requires lists the environment variables the plugin can't run without
+	Requires []string `toml:"requires,omitempty"`
+	// Secrets are injected from the secret store as env vars or files
+	Secrets []SecretRef `toml:"secret,omitempty"`
+	// RateLimit caps requests to the plugin's provider, e.g. "4/m"
+	RateLimit string `toml:"rate_limit,omitempty"`
+	// Version is the image tag to run, defaults to latest
+	Version string `toml:"version,omitempty"`
+	// Digest pins the image to an exact sha256 digest
+	Digest    string `toml:"digest,omitempty"`
+	Installed bool   `toml:"installed,omitempty"`
+	// PoolSize is the number of warm containers kept for the plugin
+	PoolSize int `toml:"pool_size,omitempty"`
+	// HostLabels are the labels a docker endpoint needs to run the plugin
+	HostLabels []string `toml:"host_labels,omitempty"`
+	// timeout is set by the scan profile that selected the plugin
+	timeout time.Duration
 }
 
 // Configuration represents the malice runtime plugins.
@@ -40,34 +57,44 @@ var Plugs Configuration
 // Try to load plugins from
 // - .malice folder       : $HOME/.malice/plugins.toml
 // - binary embedded file : bindata
-func Load() {
-
-	var configPath string
+// An invalid plugins.toml falls back to its backup from before the last edit.
+func Load() error {
 
 	// Check for plugins config in .malice folder
-	configPath = path.Join(maldirs.GetPluginsDir(), "./plugins.toml")
+	configPath := ConfigPath()
 	if _, err := os.Stat(configPath); err == nil {
-		_, err := toml.DecodeFile(configPath, &Plugs)
-		er.CheckError(err)
+		config, err := LoadConfig(configPath)
+		if err != nil {
+			backup, backupErr := LoadConfig(BackupPath())
+			if backupErr != nil {
+				return err
+			}
+			log.WithError(err).Warnf("using the plugins config backup, fix %s or run `malice plugin rollback`", configPath)
+			config = backup
+		}
+		Plugs = config
 		log.Debug("Malice plugins loaded from: ", configPath)
-		return
+		return nil
 	}
 
 	// Read plugin config out of bindata
 	tomlData, err := Asset("plugins/plugins.toml")
 	if err != nil {
-		log.Error(err)
+		return err
 	}
-	if _, err = toml.Decode(string(tomlData), &Plugs); err == nil {
-		// Create .malice folder in the users home directory
-		er.CheckError(os.MkdirAll(maldirs.GetPluginsDir(), 0777))
-		// Create the plugins config in the .malice folder
-		er.CheckError(ioutil.WriteFile(configPath, tomlData, 0644))
-		log.Debug("Malice plugins loaded from plugins/bindata.go")
+	if Plugs, err = ParseConfig(tomlData); err != nil {
+		return err
 	}
-	er.CheckError(err)
-
-	return
+	// Create .malice folder in the users home directory
+	if err := os.MkdirAll(maldirs.GetPluginsDir(), 0777); err != nil {
+		return errors.Wrap(err, "failed to create plugins directory")
+	}
+	// Create the plugins config in the .malice folder
+	if err := writeConfig(configPath, tomlData); err != nil {
+		return errors.Wrap(err, "failed to write plugins config")
+	}
+	log.Debug("Malice plugins loaded from plugins/bindata.go")
+	return nil
 }
 
 // func setInstalledFlag() {
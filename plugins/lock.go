@@ -0,0 +1,267 @@
+package plugins
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	log "github.com/Sirupsen/logrus"
+	"github.com/maliceio/malice/malice/docker/client"
+	"github.com/maliceio/malice/malice/docker/client/image"
+	"github.com/maliceio/malice/malice/maldirs"
+	"github.com/pkg/errors"
+)
+
+const lockHeader = "# This file is generated by `malice plugin update`, commit it to reproduce scans on other machines.\n\n"
+
+// LockEntry records the image digest a plugin resolved to
+type LockEntry struct {
+	Name  string `toml:"name"`
+	Image string `toml:"image"`
+	// Digest is the registry digest, or the image ID for plugins built from source
+	Digest   string    `toml:"digest"`
+	Built    bool      `toml:"built,omitempty"`
+	Resolved time.Time `toml:"resolved"`
+}
+
+// Lock is the content of plugins.lock
+type Lock struct {
+	Plugins []LockEntry `toml:"plugin"`
+}
+
+// DigestChange is the image digest a plugin moved from and to during an update
+type DigestChange struct {
+	Plugin string
+	Image  string
+	Old    string
+	New    string
+	Err    error
+}
+
+// LockPath returns the path of the user's plugins.lock
+func LockPath() string {
+	return path.Join(maldirs.GetPluginsDir(), "./plugins.lock")
+}
+
+// LoadLock reads plugins.lock, a missing lockfile is returned empty
+func LoadLock() (Lock, error) {
+	var lock Lock
+	data, err := ioutil.ReadFile(LockPath())
+	if os.IsNotExist(err) {
+		return lock, nil
+	} else if err != nil {
+		return lock, errors.Wrap(err, "failed to read plugins lock")
+	}
+	if _, err := toml.Decode(string(data), &lock); err != nil {
+		return lock, errors.Wrap(err, "invalid plugins lock")
+	}
+	return lock, nil
+}
+
+// Save atomically replaces plugins.lock
+func (l Lock) Save() error {
+	sort.Slice(l.Plugins, func(i, j int) bool { return l.Plugins[i].Name < l.Plugins[j].Name })
+
+	buf := bytes.NewBufferString(lockHeader)
+	if err := toml.NewEncoder(buf).Encode(l); err != nil {
+		return errors.Wrap(err, "failed to encode plugins lock")
+	}
+	if err := os.MkdirAll(maldirs.GetPluginsDir(), 0777); err != nil {
+		return err
+	}
+	return writeConfig(LockPath(), buf.Bytes())
+}
+
+// Get returns the lock entry of a plugin
+func (l Lock) Get(name string) (LockEntry, bool) {
+	for _, entry := range l.Plugins {
+		if entry.Name == name {
+			return entry, true
+		}
+	}
+	return LockEntry{}, false
+}
+
+// Set adds or replaces the lock entry of a plugin
+func (l *Lock) Set(entry LockEntry) {
+	for i := range l.Plugins {
+		if l.Plugins[i].Name == entry.Name {
+			l.Plugins[i] = entry
+			return
+		}
+	}
+	l.Plugins = append(l.Plugins, entry)
+}
+
+// ImageRef returns the image reference to run, pinned to the plugin's
+// version tag and digest when they are set
+func (plugin Plugin) ImageRef() string {
+	ref := image.WithTag(plugin.Image, plugin.Version)
+	if plugin.Digest != "" && !strings.Contains(ref, "@") {
+		ref += "@" + plugin.Digest
+	}
+	return ref
+}
+
+// ResolvedDigest returns the registry digest of the plugin's local image, or
+// its image ID if the image was built from source
+func (plugin Plugin) ResolvedDigest(docker *client.Docker) (string, error) {
+	inspect, err := image.Inspect(docker, plugin.ImageRef())
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to inspect plugin %s image", plugin.Name)
+	}
+	if digest := image.LocalDigest(inspect, plugin.Image); digest != "" {
+		return digest, nil
+	}
+	return inspect.ID, nil
+}
+
+// DefaultPullConcurrency is the number of images UpdatePlugins pulls at once
+const DefaultPullConcurrency = 4
+
+// UpdateOptions tune how UpdatePluginsWithOptions fetches plugin images
+type UpdateOptions struct {
+	// FromSource builds every plugin from its repository
+	FromSource bool
+	// Locked pulls plugins already in plugins.lock by their locked digest
+	Locked bool
+	// Concurrency bounds the pulls running at once, DefaultPullConcurrency when 0
+	Concurrency int
+	// SupportImages are pulled along with the plugins, e.g. the database image
+	SupportImages []string
+	// Progress is where the progress bar of the pulls is drawn, os.Stdout when nil
+	Progress io.Writer
+}
+
+// UpdatePlugins pulls (or builds from source) each plugin's pinned image and
+// records the resolved digests in plugins.lock. With locked set, plugins
+// already in the lock are pulled by their locked digest instead, which
+// reproduces another machine's plugin images.
+func UpdatePlugins(docker *client.Docker, plugins []Plugin, fromSource, locked bool) []DigestChange {
+	return UpdatePluginsWithOptions(docker, plugins, UpdateOptions{FromSource: fromSource, Locked: locked})
+}
+
+// UpdatePluginsWithOptions is UpdatePlugins with the pulls spread over
+// opts.Concurrency workers. Builds print their output, so they run one after
+// the other before the pulls start.
+func UpdatePluginsWithOptions(docker *client.Docker, plugins []Plugin, opts UpdateOptions) []DigestChange {
+	lock, err := LoadLock()
+	if err != nil {
+		log.WithError(err).Warn("ignoring plugins lock")
+	}
+
+	changes := make([]DigestChange, len(plugins))
+	built := make([]bool, len(plugins))
+	var pulls []imagePull
+	for i, plugin := range plugins {
+		old, inLock := lock.Get(plugin.Name)
+		changes[i] = DigestChange{Plugin: plugin.Name, Image: plugin.ImageRef(), Old: old.Digest}
+		if inLock && old.Image != changes[i].Image {
+			// the plugin's image or version changed since it was locked
+			inLock = false
+		}
+
+		built[i] = opts.FromSource || plugin.Build
+		switch {
+		case opts.Locked && inLock && !old.Built:
+			pulls = append(pulls, lockedPull(plugin, old.Digest, &changes[i].Err))
+		case built[i]:
+			changes[i].Err = plugin.UpdatePluginFromRepository(docker)
+		default:
+			pulls = append(pulls, imagePull{ref: image.WithTag(plugin.ImageRef(), "latest"), err: &changes[i].Err})
+		}
+	}
+	supportErrs := make([]error, len(opts.SupportImages))
+	for i, ref := range opts.SupportImages {
+		pulls = append(pulls, imagePull{ref: image.WithTag(ref, "latest"), err: &supportErrs[i]})
+	}
+	pullImages(docker, pulls, opts.Concurrency, opts.Progress)
+	for i, err := range supportErrs {
+		if err != nil {
+			log.WithError(err).WithField("image", opts.SupportImages[i]).Error("failed to pull image")
+		}
+	}
+
+	for i, plugin := range plugins {
+		change := &changes[i]
+		if change.Err == nil {
+			change.New, change.Err = plugin.ResolvedDigest(docker)
+		}
+		if change.Err == nil {
+			lock.Set(LockEntry{
+				Name:     plugin.Name,
+				Image:    change.Image,
+				Digest:   change.New,
+				Built:    built[i],
+				Resolved: time.Now().UTC().Truncate(time.Second),
+			})
+		}
+	}
+
+	if err := lock.Save(); err != nil {
+		log.WithError(err).Error("failed to write plugins lock")
+	}
+	return changes
+}
+
+// imagePull is an image to pull, tagged as tagAs once pulled when it is set.
+// The error of the pull is stored in err.
+type imagePull struct {
+	ref   string
+	tagAs string
+	err   *error
+}
+
+// lockedPull pulls the plugin image by digest and tags it as the plugin's image
+func lockedPull(plugin Plugin, digest string, err *error) imagePull {
+	ref := image.ParseReference(plugin.ImageRef())
+	ref.Tag = ""
+	ref.Digest = digest
+	pull := imagePull{ref: ref.String(), err: err}
+	if plugin.Digest == "" {
+		pull.tagAs = plugin.ImageRef()
+	}
+	return pull
+}
+
+// pullImages runs the pulls on concurrency workers and draws their summed
+// progress on out
+func pullImages(docker *client.Docker, pulls []imagePull, concurrency int, out io.Writer) {
+	if len(pulls) == 0 {
+		return
+	}
+	if concurrency <= 0 {
+		concurrency = DefaultPullConcurrency
+	}
+	if out == nil {
+		out = os.Stdout
+	}
+
+	bar := image.NewPullBar(out, len(pulls))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for _, pull := range pulls {
+		wg.Add(1)
+		go func(pull imagePull) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			err := image.PullProgress(docker, pull.ref, "", bar.Update)
+			if err == nil && pull.tagAs != "" {
+				err = image.Tag(docker, pull.ref, pull.tagAs)
+			}
+			*pull.err = err
+			bar.Finish(pull.ref, err)
+		}(pull)
+	}
+	wg.Wait()
+	bar.Close()
+}
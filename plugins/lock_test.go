@@ -0,0 +1,112 @@
+package plugins
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/maliceio/malice/malice/docker/client/fake"
+	"github.com/maliceio/malice/malice/maldirs"
+)
+
+func TestImageRef(t *testing.T) {
+	const digest = "sha256:4b8c5a0b5ed9a7ec2cd3c7ebd28b9d3c7cc0b2fc4bc1a8b5b0d8a1bdc0c3d4e5"
+
+	tests := []struct {
+		name   string
+		plugin Plugin
+		want   string
+	}{
+		{
+			name:   "unpinned",
+			plugin: Plugin{Image: "malice/avast"},
+			want:   "malice/avast",
+		},
+		{
+			name:   "version",
+			plugin: Plugin{Image: "malice/avast", Version: "0.3.0"},
+			want:   "malice/avast:0.3.0",
+		},
+		{
+			name:   "version and digest",
+			plugin: Plugin{Image: "malice/avast", Version: "0.3.0", Digest: digest},
+			want:   "malice/avast:0.3.0@" + digest,
+		},
+		{
+			name:   "digest already in image",
+			plugin: Plugin{Image: "malice/avast@" + digest, Digest: digest},
+			want:   "malice/avast@" + digest,
+		},
+		{
+			name:   "registry with port",
+			plugin: Plugin{Image: "localhost:5000/avast", Version: "dev"},
+			want:   "localhost:5000/avast:dev",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.plugin.ImageRef(); got != tt.want {
+				t.Errorf("ImageRef() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLockSet(t *testing.T) {
+	var lock Lock
+	lock.Set(LockEntry{Name: "avast", Image: "malice/avast", Digest: "sha256:aaaa"})
+	lock.Set(LockEntry{Name: "yara", Image: "malice/yara", Digest: "sha256:bbbb"})
+	lock.Set(LockEntry{Name: "avast", Image: "malice/avast", Digest: "sha256:cccc"})
+
+	if len(lock.Plugins) != 2 {
+		t.Fatalf("Set() kept %d entries, want 2", len(lock.Plugins))
+	}
+	if entry, ok := lock.Get("avast"); !ok || entry.Digest != "sha256:cccc" {
+		t.Errorf("Get(avast) = %+v, %v, want digest sha256:cccc", entry, ok)
+	}
+	if _, ok := lock.Get("clamav"); ok {
+		t.Error("Get(clamav) found a plugin that was never locked")
+	}
+}
+
+func TestUpdatePlugins(t *testing.T) {
+	defer func(base string) { maldirs.BaseDir = base }(maldirs.BaseDir)
+	maldirs.BaseDir = t.TempDir()
+
+	runtime := fake.New()
+	docker := runtime.Docker()
+	plugins := []Plugin{{Name: "avast", Image: "malice/avast"}, {Name: "yara", Image: "malice/yara", Version: "0.2.0"}}
+
+	changes := UpdatePlugins(docker, plugins, false, false)
+	lock, err := LoadLock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, change := range changes {
+		entry, ok := lock.Get(change.Plugin)
+		if change.Err != nil || change.New == "" || !ok || entry.Digest != change.New || entry.Image != change.Image {
+			t.Errorf("change %+v, lock entry %+v, want the pulled digest locked", change, entry)
+		}
+	}
+
+	// a locked update pulls the locked digests again
+	changes = UpdatePlugins(docker, plugins, false, true)
+	for _, change := range changes {
+		if change.Err != nil || change.Old != change.New {
+			t.Errorf("locked update %+v, want the locked digest", change)
+		}
+	}
+
+	// failed pulls keep the locked digest
+	pullErr := errors.New("registry unreachable")
+	runtime.Fail("ImagePull", pullErr)
+	lock, _ = LoadLock()
+	before, _ := lock.Get("avast")
+	changes = UpdatePlugins(docker, plugins[:1], false, false)
+	if len(changes) != 1 || changes[0].Err != pullErr {
+		t.Errorf("UpdatePlugins() = %+v, want the pull error", changes)
+	}
+	if lock, _ = LoadLock(); lock.Plugins[0] != before {
+		t.Errorf("lock entry = %+v, want %+v kept", lock.Plugins[0], before)
+	}
+}
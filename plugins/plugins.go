@@ -1,50 +1,215 @@
 package plugins
 
 import (
-	"bytes"
+	"context"
 	"fmt"
-	"io/ioutil"
-	"path"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
 
 	"os"
 
-	"github.com/BurntSushi/toml"
 	log "github.com/Sirupsen/logrus"
 	"github.com/docker/docker/api/types/strslice"
 	runconfigopts "github.com/docker/docker/runconfig/opts"
 	"github.com/malice-plugins/pkgs/utils"
 	"github.com/maliceio/malice/config"
+	"github.com/maliceio/malice/malice/delivery"
 	"github.com/maliceio/malice/malice/docker/client"
 	"github.com/maliceio/malice/malice/docker/client/container"
 	"github.com/maliceio/malice/malice/docker/client/image"
-	er "github.com/maliceio/malice/malice/errors"
+	"github.com/maliceio/malice/malice/docker/client/network"
 	"github.com/maliceio/malice/malice/maldirs"
+	"github.com/maliceio/malice/malice/secrets"
+	"github.com/maliceio/malice/utils/clitable"
 	"github.com/parnurzeal/gorequest"
+	"github.com/pkg/errors"
 )
 
-// StartPlugin starts plugin
-func (plugin Plugin) StartPlugin(docker *client.Docker, arg string, scanID string, logs, elasticsearchInDocker bool, wg *sync.WaitGroup) {
+// SigDBLabel is the image label plugins use to advertise their signature database version
+const SigDBLabel = "io.malice.plugin.sigdb.version"
 
-	defer wg.Done()
+// ImageState returns the ID of the plugin's installed image and the signature
+// database version it advertises, together they identify the engine that
+// produced a result
+func (plugin Plugin) ImageState(docker *client.Docker) (digest string, sigDBVersion string, err error) {
+	inspect, err := image.Inspect(docker, plugin.ImageRef())
+	if err != nil {
+		return "", "", errors.Wrapf(err, "failed to inspect plugin %s image", plugin.Name)
+	}
+	if inspect.Config != nil {
+		sigDBVersion = inspect.Config.Labels[SigDBLabel]
+	}
+	return inspect.ID, sigDBVersion, nil
+}
 
-	var links []string
-	cmd := plugin.buildCmd(arg, logs)
-	binds := []string{config.Conf.Docker.Binds} // []string{maldirs.GetSampledsDir() + ":/malware:ro"},
-	env := plugin.getPluginEnv()
+// Result is the outcome of running a plugin against a sample or hash
+type Result struct {
+	Plugin   string
+	Category string
+	ScanID   string
+	ExitCode int64
+	// Data is the plugin's result document, empty when the plugin failed
+	Data map[string]interface{}
+	// Schema is the result schema the output was checked against, e.g. av/v1
+	Schema string
+	// Violations lists every way the output failed to match Schema
+	Violations []string
+	// Cached is true when Data was reused from an earlier scan of the same sample
+	Cached bool
+	// Image and ImageDigest identify the plugin image that produced the result
+	Image       string
+	ImageDigest string
+	Err         error
+}
 
-	env = append(env, "MALICE_SCANID="+scanID)
-	env = append(env, "MALICE_TIMEOUT="+utils.Getopt("MALICE_TIMEOUT", strconv.Itoa(config.Conf.Docker.Timeout)))
-	if elasticsearchInDocker {
-		env = append(env, "MALICE_ELASTICSEARCH_URL=http://elasticsearch:9200")
-		links = []string{config.Conf.Docker.Links}
+// Valid returns true if the plugin ran and its output matched its result schema
+func (r Result) Valid() bool {
+	return r.Err == nil && len(r.Violations) == 0
+}
+
+// printMu keeps the tables of plugins finishing at the same time apart
+var printMu sync.Mutex
+
+// Print prints the result as a markdown table the way plugins print it when
+// run with -t, plugins that render their own table return it as markdown
+func (r Result) Print() {
+	printMu.Lock()
+	defer printMu.Unlock()
+
+	if r.Err != nil || r.Data == nil {
+		return
+	}
+	if markdown, ok := r.Data["markdown"].(string); ok {
+		fmt.Println(markdown)
+		return
+	}
+
+	keys := make([]string, 0, len(r.Data))
+	for key := range r.Data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	fmt.Printf("#### %s\n", r.Plugin)
+	table := clitable.New([]string{"Field", "Value"})
+	for _, key := range keys {
+		table.AddRow(map[string]interface{}{"Field": key, "Value": fmt.Sprint(r.Data[key])})
+	}
+	table.Markdown = true
+	table.Print()
+}
+
+// newResult returns an empty result of the plugin identifying its image
+func (plugin Plugin) newResult(docker *client.Docker, scanID string) Result {
+	result := Result{Plugin: plugin.Name, Category: plugin.Category, ScanID: scanID, Image: plugin.ImageRef()}
+	if schema, ok := plugin.Schema(); ok {
+		result.Schema = schema.ID()
+	}
+	if digest, err := plugin.ResolvedDigest(docker); err == nil {
+		result.ImageDigest = digest
 	} else {
-		env = append(env, "MALICE_ELASTICSEARCH_URL="+utils.Getopt("MALICE_ELASTICSEARCH_URL", config.Conf.DB.URL))
-		env = append(env, "MALICE_ELASTICSEARCH_USERNAME="+utils.Getopt("MALICE_ELASTICSEARCH_USERNAME", config.Conf.DB.Username))
-		env = append(env, "MALICE_ELASTICSEARCH_PASSWORD="+utils.Getopt("MALICE_ELASTICSEARCH_PASSWORD", config.Conf.DB.Password))
-		links = nil
+		log.WithError(err).Debug("unable to resolve plugin image digest")
+	}
+	return result
+}
+
+// checkOutput sets the result data from the plugin output and flags output
+// that does not match the plugin's result schema
+func (plugin Plugin) checkOutput(result *Result, output []byte) {
+	result.Data, result.Violations, result.Err = plugin.ValidateResults(output)
+	if len(result.Violations) > 0 {
+		log.WithFields(log.Fields{
+			"name":       plugin.Name,
+			"schema":     result.Schema,
+			"violations": result.Violations,
+		}).Warn("plugin output does not match its result schema")
+	}
+}
+
+// scanEnv returns the env of one run of the plugin
+func (plugin Plugin) scanEnv(scanID string) []string {
+	timeout := utils.Getopt("MALICE_TIMEOUT", strconv.Itoa(config.Conf.Docker.Timeout))
+	if plugin.timeout > 0 {
+		timeout = strconv.Itoa(int(plugin.timeout.Seconds()))
+	}
+	return []string{"MALICE_SCANID=" + scanID, "MALICE_TIMEOUT=" + timeout}
+}
+
+// databaseEnv returns the env intel plugins need to store their results, the
+// database container is reached by its alias on the network of malice
+func databaseEnv(elasticsearchInDocker bool) []string {
+	if elasticsearchInDocker {
+		return []string{"MALICE_ELASTICSEARCH_URL=http://elasticsearch:9200"}
+	}
+	return []string{
+		"MALICE_ELASTICSEARCH_URL=" + utils.Getopt("MALICE_ELASTICSEARCH_URL", config.Conf.DB.URL),
+		"MALICE_ELASTICSEARCH_USERNAME=" + utils.Getopt("MALICE_ELASTICSEARCH_USERNAME", config.Conf.DB.Username),
+		"MALICE_ELASTICSEARCH_PASSWORD=" + utils.Getopt("MALICE_ELASTICSEARCH_PASSWORD", config.Conf.DB.Password),
+	}
+}
+
+// proxyEnv returns the env pointing intel plugins at the proxy of the network
+// of malice, an internal network has no other way out
+func proxyEnv() []string {
+	if config.Conf.Network.Proxy == "" {
+		return nil
+	}
+	return []string{
+		"HTTP_PROXY=" + config.Conf.Network.Proxy,
+		"HTTPS_PROXY=" + config.Conf.Network.Proxy,
+	}
+}
+
+// StartPlugin runs the plugin container against arg and returns its validated
+// result. File plugins get the sample with SHA256 arg delivered by sample,
+// which is nil when arg is a hash to look up. Only intel plugins join the
+// network of malice and store their own results, the results of file plugins
+// are stored by the caller. A plugin still running once ctx is done or its
+// timeout passed is removed.
+func (plugin Plugin) StartPlugin(ctx context.Context, docker *client.Docker, arg string, scanID string, sample delivery.Strategy, logs, elasticsearchInDocker bool) Result {
+
+	result := plugin.newResult(docker, scanID)
+	ctx, cancel := context.WithTimeout(ctx, plugin.Timeout())
+	defer cancel()
+
+	// a missing image is pulled before it is verified, the verification needs
+	// the pulled image's digest
+	if err := plugin.EnsureImage(docker); err != nil {
+		result.Err = err
+		return result
+	}
+	// plugins run against hostile samples with our data mounted, only run trusted images
+	imageRef, err := plugin.VerifyImage(docker)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	// plugins always print JSON, with logs the result is printed as a table
+	// once it was checked
+	cmd := plugin.buildCmd(arg)
+	if sample != nil {
+		cmd = plugin.buildCmd(sample.Path(arg))
+	}
+	var binds []string
+	env := plugin.getPluginEnv()
+
+	secretEnv, secretBinds, cleanup, err := plugin.injectSecrets(secrets.NewStore(maldirs.GetSecretsDir()))
+	if err != nil {
+		result.Err = errors.Wrapf(err, "failed to inject plugin %s secrets", plugin.Name)
+		return result
+	}
+	defer cleanup()
+	env = append(env, secretEnv...)
+	binds = append(binds, secretBinds...)
+
+	env = append(env, plugin.scanEnv(scanID)...)
+	var net container.Network
+	if strings.Contains(plugin.Category, "intel") {
+		net.Name = network.Name()
+		env = append(env, databaseEnv(elasticsearchInDocker)...)
+		env = append(env, proxyEnv()...)
 	}
 
 	log.WithFields(log.Fields{
@@ -53,31 +218,60 @@ func (plugin Plugin) StartPlugin(docker *client.Docker, arg string, scanID strin
 	}).Debug("env: ", env)
 	// env = append(env, "MALICE_ELASTICSEARCH="+utils.Getopt("MALICE_ELASTICSEARCH", getDbAddr()))
 
-	contJSON, err := container.Start(
+	// the labels let `malice gc` remove the container if this scan dies
+	labels := client.OwnerLabels(scanID, plugin.Name)
+	contJSON, err := container.StartWithSample(
 		docker,             // docker *client.Docker,
 		cmd,                // cmd strslice.StrSlice,
 		plugin.Name+scanID, // name string,
-		plugin.Image,       // image string,
-		logs,               // logs bool,
+		imageRef,           // image string,
+		false,              // logs bool,
 		binds,              // binds []string,
-		nil,                // portBindings nat.PortMap,
-		links,              // links []string,
+		net,                // net container.Network,
 		env,                // env []string,
+		labels,             // labels map[string]string,
+		sample,             // sample delivery.Strategy,
+		arg,                // sha256 string,
 	)
+	if err != nil {
+		result.Err = errors.Wrapf(err, "failed to start plugin %s", plugin.Name)
+		return result
+	}
 	log.WithFields(log.Fields{
 		"name": contJSON.Name,
 		"env":  config.Conf.Environment.Run,
 	}).Debug("Plugin Container Started")
 
 	defer func() {
-		er.CheckError(container.Remove(docker, contJSON.ID, true, false, true))
+		if err := container.Remove(docker, contJSON.ID, true, false, true); err != nil {
+			log.WithError(err).WithField("name", contJSON.Name).Warn("failed to remove plugin container")
+			return
+		}
 		log.WithFields(log.Fields{
 			"name": contJSON.Name,
 			"env":  config.Conf.Environment.Run,
 		}).Debug("Plugin Container Removed")
 	}()
 
-	er.CheckError(err)
+	if result.ExitCode, err = container.Wait(ctx, docker, contJSON.ID); err != nil {
+		if ctx.Err() != nil {
+			result.Err = errors.Wrapf(ctx.Err(), "plugin %s timed out", plugin.Name)
+		} else {
+			result.Err = errors.Wrapf(err, "failed waiting on plugin %s", plugin.Name)
+		}
+		return result
+	}
+	output, err := container.Output(docker, contJSON.ID)
+	if err != nil {
+		result.Err = errors.Wrapf(err, "failed to read plugin %s output", plugin.Name)
+		return result
+	}
+	plugin.checkOutput(&result, output)
+	if logs {
+		result.Print()
+	}
+
+	return result
 }
 
 // getDbAddr gets address of DB server
@@ -86,30 +280,35 @@ func getDbAddr() string {
 }
 
 // buildCmd creates plugin run command
-func (plugin Plugin) buildCmd(args string, logs bool) strslice.StrSlice {
+func (plugin Plugin) buildCmd(args string) strslice.StrSlice {
 
 	cmdStr := strslice.StrSlice{}
-	if plugin.APIKey != "" {
-		cmdStr = append(cmdStr, "--api", plugin.APIKey)
-	}
 	if plugin.Cmd != "" {
 		cmdStr = append(cmdStr, plugin.Cmd)
 	}
-	if logs {
-		cmdStr = append(cmdStr, "-t")
-	}
 	cmdStr = append(cmdStr, args)
 
 	return cmdStr
 }
 
 // RunIntelPlugins run all Intel plugins
-func RunIntelPlugins(docker *client.Docker, hash string, scanID string, logs, elasticsearchInDocker bool) {
+func RunIntelPlugins(docker *client.Docker, hash string, scanID string, logs, elasticsearchInDocker bool) []Result {
+	hashes, err := hashesFor(hash)
+	if err != nil {
+		log.WithError(err).Error("cannot run intel plugins")
+		return nil
+	}
+	return RunIntelPluginsForSample(context.Background(), docker, Profile{}, hashes, scanID, nil, nil, logs, elasticsearchInDocker)
+}
 
-	hashType, _ := utils.GetHashType(hash)
+// RunIntelPluginsForSample runs the Intel plugins of the profile, handing
+// each plugin the sample hash (by type) it supports. The plugin containers
+// count against sem, which bounds the containers of a scan, and wait for the
+// limiter of their provider; either may be nil.
+func RunIntelPluginsForSample(ctx context.Context, docker *client.Docker, profile Profile, hashes map[string]string, scanID string, sem chan struct{}, limiters map[string]*Limiter, logs, elasticsearchInDocker bool) []Result {
 
 	log.Debug("Looking for Intel plugins...")
-	intelPlugins := GetIntelPlugins(hashType, true)
+	intelPlugins := profile.IntelPlugins(hashTypes(hashes))
 	log.Debug("Found these plugins: ")
 	for _, plugin := range intelPlugins {
 		log.Debugf(" - %v", plugin.Name)
@@ -118,10 +317,33 @@ func RunIntelPlugins(docker *client.Docker, hash string, scanID string, logs, el
 	var wg sync.WaitGroup
 	wg.Add(len(intelPlugins))
 
-	for _, plugin := range intelPlugins {
-		go plugin.StartPlugin(docker, hash, scanID, logs, elasticsearchInDocker, &wg)
+	results := make([]Result, len(intelPlugins))
+	for i, plugin := range intelPlugins {
+		go func(i int, plugin Plugin) {
+			defer wg.Done()
+			// wait for the provider before taking a slot other plugins could use
+			if err := limiters[plugin.Name].Wait(ctx); err != nil {
+				results[i] = plugin.newResult(docker, scanID)
+				results[i].Err = err
+				return
+			}
+			if sem != nil {
+				select {
+				case sem <- struct{}{}:
+					defer func() { <-sem }()
+				case <-ctx.Done():
+					results[i] = plugin.newResult(docker, scanID)
+					results[i].Err = ctx.Err()
+					return
+				}
+			}
+			hash, _ := plugin.PickHash(hashes)
+			results[i] = plugin.StartPlugin(ctx, docker, hash, scanID, nil, logs, elasticsearchInDocker)
+		}(i, plugin)
 	}
 	wg.Wait()
+
+	return results
 }
 
 func (plugin *Plugin) getPluginEnv() []string {
@@ -150,83 +372,52 @@ func PostResults(url string, resultJSON []byte, taskID string) {
 		End(printStatus)
 }
 
-//InstallPlugin installs a new malice plugin
-func InstallPlugin(plugin *Plugin) (err error) {
-
-	var newPlugin = Configuration{
-		[]Plugin{
-			Plugin{
-				Name:        plugin.Name,
-				Enabled:     plugin.Enabled,
-				Category:    plugin.Category,
-				Description: plugin.Description,
-				Image:       plugin.Image,
-				Mime:        plugin.Mime,
-			},
-		},
-	}
-
-	buf := new(bytes.Buffer)
-	er.CheckError(toml.NewEncoder(buf).Encode(newPlugin))
-	// fmt.Println(buf.String())
-
-	// open plugin config file
-	configPath := path.Join(maldirs.GetPluginsDir(), "./plugins.toml")
-	f, err := os.OpenFile(configPath, os.O_APPEND|os.O_WRONLY, 0600)
-	if err != nil {
-		panic(err)
-	}
-	defer f.Close()
-	// write new plugin to installed plugin config
-	if _, err = f.WriteString("\n" + buf.String()); err != nil {
-		panic(err)
+// InstallPlugin validates a plugin and registers it in the user's plugins.toml
+func InstallPlugin(plugin *Plugin) error {
+	if err := plugin.Validate(); err != nil {
+		return err
 	}
-	return
+	plugin.Installed = true
+	return editConfig(func(data []byte, config Configuration) ([]byte, error) {
+		if err := checkDuplicate(config.Plugins, *plugin); err != nil {
+			return nil, err
+		}
+		return appendPlugin(data, *plugin)
+	})
 }
 
-// DeletePlugin deletes a plugin
+// DeletePlugin removes a plugin from the user's plugins.toml, keeping the
+// comments and layout of the rest of the file
 func DeletePlugin(name string) error {
-
-	for i, plugin := range Plugs.Plugins {
-		if strings.EqualFold(plugin.Name, name) {
-			Plugs.Plugins = append(Plugs.Plugins[:i], Plugs.Plugins[i+1:]...)
-			break
-		}
-	}
-
-	buf := new(bytes.Buffer)
-	er.CheckError(toml.NewEncoder(buf).Encode(Plugs))
-
-	// open plugin config file
-	configPath := path.Join(maldirs.GetPluginsDir(), "./plugins.toml")
-	err := ioutil.WriteFile(configPath, buf.Bytes(), 0644)
-	return err
+	return editConfig(func(data []byte, _ Configuration) ([]byte, error) {
+		return removePlugin(data, name)
+	})
 }
 
 // InstalledPluginsCheck checks that all enabled plugins are installed
 func InstalledPluginsCheck(docker *client.Docker) bool {
 	for _, plugin := range getEnabled(Plugs.Plugins) {
-		if _, exists, _ := image.Exists(docker, plugin.Image); !exists {
+		if _, exists, _ := image.Exists(docker, plugin.ImageRef()); !exists {
 			return false
 		}
 	}
 	return true
 }
 
-// UpdatePlugin performs a docker pull on all registered plugins checking for updates
-func (plugin Plugin) UpdatePlugin(docker *client.Docker) {
-	image.Pull(docker, plugin.Image, "latest")
+// UpdatePlugin pulls the plugin's pinned image and records its digest in plugins.lock
+func (plugin Plugin) UpdatePlugin(docker *client.Docker) DigestChange {
+	return UpdatePlugins(docker, []Plugin{plugin}, false, false)[0]
 }
 
 // UpdatePluginFromRepository performs a docker build on a plugins remote repository
-func (plugin Plugin) UpdatePluginFromRepository(docker *client.Docker) {
+func (plugin Plugin) UpdatePluginFromRepository(docker *client.Docker) error {
 
 	log.Info("[Building Plugin from Source] ===> ", plugin.Name)
 
 	var buildArgs map[string]*string
 	var quiet = false
 
-	tags := []string{"malice/" + plugin.Name + ":latest"}
+	tags := []string{image.WithTag(plugin.Image, plugin.Version)}
 
 	if config.Conf.Proxy.Enable {
 		buildArgs = runconfigopts.ConvertKVStringsToMapWithNil([]string{
@@ -239,48 +430,35 @@ func (plugin Plugin) UpdatePluginFromRepository(docker *client.Docker) {
 
 	labels := runconfigopts.ConvertKVStringsToMap([]string{"io.malice.plugin.installed.from=repository"})
 
-	image.Build(docker, plugin.Repository, tags, buildArgs, labels, quiet)
+	if err := image.Build(docker, plugin.Repository, tags, buildArgs, labels, quiet); err != nil {
+		return errors.Wrapf(err, "failed to build plugin %s", plugin.Name)
+	}
+	return nil
 }
 
 // UpdateEnabledPlugins performs a docker pull on all enabled plugins checking for updates
-func UpdateEnabledPlugins(docker *client.Docker) {
-	// Pull busybox (used to copy samples to malice volume)
-	image.Pull(docker, "busybox", "latest")
-	// Pull blacktop/elk (used to store malice scan results data)
-	image.Pull(docker, config.Conf.DB.Image, "latest")
-	// Pull all enabled malice plugin images
-	for _, plugin := range GetEnabledPlugins() {
-		fmt.Println("[Updating Plugin] ===> ", plugin.Name)
-		if plugin.Build {
-			plugin.UpdatePluginFromRepository(docker)
-		} else {
-			image.Pull(docker, plugin.Image, "latest")
-		}
-	}
+func UpdateEnabledPlugins(docker *client.Docker) []DigestChange {
+	return UpdatePluginsWithOptions(docker, GetEnabledPlugins(), UpdateOptions{SupportImages: SupportImages()})
+}
+
+// InstallEnabledPlugins pulls all enabled plugins, reusing the digests
+// recorded in plugins.lock so every machine runs the same images
+func InstallEnabledPlugins(docker *client.Docker) []DigestChange {
+	return UpdatePluginsWithOptions(docker, GetEnabledPlugins(), UpdateOptions{Locked: true, SupportImages: SupportImages()})
 }
 
 // UpdateAllPlugins performs a docker pull on all registered plugins checking for updates
-func UpdateAllPlugins(docker *client.Docker) {
-	// Pull busybox (used to copy samples to malice volume)
-	image.Pull(docker, "busybox", "latest")
-	// Pull blacktop/elk (used to store malice scan results data)
-	image.Pull(docker, config.Conf.DB.Image, "latest")
-	plugins := Plugs.Plugins
-	for _, plugin := range plugins {
-		fmt.Println("[Updating Plugin] ===> ", plugin.Name)
-		if plugin.Build {
-			plugin.UpdatePluginFromRepository(docker)
-		} else {
-			image.Pull(docker, plugin.Image, "latest")
-		}
-	}
+func UpdateAllPlugins(docker *client.Docker) []DigestChange {
+	return UpdatePluginsWithOptions(docker, Plugs.Plugins, UpdateOptions{SupportImages: SupportImages()})
 }
 
 // UpdateAllPluginsFromSource performs a docker build on a plugins remote repository on all registered plugins
-func UpdateAllPluginsFromSource(docker *client.Docker) {
-	plugins := Plugs.Plugins
-	for _, plugin := range plugins {
-		fmt.Println("[Updating Plugin from Source] ===> ", plugin.Name)
-		plugin.UpdatePluginFromRepository(docker)
-	}
+func UpdateAllPluginsFromSource(docker *client.Docker) []DigestChange {
+	return UpdatePlugins(docker, Plugs.Plugins, true, false)
+}
+
+// SupportImages returns the images malice needs besides its plugins
+func SupportImages() []string {
+	// blacktop/elk is used to store malice scan results data
+	return []string{config.Conf.DB.Image}
 }
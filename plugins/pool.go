@@ -0,0 +1,420 @@
+package plugins
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/maliceio/malice/config"
+	"github.com/maliceio/malice/malice/delivery"
+	"github.com/maliceio/malice/malice/docker/client"
+	"github.com/maliceio/malice/malice/docker/client/container"
+	"github.com/maliceio/malice/malice/docker/client/image"
+	"github.com/maliceio/malice/malice/maldirs"
+	"github.com/maliceio/malice/malice/secrets"
+	"github.com/pkg/errors"
+)
+
+// PoolLabel is the container label holding the plugin of a warm container
+const PoolLabel = "io.malice.pool"
+
+// removeSampleTimeout bounds removing a scanned sample from a warm container
+const removeSampleTimeout = 30 * time.Second
+
+// PoolOptions configures a Pool
+type PoolOptions struct {
+	// Size is the number of warm containers of plugins without a pool_size
+	Size int
+	// MaxScans replaces a container after it scanned this many samples, it
+	// never is with 0
+	MaxScans int
+	// HealthInterval is how often idle containers are checked, they aren't with 0
+	HealthInterval time.Duration
+	// Delivery is the strategy the scans prepare samples with, stdin and
+	// store are replaced by copy as plugins are exec'd in running containers,
+	// which can't get new mounts. Without
+	// it the argument of Run is passed to the plugins as is.
+	Delivery delivery.Strategy
+}
+
+// PoolOptionsFromConfig returns the pool options set in the config
+func PoolOptionsFromConfig() (PoolOptions, error) {
+	opts := PoolOptions{Size: config.Conf.Pool.Size, MaxScans: config.Conf.Pool.MaxScans}
+	if opts.Size <= 0 {
+		opts.Size = 1
+	}
+	if config.Conf.Pool.HealthInterval != "" {
+		interval, err := time.ParseDuration(config.Conf.Pool.HealthInterval)
+		if err != nil {
+			return opts, errors.Wrapf(err, "invalid pool health_interval %q in config", config.Conf.Pool.HealthInterval)
+		}
+		opts.HealthInterval = interval
+	}
+	return opts, nil
+}
+
+// Pool keeps containers of plugins running and scans samples by exec'ing the
+// plugin's command in them, instead of creating a container per sample, so
+// engines load their signature databases once per container.
+type Pool struct {
+	docker *client.Docker
+	opts   PoolOptions
+	sample delivery.Strategy
+
+	mu     sync.Mutex
+	pools  map[string]*pluginPool
+	closed bool
+	stop   chan struct{}
+	wg     sync.WaitGroup
+}
+
+// pluginPool holds the warm containers of a plugin
+type pluginPool struct {
+	plugin Plugin
+	// image is the verified image the containers run
+	image string
+	// entrypoint of the image, the containers idle instead of running it
+	entrypoint []string
+	binds      []string
+	env        []string
+	cleanup    func()
+	// idle holds the containers waiting for a sample, slots the containers
+	// running, both have the size of the pool
+	idle  chan *warmContainer
+	slots chan struct{}
+}
+
+type warmContainer struct {
+	id    string
+	name  string
+	scans int
+}
+
+// NewPool returns an empty pool, plugins are warmed up by Warm or on their
+// first Run. Close removes the containers.
+func NewPool(docker *client.Docker, opts PoolOptions) *Pool {
+	if opts.Size <= 0 {
+		opts.Size = 1
+	}
+	p := &Pool{
+		docker: docker,
+		opts:   opts,
+		pools:  make(map[string]*pluginPool),
+		stop:   make(chan struct{}),
+	}
+	if opts.Delivery != nil {
+		p.sample = delivery.Running(opts.Delivery)
+	}
+	if opts.HealthInterval > 0 {
+		p.wg.Add(1)
+		go p.checkHealthEvery(opts.HealthInterval)
+	}
+	return p
+}
+
+// Warm starts the warm containers of the plugins
+func (p *Pool) Warm(plugins []Plugin) error {
+	var failed []string
+	for _, plugin := range plugins {
+		pool, err := p.pluginPool(plugin)
+		if err == nil {
+			err = p.fill(pool)
+		}
+		if err != nil {
+			log.WithError(err).WithField("plugin", plugin.Name).Error("failed to warm up plugin")
+			failed = append(failed, plugin.Name)
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to warm up plugins %v", failed)
+	}
+	return nil
+}
+
+// Run scans arg with the plugin in one of its warm containers, waiting for
+// one while all of them are busy
+func (p *Pool) Run(ctx context.Context, plugin Plugin, arg, scanID string) Result {
+	result := plugin.newResult(p.docker, scanID)
+
+	pool, err := p.pluginPool(plugin)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, plugin.Timeout())
+	defer cancel()
+	warm, err := p.get(ctx, pool)
+	if err != nil {
+		result.Err = errors.Wrapf(err, "no warm container for plugin %s", plugin.Name)
+		return result
+	}
+
+	path := arg
+	if p.sample != nil {
+		if err := p.sample.Deliver(ctx, p.docker, warm.id, arg); err != nil {
+			if !p.put(pool, warm, true) {
+				p.refill(pool)
+			}
+			result.Err = errors.Wrapf(err, "failed to deliver sample to plugin %s", plugin.Name)
+			return result
+		}
+		path = p.sample.Path(arg)
+	}
+	cmd := append(append([]string{}, pool.entrypoint...), plugin.buildCmd(path)...)
+	exitCode, output, err := container.Exec(ctx, p.docker, warm.id, cmd, plugin.scanEnv(scanID))
+	warm.scans++
+	// a failed exec, e.g. one that timed out, may leave the engine busy or broken
+	healthy := err == nil
+	if healthy && p.sample != nil && p.sample.Name() == delivery.Copy {
+		// the next scans in the container must not see the sample, a container
+		// it can't be removed from is replaced
+		healthy = p.removeSample(warm, delivery.SampleDir+"/"+path) == nil
+	}
+	if !p.put(pool, warm, healthy) {
+		p.refill(pool)
+	}
+
+	result.ExitCode = exitCode
+	if err != nil {
+		result.Err = errors.Wrapf(err, "failed to run plugin %s in container %s", plugin.Name, warm.name)
+		return result
+	}
+	plugin.checkOutput(&result, output)
+	return result
+}
+
+// removeSample removes a copied sample from the warm container. It doesn't
+// use the scan's context, which may have expired with the scan.
+func (p *Pool) removeSample(warm *warmContainer, path string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), removeSampleTimeout)
+	defer cancel()
+	exitCode, _, err := container.Exec(ctx, p.docker, warm.id, []string{"rm", "-f", path}, nil)
+	if err == nil && exitCode != 0 {
+		err = fmt.Errorf("rm exited with %d", exitCode)
+	}
+	if err != nil {
+		log.WithError(err).WithField("container", warm.name).Warn("failed to remove sample from warm container")
+	}
+	return err
+}
+
+// Close removes the warm containers, containers scanning a sample are
+// removed once they are done
+func (p *Pool) Close() {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return
+	}
+	p.closed = true
+	close(p.stop)
+	pools := p.pools
+	p.mu.Unlock()
+
+	p.wg.Wait()
+	for _, pool := range pools {
+		for drained := false; !drained; {
+			select {
+			case warm := <-pool.idle:
+				p.remove(pool, warm)
+			default:
+				drained = true
+			}
+		}
+		pool.cleanup()
+	}
+}
+
+func (p *Pool) isClosed() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.closed
+}
+
+// pluginPool returns the pool of the plugin, creating it on first use
+func (p *Pool) pluginPool(plugin Plugin) (*pluginPool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return nil, errors.New("the plugin pool is closed")
+	}
+	if pool, ok := p.pools[plugin.Name]; ok {
+		return pool, nil
+	}
+
+	if err := plugin.EnsureImage(p.docker); err != nil {
+		return nil, err
+	}
+	// plugins run against hostile samples with our data mounted, only run trusted images
+	imageRef, err := plugin.VerifyImage(p.docker)
+	if err != nil {
+		return nil, err
+	}
+	inspect, err := image.Inspect(p.docker, imageRef)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to inspect plugin %s image", plugin.Name)
+	}
+	secretEnv, secretBinds, cleanup, err := plugin.injectSecrets(secrets.NewStore(maldirs.GetSecretsDir()))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to inject plugin %s secrets", plugin.Name)
+	}
+
+	size := p.opts.Size
+	if plugin.PoolSize > 0 {
+		size = plugin.PoolSize
+	}
+	pool := &pluginPool{
+		plugin:  plugin,
+		image:   imageRef,
+		binds:   secretBinds,
+		env:     append(plugin.getPluginEnv(), secretEnv...),
+		cleanup: cleanup,
+		idle:    make(chan *warmContainer, size),
+		slots:   make(chan struct{}, size),
+	}
+	if inspect.Config != nil {
+		pool.entrypoint = inspect.Config.Entrypoint
+	}
+	p.pools[plugin.Name] = pool
+	return pool, nil
+}
+
+// get takes an idle container of the pool, starting one while the pool isn't full
+func (p *Pool) get(ctx context.Context, pool *pluginPool) (*warmContainer, error) {
+	select {
+	case warm := <-pool.idle:
+		return warm, nil
+	default:
+	}
+	select {
+	case warm := <-pool.idle:
+		return warm, nil
+	case pool.slots <- struct{}{}:
+		warm, err := p.start(ctx, pool)
+		if err != nil {
+			<-pool.slots
+			return nil, err
+		}
+		return warm, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// put returns a container to the pool, unhealthy and worn out containers
+// and those of a closed pool are removed instead and put returns false
+func (p *Pool) put(pool *pluginPool, warm *warmContainer, healthy bool) bool {
+	wornOut := p.opts.MaxScans > 0 && warm.scans >= p.opts.MaxScans
+	if !healthy || wornOut || p.isClosed() {
+		if wornOut {
+			log.WithFields(log.Fields{"plugin": pool.plugin.Name, "container": warm.name, "scans": warm.scans}).Debug("recycling warm container")
+		}
+		p.remove(pool, warm)
+		return false
+	}
+	pool.idle <- warm
+	return true
+}
+
+// fill starts containers until the pool is full
+func (p *Pool) fill(pool *pluginPool) error {
+	for !p.isClosed() {
+		select {
+		case pool.slots <- struct{}{}:
+		default:
+			return nil
+		}
+		warm, err := p.start(context.Background(), pool)
+		if err != nil {
+			<-pool.slots
+			return err
+		}
+		pool.idle <- warm
+	}
+	return nil
+}
+
+// refill fills the pool in the background
+func (p *Pool) refill(pool *pluginPool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return
+	}
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		if err := p.fill(pool); err != nil {
+			log.WithError(err).WithField("plugin", pool.plugin.Name).Error("failed to replace warm container")
+		}
+	}()
+}
+
+func (p *Pool) start(ctx context.Context, pool *pluginPool) (*warmContainer, error) {
+	name := client.ContainerName(pool.plugin.Name + "-warm")
+	labels := client.OwnerLabels("", pool.plugin.Name)
+	labels[PoolLabel] = pool.plugin.Name
+	id, err := container.StartIdle(ctx, p.docker, name, pool.image, pool.binds, pool.env, labels, p.sample)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to start warm container for plugin %s", pool.plugin.Name)
+	}
+	log.WithFields(log.Fields{"plugin": pool.plugin.Name, "container": name}).Debug("started warm container")
+	return &warmContainer{id: id, name: name}, nil
+}
+
+// remove removes the container and frees its slot in the pool
+func (p *Pool) remove(pool *pluginPool, warm *warmContainer) {
+	if err := container.Remove(p.docker, warm.id, true, false, true); err != nil {
+		log.WithError(err).WithFields(log.Fields{"plugin": pool.plugin.Name, "container": warm.name}).Warn("failed to remove warm container")
+	}
+	<-pool.slots
+}
+
+func (p *Pool) checkHealthEvery(interval time.Duration) {
+	defer p.wg.Done()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-ticker.C:
+			p.checkHealth()
+		}
+	}
+}
+
+// checkHealth replaces the idle containers that stopped running
+func (p *Pool) checkHealth() {
+	p.mu.Lock()
+	pools := make([]*pluginPool, 0, len(p.pools))
+	for _, pool := range p.pools {
+		pools = append(pools, pool)
+	}
+	p.mu.Unlock()
+
+	for _, pool := range pools {
+		for i := len(pool.idle); i > 0; i-- {
+			var warm *warmContainer
+			select {
+			case warm = <-pool.idle:
+			default:
+			}
+			if warm == nil {
+				break
+			}
+			inspect, err := container.Inspect(p.docker, warm.id)
+			healthy := err == nil && inspect.ContainerJSONBase != nil && inspect.State != nil && inspect.State.Running
+			if !healthy {
+				log.WithFields(log.Fields{"plugin": pool.plugin.Name, "container": warm.name}).Warn("replacing dead warm container")
+			}
+			p.put(pool, warm, healthy)
+		}
+		if err := p.fill(pool); err != nil {
+			log.WithError(err).WithField("plugin", pool.plugin.Name).Error("failed to replace warm container")
+		}
+	}
+}
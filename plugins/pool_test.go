@@ -0,0 +1,105 @@
+package plugins
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/maliceio/malice/malice/delivery"
+	"github.com/maliceio/malice/malice/docker/client/container"
+	"github.com/maliceio/malice/malice/docker/client/fake"
+	"github.com/maliceio/malice/malice/maldirs"
+)
+
+const poolSHA256 = "275a021bbfb6489e54d471899f7db9d1663fc695ec2fe2a2c4538aabf651fd0f"
+
+func TestPoolRun(t *testing.T) {
+	defer func(base string) { maldirs.BaseDir = base }(maldirs.BaseDir)
+	maldirs.BaseDir = t.TempDir()
+
+	runtime := fake.New()
+	runtime.AddImage("malice/avast", "sha256:avast", nil)
+	runtime.Script("malice/avast", fake.Behavior{Stdout: `{"avast":{"infected":false}}`})
+	docker := runtime.Docker()
+
+	// warm containers can't read stdin, they get a copy of the sample
+	sample, err := delivery.New(delivery.Stdin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	samplePath := filepath.Join(t.TempDir(), "sample")
+	if err := os.WriteFile(samplePath, []byte("X5O!P%@AP"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := sample.Prepare(samplePath, poolSHA256); err != nil {
+		t.Fatal(err)
+	}
+
+	pool := NewPool(docker, PoolOptions{Size: 1, MaxScans: 2, Delivery: sample})
+	plugin := Plugin{Name: "avast", Category: "av", Image: "malice/avast", Cmd: "-V"}
+	for i := 0; i < 3; i++ {
+		result := pool.Run(context.Background(), plugin, poolSHA256, "scan1")
+		if result.Err != nil || result.ExitCode != 0 || result.Data == nil {
+			t.Fatalf("scan %d: Run() = %+v, want the plugin output", i, result)
+		}
+	}
+	running, err := container.List(docker, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(running) != 1 {
+		t.Fatalf("got %d running containers, want 1 warm container", len(running))
+	}
+	// the sample is removed after the scan, later scans mustn't see it
+	rm := "rm -f " + delivery.SampleDir + "/" + poolSHA256
+	if execs := runtime.Execs(running[0].ID); len(execs) != 2 || execs[0][len(execs[0])-1] != poolSHA256 || strings.Join(execs[1], " ") != rm {
+		t.Errorf("recycled container ran %v, want the third scan and %q only", execs, rm)
+	}
+
+	if copies := runtime.Count("CopyToContainer"); copies != 3 {
+		t.Errorf("sample copied %d times, want once per scan", copies)
+	}
+
+	pool.Close()
+	if got := runtime.Count("ContainerCreate"); got != 2 {
+		t.Errorf("created %d containers, want 2 after recycling once", got)
+	}
+	if all, _ := container.List(docker, true); len(all) != 0 {
+		t.Errorf("warm containers left behind: %+v", all)
+	}
+}
+
+func TestPoolCheckHealth(t *testing.T) {
+	defer func(base string) { maldirs.BaseDir = base }(maldirs.BaseDir)
+	maldirs.BaseDir = t.TempDir()
+
+	runtime := fake.New()
+	runtime.AddImage("malice/clamav", "sha256:clamav", nil)
+	docker := runtime.Docker()
+
+	pool := NewPool(docker, PoolOptions{Size: 1})
+	defer pool.Close()
+	plugin := Plugin{Name: "clamav", Category: "av", Image: "malice/clamav", PoolSize: 2}
+	if err := pool.Warm([]Plugin{plugin}); err != nil {
+		t.Fatal(err)
+	}
+	running, _ := container.List(docker, false)
+	if len(running) != 2 {
+		t.Fatalf("warmed up %d containers, want the plugin's pool_size of 2", len(running))
+	}
+
+	runtime.Kill(running[0].ID)
+	pool.checkHealth()
+
+	after, _ := container.List(docker, false)
+	if len(after) != 2 {
+		t.Fatalf("got %d running containers after the health check, want 2", len(after))
+	}
+	for _, c := range after {
+		if c.ID == running[0].ID {
+			t.Errorf("dead container %s was kept", c.ID)
+		}
+	}
+}
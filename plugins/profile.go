@@ -0,0 +1,192 @@
+package plugins
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/maliceio/malice/config"
+	"github.com/pkg/errors"
+)
+
+// Profile is a named scan preset from the config. It selects the plugins of
+// a scan instead of their enabled flag and can override the scan's limits.
+// The zero Profile selects the enabled plugins.
+type Profile struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	// Plugins and Categories select plugins by name and category
+	Plugins    []string `json:"plugins,omitempty"`
+	Categories []string `json:"categories,omitempty"`
+	// All selects every installed plugin
+	All bool `json:"all,omitempty"`
+	// Timeout limits each plugin run, ScanTimeout the scan of one sample
+	Timeout     time.Duration `json:"-"`
+	ScanTimeout time.Duration `json:"-"`
+	// Concurrency bounds the plugin containers running at once
+	Concurrency int `json:"concurrency,omitempty"`
+}
+
+// MarshalJSON renders the timeouts as durations like the config does
+func (p Profile) MarshalJSON() ([]byte, error) {
+	type profile Profile
+	duration := func(d time.Duration) string {
+		if d == 0 {
+			return ""
+		}
+		return d.String()
+	}
+	return json.Marshal(struct {
+		profile
+		Timeout     string `json:"timeout,omitempty"`
+		ScanTimeout string `json:"scan_timeout,omitempty"`
+	}{profile(p), duration(p.Timeout), duration(p.ScanTimeout)})
+}
+
+// GetProfile returns the named profile from the config, the zero Profile if
+// name is empty
+func GetProfile(name string) (Profile, error) {
+	if name == "" {
+		return Profile{}, nil
+	}
+	conf, ok := config.Conf.Profiles[name]
+	if !ok {
+		return Profile{}, fmt.Errorf("unknown profile %q, expected one of %s", name, strings.Join(profileNames(), ", "))
+	}
+
+	profile := Profile{
+		Name:        name,
+		Description: conf.Description,
+		Plugins:     conf.Plugins,
+		Categories:  conf.Categories,
+		All:         conf.All,
+		Concurrency: conf.Concurrency,
+	}
+	if !profile.All && len(profile.Plugins) == 0 && len(profile.Categories) == 0 {
+		return profile, fmt.Errorf("profile %s selects no plugins, set plugins, categories or all", name)
+	}
+	if profile.Concurrency < 0 {
+		return profile, fmt.Errorf("profile %s concurrency must not be negative", name)
+	}
+	var err error
+	if profile.Timeout, err = parseProfileDuration(conf.Timeout); err != nil {
+		return profile, errors.Wrapf(err, "profile %s timeout", name)
+	}
+	if profile.ScanTimeout, err = parseProfileDuration(conf.ScanTimeout); err != nil {
+		return profile, errors.Wrapf(err, "profile %s scan_timeout", name)
+	}
+	return profile, nil
+}
+
+// Profiles returns every profile in the config sorted by name
+func Profiles() ([]Profile, error) {
+	var profiles []Profile
+	for _, name := range profileNames() {
+		profile, err := GetProfile(name)
+		if err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, profile)
+	}
+	return profiles, nil
+}
+
+func profileNames() []string {
+	names := make([]string, 0, len(config.Conf.Profiles))
+	for name := range config.Conf.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func parseProfileDuration(value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err == nil && d < 0 {
+		err = fmt.Errorf("%s is negative", value)
+	}
+	return d, err
+}
+
+// Selects returns true if the profile runs the plugin
+func (p Profile) Selects(plugin Plugin) bool {
+	if p.Name == "" {
+		return plugin.Enabled
+	}
+	if p.All {
+		return true
+	}
+	for _, name := range p.Plugins {
+		if strings.EqualFold(name, plugin.Name) {
+			return true
+		}
+	}
+	for _, category := range p.Categories {
+		if strings.EqualFold(category, plugin.Category) {
+			return true
+		}
+	}
+	return false
+}
+
+// selectPlugins returns the plugins the profile runs with its timeout applied
+func (p Profile) selectPlugins(plugins []Plugin) []Plugin {
+	selected := []Plugin{}
+	for _, plugin := range plugins {
+		if p.Selects(plugin) {
+			plugin.timeout = p.Timeout
+			selected = append(selected, plugin)
+		}
+	}
+	return selected
+}
+
+// PluginsForMime returns the installed plugins of the profile that can scan
+// the mime type
+func (p Profile) PluginsForMime(mime string) []Plugin {
+	if p.Name == "" {
+		return GetPluginsForMime(mime, true)
+	}
+	return getMime(mime, p.selectPlugins(getInstalled()))
+}
+
+// FilePlugins returns the installed plugins of the profile that scan files
+// rather than look up hashes
+func (p Profile) FilePlugins() []Plugin {
+	filePlugins := []Plugin{}
+	for _, plugin := range p.PluginsForMime("") {
+		if !strings.Contains(plugin.Category, "intel") {
+			filePlugins = append(filePlugins, plugin)
+		}
+	}
+	return filePlugins
+}
+
+// IntelPlugins returns the installed intel plugins of the profile that
+// support one of the hash types and have their required credentials set
+func (p Profile) IntelPlugins(hashTypes []string) []Plugin {
+	if p.Name == "" {
+		return getIntelPlugins(hashTypes, true)
+	}
+	return selectIntelPlugins(getIntel(p.selectPlugins(getInstalled())), hashTypes)
+}
+
+// defaultPluginTimeout limits plugin runs when neither the profile nor the
+// config set a timeout
+const defaultPluginTimeout = 2 * time.Minute
+
+// Timeout returns how long the plugin may take to scan a sample
+func (plugin Plugin) Timeout() time.Duration {
+	if plugin.timeout > 0 {
+		return plugin.timeout
+	}
+	if config.Conf.Docker.Timeout > 0 {
+		return time.Duration(config.Conf.Docker.Timeout) * time.Second
+	}
+	return defaultPluginTimeout
+}
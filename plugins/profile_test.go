@@ -0,0 +1,123 @@
+package plugins
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/maliceio/malice/config"
+)
+
+const testProfiles = `
+[profile.quick]
+  description = "fast triage"
+  categories = ["intel"]
+  plugins = ["clamav", "YARA"]
+  timeout = "1m"
+  concurrency = 4
+
+[profile.full]
+  all = true
+  scan_timeout = "1h"
+
+[profile.empty]
+  description = "selects nothing"
+
+[profile.slow]
+  all = true
+  timeout = "forever"
+`
+
+func loadTestProfiles(t *testing.T) {
+	saved := config.Conf
+	t.Cleanup(func() { config.Conf = saved })
+	config.Conf.Docker.Timeout = 0
+	config.Conf.Profiles = nil
+	if _, err := toml.Decode(testProfiles, &config.Conf); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGetProfile(t *testing.T) {
+	loadTestProfiles(t)
+
+	tests := []struct {
+		name    string
+		want    Profile
+		wantErr string
+	}{
+		{name: "", want: Profile{}},
+		{name: "quick", want: Profile{Name: "quick", Description: "fast triage", Categories: []string{"intel"}, Plugins: []string{"clamav", "YARA"}, Timeout: time.Minute, Concurrency: 4}},
+		{name: "full", want: Profile{Name: "full", All: true, ScanTimeout: time.Hour}},
+		{name: "empty", wantErr: "selects no plugins"},
+		{name: "slow", wantErr: "profile slow timeout"},
+		{name: "nightly", wantErr: `unknown profile "nightly", expected one of empty, full, quick, slow`},
+	}
+
+	for _, tt := range tests {
+		got, err := GetProfile(tt.name)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("GetProfile(%q) error = %v, want %q", tt.name, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("GetProfile(%q) error = %v", tt.name, err)
+			continue
+		}
+		gotJSON, _ := json.Marshal(got)
+		wantJSON, _ := json.Marshal(tt.want)
+		if string(gotJSON) != string(wantJSON) || got.Timeout != tt.want.Timeout || got.ScanTimeout != tt.want.ScanTimeout {
+			t.Errorf("GetProfile(%q) = %s, want %s", tt.name, gotJSON, wantJSON)
+		}
+	}
+}
+
+func TestProfileSelects(t *testing.T) {
+	loadTestProfiles(t)
+	quick, _ := GetProfile("quick")
+	full, _ := GetProfile("full")
+
+	tests := []struct {
+		plugin              Plugin
+		none, quick, isFull bool
+	}{
+		{plugin: Plugin{Name: "nsrl", Category: "intel", Enabled: true}, none: true, quick: true, isFull: true},
+		{plugin: Plugin{Name: "yara", Category: "av"}, quick: true, isFull: true},
+		{plugin: Plugin{Name: "avast", Category: "av", Enabled: true}, none: true, isFull: true},
+		{plugin: Plugin{Name: "pdf", Category: "document"}, isFull: true},
+	}
+
+	for _, tt := range tests {
+		if got := (Profile{}).Selects(tt.plugin); got != tt.none {
+			t.Errorf("no profile Selects(%s) = %t, want %t", tt.plugin.Name, got, tt.none)
+		}
+		if got := quick.Selects(tt.plugin); got != tt.quick {
+			t.Errorf("quick Selects(%s) = %t, want %t", tt.plugin.Name, got, tt.quick)
+		}
+		if got := full.Selects(tt.plugin); got != tt.isFull {
+			t.Errorf("full Selects(%s) = %t, want %t", tt.plugin.Name, got, tt.isFull)
+		}
+	}
+
+	selected := quick.selectPlugins([]Plugin{{Name: "clamav", Category: "av"}, {Name: "avast", Category: "av"}})
+	if len(selected) != 1 || selected[0].Timeout() != time.Minute {
+		t.Errorf("quick selectPlugins() = %+v, want clamav with a 1m timeout", selected)
+	}
+	if got := (Plugin{Name: "avast"}).Timeout(); got != defaultPluginTimeout {
+		t.Errorf("Timeout() without profile or config = %s, want %s", got, defaultPluginTimeout)
+	}
+}
+
+func TestProfileJSON(t *testing.T) {
+	data, err := json.Marshal(Profile{Name: "quick", Plugins: []string{"clamav"}, Timeout: time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"name":"quick","plugins":["clamav"],"timeout":"1m0s"}`; string(data) != want {
+		t.Errorf("json = %s, want %s", data, want)
+	}
+}
@@ -0,0 +1,105 @@
+package plugins
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+// RateInterval parses the plugin's rate_limit setting, e.g. "4/m" for four
+// requests a minute, into the minimum time between two requests.
+// Plugins without a rate limit return zero.
+func (plugin Plugin) RateInterval() (time.Duration, error) {
+	if plugin.RateLimit == "" {
+		return 0, nil
+	}
+
+	parts := strings.SplitN(plugin.RateLimit, "/", 2)
+	if len(parts) != 2 {
+		return 0, fmt.Errorf("invalid rate_limit %q for plugin %s, expected N/s, N/m or N/h", plugin.RateLimit, plugin.Name)
+	}
+
+	count, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+	if err != nil || count < 1 {
+		return 0, fmt.Errorf("invalid rate_limit %q for plugin %s, count must be a positive integer", plugin.RateLimit, plugin.Name)
+	}
+
+	var period time.Duration
+	switch strings.TrimSpace(parts[1]) {
+	case "s":
+		period = time.Second
+	case "m":
+		period = time.Minute
+	case "h":
+		period = time.Hour
+	case "d":
+		period = 24 * time.Hour
+	default:
+		return 0, fmt.Errorf("invalid rate_limit %q for plugin %s, unknown period %q", plugin.RateLimit, plugin.Name, parts[1])
+	}
+
+	return period / time.Duration(count), nil
+}
+
+// Limiter spaces out requests to a rate limited provider
+type Limiter struct {
+	interval time.Duration
+	mu       sync.Mutex
+	next     time.Time
+}
+
+// NewLimiter creates a Limiter allowing one request every interval
+func NewLimiter(interval time.Duration) *Limiter {
+	return &Limiter{interval: interval}
+}
+
+// ProviderLimiters creates a limiter for every plugin with a rate_limit, to be
+// shared by all requests to its provider
+func ProviderLimiters(intelPlugins []Plugin) map[string]*Limiter {
+	limiters := make(map[string]*Limiter)
+	for _, plugin := range intelPlugins {
+		interval, err := plugin.RateInterval()
+		if err != nil {
+			log.WithError(err).Warn("ignoring rate limit")
+			continue
+		}
+		if interval > 0 {
+			limiters[plugin.Name] = NewLimiter(interval)
+		}
+	}
+	return limiters
+}
+
+// Wait blocks until the next request is allowed or ctx is done
+func (l *Limiter) Wait(ctx context.Context) error {
+	if l == nil || l.interval <= 0 {
+		return nil
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	delay := l.next.Sub(now)
+	l.next = l.next.Add(l.interval)
+	l.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
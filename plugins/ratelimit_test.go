@@ -0,0 +1,57 @@
+package plugins
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRateInterval(t *testing.T) {
+	tests := []struct {
+		name      string
+		rateLimit string
+		want      time.Duration
+		wantError bool
+	}{
+		{name: "no limit", rateLimit: "", want: 0},
+		{name: "per minute", rateLimit: "4/m", want: 15 * time.Second},
+		{name: "per second", rateLimit: "10/s", want: 100 * time.Millisecond},
+		{name: "per day", rateLimit: "500/d", want: 24 * time.Hour / 500},
+		{name: "missing period", rateLimit: "4", wantError: true},
+		{name: "zero count", rateLimit: "0/m", wantError: true},
+		{name: "unknown period", rateLimit: "4/w", wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Plugin{Name: "virustotal", RateLimit: tt.rateLimit}.RateInterval()
+			if (err != nil) != tt.wantError {
+				t.Fatalf("RateInterval() error = %v, want error %v", err, tt.wantError)
+			}
+			if got != tt.want {
+				t.Errorf("RateInterval() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLimiterWait(t *testing.T) {
+	limiter := NewLimiter(50 * time.Millisecond)
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := limiter.Wait(context.Background()); err != nil {
+			t.Fatalf("Wait() error = %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Errorf("3 requests took %v, want at least 100ms", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	limiter.Wait(ctx)
+	if err := limiter.Wait(ctx); err != context.Canceled {
+		t.Errorf("Wait() on cancelled context error = %v, want %v", err, context.Canceled)
+	}
+}
@@ -0,0 +1,278 @@
+package plugins
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Languages are the plugin templates `malice plugin init` can render
+var Languages = []string{"go", "python"}
+
+// templatesDir is the embedded directory holding a template per language
+const templatesDir = "plugins/templates"
+
+// scaffoldVersion is the version of a newly generated plugin
+const scaffoldVersion = "0.1.0"
+
+var placeholderRegex = regexp.MustCompile(`\{\{ ([a-z_]+) \}\}`)
+
+// ScaffoldOptions describes the plugin `malice plugin init` generates
+type ScaffoldOptions struct {
+	Lang        string
+	Name        string
+	Category    string
+	Mime        string
+	Description string
+	// Image defaults to malice/<name>
+	Image string
+	// Repository is the go import path of the plugin, defaults to github.com/malice-plugins/<name>
+	Repository string
+	Author     string
+	Email      string
+}
+
+// Scaffold renders the plugin template for opts.Lang into dir, which must not exist yet
+func Scaffold(dir string, opts ScaffoldOptions) error {
+	values, err := opts.values()
+	if err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(dir); err == nil {
+		return fmt.Errorf("%s already exists", dir)
+	}
+
+	templateDir := path.Join(templatesDir, opts.Lang)
+	names, err := AssetDir(templateDir)
+	if err != nil {
+		return errors.Wrapf(err, "no %s plugin template", opts.Lang)
+	}
+	sort.Strings(names)
+
+	files := make(map[string][]byte, len(names))
+	for _, name := range names {
+		data, err := Asset(path.Join(templateDir, name))
+		if err != nil {
+			return err
+		}
+		rendered, err := renderTemplate(string(data), values)
+		if err != nil {
+			return errors.Wrapf(err, "failed to render %s", name)
+		}
+		files[strings.TrimSuffix(name, ".template")] = []byte(rendered)
+	}
+
+	// the rendered manifest has to be installable as is
+	if _, err := ParseManifest(files[ManifestFile]); err != nil {
+		return errors.Wrap(err, "rendered manifest is invalid")
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	for name, data := range files {
+		mode := os.FileMode(0644)
+		if name == "scan.py" {
+			mode = 0755
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, name), data, mode); err != nil {
+			os.RemoveAll(dir)
+			return err
+		}
+	}
+	return nil
+}
+
+// values validates the options and returns the template placeholder values
+func (opts ScaffoldOptions) values() (map[string]string, error) {
+	var problems []string
+	if !stringInSlice(opts.Lang, Languages) {
+		problems = append(problems, fmt.Sprintf("unknown language %q, expected one of %s", opts.Lang, strings.Join(Languages, ", ")))
+	}
+	if !pluginNameValidRegex.MatchString(opts.Name) {
+		problems = append(problems, fmt.Sprintf("invalid name %q, use lower case letters, digits, '_', '-' and '.'", opts.Name))
+	}
+	if !stringInSlice(opts.Category, Categories) {
+		problems = append(problems, fmt.Sprintf("unknown category %q, expected one of %s", opts.Category, strings.Join(Categories, ", ")))
+	}
+	if opts.Mime == "" {
+		problems = append(problems, "mime is required")
+	}
+	for field, value := range map[string]string{
+		"description": opts.Description, "mime": opts.Mime, "image": opts.Image,
+		"repository": opts.Repository, "author": opts.Author, "email": opts.Email,
+	} {
+		if strings.ContainsAny(value, "\"\\\n") {
+			problems = append(problems, fmt.Sprintf("%s must not contain quotes, backslashes or newlines", field))
+		}
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return nil, fmt.Errorf("invalid plugin %s: %s", opts.Name, strings.Join(problems, "; "))
+	}
+
+	category := strings.ToLower(opts.Category)
+	description := opts.Description
+	if description == "" {
+		description = fmt.Sprintf("%s %s plugin", opts.Name, category)
+	}
+	image := opts.Image
+	if image == "" {
+		image = "malice/" + opts.Name
+	}
+	repository := opts.Repository
+	if repository == "" {
+		repository = "github.com/malice-plugins/" + opts.Name
+	}
+	repository = strings.TrimSuffix(strings.TrimPrefix(strings.TrimPrefix(repository, "https://"), "http://"), ".git")
+	author := opts.Author
+	if author == "" {
+		author = "malice"
+	}
+	creator := author
+	if opts.Email != "" {
+		creator = fmt.Sprintf("%s <%s>", author, opts.Email)
+	}
+	schema, _ := GetSchema(category, "")
+
+	return map[string]string{
+		"plugin_name":              opts.Name,
+		"plugin_version":           scaffoldVersion,
+		"plugin_description":       description,
+		"description":              description,
+		"usage":                    description,
+		"plugin_category":          category,
+		"plugin_keywords":          category,
+		"plugin_image":             image,
+		"plugin_repo":              repository,
+		"plugin_repository":        "https://" + repository,
+		"plugin_license":           "MIT",
+		"malice_engines_supported": ">=0.4.0",
+		"plugin_cmd":               "",
+		"plugin_mime":              opts.Mime,
+		"plugin_env_var":           strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(opts.Name)),
+		"plugin_pipes_to":          "",
+		"plugin_pipes_from":        "",
+		"creator":                  creator,
+		"author":                   author,
+		"email":                    opts.Email,
+		"current_year":             time.Now().Format("2006"),
+		"plugin_results_fields":    goResultFields(schema),
+		"plugin_results_init":      goResultInit(schema),
+		"plugin_results":           pythonResults(schema),
+	}, nil
+}
+
+// renderTemplate replaces every {{ placeholder }} in a template, other
+// braces (e.g. go templates in the plugin's own code) are left alone
+func renderTemplate(tmpl string, values map[string]string) (string, error) {
+	var missing []string
+	rendered := placeholderRegex.ReplaceAllStringFunc(tmpl, func(match string) string {
+		key := placeholderRegex.FindStringSubmatch(match)[1]
+		value, ok := values[key]
+		if !ok {
+			missing = append(missing, key)
+			return match
+		}
+		return value
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("no value for placeholders %s", strings.Join(missing, ", "))
+	}
+	return rendered, nil
+}
+
+// schemaFields returns the schema's fields, or a single data array for
+// categories without a result schema
+func schemaFields(schema Schema) []Field {
+	if len(schema.Fields) == 0 {
+		return []Field{{Name: "data", Type: TypeArray}}
+	}
+	return schema.Fields
+}
+
+// goFieldName converts a result field to an exported go identifier
+func goFieldName(name string) string {
+	parts := strings.FieldsFunc(name, func(r rune) bool { return r == '_' || r == '-' })
+	for i, part := range parts {
+		parts[i] = strings.ToUpper(part[:1]) + part[1:]
+	}
+	return strings.Join(parts, "")
+}
+
+var goTypes = map[string]string{
+	TypeBoolean: "bool",
+	TypeString:  "string",
+	TypeNumber:  "float64",
+	TypeArray:   "[]string",
+	TypeObject:  "map[string]interface{}",
+}
+
+// goResultFields renders the schema as gofmt aligned struct fields
+func goResultFields(schema Schema) string {
+	fields := schemaFields(schema)
+	nameWidth, typeWidth := 0, 0
+	for _, field := range fields {
+		if n := len(goFieldName(field.Name)); n > nameWidth {
+			nameWidth = n
+		}
+		if n := len(goTypes[field.Type]); n > typeWidth {
+			typeWidth = n
+		}
+	}
+	lines := make([]string, len(fields))
+	for i, field := range fields {
+		lines[i] = fmt.Sprintf("\t%-*s %-*s `json:\"%s\" structs:\"%s\"`", nameWidth, goFieldName(field.Name), typeWidth, goTypes[field.Type], field.Name, field.Name)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// goResultInit renders a resultsData literal that marshals arrays and
+// objects as empty values instead of null
+func goResultInit(schema Schema) string {
+	var fields []Field
+	width := 0
+	for _, field := range schemaFields(schema) {
+		if field.Type != TypeArray && field.Type != TypeObject {
+			continue
+		}
+		fields = append(fields, field)
+		if n := len(goFieldName(field.Name)) + 1; n > width {
+			width = n
+		}
+	}
+	if len(fields) == 0 {
+		return "resultsData{}"
+	}
+	lines := []string{"resultsData{"}
+	for _, field := range fields {
+		lines = append(lines, fmt.Sprintf("\t\t%-*s %s{},", width, goFieldName(field.Name)+":", goTypes[field.Type]))
+	}
+	return strings.Join(append(lines, "\t}"), "\n")
+}
+
+var pythonDefaults = map[string]string{
+	TypeBoolean: "False",
+	TypeString:  "''",
+	TypeNumber:  "0",
+	TypeArray:   "[]",
+	TypeObject:  "{}",
+}
+
+// pythonResults renders the schema as a python dict of default values
+func pythonResults(schema Schema) string {
+	lines := []string{"{"}
+	for _, field := range schemaFields(schema) {
+		lines = append(lines, fmt.Sprintf("        '%s': %s,", field.Name, pythonDefaults[field.Type]))
+	}
+	return strings.Join(append(lines, "    }"), "\n")
+}
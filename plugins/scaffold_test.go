@@ -0,0 +1,154 @@
+package plugins
+
+import (
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestScaffold(t *testing.T) {
+	tests := []struct {
+		name string
+		opts ScaffoldOptions
+		code string
+	}{
+		{
+			name: "go av plugin",
+			opts: ScaffoldOptions{Lang: "go", Name: "clamav-lite", Category: "av", Mime: "*", Author: "blacktop", Email: "blacktop@malice.io"},
+			code: "scan.go",
+		},
+		{
+			name: "go exe plugin",
+			opts: ScaffoldOptions{Lang: "go", Name: "pescan", Category: "exe", Mime: "application/x-dosexec"},
+			code: "scan.go",
+		},
+		{
+			name: "python intel plugin",
+			opts: ScaffoldOptions{Lang: "python", Name: "hashdd", Category: "intel", Mime: "*", Description: "hashdd lookup"},
+			code: "scan.py",
+		},
+		{
+			name: "python metadata plugin",
+			opts: ScaffoldOptions{Lang: "python", Name: "exif", Category: "metadata", Mime: "image/jpeg"},
+			code: "scan.py",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := filepath.Join(t.TempDir(), tt.opts.Name)
+			if err := Scaffold(dir, tt.opts); err != nil {
+				t.Fatalf("Scaffold() error = %v", err)
+			}
+
+			for _, name := range []string{ManifestFile, "Dockerfile", ".gitignore", tt.code} {
+				data, err := ioutil.ReadFile(filepath.Join(dir, name))
+				if err != nil {
+					t.Fatalf("missing %s: %v", name, err)
+				}
+				if match := placeholderRegex.Find(data); match != nil {
+					t.Errorf("%s still contains %s", name, match)
+				}
+			}
+
+			data, _ := ioutil.ReadFile(filepath.Join(dir, ManifestFile))
+			plugin, err := ParseManifest(data)
+			if err != nil {
+				t.Fatalf("rendered manifest is invalid: %v", err)
+			}
+			if plugin.Name != tt.opts.Name || plugin.Category != tt.opts.Category || plugin.Mime != tt.opts.Mime || plugin.Image != "malice/"+tt.opts.Name {
+				t.Errorf("manifest = %+v, want name %s, category %s, mime %s", plugin, tt.opts.Name, tt.opts.Category, tt.opts.Mime)
+			}
+
+			if tt.code == "scan.go" {
+				src, _ := ioutil.ReadFile(filepath.Join(dir, "scan.go"))
+				formatted, err := format.Source(src)
+				if err != nil {
+					t.Fatalf("rendered scan.go does not parse: %v", err)
+				}
+				file, err := parser.ParseFile(token.NewFileSet(), "scan.go", src, parser.ImportsOnly)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(formatted) != string(src) {
+					t.Error("rendered scan.go is not gofmt'd")
+				}
+
+				// go mod tidy and go build need the network, the module
+				// itself must be valid though
+				if _, err := exec.LookPath("go"); err == nil {
+					cmd := exec.Command("go", "list", "-m")
+					cmd.Dir = dir
+					cmd.Env = append(os.Environ(), "GOWORK=off", "GOFLAGS=")
+					out, err := cmd.CombinedOutput()
+					if want := "github.com/malice-plugins/" + tt.opts.Name; err != nil || strings.TrimSpace(string(out)) != want {
+						t.Errorf("go list -m = %q, %v, want %s", out, err, want)
+					}
+				}
+				for _, spec := range file.Imports {
+					if path := strings.Trim(spec.Path.Value, `"`); path != strings.ToLower(path) {
+						t.Errorf("rendered scan.go imports %s, not the lower case path its module declares", path)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestScaffoldInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		opts ScaffoldOptions
+		want string
+	}{
+		{
+			name: "unknown language",
+			opts: ScaffoldOptions{Lang: "rust", Name: "x", Category: "av", Mime: "*"},
+			want: `unknown language "rust"`,
+		},
+		{
+			name: "unknown category",
+			opts: ScaffoldOptions{Lang: "go", Name: "x", Category: "sandbox", Mime: "*"},
+			want: `unknown category "sandbox"`,
+		},
+		{
+			name: "invalid name",
+			opts: ScaffoldOptions{Lang: "go", Name: "My Plugin", Category: "av", Mime: "*"},
+			want: "invalid name",
+		},
+		{
+			name: "missing mime",
+			opts: ScaffoldOptions{Lang: "python", Name: "x", Category: "av"},
+			want: "mime is required",
+		},
+		{
+			name: "quote in description",
+			opts: ScaffoldOptions{Lang: "python", Name: "x", Category: "av", Mime: "*", Description: `say "hi"`},
+			want: "description must not contain quotes",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Scaffold(filepath.Join(t.TempDir(), "plugin"), tt.opts)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Scaffold() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+
+	dir := t.TempDir()
+	err := Scaffold(dir, ScaffoldOptions{Lang: "go", Name: "x", Category: "av", Mime: "*"})
+	if err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("Scaffold() into existing dir error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ManifestFile)); !os.IsNotExist(err) {
+		t.Error("Scaffold() wrote into an existing directory")
+	}
+}
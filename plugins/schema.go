@@ -0,0 +1,170 @@
+package plugins
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// SchemaVersion is the plugin result schema version used when a plugin does not pin one
+const SchemaVersion = "v1"
+
+// NoSchema opts a plugin out of its category's result schema, e.g. yara is
+// an av plugin but reports matched rules instead of a detection
+const NoSchema = "none"
+
+// JSON types a result field can be declared as
+const (
+	TypeBoolean = "boolean"
+	TypeString  = "string"
+	TypeNumber  = "number"
+	TypeArray   = "array"
+	TypeObject  = "object"
+)
+
+// Field describes a single key inside a plugin's result document
+type Field struct {
+	Name     string
+	Type     string
+	Required bool
+}
+
+// Schema describes the result document a plugin category must produce
+type Schema struct {
+	Category string
+	Version  string
+	Fields   []Field
+}
+
+// ID returns the schema identifier, e.g. av/v1
+func (s Schema) ID() string {
+	return s.Category + "/" + s.Version
+}
+
+// schemas holds the result schemas by category and version
+var schemas = map[string]map[string]Schema{
+	"av": {
+		"v1": {
+			Category: "av",
+			Version:  "v1",
+			Fields: []Field{
+				{Name: "infected", Type: TypeBoolean, Required: true},
+				{Name: "result", Type: TypeString, Required: true},
+				{Name: "engine", Type: TypeString, Required: true},
+				{Name: "updated", Type: TypeString, Required: true},
+			},
+		},
+	},
+	"intel": {
+		"v1": {
+			Category: "intel",
+			Version:  "v1",
+			Fields: []Field{
+				{Name: "found", Type: TypeBoolean, Required: true},
+				{Name: "permalink", Type: TypeString},
+			},
+		},
+	},
+	"exe": {
+		"v1": {
+			Category: "exe",
+			Version:  "v1",
+			Fields: []Field{
+				{Name: "sections", Type: TypeArray, Required: true},
+				{Name: "imports", Type: TypeArray, Required: true},
+			},
+		},
+	},
+}
+
+// GetSchema returns the result schema for a category and version.
+// An empty version selects SchemaVersion.
+func GetSchema(category, version string) (Schema, bool) {
+	if version == "" {
+		version = SchemaVersion
+	}
+	schema, ok := schemas[strings.ToLower(category)][version]
+	return schema, ok
+}
+
+// Schema returns the result schema the plugin's output is checked against
+func (plugin Plugin) Schema() (Schema, bool) {
+	if plugin.SchemaVersion == NoSchema {
+		return Schema{}, false
+	}
+	return GetSchema(plugin.Category, plugin.SchemaVersion)
+}
+
+// ValidateResults parses a plugin's JSON output and checks it against the
+// schema for the plugin's category. It returns the plugin's result document
+// and a list of schema violations. The error is only set when the output is
+// not a JSON object at all.
+func (plugin Plugin) ValidateResults(output []byte) (map[string]interface{}, []string, error) {
+	var doc map[string]interface{}
+	if err := json.Unmarshal(output, &doc); err != nil {
+		return nil, nil, errors.Wrapf(err, "plugin %s output is not a JSON object", plugin.Name)
+	}
+
+	data, ok := doc[plugin.Name].(map[string]interface{})
+	if !ok {
+		keys := make([]string, 0, len(doc))
+		for key := range doc {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		return nil, []string{fmt.Sprintf("results must be an object under top-level key %q, found keys %v", plugin.Name, keys)}, nil
+	}
+
+	schema, ok := plugin.Schema()
+	if !ok {
+		if _, known := schemas[strings.ToLower(plugin.Category)]; known && plugin.SchemaVersion != NoSchema {
+			return data, []string{fmt.Sprintf("unknown %s schema version %q", plugin.Category, plugin.SchemaVersion)}, nil
+		}
+		// categories without a schema and plugins opting out only need to
+		// be keyed by plugin name
+		return data, nil, nil
+	}
+
+	return data, schema.Validate(data), nil
+}
+
+// Validate checks a result document against the schema and returns every violation found
+func (s Schema) Validate(data map[string]interface{}) []string {
+	var violations []string
+	for _, field := range s.Fields {
+		value, present := data[field.Name]
+		if !present {
+			if field.Required {
+				violations = append(violations, fmt.Sprintf("missing required field %q", field.Name))
+			}
+			continue
+		}
+		if got := jsonType(value); got != field.Type {
+			violations = append(violations, fmt.Sprintf("field %q must be %s, got %s", field.Name, field.Type, got))
+		}
+	}
+	return violations
+}
+
+// jsonType returns the JSON type name of a value decoded by encoding/json
+func jsonType(value interface{}) string {
+	switch value.(type) {
+	case bool:
+		return TypeBoolean
+	case string:
+		return TypeString
+	case float64, json.Number:
+		return TypeNumber
+	case []interface{}:
+		return TypeArray
+	case map[string]interface{}:
+		return TypeObject
+	case nil:
+		return "null"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
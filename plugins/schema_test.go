@@ -0,0 +1,123 @@
+package plugins
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestValidateResults(t *testing.T) {
+	avast := Plugin{Name: "avast", Category: "av"}
+	nsrl := Plugin{Name: "nsrl", Category: "intel"}
+	fileinfo := Plugin{Name: "fileinfo", Category: "metadata"}
+
+	tests := []struct {
+		name           string
+		plugin         Plugin
+		output         string
+		wantError      bool
+		wantViolations []string
+	}{
+		{
+			name:   "valid av result",
+			plugin: avast,
+			output: `{"avast":{"infected":true,"result":"EICAR Test-NOT virus!!!","engine":"4.7.4","updated":"20240101"}}`,
+		},
+		{
+			name:           "av result missing fields",
+			plugin:         avast,
+			output:         `{"avast":{"infected":"yes","engine":"4.7.4"}}`,
+			wantViolations: []string{`field "infected" must be boolean, got string`, `missing required field "result"`, `missing required field "updated"`},
+		},
+		{
+			name:   "intel result with optional permalink omitted",
+			plugin: nsrl,
+			output: `{"nsrl":{"found":false}}`,
+		},
+		{
+			name:           "results not keyed by plugin name",
+			plugin:         nsrl,
+			output:         `{"found":false}`,
+			wantViolations: []string{`top-level key "nsrl"`},
+		},
+		{
+			name:   "category without schema",
+			plugin: fileinfo,
+			output: `{"fileinfo":{"magic":"ASCII text"}}`,
+		},
+		{
+			name:           "unknown schema version",
+			plugin:         Plugin{Name: "avast", Category: "av", SchemaVersion: "v9"},
+			output:         `{"avast":{}}`,
+			wantViolations: []string{`unknown av schema version "v9"`},
+		},
+		{
+			name:   "plugin opting out of its category schema",
+			plugin: Plugin{Name: "yara", Category: "av", SchemaVersion: NoSchema},
+			output: `{"yara":{"matches":[]}}`,
+		},
+		{
+			name:      "output is not json",
+			plugin:    avast,
+			output:    "#### avast\n| Infected |",
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, violations, err := tt.plugin.ValidateResults([]byte(tt.output))
+			if (err != nil) != tt.wantError {
+				t.Fatalf("got error %v, want error %v", err, tt.wantError)
+			}
+			if len(violations) != len(tt.wantViolations) {
+				t.Fatalf("got violations %q, want %q", violations, tt.wantViolations)
+			}
+			for i, want := range tt.wantViolations {
+				if !strings.Contains(violations[i], want) {
+					t.Errorf("violation %q does not contain %q", violations[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestBundledPluginsMatchSchemas(t *testing.T) {
+	builtin, err := Asset("plugins/plugins.toml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	config, err := ParseConfig(builtin)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// results shaped like the ones the plugins print, by category unless a
+	// plugin prints something of its own
+	byCategory := map[string]string{
+		"av":       `{"infected":false,"result":"","engine":"1.0","updated":"20240101"}`,
+		"intel":    `{"found":true,"permalink":"https://example.com"}`,
+		"exe":      `{"sections":[],"imports":[]}`,
+		"metadata": `{"magic":"ASCII text"}`,
+		"document": `{"markdown":"#### doc"}`,
+		"archive":  `{"files":[]}`,
+	}
+	byPlugin := map[string]string{
+		"yara":  `{"matches":[{"Rule":"eicar","Namespace":"","Tags":[],"Meta":{},"Strings":[]}]}`,
+		"floss": `{"ascii":["kernel32.dll"],"utf-16":[],"decoded":[],"stack":[]}`,
+	}
+
+	for _, plugin := range config.Plugins {
+		data, ok := byPlugin[plugin.Name]
+		if !ok {
+			if data, ok = byCategory[plugin.Category]; !ok {
+				t.Errorf("no sample output for plugin %s of category %s", plugin.Name, plugin.Category)
+				continue
+			}
+		}
+		output := fmt.Sprintf(`{%q:%s}`, plugin.Name, data)
+		if _, violations, err := plugin.ValidateResults([]byte(output)); err != nil || len(violations) > 0 {
+			t.Errorf("plugin %s: ValidateResults() = %q, %v", plugin.Name, violations, err)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package plugins
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/maliceio/malice/malice/docker/client/container"
+	"github.com/maliceio/malice/malice/maldirs"
+	"github.com/maliceio/malice/malice/secrets"
+	"github.com/pkg/errors"
+)
+
+// SecretRef references a secret in the secret store by name. The secret is
+// handed to the plugin as an environment variable, a read-only file or both,
+// never on the command line.
+type SecretRef struct {
+	Name string `toml:"name"`
+	// Env is the environment variable the secret is injected as
+	Env string `toml:"env,omitempty"`
+	// File is the absolute path inside the container the secret is mounted at
+	File string `toml:"file,omitempty"`
+}
+
+// hasSecretFor returns true if one of the plugin's secrets provides the env var
+func (plugin Plugin) hasSecretFor(env string) bool {
+	store := secrets.NewStore(maldirs.GetSecretsDir())
+	for _, ref := range plugin.Secrets {
+		if ref.Env == env && store.Has(ref.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+// MountsSecrets returns true if one of the plugin's secrets is mounted as a
+// file, which is written on this host for the daemon to bind mount
+func (plugin Plugin) MountsSecrets() bool {
+	for _, ref := range plugin.Secrets {
+		if ref.File != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// injectSecrets resolves the plugin's secrets into env entries and binds.
+// The returned cleanup removes the files written for mounted secrets.
+func (plugin Plugin) injectSecrets(store *secrets.Store) ([]string, []string, func(), error) {
+	var env, binds []string
+	var tmpDir string
+	cleanup := func() {
+		if tmpDir != "" {
+			os.RemoveAll(tmpDir)
+		}
+	}
+
+	if plugin.APIKey != "" {
+		notice("apikey:"+plugin.Name, "plugin %s: apikey in plugins.toml is no longer used, run `malice secret set` and reference it with [[plugin.secret]]", plugin.Name)
+	}
+
+	for _, ref := range plugin.Secrets {
+		// an exported environment variable takes precedence over the store
+		if ref.Env != "" && ref.File == "" && os.Getenv(ref.Env) != "" {
+			env = append(env, fmt.Sprintf("%s=%s", ref.Env, os.Getenv(ref.Env)))
+			continue
+		}
+
+		value, err := store.Get(ref.Name)
+		if err == secrets.ErrNotFound {
+			log.Debugf("plugin %s: secret %s is not set", plugin.Name, ref.Name)
+			continue
+		} else if err != nil {
+			cleanup()
+			return nil, nil, func() {}, err
+		}
+
+		if ref.Env != "" {
+			env = append(env, fmt.Sprintf("%s=%s", ref.Env, value))
+		}
+		if ref.File == "" {
+			continue
+		}
+		if !path.IsAbs(ref.File) {
+			cleanup()
+			return nil, nil, func() {}, fmt.Errorf("secret %s file %q must be an absolute container path", ref.Name, ref.File)
+		}
+
+		if tmpDir == "" {
+			runDir := filepath.Join(store.Dir, "run")
+			if err := os.MkdirAll(runDir, 0700); err != nil {
+				return nil, nil, func() {}, errors.Wrap(err, "failed to create secrets run dir")
+			}
+			removeStaleSecrets(runDir)
+			if tmpDir, err = ioutil.TempDir(runDir, fmt.Sprintf("%s-%d-", plugin.Name, os.Getpid())); err != nil {
+				return nil, nil, func() {}, errors.Wrap(err, "failed to create secrets run dir")
+			}
+		}
+		// only readable by our user, plugins running as another user get the
+		// secret with env instead
+		hostPath := filepath.Join(tmpDir, ref.Name)
+		if err := ioutil.WriteFile(hostPath, []byte(value), 0400); err != nil {
+			cleanup()
+			return nil, nil, func() {}, errors.Wrapf(err, "failed to write secret %s", ref.Name)
+		}
+		binds = append(binds, hostPath+":"+ref.File+":ro")
+	}
+
+	return env, binds, cleanup, nil
+}
+
+// removeStaleSecrets removes the secret files left in runDir by malice
+// processes that died before cleaning up, e.g. a killed `malice watch`
+func removeStaleSecrets(runDir string) {
+	entries, err := ioutil.ReadDir(runDir)
+	if err != nil {
+		log.WithError(err).Warn("failed to list secrets run dir")
+		return
+	}
+	for _, entry := range entries {
+		// the dirs are named <plugin>-<pid>-<random>
+		fields := strings.Split(entry.Name(), "-")
+		if len(fields) >= 3 {
+			if pid, err := strconv.Atoi(fields[len(fields)-2]); err == nil && container.ProcessAlive(pid) {
+				continue
+			}
+		}
+		if err := os.RemoveAll(filepath.Join(runDir, entry.Name())); err != nil {
+			log.WithError(err).Warnf("failed to remove stale secrets %s", entry.Name())
+		}
+	}
+}
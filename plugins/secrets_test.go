@@ -0,0 +1,95 @@
+package plugins
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/maliceio/malice/malice/secrets"
+)
+
+func TestInjectSecrets(t *testing.T) {
+	t.Setenv(secrets.MasterKeyEnv, "")
+	t.Setenv("MALICE_VT_API", "")
+	store := secrets.NewStore(t.TempDir())
+	if err := store.Set("vt_api", "s3cr3t-key"); err != nil {
+		t.Fatal(err)
+	}
+
+	plugin := Plugin{
+		Name: "virustotal",
+		Secrets: []SecretRef{
+			{Name: "vt_api", Env: "MALICE_VT_API"},
+			{Name: "vt_api", File: "/run/secrets/vt_api"},
+			{Name: "missing", Env: "MALICE_MISSING"},
+		},
+	}
+
+	env, binds, cleanup, err := plugin.injectSecrets(store)
+	if err != nil {
+		t.Fatalf("injectSecrets() error = %v", err)
+	}
+	if len(env) != 1 || env[0] != "MALICE_VT_API=s3cr3t-key" {
+		t.Errorf("injectSecrets() env = %v", env)
+	}
+	if len(binds) != 1 || !strings.HasSuffix(binds[0], ":/run/secrets/vt_api:ro") {
+		t.Fatalf("injectSecrets() binds = %v", binds)
+	}
+	hostPath := strings.SplitN(binds[0], ":", 2)[0]
+	if data, err := ioutil.ReadFile(hostPath); err != nil || string(data) != "s3cr3t-key" {
+		t.Errorf("mounted secret = %q, %v", data, err)
+	}
+	if info, err := os.Stat(hostPath); err != nil || info.Mode().Perm()&0077 != 0 {
+		t.Errorf("mounted secret mode = %v, %v, want it private", info.Mode(), err)
+	}
+
+	cleanup()
+	if _, err := os.Stat(hostPath); !os.IsNotExist(err) {
+		t.Errorf("cleanup() left %s behind", hostPath)
+	}
+
+	t.Setenv("MALICE_VT_API", "from-env")
+	env, _, cleanup, err = plugin.injectSecrets(store)
+	defer cleanup()
+	if err != nil || len(env) != 1 || env[0] != "MALICE_VT_API=from-env" {
+		t.Errorf("injectSecrets() with exported env = %v, %v, want the exported value", env, err)
+	}
+
+	plugin.Secrets = []SecretRef{{Name: "vt_api", File: "relative/path"}}
+	if _, _, _, err := plugin.injectSecrets(store); err == nil {
+		t.Error("injectSecrets() accepted a relative file path")
+	}
+}
+
+func TestInjectSecretsRemovesStale(t *testing.T) {
+	t.Setenv(secrets.MasterKeyEnv, "")
+	store := secrets.NewStore(t.TempDir())
+	if err := store.Set("vt_api", "s3cr3t-key"); err != nil {
+		t.Fatal(err)
+	}
+	// left behind by a dead process and by a running one
+	runDir := filepath.Join(store.Dir, "run")
+	stale := filepath.Join(runDir, "virustotal-999999999-123")
+	live := filepath.Join(runDir, fmt.Sprintf("virustotal-%d-456", os.Getpid()))
+	for _, dir := range []string{stale, live} {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	plugin := Plugin{Name: "virustotal", Secrets: []SecretRef{{Name: "vt_api", File: "/run/secrets/vt_api"}}}
+	_, _, cleanup, err := plugin.injectSecrets(store)
+	if err != nil {
+		t.Fatalf("injectSecrets() error = %v", err)
+	}
+	defer cleanup()
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Errorf("secrets of a dead process were kept")
+	}
+	if _, err := os.Stat(live); err != nil {
+		t.Errorf("secrets of a running process were removed: %v", err)
+	}
+}
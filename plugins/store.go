@@ -0,0 +1,180 @@
+package plugins
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	log "github.com/Sirupsen/logrus"
+	"github.com/pkg/errors"
+)
+
+const (
+	// configLockTimeout is how long an edit waits for another malice process
+	configLockTimeout = 10 * time.Second
+	// configLockStale is the age after which a lock is considered left
+	// behind by a crashed process
+	configLockStale = time.Minute
+)
+
+// configMu serializes config edits within this process, the lock file
+// serializes them between processes (the CLI and the API server)
+var configMu sync.Mutex
+
+// BackupPath returns the path of the plugins.toml backup taken before each edit
+func BackupPath() string {
+	return ConfigPath() + ".bak"
+}
+
+// ParseConfig decodes a plugins config and validates every plugin in it
+func ParseConfig(data []byte) (Configuration, error) {
+	var config Configuration
+	if _, err := toml.Decode(string(data), &config); err != nil {
+		return config, errors.Wrap(err, "invalid plugins config")
+	}
+
+	var problems []string
+	seen := make(map[string]bool)
+	for _, plugin := range config.Plugins {
+		// apikey is deprecated but still honoured in the user's config
+		plugin.APIKey = ""
+		if err := plugin.Validate(); err != nil {
+			problems = append(problems, err.Error())
+		}
+		if seen[strings.ToLower(plugin.Name)] {
+			problems = append(problems, fmt.Sprintf("plugin %s is defined more than once", plugin.Name))
+		}
+		seen[strings.ToLower(plugin.Name)] = true
+	}
+	if len(problems) > 0 {
+		return config, fmt.Errorf("invalid plugins config: %s", strings.Join(problems, "; "))
+	}
+	return config, nil
+}
+
+// LoadConfig reads and validates a plugins config file
+func LoadConfig(path string) (Configuration, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return Configuration{}, errors.Wrap(err, "failed to read plugins config")
+	}
+	config, err := ParseConfig(data)
+	return config, errors.Wrap(err, path)
+}
+
+// editConfig applies edit to the user's plugins.toml while holding the config
+// lock. The edited config must still validate; the previous file is kept as
+// the backup and plugins.toml is replaced atomically.
+func editConfig(edit func(data []byte, config Configuration) ([]byte, error)) error {
+	configMu.Lock()
+	defer configMu.Unlock()
+
+	configPath := ConfigPath()
+	unlock, err := lockConfig(configPath)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	data, err := ioutil.ReadFile(configPath)
+	if err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "failed to read plugins config")
+	}
+	current, err := ParseConfig(data)
+	if err != nil {
+		return errors.Wrapf(err, "fix %s or run `malice plugin rollback`", configPath)
+	}
+
+	updated, err := edit(data, current)
+	if err != nil {
+		return err
+	}
+	config, err := ParseConfig(updated)
+	if err != nil {
+		return errors.Wrap(err, "refusing to write plugins config")
+	}
+
+	if len(data) > 0 {
+		if err := writeConfig(BackupPath(), data); err != nil {
+			return errors.Wrap(err, "failed to back up plugins config")
+		}
+	}
+	if err := writeConfig(configPath, updated); err != nil {
+		return err
+	}
+	Plugs = config
+	return nil
+}
+
+// RollbackConfig swaps plugins.toml with the backup taken before the last
+// edit, so a second rollback undoes the first
+func RollbackConfig() error {
+	configMu.Lock()
+	defer configMu.Unlock()
+
+	configPath := ConfigPath()
+	unlock, err := lockConfig(configPath)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	backup, err := ioutil.ReadFile(BackupPath())
+	if os.IsNotExist(err) {
+		return errors.New("no plugins config backup to roll back to")
+	} else if err != nil {
+		return errors.Wrap(err, "failed to read plugins config backup")
+	}
+	config, err := ParseConfig(backup)
+	if err != nil {
+		return errors.Wrap(err, BackupPath())
+	}
+
+	data, err := ioutil.ReadFile(configPath)
+	if err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "failed to read plugins config")
+	}
+	if err := writeConfig(configPath, backup); err != nil {
+		return err
+	}
+	if len(data) > 0 {
+		if err := writeConfig(BackupPath(), data); err != nil {
+			log.WithError(err).Warn("failed to keep the replaced plugins config as the backup")
+		}
+	}
+	Plugs = config
+	return nil
+}
+
+// lockConfig takes the lock file next to the plugins config and returns the
+// func that releases it. The lock is created exclusively so it works on every
+// platform; a lock older than configLockStale is taken over.
+func lockConfig(configPath string) (func(), error) {
+	lockPath := configPath + ".lock"
+	deadline := time.Now().Add(configLockTimeout)
+	for {
+		f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			fmt.Fprintf(f, "%d\n", os.Getpid())
+			f.Close()
+			return func() { os.Remove(lockPath) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, errors.Wrap(err, "failed to lock plugins config")
+		}
+
+		if info, err := os.Stat(lockPath); err == nil && time.Since(info.ModTime()) > configLockStale {
+			log.Warnf("removing stale plugins config lock %s", lockPath)
+			os.Remove(lockPath)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("plugins config is locked by another malice process, remove %s if none is running", lockPath)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
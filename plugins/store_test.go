@@ -0,0 +1,222 @@
+package plugins
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/maliceio/malice/malice/maldirs"
+)
+
+const storeConfig = `# Malice plugins
+[[plugin]]
+  name = "nsrl"
+  enabled = true
+  category = "intel"
+  image = "malice/nsrl"
+  mime = "hash"
+
+# needs a VirusTotal key
+[[plugin]]
+  name = "virustotal"
+  enabled = false
+  category = "intel"
+  image = "malice/virustotal"
+  mime = "hash"
+  [[plugin.secret]]
+    name = "vt_api"
+    env = "MALICE_VT_API"
+
+# the last one
+[[plugin]]
+  name = "fileinfo"
+  enabled = true
+  category = "metadata"
+  image = "malice/fileinfo"
+  mime = "*"
+`
+
+func TestRemovePlugin(t *testing.T) {
+	tests := []struct {
+		plugin  string
+		want    []string
+		notWant []string
+	}{
+		{
+			plugin:  "virustotal",
+			want:    []string{"# Malice plugins\n", "mime = \"hash\"\n\n# needs a VirusTotal key\n# the last one\n[[plugin]]\n  name = \"fileinfo\""},
+			notWant: []string{"\"virustotal\"", "vt_api"},
+		},
+		{
+			plugin:  "nsrl",
+			want:    []string{"# Malice plugins\n# needs a VirusTotal key\n[[plugin]]\n  name = \"virustotal\""},
+			notWant: []string{"nsrl"},
+		},
+		{
+			plugin:  "fileinfo",
+			want:    []string{"  env = \"MALICE_VT_API\"\n\n# the last one\n"},
+			notWant: []string{"fileinfo"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.plugin, func(t *testing.T) {
+			got, err := removePlugin([]byte(storeConfig), tt.plugin)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(string(got), want) {
+					t.Errorf("removePlugin() =\n%s\nwant it to contain\n%s", got, want)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(string(got), notWant) {
+					t.Errorf("removePlugin() =\n%s\nstill contains %q", got, notWant)
+				}
+			}
+			if strings.HasSuffix(string(got), "\n\n") || !strings.HasSuffix(string(got), "\n") {
+				t.Errorf("removePlugin() = %q, want a single trailing newline", got)
+			}
+			if _, err := ParseConfig(got); err != nil {
+				t.Errorf("removePlugin() result is invalid: %v", err)
+			}
+		})
+	}
+
+	if _, err := removePlugin([]byte(storeConfig), "avast"); err == nil {
+		t.Error("removePlugin() of an unknown plugin returned no error")
+	}
+}
+
+func TestParseConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		wantErr string
+	}{
+		{name: "valid", config: storeConfig},
+		{name: "deprecated apikey", config: storeConfig + "  apikey = \"secret\"\n"},
+		{name: "broken toml", config: storeConfig + "[[plugin]\n", wantErr: "invalid plugins config"},
+		{name: "missing image", config: "[[plugin]]\n  name = \"avast\"\n  category = \"av\"\n  mime = \"*\"\n", wantErr: "image is required"},
+		{name: "duplicate", config: storeConfig + "\n[[plugin]]\n  name = \"NSRL\"\n  category = \"intel\"\n  image = \"malice/nsrl2\"\n  mime = \"hash\"\n", wantErr: "NSRL is defined more than once"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseConfig([]byte(tt.config))
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("ParseConfig() = %v", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Errorf("ParseConfig() = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	builtin, err := Asset("plugins/plugins.toml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseConfig(builtin); err != nil {
+		t.Errorf("bundled plugins.toml is invalid: %v", err)
+	}
+}
+
+// useTestConfig points the plugins config at a temp dir holding data
+func useTestConfig(t *testing.T, data string) {
+	saved, savedPlugs := maldirs.BaseDir, Plugs
+	t.Cleanup(func() { maldirs.BaseDir, Plugs = saved, savedPlugs })
+	maldirs.BaseDir = t.TempDir()
+	if err := os.MkdirAll(maldirs.GetPluginsDir(), 0777); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(ConfigPath(), []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestEditConfigRollback(t *testing.T) {
+	useTestConfig(t, storeConfig)
+
+	if err := SetPluginOption("nsrl", "image", ""); err == nil {
+		t.Error("SetPluginOption() wrote a plugin without an image")
+	}
+	if _, err := os.Stat(BackupPath()); !os.IsNotExist(err) {
+		t.Error("a refused edit left a backup")
+	}
+
+	if err := DeletePlugin("virustotal"); err != nil {
+		t.Fatal(err)
+	}
+	if len(Plugs.Plugins) != 2 {
+		t.Errorf("Plugs has %d plugins after DeletePlugin(), want 2", len(Plugs.Plugins))
+	}
+	if backup, _ := ioutil.ReadFile(BackupPath()); string(backup) != storeConfig {
+		t.Errorf("backup =\n%s\nwant the config from before the edit", backup)
+	}
+
+	if err := RollbackConfig(); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := ioutil.ReadFile(ConfigPath()); string(data) != storeConfig {
+		t.Errorf("plugins.toml after RollbackConfig() =\n%s\nwant the original config", data)
+	}
+	if len(Plugs.Plugins) != 3 {
+		t.Errorf("Plugs has %d plugins after RollbackConfig(), want 3", len(Plugs.Plugins))
+	}
+
+	// a broken plugins.toml is refused for edits and replaced by the backup on load
+	if err := ioutil.WriteFile(ConfigPath(), []byte("[[plugin]\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetPluginOption("nsrl", "enabled", false); err == nil {
+		t.Error("SetPluginOption() edited a broken config")
+	}
+	if err := Load(); err != nil {
+		t.Fatalf("Load() = %v, want the backup loaded", err)
+	}
+	if len(Plugs.Plugins) != 2 {
+		t.Errorf("Load() loaded %d plugins, want the 2 of the backup", len(Plugs.Plugins))
+	}
+}
+
+func TestEditConfigConcurrent(t *testing.T) {
+	useTestConfig(t, storeConfig)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs <- AddPlugin(Plugin{
+				Name:     fmt.Sprintf("yara%d", i),
+				Category: "av",
+				Image:    fmt.Sprintf("malice/yara%d", i),
+				Mime:     "*",
+			})
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Error(err)
+		}
+	}
+
+	config, err := LoadConfig(ConfigPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(config.Plugins) != 13 {
+		t.Errorf("plugins.toml has %d plugins, want 13", len(config.Plugins))
+	}
+	if _, err := os.Stat(ConfigPath() + ".lock"); !os.IsNotExist(err) {
+		t.Error("the config lock was not released")
+	}
+}
@@ -0,0 +1,3 @@
+module {{ plugin_repo }}
+
+go 1.21
@@ -4,15 +4,16 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
-	"regexp"
-	"strconv"
+	"os/exec"
+	"sort"
 	"strings"
 	"time"
 
-	log "github.com/Sirupsen/logrus"
 	"github.com/fatih/structs"
+	"github.com/malice-plugins/pkgs/database"
 	"github.com/malice-plugins/pkgs/database/elasticsearch"
 	"github.com/malice-plugins/pkgs/utils"
+	log "github.com/sirupsen/logrus"
 	"github.com/urfave/cli/v2"
 )
 
@@ -27,152 +28,142 @@ const (
 	category = "{{ plugin_category }}"
 )
 
+// pluginResults is the document malice expects on stdout, keyed by plugin name
 type pluginResults struct {
-	ID   string      `json:"id" structs:"id,omitempty"`
-	Data resultsData `json:"{{ plugin_name }}" structs:"{{ plugin_name }}"`
-}
-
-type {{ plugin_name }} struct {
-	Results resultsData `json:"{{ plugin_name }}"`
+	ID      string      `json:"id" structs:"id,omitempty"`
+	Results resultsData `json:"{{ plugin_name }}" structs:"{{ plugin_name }}"`
 }
 
+// resultsData holds the fields of the {{ plugin_category }} result schema
 type resultsData struct {
-	Data   []string         `json:"data" structs:"data"`
+{{ plugin_results_fields }}
 }
 
-func printMarkDownTable(f {{ plugin_name }}) {
-	fmt.Printf("#### {{ plugin_name }}\n\n")
+func printMarkDownTable(results pluginResults) {
+	fields := structs.Map(results.Results)
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	fmt.Printf("#### %s\n\n", name)
+	fmt.Printf("| %s |\n", strings.Join(keys, " | "))
+	fmt.Printf("|%s\n", strings.Repeat(" --- |", len(keys)))
+	values := make([]string, len(keys))
+	for i, key := range keys {
+		values[i] = fmt.Sprint(fields[key])
+	}
+	fmt.Printf("| %s |\n", strings.Join(values, " | "))
 }
 
-func parsePluginOutput(pluginOutput string, all bool) resultsData {
-
-	keepLines := []string{}
-	results := resultsData{}
+// parsePluginOutput turns the scanner's output into the plugin's results
+func parsePluginOutput(output string) resultsData {
+	results := {{ plugin_results_init }}
 
-	lines := strings.Split(pluginOutput, "\n")
-
-	// remove empty lines
-	for _, line := range lines {
-		if len(strings.TrimSpace(line)) != 0 {
-			keepLines = append(keepLines, strings.TrimSpace(line))
+	for _, line := range strings.Split(output, "\n") {
+		if len(strings.TrimSpace(line)) == 0 {
+			continue
 		}
-	}
-	// build results data
-	for i := 0; i < len(keepLines); i++ {
-
+		// TODO: fill results from the scanner's output
 	}
 
 	return results
 }
 
 // scanFile scans file
-func scanFile(path string, all bool) pluginResults {
-	pResults := pluginResults{}
-	pResults.Results = parsePluginOutput(utils.RunCommand("/usr/bin/plugin_binary", "-flag", path))
-
-	return pResults
+func scanFile(path string) (pluginResults, error) {
+	// TODO: replace with the command that scans the file
+	output, err := exec.Command("/bin/true", path).CombinedOutput()
+	if err != nil {
+		return pluginResults{}, fmt.Errorf("scan failed: %s", err)
+	}
+	return pluginResults{Results: parsePluginOutput(string(output))}, nil
 }
 
-var appHelpTemplate = `Usage: {{.HelpName}} {{if .VisibleFlags}}[OPTIONS] {{end}}COMMAND [arg...]
-
-{{.Usage}}
-
-Version: {{.Version}}{{if .Authors}}
-
-Author:{{range .Authors}}
-  {{.}}{{end}}{{end}}
-{{if .VisibleFlags}}
-Options:
-  {{range .VisibleFlags}}{{.}}
-  {{end}}{{end}}
-Commands:
-  {{range .Commands}}{{.Name}}{{ "\t" }}{{.Usage}}
-  {{end}}
-Run '{{.Name}} COMMAND --help' for more information on a command.
-`
-
 func main() {
-	cli.AppHelpTemplate = appHelpTemplate
 	app := cli.NewApp()
-	app.Name = "{{ plugin_name }}"
-	app.Author = "{{ author }}"
-	app.Email = "{{ email }}"
+	app.Name = name
+	app.Authors = []*cli.Author{{Name: "{{ author }}", Email: "{{ email }}"}}
 	app.Version = Version + ", BuildTime: " + BuildTime
 	app.Compiled, _ = time.Parse("20060102", BuildTime)
 	app.Usage = "{{ usage }}"
 	var table bool
-	var all bool
-	var elasticsearch string
+	var elasticsearchURL string
 	app.Flags = []cli.Flag{
 		&cli.BoolFlag{
-			Name:  "verbose, V",
-			Usage: "verbose output",
+			Name:    "verbose",
+			Aliases: []string{"V"},
+			Usage:   "verbose output",
 		},
 		&cli.StringFlag{
 			Name:        "elasticsearch",
 			Value:       "",
-			Usage:       "elasticsearch address for Malice to store results",
-			EnvVars:     []string{"MALICE_ELASTICSEARCH"},
-			Destination: &elasticsearch,
-		},
-		&cli.BoolFlag{
-			Name:   "post, p",
-			Usage:  "POST results to Malice webhook",
-			EnvVars: []string{"MALICE_ENDPOINT"},
+			Usage:       "elasticsearch url for Malice to store results",
+			EnvVars:     []string{"MALICE_ELASTICSEARCH_URL"},
+			Destination: &elasticsearchURL,
 		},
 		&cli.BoolFlag{
-			Name:   "proxy, x",
-			Usage:  "proxy settings for Malice webhook endpoint",
-			EnvVars: []string{"MALICE_PROXY"},
-		},
-		&cli.BoolFlag{
-			Name:        "table, t",
+			Name:        "table",
+			Aliases:     []string{"t"},
 			Usage:       "output as Markdown table",
 			Destination: &table,
 		},
-		&cli.BoolFlag{
-			Name:        "all, a",
-			Usage:       "output ascii/utf-16 strings",
-			Destination: &all,
-		},
 	}
-	app.ArgsUsage = "FILE to scan with {{ plugin_name }}"
+	app.ArgsUsage = "FILE to scan with " + name
 	app.Action = func(c *cli.Context) error {
-		if c.Args().Len() > 0 {
-			path := c.Args().First()
-			// Check that file exists
-			if _, err := os.Stat(path); os.IsNotExist(err) {
-				utils.Assert(err)
-			}
-
-			if c.Bool("verbose") {
-				log.SetLevel(log.DebugLevel)
-			}
+		if c.Args().Len() == 0 {
+			return fmt.Errorf("please supply a file to scan with %s", name)
+		}
+		path := c.Args().First()
+		if _, err := os.Stat(path); err != nil {
+			return err
+		}
 
-			pluginOut := scanFile(path, all)
+		if c.Bool("verbose") {
+			log.SetLevel(log.DebugLevel)
+		}
 
-			// upsert into Database
-			elasticsearch.InitElasticSearch()
-			elasticsearch.WritePluginResultsToDatabase(elasticsearch.PluginResults{
-				ID:       utils.Getopt("MALICE_SCANID", utils.GetSHA256(path)),
+		results, err := scanFile(path)
+		if err != nil {
+			return err
+		}
+		results.ID = utils.Getopt("MALICE_SCANID", utils.GetSHA256(path))
+
+		// upsert into Database
+		if elasticsearchURL != "" {
+			es := elasticsearch.Database{
+				Index:    utils.Getopt("MALICE_ELASTICSEARCH_INDEX", "malice"),
+				Type:     utils.Getopt("MALICE_ELASTICSEARCH_TYPE", "samples"),
+				URL:      elasticsearchURL,
+				Username: os.Getenv("MALICE_ELASTICSEARCH_USERNAME"),
+				Password: os.Getenv("MALICE_ELASTICSEARCH_PASSWORD"),
+			}
+			es.Init()
+			err := es.StorePluginResults(database.PluginResults{
+				ID:       results.ID,
 				Name:     name,
 				Category: category,
-				Data:     structs.Map(pluginOut.Results),
+				Data:     structs.Map(results.Results),
 			})
-
-			if table {
-				printMarkDownTable(pluginOut)
-			} else {
-				pluginJSON, err := json.Marshal(pluginOut)
-				utils.Assert(err)
-				fmt.Println(string(pluginJSON))
+			if err != nil {
+				log.WithError(err).Error("failed to store results")
 			}
-		} else {
-			log.Fatal(fmt.Errorf("Please supply a file to scan with {{ plugin_name }}"))
 		}
+
+		if table {
+			printMarkDownTable(results)
+			return nil
+		}
+		out, err := json.Marshal(results)
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(out))
 		return nil
 	}
 
-	err := app.Run(os.Args)
-	utils.Assert(err)
+	if err := app.Run(os.Args); err != nil {
+		log.Fatal(err)
+	}
 }
@@ -1,29 +1,57 @@
-#!/usr/bin/env python
+#!/usr/bin/env python3
 # -*- coding: utf-8 -*-
 """
 scan.py
 ~~~~~~~~
 {{ description }}.
-:copyright: (c) 2016 by {{ creator }}.
+:copyright: (c) {{ current_year }} by {{ creator }}.
 :license: MIT
 """
 
 import argparse
+import json
+import os
+import sys
+
+NAME = '{{ plugin_name }}'
+CATEGORY = '{{ plugin_category }}'
+
+
+def scan(path):
+    """Scan the file and return the fields of the {{ plugin_category }} result schema."""
+    results = {{ plugin_results }}
+    # TODO: fill results by scanning path
+    return results
+
+
+def print_markdown_table(results):
+    keys = sorted(results)
+    print('#### %s\n' % NAME)
+    print('| %s |' % ' | '.join(keys))
+    print('|%s' % (' --- |' * len(keys)))
+    print('| %s |' % ' | '.join(str(results[key]) for key in keys))
 
 
 def main():
-    parser = argparse.ArgumentParser(prog='{{ plugin_name }}')
-    parser.add_argument("-v", "--verbose", help="Display verbose output message", action="store_true", required=False)
-    parser.add_argument('hash', metavar='MD5', type=str, nargs='+', help='a md5 hash to search for.')
+    parser = argparse.ArgumentParser(prog=NAME, description='{{ usage }}')
+    parser.add_argument('-v', '--verbose', help='Display verbose output message', action='store_true', required=False)
+    parser.add_argument('-t', '--table', help='output as Markdown table', action='store_true', required=False)
+    parser.add_argument('file', metavar='FILE', type=str, help='file to scan with %s' % NAME)
     args = parser.parse_args()
 
-    if args.hash:
-        print "Do something awesome with hash: " + args.hash
-    return
+    if not os.path.isfile(args.file):
+        parser.error('%s is not a file' % args.file)
+
+    results = scan(args.file)
+    if args.table:
+        print_markdown_table(results)
+    else:
+        print(json.dumps({NAME: results}))
 
 
-if __name__ == "__main__":
+if __name__ == '__main__':
     try:
         main()
     except Exception as e:
-        print "Error: %s" % e
+        print('Error: %s' % e, file=sys.stderr)
+        sys.exit(1)
\ No newline at end of file
@@ -0,0 +1,127 @@
+package plugins
+
+import (
+	"crypto"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"sync"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/maliceio/malice/config"
+	"github.com/maliceio/malice/malice/docker/client"
+	"github.com/maliceio/malice/malice/docker/client/image"
+	"github.com/pkg/errors"
+)
+
+// Trust policies for plugin images
+const (
+	// TrustWarn logs plugin images that fail verification and runs them anyway
+	TrustWarn = "warn"
+	// TrustEnforce refuses to run plugin images that fail verification
+	TrustEnforce = "enforce"
+)
+
+// verifiedImages caches the image digests verified by this process
+var verifiedImages = struct {
+	sync.Mutex
+	digests map[string]bool
+}{digests: make(map[string]bool)}
+
+// VerifyImage checks the signature of the plugin's pulled image against the
+// configured public key and returns the image to run, pinned to the verified
+// digest so a re-tagged image can't be swapped in. Under the warn policy
+// failures are logged and the plugin's image is returned unpinned.
+func (plugin Plugin) VerifyImage(docker *client.Docker) (string, error) {
+	policy := strings.ToLower(config.Conf.Trust.Policy)
+	if policy == "" {
+		policy = TrustWarn
+	}
+	if policy != TrustWarn && policy != TrustEnforce {
+		return "", fmt.Errorf("unknown trust policy %q, expected %s or %s", config.Conf.Trust.Policy, TrustWarn, TrustEnforce)
+	}
+	if config.Conf.Trust.PublicKey == "" {
+		if policy == TrustEnforce {
+			return "", fmt.Errorf("trust policy %s requires trust.public_key", TrustEnforce)
+		}
+		return plugin.ImageRef(), nil
+	}
+
+	pinned, err := plugin.verifyImage(docker, config.Conf.Trust.PublicKey)
+	if err == nil {
+		return pinned, nil
+	}
+	if policy == TrustEnforce {
+		return "", errors.Wrapf(err, "refusing to run plugin %s", plugin.Name)
+	}
+	log.WithError(err).WithField("plugin", plugin.Name).Warn("plugin image signature not verified")
+	return plugin.ImageRef(), nil
+}
+
+// verifyImage verifies the registry digest of the plugin's pulled image and
+// returns the image pinned to it
+func (plugin Plugin) verifyImage(docker *client.Docker, keyPath string) (string, error) {
+	key, err := loadPublicKey(keyPath)
+	if err != nil {
+		return "", err
+	}
+
+	inspect, err := image.Inspect(docker, plugin.ImageRef())
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to inspect plugin %s image", plugin.Name)
+	}
+	digest := image.LocalDigest(inspect, plugin.Image)
+	if digest == "" {
+		return "", fmt.Errorf("image %s has no registry digest, local builds can't be verified", plugin.ImageRef())
+	}
+	if plugin.Digest != "" && plugin.Digest != digest {
+		return "", fmt.Errorf("image %s is %s, not the pinned %s", plugin.ImageRef(), digest, plugin.Digest)
+	}
+
+	ref := image.ParseReference(plugin.Image)
+	ref.Tag, ref.Digest = "", digest
+	pinned := ref.String()
+
+	verifiedImages.Lock()
+	defer verifiedImages.Unlock()
+	if !verifiedImages.digests[pinned] {
+		if err := image.VerifySignature(plugin.Image, digest, key); err != nil {
+			return "", errors.Wrapf(err, "image %s", pinned)
+		}
+		verifiedImages.digests[pinned] = true
+	}
+	return pinned, nil
+}
+
+func loadPublicKey(path string) (crypto.PublicKey, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read trust public key")
+	}
+	return image.ParsePublicKey(data)
+}
+
+// Verification is the outcome of checking a plugin image's signature
+type Verification struct {
+	Plugin string
+	// Image is pinned to the verified digest, unpinned if verification failed
+	Image string
+	Err   error
+}
+
+// VerifyPlugins checks the signature of every plugin's pulled image against
+// the configured public key, whatever the trust policy
+func VerifyPlugins(docker *client.Docker, plugins []Plugin) ([]Verification, error) {
+	if config.Conf.Trust.PublicKey == "" {
+		return nil, fmt.Errorf("no trust.public_key configured")
+	}
+	verifications := make([]Verification, 0, len(plugins))
+	for _, plugin := range plugins {
+		pinned, err := plugin.verifyImage(docker, config.Conf.Trust.PublicKey)
+		if err != nil {
+			pinned = plugin.ImageRef()
+		}
+		verifications = append(verifications, Verification{Plugin: plugin.Name, Image: pinned, Err: err})
+	}
+	return verifications, nil
+}
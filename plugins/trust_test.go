@@ -0,0 +1,39 @@
+package plugins
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/maliceio/malice/config"
+)
+
+func TestVerifyImageWithoutKey(t *testing.T) {
+	saved := config.Conf.Trust
+	defer func() { config.Conf.Trust = saved }()
+	plugin := Plugin{Name: "avast", Image: "malice/avast", Version: "0.3.0"}
+
+	tests := []struct {
+		policy  string
+		want    string
+		wantErr string
+	}{
+		{policy: "", want: "malice/avast:0.3.0"},
+		{policy: TrustWarn, want: "malice/avast:0.3.0"},
+		{policy: "Enforce", wantErr: "requires trust.public_key"},
+		{policy: "strict", wantErr: "unknown trust policy"},
+	}
+
+	for _, tt := range tests {
+		config.Conf.Trust.Policy, config.Conf.Trust.PublicKey = tt.policy, ""
+		got, err := plugin.VerifyImage(nil)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("policy %q: VerifyImage() error = %v, want %q", tt.policy, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("policy %q: VerifyImage() = %q, %v, want %q", tt.policy, got, err, tt.want)
+		}
+	}
+}